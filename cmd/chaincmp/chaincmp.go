@@ -35,21 +35,37 @@ var (
 
 func main() {
 	err := run()
-	if err != nil {
-		switch err {
-		case errInvalidParameters:
-			showUsageAndExit()
-			os.Exit(2)
-		case errUserTermination:
-			os.Exit(130)
-		case errFork:
-			os.Exit(1)
-		case errUnavailable:
-			os.Exit(69)
-		case errFailure:
-			os.Exit(70)
-		}
+	if err == errInvalidParameters {
+		showUsageAndExit()
 	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode maps the result of run to the exit code of the utility.
+// Unexpected errors are reported as internal software error (EX_SOFTWARE).
+func exitCode(err error) int {
+	switch err {
+	case nil:
+		return 0
+	case errInvalidParameters:
+		return 2
+	case errUserTermination:
+		return 130
+	case errFork:
+		return 1
+	case errUnavailable:
+		return 69
+	default:
+		return 70
+	}
+}
+
+// fetchError returns the error to report on failure to fetch data from nodes.
+func fetchError(interrupt <-chan struct{}) error {
+	if interrupted(interrupt) {
+		return errUserTermination
+	}
+	return errUnavailable
 }
 
 func run() error {
@@ -59,6 +75,9 @@ func run() error {
 	var reference string
 	var verbose bool
 	var silent bool
+	var strict bool
+	var noDiff bool
+	var diffFile string
 
 	flag.StringVarP(&node, "node", "n", "", "URL of the node")
 	flag.StringVarP(&reference, "references", "r", defaultURL, "A list of space-separated URLs of reference nodes, for example \"http://127.0.0.1:6869 https://nodes.wavesnodes.com\"")
@@ -66,6 +85,9 @@ func run() error {
 	flag.BoolVarP(&showVersion, "version", "v", false, "Print version information and quit")
 	flag.BoolVar(&verbose, "verbose", false, "Logs additional information; incompatible with \"silent\"")
 	flag.BoolVar(&silent, "silent", false, "Produce no output except this help message; incompatible with \"verbose\"")
+	flag.BoolVar(&strict, "strict", false, "Treat any fork as failure, even a very short one that probably will be resolved automatically")
+	flag.BoolVar(&noDiff, "no-diff", false, "Do not compare the first divergent blocks of the node and a reference node")
+	flag.StringVar(&diffFile, "diff-file", "", "Path to the file to write the differences of the first divergent blocks as JSON")
 	flag.Parse()
 
 	if showHelp {
@@ -122,10 +144,7 @@ func run() error {
 	hs, err := heights(interrupt, clients)
 	if err != nil {
 		zap.S().Errorf("Failed to retrieve heights from all nodes: %s", err)
-		if interrupted(interrupt) {
-			return errUserTermination
-		}
-		return errUnavailable
+		return fetchError(interrupt)
 	}
 	for i, h := range hs {
 		zap.S().Debugf("%d: Height = %d", i, h)
@@ -137,10 +156,7 @@ func run() error {
 	ch, err := findLastCommonHeight(interrupt, clients, 1, stop)
 	if err != nil {
 		zap.S().Errorf("Failed to find last common height: %s", err)
-		if interrupted(interrupt) {
-			return errUserTermination
-		}
-		return err
+		return fetchError(interrupt)
 	}
 
 	h := hs[0]
@@ -158,9 +174,31 @@ func run() error {
 	case ch < h && ch < refLowest:
 		fl := h - ch
 		zap.S().Warnf("Node '%s' is on fork of length %d blocks since last common block at height %d", node, fl, ch)
+		if !noDiff {
+			d, err := diffDivergentBlocks(interrupt, urls, clients, hs, ch+1)
+			switch {
+			case err != nil:
+				zap.S().Errorf("Failed to compare divergent blocks: %s", err)
+				if interrupted(interrupt) {
+					return errUserTermination
+				}
+			default:
+				if !silent {
+					printDiff(os.Stdout, d)
+				}
+				if diffFile != "" {
+					if err := writeDiff(diffFile, d); err != nil {
+						zap.S().Errorf("Failed to save differences: %s", err)
+					}
+				}
+			}
+		}
 		switch {
 		case fl < 10:
 			zap.S().Infof("The fork is very short, highly likely the node is OK")
+			if strict {
+				return errFork
+			}
 			return nil
 		case fl < 100:
 			zap.S().Warn("The fork is short and possibly the node will rollback and switch on the correct fork automatically")
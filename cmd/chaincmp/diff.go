@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	requestTimeout   = 30 * time.Second
+	maxDiffAddresses = 100
+	notAvailable     = "n/a"
+)
+
+type fieldDiff struct {
+	Field     string `json:"field"`
+	Node      string `json:"node"`
+	Reference string `json:"reference"`
+}
+
+type transactionInfo struct {
+	ID        string `json:"id"`
+	Type      byte   `json:"type"`
+	Version   byte   `json:"version"`
+	Sender    string `json:"sender"`
+	Fee       uint64 `json:"fee"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+type transactionsDiff struct {
+	Common        int               `json:"common"`
+	OnlyNode      []transactionInfo `json:"only_node"`
+	OnlyReference []transactionInfo `json:"only_reference"`
+}
+
+type balanceDiff struct {
+	Address   string `json:"address"`
+	Node      uint64 `json:"node"`
+	Reference uint64 `json:"reference"`
+}
+
+type dataDiff struct {
+	Address   string `json:"address"`
+	Key       string `json:"key"`
+	Node      string `json:"node"`
+	Reference string `json:"reference"`
+}
+
+// blockDiff describes the differences between the first divergent blocks of the node and a reference node.
+// Balances are WAVES balances at the height of divergent blocks, data entries are the current values
+// of entries touched by Data transactions of the blocks, because nodes' API doesn't provide historical data.
+type blockDiff struct {
+	Height         int              `json:"height"`
+	NodeURL        string           `json:"node_url"`
+	NodeBlock      proto.BlockID    `json:"node_block"`
+	ReferenceURL   string           `json:"reference_url"`
+	ReferenceBlock proto.BlockID    `json:"reference_block"`
+	Headers        []fieldDiff      `json:"headers"`
+	Transactions   transactionsDiff `json:"transactions"`
+	Balances       []balanceDiff    `json:"balances"`
+	Data           []dataDiff       `json:"data"`
+}
+
+type side struct {
+	url    string
+	client *client.Client
+	height int
+	block  *client.Block
+}
+
+// diffDivergentBlocks fetches the blocks at the given height from the node and the first reference node that has
+// a different block at the height and compares them.
+func diffDivergentBlocks(interrupt <-chan struct{}, urls []string, clients []*client.Client, heights []int, height int) (*blockDiff, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	node := &side{url: urls[0], client: clients[0], height: heights[0]}
+	if err := node.fetchBlock(ctx, height); err != nil {
+		return nil, err
+	}
+	var reference *side
+	for i := 1; i < len(clients); i++ {
+		if heights[i] < height {
+			continue
+		}
+		s := &side{url: urls[i], client: clients[i], height: heights[i]}
+		if err := s.fetchBlock(ctx, height); err != nil {
+			return nil, err
+		}
+		if s.block.ID != node.block.ID {
+			reference = s
+			break
+		}
+	}
+	if reference == nil {
+		return nil, errors.Errorf("no reference node with different block at height %d", height)
+	}
+
+	d := &blockDiff{
+		Height:         height,
+		NodeURL:        node.url,
+		NodeBlock:      node.block.ID,
+		ReferenceURL:   reference.url,
+		ReferenceBlock: reference.block.ID,
+		Headers:        diffHeaders(&node.block.Headers, &reference.block.Headers),
+	}
+	td, err := diffTransactions(node.block, reference.block)
+	if err != nil {
+		return nil, err
+	}
+	d.Transactions = td
+	d.Balances, err = diffBalances(ctx, node, reference)
+	if err != nil {
+		return nil, err
+	}
+	d.Data, err = diffData(ctx, node, reference)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (s *side) fetchBlock(ctx context.Context, height int) error {
+	rctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	b, _, err := s.client.Blocks.At(rctx, uint64(height))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block at height %d from '%s'", height, s.url)
+	}
+	s.block = b
+	return nil
+}
+
+func (s *side) balance(ctx context.Context, address proto.Address) (uint64, error) {
+	rctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	confirmations := s.height - int(s.block.Height)
+	b, _, err := s.client.Addresses.BalanceAfterConfirmations(rctx, address, uint64(confirmations))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get balance of '%s' from '%s'", address.String(), s.url)
+	}
+	return b.Balance, nil
+}
+
+func (s *side) dataEntry(ctx context.Context, address proto.Address, key string) string {
+	rctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	e, _, err := s.client.Addresses.DataKey(rctx, address, key)
+	if err != nil {
+		zap.S().Debugf("Failed to get data entry '%s' of '%s' from '%s': %v", key, address.String(), s.url, err)
+		return notAvailable
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return notAvailable
+	}
+	return string(b)
+}
+
+func diffHeaders(node, reference *client.Headers) []fieldDiff {
+	r := make([]fieldDiff, 0)
+	add := func(field, a, b string) {
+		if a != b {
+			r = append(r, fieldDiff{Field: field, Node: a, Reference: b})
+		}
+	}
+	u := func(v uint64) string { return strconv.FormatUint(v, 10) }
+	add("id", node.ID.String(), reference.ID.String())
+	add("version", u(node.Version), u(reference.Version))
+	add("timestamp", u(node.Timestamp), u(reference.Timestamp))
+	add("reference", node.Reference.String(), reference.Reference.String())
+	add("base-target", u(node.NxtConsensus.BaseTarget), u(reference.NxtConsensus.BaseTarget))
+	add("generation-signature", node.NxtConsensus.GenerationSignature, reference.NxtConsensus.GenerationSignature)
+	add("features", fmt.Sprint(node.Features), fmt.Sprint(reference.Features))
+	add("generator", node.Generator.String(), reference.Generator.String())
+	add("signature", node.Signature.String(), reference.Signature.String())
+	add("blocksize", u(node.Blocksize), u(reference.Blocksize))
+	add("transactionCount", u(node.TransactionCount), u(reference.TransactionCount))
+	return r
+}
+
+func diffTransactions(node, reference *client.Block) (transactionsDiff, error) {
+	r := transactionsDiff{OnlyNode: make([]transactionInfo, 0), OnlyReference: make([]transactionInfo, 0)}
+	ni, err := transactionInfos(node)
+	if err != nil {
+		return r, err
+	}
+	ri, err := transactionInfos(reference)
+	if err != nil {
+		return r, err
+	}
+	ids := make(map[string]struct{}, len(ri))
+	for _, ti := range ri {
+		ids[ti.ID] = struct{}{}
+	}
+	common := make(map[string]struct{}, len(ni))
+	for _, ti := range ni {
+		if _, ok := ids[ti.ID]; ok {
+			common[ti.ID] = struct{}{}
+			continue
+		}
+		r.OnlyNode = append(r.OnlyNode, ti)
+	}
+	for _, ti := range ri {
+		if _, ok := common[ti.ID]; !ok {
+			r.OnlyReference = append(r.OnlyReference, ti)
+		}
+	}
+	r.Common = len(common)
+	return r, nil
+}
+
+func transactionInfos(b *client.Block) ([]transactionInfo, error) {
+	scheme := blockScheme(b)
+	r := make([]transactionInfo, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		id, err := tx.GetID(scheme)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get transaction ID")
+		}
+		ti := transactionInfo{
+			ID:        base58.Encode(id),
+			Type:      byte(tx.GetTypeInfo().Type),
+			Version:   tx.GetVersion(),
+			Fee:       tx.GetFee(),
+			Timestamp: tx.GetTimestamp(),
+		}
+		if a, ok := sender(scheme, tx); ok {
+			ti.Sender = a.String()
+		}
+		r[i] = ti
+	}
+	return r, nil
+}
+
+func diffBalances(ctx context.Context, node, reference *side) ([]balanceDiff, error) {
+	addresses := touchedAddresses(node.block, reference.block)
+	if len(addresses) > maxDiffAddresses {
+		zap.S().Warnf("Too many addresses touched by divergent blocks, only %d of %d will be compared", maxDiffAddresses, len(addresses))
+		addresses = addresses[:maxDiffAddresses]
+	}
+	r := make([]balanceDiff, 0)
+	for _, a := range addresses {
+		nb, err := node.balance(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		rb, err := reference.balance(ctx, a)
+		if err != nil {
+			return nil, err
+		}
+		if nb != rb {
+			r = append(r, balanceDiff{Address: a.String(), Node: nb, Reference: rb})
+		}
+	}
+	return r, nil
+}
+
+func diffData(ctx context.Context, node, reference *side) ([]dataDiff, error) {
+	type entryKey struct {
+		address proto.Address
+		key     string
+	}
+	keys := make(map[entryKey]struct{})
+	for _, b := range []*client.Block{node.block, reference.block} {
+		scheme := blockScheme(b)
+		for _, tx := range b.Transactions {
+			dtx, ok := tx.(*proto.DataWithProofs)
+			if !ok {
+				continue
+			}
+			a, err := proto.NewAddressFromPublicKey(scheme, dtx.SenderPK)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to build sender address")
+			}
+			for _, e := range dtx.Entries {
+				keys[entryKey{address: a, key: e.GetKey()}] = struct{}{}
+			}
+		}
+	}
+	r := make([]dataDiff, 0)
+	for k := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		nv := node.dataEntry(ctx, k.address, k.key)
+		rv := reference.dataEntry(ctx, k.address, k.key)
+		if nv != rv {
+			r = append(r, dataDiff{Address: k.address.String(), Key: k.key, Node: nv, Reference: rv})
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Address != r[j].Address {
+			return r[i].Address < r[j].Address
+		}
+		return r[i].Key < r[j].Key
+	})
+	return r, nil
+}
+
+// touchedAddresses returns the sorted list of generators, senders and recipients of transactions of the blocks.
+// Recipients given by aliases are omitted.
+func touchedAddresses(blocks ...*client.Block) []proto.Address {
+	m := make(map[proto.Address]struct{})
+	addRecipient := func(r proto.Recipient) {
+		if r.Address != nil {
+			m[*r.Address] = struct{}{}
+		}
+	}
+	for _, b := range blocks {
+		scheme := blockScheme(b)
+		m[b.Generator] = struct{}{}
+		for _, tx := range b.Transactions {
+			if a, ok := sender(scheme, tx); ok {
+				m[a] = struct{}{}
+			}
+			switch t := tx.(type) {
+			case *proto.Payment:
+				m[t.Recipient] = struct{}{}
+			case *proto.TransferWithSig:
+				addRecipient(t.Recipient)
+			case *proto.TransferWithProofs:
+				addRecipient(t.Recipient)
+			case *proto.MassTransferWithProofs:
+				for _, e := range t.Transfers {
+					addRecipient(e.Recipient)
+				}
+			case *proto.LeaseWithSig:
+				addRecipient(t.Recipient)
+			case *proto.LeaseWithProofs:
+				addRecipient(t.Recipient)
+			}
+		}
+	}
+	r := make([]proto.Address, 0, len(m))
+	for a := range m {
+		r = append(r, a)
+	}
+	sort.Slice(r, func(i, j int) bool { return r[i].String() < r[j].String() })
+	return r
+}
+
+func sender(scheme proto.Scheme, tx proto.Transaction) (proto.Address, bool) {
+	pk := tx.GetSenderPK()
+	if pk == (crypto.PublicKey{}) { // Genesis transactions have no sender
+		return proto.Address{}, false
+	}
+	a, err := proto.NewAddressFromPublicKey(scheme, pk)
+	if err != nil {
+		return proto.Address{}, false
+	}
+	return a, true
+}
+
+func blockScheme(b *client.Block) proto.Scheme {
+	return b.Generator[1]
+}
+
+func printDiff(w io.Writer, d *blockDiff) {
+	fmt.Fprintf(w, "First divergent blocks at height %d:\n", d.Height)
+	fmt.Fprintf(w, "  node:      %s %s\n", d.NodeURL, d.NodeBlock.String())
+	fmt.Fprintf(w, "  reference: %s %s\n", d.ReferenceURL, d.ReferenceBlock.String())
+	fmt.Fprintln(w, "Headers:")
+	for _, f := range d.Headers {
+		fmt.Fprintf(w, "  %s:\n    node:      %s\n    reference: %s\n", f.Field, f.Node, f.Reference)
+	}
+	fmt.Fprintf(w, "Transactions: %d common, %d only on node, %d only on reference\n", d.Transactions.Common, len(d.Transactions.OnlyNode), len(d.Transactions.OnlyReference))
+	printTransactions := func(prefix string, txs []transactionInfo) {
+		for _, t := range txs {
+			fmt.Fprintf(w, "  %s %s type %d v%d sender %s fee %d timestamp %d\n", prefix, t.ID, t.Type, t.Version, t.Sender, t.Fee, t.Timestamp)
+		}
+	}
+	printTransactions("-", d.Transactions.OnlyNode)
+	printTransactions("+", d.Transactions.OnlyReference)
+	if len(d.Balances) > 0 {
+		fmt.Fprintf(w, "WAVES balances at height %d:\n", d.Height)
+		for _, b := range d.Balances {
+			fmt.Fprintf(w, "  %s: node %d, reference %d\n", b.Address, b.Node, b.Reference)
+		}
+	}
+	if len(d.Data) > 0 {
+		fmt.Fprintln(w, "Current data entries:")
+		for _, e := range d.Data {
+			fmt.Fprintf(w, "  %s '%s':\n    node:      %s\n    reference: %s\n", e.Address, e.Key, e.Node, e.Reference)
+		}
+	}
+}
+
+func writeDiff(path string, d *blockDiff) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to create diff file")
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(d); err != nil {
+		return errors.Wrap(err, "failed to write diff")
+	}
+	return nil
+}
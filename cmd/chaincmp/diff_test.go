@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/client"
+)
+
+const (
+	testGenerator  = "3My3KZgFQ3CrVHgz6vGRt8687sH4oAA1qp8"
+	testSender     = "3Mv61qe6egMSjRDZiiuvJDnf3Q1qW9tTZDB"
+	testRecipient  = "3N5jhcA7R98AUN12ee9pB7unvnAKfzb3nen"
+	nodeSignature  = "2WKKGrsL4kyqWPST9ZL4if198V9qYP5NMa92rv9mxGW56iqhseqaQYv15A74ThwtwZC2idj8C5px1b35oyQLzUKt"
+	refSignature   = "4AjgBor9GpaMd7sRg7XDMpLrTZam23XMuh7rWqTFKAzTaK3h7gPbLJQQWfWG5dM8yoZjyNDFFoLLPth4esRBz94w"
+	testReference  = "5Vwh1KEGqiBVG9ExuSKZwgwSEPbiU6CxvqL7TmtbpXd1eLQd3G4barxB161qLC3sDoVkTGwrhZEFtCBLqaRde5jt"
+	testTransferID = "FYyDuMdFsJJinXcZhwdXvgnNgXKv7WnFiADxEAK2bE3j"
+)
+
+const testTransferJSON = `{
+  "type": 4,
+  "id": "FYyDuMdFsJJinXcZhwdXvgnNgXKv7WnFiADxEAK2bE3j",
+  "sender": "3Mv61qe6egMSjRDZiiuvJDnf3Q1qW9tTZDB",
+  "senderPublicKey": "FkoFqtAeibv2E6Y86ZDRfAkZz61LwUMjLAP2gmS1j7xe",
+  "fee": 189598,
+  "timestamp": 1485530441535,
+  "signature": "4AjgBor9GpaMd7sRg7XDMpLrTZam23XMuh7rWqTFKAzTaK3h7gPbLJQQWfWG5dM8yoZjyNDFFoLLPth4esRBz94w",
+  "version": 1,
+  "recipient": "3N5jhcA7R98AUN12ee9pB7unvnAKfzb3nen",
+  "assetId": null,
+  "feeAssetId": null,
+  "amount": 26,
+  "attachment": ""
+}`
+
+func blockJSON(signature string, height int, txs ...string) string {
+	return fmt.Sprintf(`{
+  "version": 2,
+  "timestamp": 1485530465594,
+  "reference": "%s",
+  "nxt-consensus": {"base-target": 450, "generation-signature": "AC94D2n1koQrY5NUtCHSfdeorxU213JNkLfJvRujmE1U"},
+  "generator": "%s",
+  "signature": "%s",
+  "id": "%s",
+  "blocksize": %d,
+  "transactionCount": %d,
+  "fee": 0,
+  "transactions": [%s],
+  "height": %d
+}`, testReference, testGenerator, signature, signature, 200+len(txs)*100, len(txs), strings.Join(txs, ","), height)
+}
+
+type testNode struct {
+	height   int
+	block    string
+	balances map[string]uint64
+	failing  bool
+}
+
+func (n *testNode) start(t *testing.T) (string, *client.Client) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if n.failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		p := r.URL.Path
+		switch {
+		case p == "/blocks/height":
+			fmt.Fprintf(w, `{"height": %d}`, n.height)
+		case strings.HasPrefix(p, "/blocks/at/"):
+			fmt.Fprint(w, n.block)
+		case strings.HasPrefix(p, "/addresses/balance/"):
+			a := strings.Split(strings.TrimPrefix(p, "/addresses/balance/"), "/")[0]
+			fmt.Fprintf(w, `{"address": "%s", "confirmations": 0, "balance": %d}`, a, n.balances[a])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	c, err := client.NewClient(client.Options{BaseUrl: s.URL, Client: s.Client()})
+	require.NoError(t, err)
+	return s.URL, c
+}
+
+func startNodes(t *testing.T, nodes ...*testNode) ([]string, []*client.Client, []int) {
+	urls := make([]string, len(nodes))
+	clients := make([]*client.Client, len(nodes))
+	heights := make([]int, len(nodes))
+	for i, n := range nodes {
+		urls[i], clients[i] = n.start(t)
+		heights[i] = n.height
+	}
+	return urls, clients, heights
+}
+
+func TestDiffDivergentBlocks(t *testing.T) {
+	node := &testNode{
+		height:   12,
+		block:    blockJSON(nodeSignature, 10, testTransferJSON),
+		balances: map[string]uint64{testGenerator: 100, testSender: 1000, testRecipient: 26},
+	}
+	same := &testNode{
+		height:   11,
+		block:    blockJSON(nodeSignature, 10, testTransferJSON),
+		balances: map[string]uint64{testGenerator: 100, testSender: 1000, testRecipient: 26},
+	}
+	reference := &testNode{
+		height:   15,
+		block:    blockJSON(refSignature, 10),
+		balances: map[string]uint64{testGenerator: 100, testSender: 1189624, testRecipient: 0},
+	}
+	urls, clients, heights := startNodes(t, node, same, reference)
+
+	d, err := diffDivergentBlocks(make(chan struct{}), urls, clients, heights, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, d.Height)
+	assert.Equal(t, urls[0], d.NodeURL)
+	assert.Equal(t, nodeSignature, d.NodeBlock.String())
+	assert.Equal(t, urls[2], d.ReferenceURL, "the first reference node with different block must be selected")
+	assert.Equal(t, refSignature, d.ReferenceBlock.String())
+
+	assert.ElementsMatch(t, []fieldDiff{
+		{Field: "id", Node: nodeSignature, Reference: refSignature},
+		{Field: "signature", Node: nodeSignature, Reference: refSignature},
+		{Field: "blocksize", Node: "300", Reference: "200"},
+		{Field: "transactionCount", Node: "1", Reference: "0"},
+	}, d.Headers)
+
+	assert.Equal(t, 0, d.Transactions.Common)
+	require.Len(t, d.Transactions.OnlyNode, 1)
+	assert.Empty(t, d.Transactions.OnlyReference)
+	tx := d.Transactions.OnlyNode[0]
+	assert.Equal(t, testTransferID, tx.ID)
+	assert.EqualValues(t, 4, tx.Type)
+	assert.Equal(t, testSender, tx.Sender)
+	assert.EqualValues(t, 189598, tx.Fee)
+
+	assert.Equal(t, []balanceDiff{
+		{Address: testSender, Node: 1000, Reference: 1189624},
+		{Address: testRecipient, Node: 26, Reference: 0},
+	}, d.Balances)
+	assert.Empty(t, d.Data)
+
+	var buf bytes.Buffer
+	printDiff(&buf, d)
+	out := buf.String()
+	assert.Contains(t, out, "First divergent blocks at height 10")
+	assert.Contains(t, out, "Transactions: 0 common, 1 only on node, 0 only on reference")
+	assert.Contains(t, out, "- "+testTransferID)
+	assert.Contains(t, out, testSender+": node 1000, reference 1189624")
+}
+
+func TestDiffDivergentBlocksCommonTransactions(t *testing.T) {
+	node := &testNode{height: 10, block: blockJSON(nodeSignature, 10, testTransferJSON)}
+	reference := &testNode{height: 10, block: blockJSON(refSignature, 10, testTransferJSON)}
+	urls, clients, heights := startNodes(t, node, reference)
+
+	d, err := diffDivergentBlocks(make(chan struct{}), urls, clients, heights, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Transactions.Common)
+	assert.Empty(t, d.Transactions.OnlyNode)
+	assert.Empty(t, d.Transactions.OnlyReference)
+	assert.Empty(t, d.Balances)
+}
+
+func TestDiffDivergentBlocksErrors(t *testing.T) {
+	node := &testNode{height: 10, block: blockJSON(nodeSignature, 10)}
+	same := &testNode{height: 10, block: blockJSON(nodeSignature, 10)}
+	behind := &testNode{height: 9, failing: true}
+	urls, clients, heights := startNodes(t, node, same, behind)
+	_, err := diffDivergentBlocks(make(chan struct{}), urls, clients, heights, 10)
+	assert.EqualError(t, err, "no reference node with different block at height 10")
+
+	failing := &testNode{height: 10, failing: true}
+	urls, clients, heights = startNodes(t, node, failing)
+	_, err = diffDivergentBlocks(make(chan struct{}), urls, clients, heights, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get block at height 10 from '"+urls[1]+"'")
+}
+
+func TestExitCode(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errFork, 1},
+		{errInvalidParameters, 2},
+		{errUnavailable, 69},
+		{errFailure, 70},
+		{fmt.Errorf("unexpected"), 70},
+		{errUserTermination, 130},
+	} {
+		assert.Equal(t, test.code, exitCode(test.err), "error: %v", test.err)
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	ok := &testNode{height: 10, block: blockJSON(nodeSignature, 10)}
+	failing := &testNode{height: 10, failing: true}
+	_, clients, _ := startNodes(t, ok, failing)
+	interrupt := make(chan struct{})
+
+	_, err := heights(interrupt, clients)
+	require.Error(t, err)
+	assert.Equal(t, errUnavailable, fetchError(interrupt))
+	assert.Equal(t, 69, exitCode(fetchError(interrupt)))
+
+	_, err = findLastCommonHeight(interrupt, clients, 1, 10)
+	require.Error(t, err)
+	assert.Equal(t, errUnavailable, fetchError(interrupt))
+
+	close(interrupt)
+	assert.Equal(t, errUserTermination, fetchError(interrupt))
+	assert.Equal(t, 130, exitCode(fetchError(interrupt)))
+}
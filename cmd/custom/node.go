@@ -11,6 +11,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/mr-tron/base58/base58"
 	"github.com/wavesplatform/gowaves/pkg/api"
 	"github.com/wavesplatform/gowaves/pkg/grpc/server"
 	"github.com/wavesplatform/gowaves/pkg/libs/bytespool"
@@ -24,11 +25,15 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/node/peer_manager"
 	"github.com/wavesplatform/gowaves/pkg/node/state_changed"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/p2p/recorder"
+	"github.com/wavesplatform/gowaves/pkg/p2p/secure"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/scoresender"
 	"github.com/wavesplatform/gowaves/pkg/services"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
+	"github.com/wavesplatform/gowaves/pkg/types"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 	"github.com/wavesplatform/gowaves/pkg/wallet"
 	"go.uber.org/zap"
@@ -47,12 +52,20 @@ var (
 	enableGrpcApi     = flag.Bool("enable-grpc-api", true, "Enables/disables gRPC API")
 	buildExtendedApi  = flag.Bool("build-extended-api", false, "Builds extended API. Note that state must be reimported in case it wasn't imported with similar flag set")
 	serveExtendedApi  = flag.Bool("serve-extended-api", false, "Serves extended API requests since the very beginning. The default behavior is to import until first block close to current time, and start serving at this point")
+	buildStateHashes  = flag.Bool("build-state-hashes", false, "Calculate and store state hashes for each block height. Note that state must be reimported in case it wasn't imported with similar flag set")
 	minerVoteFeatures = flag.String("vote", "", "Miner vote features")
 	reward            = flag.String("reward", "", "Miner reward: for example 600000000")
 	minerDelayParam   = flag.String("miner-delay", "4h", "Interval after last block then generation is allowed. example 1d4h30m")
 	walletPath        = flag.String("wallet-path", "", "Path to wallet, or ~/.waves by default")
 	walletPassword    = flag.String("wallet-password", "", "Pass password for wallet. Extremely insecure")
 	limitConnectionsS = flag.String("limit-connections", "30", "N incoming and outgoing connections")
+	disableNtp        = flag.Bool("disable-ntp", false, "Use local clock instead of NTP time, useful for local networks without Internet access")
+	devMode           = flag.Bool("dev-mode", false, "Development mode: blocks are produced on demand with REST API or as soon as transactions appear in UTX pool, node's time can be moved forward with REST API")
+	recordP2P         = flag.String("record-p2p", "", "Record connections and messages of peers to the given file for replay. Not set by default")
+	secureKey         = flag.String("secure-key", "", "Path to the node key file, enables the encrypted transport with the peers supporting it. The key is generated if the file doesn't exist")
+	secureAllow       = flag.String("secure-allow", "", "Comma separated base58 public node keys allowed over the encrypted transport, any key if empty. Non-empty list implies -secure-required")
+	secureRequired    = flag.Bool("secure-required", false, "Reject the peers that don't support the encrypted transport")
+	devMineInterval   = flag.Duration("dev-mine-interval", 100*time.Millisecond, "Interval of UTX pool checks in development mode, zero disables automatic mining of transactions")
 )
 
 func init() {
@@ -123,12 +136,6 @@ func main() {
 		}
 	}
 
-	ntptm, err := ntptime.TryNew("pool.ntp.org", 10)
-	if err != nil {
-		zap.S().Error(err)
-		return
-	}
-
 	minerDelaySecond, err := common.ParseDuration(*minerDelayParam)
 	if err != nil {
 		zap.S().Error(err)
@@ -137,11 +144,26 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	go ntptm.Run(ctx, 2*time.Minute)
+	var ntptm types.Time = ntptime.Stub{}
+	var devTime *ntptime.DevTime
+	if *devMode {
+		devTime = ntptime.NewDevTime()
+		ntptm = devTime
+	} else if !*disableNtp {
+		tm, err := ntptime.TryNew("pool.ntp.org", 10)
+		if err != nil {
+			zap.S().Error(err)
+			cancel()
+			return
+		}
+		go tm.Run(ctx, 2*time.Minute)
+		ntptm = tm
+	}
 
 	params := state.DefaultStateParams()
 	params.StoreExtendedApiData = *buildExtendedApi
 	params.ProvideExtendedApi = *serveExtendedApi
+	params.BuildStateHashes = *buildStateHashes
 	params.Time = ntptm
 	state, err := state.NewState(path, params, custom)
 	if err != nil {
@@ -178,22 +200,56 @@ func main() {
 
 	parent := peer.NewParent()
 
-	peerSpawnerImpl := peer_manager.NewPeerSpawner(btsPool, parent, conf.WavesNetwork, declAddr, "gowaves", uint64(rand.Int()), version)
+	var p2pRecorder peer.Recorder
+	if *recordP2P != "" {
+		w, err := recorder.Create(*recordP2P)
+		if err != nil {
+			zap.S().Error(err)
+			cancel()
+			return
+		}
+		defer func() {
+			if err := w.Close(); err != nil {
+				zap.S().Errorf("Failed to close P2P recording: %v", err)
+			}
+		}()
+		p2pRecorder = w
+	}
+
+	transport, err := secure.FromOptions(*secureKey, *secureAllow, *secureRequired)
+	if err != nil {
+		zap.S().Errorf("Failed to configure secure transport: %v", err)
+		cancel()
+		return
+	}
+	if transport != nil {
+		zap.S().Infof("Secure transport enabled, node key %s", base58.Encode(transport.PublicKey()))
+	}
+
+	peerSpawnerImpl := peer_manager.NewPeerSpawner(btsPool, parent, conf.WavesNetwork, declAddr, "gowaves", uint64(rand.Int()), version, p2pRecorder, transport)
 
 	peerManager := peer_manager.NewPeerManager(peerSpawnerImpl, state, int(limitConnections))
 	go peerManager.Run(ctx)
 
-	scheduler := scheduler2.NewScheduler(
-		state,
-		wal,
-		custom,
-		ntptm,
-		scheduler2.NewMinerConsensus(peerManager, 1),
-		proto.NewTimestampFromUSeconds(minerDelaySecond),
-	)
+	var scheduler *scheduler2.SchedulerImpl
+	if *devMode {
+		scheduler = scheduler2.NewManualScheduler(state, wal, custom, ntptm)
+	} else {
+		scheduler = scheduler2.NewScheduler(
+			state,
+			wal,
+			custom,
+			ntptm,
+			scheduler2.NewMinerConsensus(peerManager, 1),
+			proto.NewTimestampFromUSeconds(minerDelaySecond),
+		)
+	}
 
 	utx := utxpool.New(10000, utxpool.NewValidator(state, ntptm), custom)
 
+	async := runner.NewAsync()
+	logRunner := runner.NewLogRunner(async)
+
 	stateChanged := state_changed.NewStateChanged()
 	blockApplier := node.NewBlocksApplier(state, ntptm)
 
@@ -207,6 +263,9 @@ func main() {
 		BlockAddedNotifier: stateChanged,
 		Subscribe:          node.NewSubscribeService(),
 		InvRequester:       ng.NewInvRequester(),
+		LoggableRunner:     logRunner,
+		Time:               ntptm,
+		Wallet:             wal,
 	}
 
 	utxClean := utxpool.NewCleaner(services)
@@ -214,9 +273,6 @@ func main() {
 	ngState := ng.NewState(services)
 	ngRuntime := ng.NewRuntime(services, ngState)
 
-	Miner := miner.NewMicroblockMiner(services, ngRuntime, proto.CustomNetScheme, features, reward)
-
-	async := runner.NewAsync()
 	scoreSender := scoresender.New(peerManager, state, 4*time.Second, async)
 
 	stateChanged.AddHandler(state_changed.NewFuncHandler(func() {
@@ -232,8 +288,32 @@ func main() {
 	})
 	stateSync := node.NewStateSync(services, scoreSender, blockApplier)
 
-	go miner.Run(ctx, Miner, scheduler)
-	go scheduler.Reschedule()
+	var devMiner *miner.DevMiner
+	if *devMode {
+		devMiner = miner.NewDevMiner(services, ngRuntime, scheduler, devTime, features, reward)
+		if *devMineInterval > 0 {
+			go devMiner.Run(ctx, *devMineInterval)
+		}
+	} else {
+		Miner := miner.NewMicroblockMiner(services, ngRuntime, custom.AddressSchemeCharacter, features, reward)
+		go miner.Run(ctx, Miner, scheduler)
+		go scheduler.Reschedule()
+		// Mining is not allowed without connected peers, so in a fresh network, where no one has blocks
+		// to sync, schedule mining again as soon as the first peer is connected.
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+					if peerManager.ConnectedCount() > 0 {
+						scheduler.Reschedule()
+						return
+					}
+				}
+			}
+		}()
+	}
 
 	n := node.NewNode(services, declAddr, declAddr, ngRuntime, stateSync)
 
@@ -253,6 +333,18 @@ func main() {
 		cancel()
 		return
 	}
+	app.SetLiquid(ngState)
+
+	txTracker := txstatus.NewTracker(state, utx, ngState, custom.AddressSchemeCharacter)
+	utx.SetEvictionListener(txTracker)
+	async.Go(func() {
+		txTracker.Run(ctx)
+	})
+	app.SetTransactionTracker(txTracker)
+
+	if devMiner != nil {
+		app.EnableDevMode(devMiner)
+	}
 
 	webApi := api.NewNodeApi(app, state, n)
 	go func() {
@@ -268,6 +360,7 @@ func main() {
 		if err != nil {
 			zap.S().Errorf("Failed to create gRPC server: %v", err)
 		}
+		grpcServer.SetTransactionTracker(txTracker)
 		go func() {
 			err := grpcServer.Run(ctx, conf.GrpcAddr)
 			if err != nil {
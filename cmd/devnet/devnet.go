@@ -0,0 +1,270 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/cmd/devnet/internal"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBalance  = 100000000000000 // 1 000 000 WAVES
+	defaultFeatures = "1,2,3,4,5,6,7,8,9,10,11,12,13"
+	stopTimeout     = 30 * time.Second
+)
+
+var (
+	logLevel       = flag.String("log-level", "INFO", "Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	nodes          = flag.Int("nodes", 3, "Number of nodes in the network")
+	accounts       = flag.String("accounts", "", "Comma separated list of genesis accounts in form seed:balance. First accounts are used as nodes' miners. By default an account with 1 000 000 WAVES is created for each node")
+	directory      = flag.String("dir", "", "Directory to put network data into, by default a new temporary directory is created")
+	nodeBinary     = flag.String("node-bin", "", "Path to the custom node binary, by default it's looked up next to this binary and in PATH")
+	host           = flag.String("host", "127.0.0.1", "Host to bind nodes' ports on")
+	basePort       = flag.Int("base-port", 16860, "First port to use, each node takes three consecutive ports for P2P, REST and gRPC")
+	blockDelay     = flag.Duration("block-delay", 5*time.Second, "Average delay between blocks")
+	scheme         = flag.String("scheme", "E", "Address scheme byte of the network")
+	features       = flag.String("features", defaultFeatures, "Comma separated list of preactivated features")
+	walletPassword = flag.String("wallet-password", "devnet", "Password of generated wallets")
+	generateOnly   = flag.Bool("generate-only", false, "Only generate network configuration without starting nodes")
+	devMode        = flag.Bool("dev-mode", false, "Start the single node in development mode, blocks are produced on demand or as soon as transactions appear")
+)
+
+func init() {
+	common.SetupLogger(*logLevel)
+}
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	flag.Parse()
+
+	cfg, err := configFromArgs()
+	if err != nil {
+		zap.S().Error(err)
+		return 1
+	}
+	network, err := internal.Generate(*cfg, time.Now())
+	if err != nil {
+		zap.S().Errorf("Failed to generate network: %v", err)
+		return 1
+	}
+	zap.S().Infof("Network configuration generated in '%s'", cfg.Directory)
+	if *generateOnly {
+		report(network)
+		return 0
+	}
+
+	bin, err := lookupNodeBinary()
+	if err != nil {
+		zap.S().Error(err)
+		return 1
+	}
+	zap.S().Infof("Using node binary '%s'", bin)
+
+	var children []*child
+	defer func() {
+		stopAll(children)
+	}()
+	exited := make(chan *child, len(network.Nodes))
+	for i := range network.Nodes {
+		c, err := start(bin, network, i, *walletPassword, exited)
+		if err != nil {
+			zap.S().Errorf("Failed to start node '%s': %v", network.Nodes[i].Name, err)
+			return 1
+		}
+		children = append(children, c)
+	}
+	report(network)
+
+	gracefulStop := make(chan os.Signal, 1)
+	signal.Notify(gracefulStop, syscall.SIGTERM, syscall.SIGINT)
+	select {
+	case sig := <-gracefulStop:
+		zap.S().Infow("Caught signal, stopping", "signal", sig)
+		return 0
+	case c := <-exited:
+		zap.S().Errorf("Node '%s' exited unexpectedly: %v, see '%s' for details", c.node.Name, c.err, c.node.LogPath)
+		return 1
+	}
+}
+
+func configFromArgs() (*internal.Config, error) {
+	if *devMode && *nodes != 1 {
+		return nil, errors.New("development mode requires single node network, use -nodes 1")
+	}
+	if len(*scheme) != 1 {
+		return nil, errors.Errorf("invalid scheme '%s', single character expected", *scheme)
+	}
+	var acs []internal.Account
+	if *accounts != "" {
+		var err error
+		acs, err = internal.ParseAccounts(*accounts)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		acs = internal.DefaultAccounts(*nodes, defaultBalance)
+	}
+	fs, err := parseFeatures(*features)
+	if err != nil {
+		return nil, err
+	}
+	dir := *directory
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "devnet")
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create temporary directory")
+		}
+	}
+	return &internal.Config{
+		Directory:      dir,
+		Nodes:          *nodes,
+		Accounts:       acs,
+		Scheme:         (*scheme)[0],
+		BlockDelay:     *blockDelay,
+		Features:       fs,
+		Host:           *host,
+		BasePort:       *basePort,
+		WalletPassword: *walletPassword,
+	}, nil
+}
+
+func parseFeatures(s string) ([]int16, error) {
+	var r []int16
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(f, 10, 16)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid feature '%s'", f)
+		}
+		r = append(r, int16(v))
+	}
+	return r, nil
+}
+
+func lookupNodeBinary() (string, error) {
+	if *nodeBinary != "" {
+		return *nodeBinary, nil
+	}
+	name := "custom"
+	if exe, err := os.Executable(); err == nil {
+		p := filepath.Join(filepath.Dir(exe), name)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	p, err := exec.LookPath(name)
+	if err != nil {
+		return "", errors.New("custom node binary not found, build it with 'go build ./cmd/custom' and provide the path with -node-bin option")
+	}
+	return p, nil
+}
+
+type child struct {
+	node internal.Node
+	cmd  *exec.Cmd
+	log  *os.File
+	done chan struct{}
+	err  error
+}
+
+func start(bin string, network *internal.Network, i int, password string, exited chan<- *child) (*child, error) {
+	node := network.Nodes[i]
+	log, err := os.OpenFile(node.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open log file")
+	}
+	args := []string{
+		"-state-path", node.StatePath,
+		"-cfg-path", network.SettingsPath,
+		"-wallet-path", node.WalletPath,
+		"-wallet-password", password,
+		"-declared-address", node.P2PAddress,
+		"-api-address", node.APIAddress,
+		"-grpc-address", node.GRPCAddress,
+		"-peers", strings.Join(network.Peers(i), ","),
+		"-log-level", *logLevel,
+		"-disable-ntp",
+	}
+	if *devMode {
+		args = append(args, "-dev-mode")
+	}
+	cmd := exec.Command(bin, args...)
+	cmd.Stdout = log
+	cmd.Stderr = log
+	if err := cmd.Start(); err != nil {
+		_ = log.Close()
+		return nil, err
+	}
+	c := &child{node: node, cmd: cmd, log: log, done: make(chan struct{})}
+	go func() {
+		c.err = cmd.Wait()
+		_ = c.log.Close()
+		close(c.done)
+		exited <- c
+	}()
+	zap.S().Infof("Node '%s' started with PID %d", node.Name, cmd.Process.Pid)
+	return c, nil
+}
+
+func stopAll(children []*child) {
+	for _, c := range children {
+		select {
+		case <-c.done:
+			continue
+		default:
+		}
+		if err := c.cmd.Process.Signal(os.Interrupt); err != nil {
+			zap.S().Warnf("Failed to interrupt node '%s': %v", c.node.Name, err)
+		}
+	}
+	deadline := time.NewTimer(stopTimeout)
+	defer deadline.Stop()
+	expired := false
+	for _, c := range children {
+		if !expired {
+			select {
+			case <-c.done:
+				continue
+			case <-deadline.C:
+				expired = true
+			}
+		}
+		select {
+		case <-c.done:
+		default:
+			zap.S().Warnf("Node '%s' did not stop in time, killing", c.node.Name)
+			_ = c.cmd.Process.Kill()
+			<-c.done
+		}
+	}
+}
+
+func report(network *internal.Network) {
+	fmt.Printf("Settings: %s\n", network.SettingsPath)
+	fmt.Println("Nodes:")
+	for _, n := range network.Nodes {
+		fmt.Printf("  %s: P2P %s, REST http://%s, gRPC %s, miner %s, log %s\n", n.Name, n.P2PAddress, n.APIAddress, n.GRPCAddress, n.Miner.String(), n.LogPath)
+	}
+	fmt.Println("Accounts:")
+	for _, a := range network.Accounts {
+		fmt.Printf("  %s: seed '%s', balance %d\n", a.Address.String(), a.Seed, a.Balance)
+	}
+}
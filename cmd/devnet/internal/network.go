@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/util/genesis_generator"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+const (
+	SettingsFile = "settings.json"
+	NetworkFile  = "devnet.json"
+	walletFile   = "wallet.dat"
+	stateDir     = "state"
+	logFile      = "node.log"
+
+	portsPerNode = 3
+)
+
+// Account is an account that receives WAVES in the genesis block.
+type Account struct {
+	Seed    string        `json:"seed"`
+	Balance uint64        `json:"balance"`
+	Address proto.Address `json:"address"`
+}
+
+// ParseAccounts parses the comma separated list of accounts in form `seed:balance`.
+func ParseAccounts(s string) ([]Account, error) {
+	var r []Account
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := strings.LastIndex(item, ":")
+		if i <= 0 {
+			return nil, errors.Errorf("invalid account '%s', expected format is seed:balance", item)
+		}
+		balance, err := strconv.ParseUint(item[i+1:], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid balance of account '%s'", item)
+		}
+		if balance == 0 || balance > math.MaxInt64 {
+			return nil, errors.Errorf("invalid balance of account '%s'", item)
+		}
+		r = append(r, Account{Seed: item[:i], Balance: balance})
+	}
+	return r, nil
+}
+
+// DefaultAccounts returns the accounts of n miners with the given balance each.
+func DefaultAccounts(n int, balance uint64) []Account {
+	r := make([]Account, n)
+	for i := range r {
+		r[i] = Account{Seed: fmt.Sprintf("devnet-node-%d", i+1), Balance: balance}
+	}
+	return r
+}
+
+// Config describes the network to generate.
+type Config struct {
+	Directory      string
+	Nodes          int
+	Accounts       []Account // First Nodes accounts are used as miners
+	Scheme         proto.Scheme
+	BlockDelay     time.Duration
+	Features       []int16
+	Host           string
+	BasePort       int
+	WalletPassword string
+}
+
+// Node describes a node of the generated network.
+type Node struct {
+	Name        string        `json:"name"`
+	Directory   string        `json:"directory"`
+	StatePath   string        `json:"state_path"`
+	WalletPath  string        `json:"wallet_path"`
+	LogPath     string        `json:"log_path"`
+	Miner       proto.Address `json:"miner"`
+	P2PAddress  string        `json:"p2p_address"`
+	APIAddress  string        `json:"api_address"`
+	GRPCAddress string        `json:"grpc_address"`
+}
+
+// Network is the description of generated network, it's stored in the NetworkFile in the network directory.
+type Network struct {
+	Scheme       proto.Scheme `json:"scheme"`
+	SettingsPath string       `json:"settings_path"`
+	Accounts     []Account    `json:"accounts"`
+	Nodes        []Node       `json:"nodes"`
+}
+
+// Generate creates the genesis block, blockchain settings and wallets of the network's nodes in the configured directory.
+func Generate(cfg Config, ts time.Time) (*Network, error) {
+	if cfg.Nodes <= 0 {
+		return nil, errors.New("number of nodes should be positive")
+	}
+	if len(cfg.Accounts) < cfg.Nodes {
+		return nil, errors.Errorf("not enough accounts for %d miners, only %d provided", cfg.Nodes, len(cfg.Accounts))
+	}
+	if cfg.BlockDelay < time.Second {
+		return nil, errors.New("block delay should be at least one second")
+	}
+	if cfg.BasePort <= 0 || cfg.BasePort+cfg.Nodes*portsPerNode > math.MaxUint16 {
+		return nil, errors.Errorf("invalid base port %d", cfg.BasePort)
+	}
+	for _, f := range cfg.Features {
+		info, ok := settings.FeaturesInfo[settings.Feature(f)]
+		if !ok || !info.Implemented {
+			return nil, errors.Errorf("unknown or not implemented feature %d", f)
+		}
+	}
+	if err := os.MkdirAll(cfg.Directory, 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create network directory")
+	}
+
+	n := &Network{Scheme: cfg.Scheme, SettingsPath: filepath.Join(cfg.Directory, SettingsFile)}
+	args := make([]interface{}, 0, 2*len(cfg.Accounts))
+	for _, a := range cfg.Accounts {
+		kp := proto.MustKeyPair([]byte(a.Seed))
+		addr, err := kp.Addr(cfg.Scheme)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create address of account '%s'", a.Seed)
+		}
+		a.Address = addr
+		n.Accounts = append(n.Accounts, a)
+		args = append(args, kp, int(a.Balance))
+	}
+	genesis, err := genesis_generator.Generate(proto.NewTimestampFromTime(ts), cfg.Scheme, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate genesis block")
+	}
+	if err := writeSettings(n.SettingsPath, newSettings(cfg, genesis)); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < cfg.Nodes; i++ {
+		name := fmt.Sprintf("node-%d", i+1)
+		dir := filepath.Join(cfg.Directory, name)
+		port := cfg.BasePort + i*portsPerNode
+		node := Node{
+			Name:        name,
+			Directory:   dir,
+			StatePath:   filepath.Join(dir, stateDir),
+			WalletPath:  filepath.Join(dir, walletFile),
+			LogPath:     filepath.Join(dir, logFile),
+			Miner:       n.Accounts[i].Address,
+			P2PAddress:  net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+			APIAddress:  net.JoinHostPort(cfg.Host, strconv.Itoa(port+1)),
+			GRPCAddress: net.JoinHostPort(cfg.Host, strconv.Itoa(port+2)),
+		}
+		if err := os.MkdirAll(node.StatePath, 0755); err != nil {
+			return nil, errors.Wrapf(err, "failed to create directory of node '%s'", name)
+		}
+		if err := writeWallet(node.WalletPath, cfg.Accounts[i].Seed, cfg.WalletPassword); err != nil {
+			return nil, err
+		}
+		n.Nodes = append(n.Nodes, node)
+	}
+	if err := n.save(filepath.Join(cfg.Directory, NetworkFile)); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// Peers returns the P2P addresses of all nodes except the given one.
+func (n *Network) Peers(node int) []string {
+	r := make([]string, 0, len(n.Nodes)-1)
+	for i, other := range n.Nodes {
+		if i != node {
+			r = append(r, other.P2PAddress)
+		}
+	}
+	return r
+}
+
+func (n *Network) save(path string) error {
+	b, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal network description")
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return errors.Wrap(err, "failed to write network description")
+	}
+	return nil
+}
+
+func newSettings(cfg Config, genesis *proto.Block) *settings.BlockchainSettings {
+	s := *settings.DefaultCustomSettings
+	s.AddressSchemeCharacter = cfg.Scheme
+	s.AverageBlockDelaySeconds = uint64(cfg.BlockDelay / time.Second)
+	s.PreactivatedFeatures = cfg.Features
+	s.FeaturesVotingPeriod = 100
+	s.VotesForFeatureActivation = 40
+	s.DoubleFeaturesPeriodsAfterHeight = math.MaxInt32
+	s.BlockRewardTerm = 100000
+	s.InitialBlockReward = 600000000
+	s.BlockRewardIncrement = 50000000
+	s.BlockRewardVotingPeriod = 10000
+	s.Genesis = *genesis
+	return &s
+}
+
+func writeSettings(path string, s *settings.BlockchainSettings) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal blockchain settings")
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return errors.Wrap(err, "failed to write blockchain settings")
+	}
+	return nil
+}
+
+func writeWallet(path, seed, password string) error {
+	w := wallet.NewWallet()
+	if err := w.AddSeed([]byte(seed)); err != nil {
+		return errors.Wrap(err, "failed to add seed to wallet")
+	}
+	b, err := w.Encode([]byte(password))
+	if err != nil {
+		return errors.Wrap(err, "failed to encode wallet")
+	}
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		return errors.Wrap(err, "failed to write wallet")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+func TestParseAccounts(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		accounts []Account
+		err      bool
+	}{
+		{"", nil, false},
+		{"alice:100", []Account{{Seed: "alice", Balance: 100}}, false},
+		{" alice:100 , bob:200,", []Account{{Seed: "alice", Balance: 100}, {Seed: "bob", Balance: 200}}, false},
+		{"seed:with:colons:300", []Account{{Seed: "seed:with:colons", Balance: 300}}, false},
+		{"alice", nil, true},
+		{":100", nil, true},
+		{"alice:", nil, true},
+		{"alice:0", nil, true},
+		{"alice:-1", nil, true},
+		{"alice:18446744073709551615", nil, true},
+	} {
+		accounts, err := ParseAccounts(tc.s)
+		if tc.err {
+			assert.Error(t, err, tc.s)
+			continue
+		}
+		require.NoError(t, err, tc.s)
+		assert.Equal(t, tc.accounts, accounts, tc.s)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "devnet")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	accounts := append(DefaultAccounts(2, 100000000000000), Account{Seed: "user", Balance: 500000000})
+	cfg := Config{
+		Directory:      dir,
+		Nodes:          2,
+		Accounts:       accounts,
+		Scheme:         'D',
+		BlockDelay:     5 * time.Second,
+		Features:       []int16{1, 2},
+		Host:           "127.0.0.1",
+		BasePort:       20000,
+		WalletPassword: "secret",
+	}
+	n, err := Generate(cfg, time.Now())
+	require.NoError(t, err)
+
+	f, err := os.Open(n.SettingsPath)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, f.Close())
+	}()
+	s, err := settings.ReadBlockchainSettings(f)
+	require.NoError(t, err)
+	assert.Equal(t, settings.Custom, s.Type)
+	assert.Equal(t, proto.Scheme('D'), s.AddressSchemeCharacter)
+	assert.Equal(t, uint64(5), s.AverageBlockDelaySeconds)
+	assert.Equal(t, []int16{1, 2}, s.PreactivatedFeatures)
+	require.Len(t, s.Genesis.Transactions, 3)
+	for i, tx := range s.Genesis.Transactions {
+		g, ok := tx.(*proto.Genesis)
+		require.True(t, ok)
+		assert.Equal(t, n.Accounts[i].Address, g.Recipient)
+		assert.Equal(t, accounts[i].Balance, g.Amount)
+	}
+
+	require.Len(t, n.Nodes, 2)
+	assert.Equal(t, "127.0.0.1:20000", n.Nodes[0].P2PAddress)
+	assert.Equal(t, "127.0.0.1:20001", n.Nodes[0].APIAddress)
+	assert.Equal(t, "127.0.0.1:20002", n.Nodes[0].GRPCAddress)
+	assert.Equal(t, "127.0.0.1:20003", n.Nodes[1].P2PAddress)
+	assert.Equal(t, []string{"127.0.0.1:20003"}, n.Peers(0))
+	for i, node := range n.Nodes {
+		assert.Equal(t, n.Accounts[i].Address, node.Miner)
+		b, err := ioutil.ReadFile(node.WalletPath)
+		require.NoError(t, err)
+		w, err := wallet.Decode(b, []byte("secret"))
+		require.NoError(t, err)
+		assert.Equal(t, [][]byte{[]byte(accounts[i].Seed)}, w.Seeds())
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, NetworkFile))
+	require.NoError(t, err)
+	var saved Network
+	require.NoError(t, json.Unmarshal(b, &saved))
+	assert.Equal(t, *n, saved)
+}
+
+func TestGenerateErrors(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "devnet")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	valid := Config{
+		Directory:  dir,
+		Nodes:      2,
+		Accounts:   DefaultAccounts(2, 100000000000000),
+		Scheme:     'D',
+		BlockDelay: time.Second,
+		Host:       "127.0.0.1",
+		BasePort:   20000,
+	}
+	cfg := valid
+	cfg.Nodes = 3
+	_, err = Generate(cfg, time.Now())
+	assert.Error(t, err)
+	cfg = valid
+	cfg.BlockDelay = 100 * time.Millisecond
+	_, err = Generate(cfg, time.Now())
+	assert.Error(t, err)
+	cfg = valid
+	cfg.Features = []int16{int16(settings.LeaseExpiration)}
+	_, err = Generate(cfg, time.Now())
+	assert.Error(t, err)
+	cfg = valid
+	cfg.BasePort = 65534
+	_, err = Generate(cfg, time.Now())
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"os"
@@ -39,6 +40,12 @@ type configuration struct {
 	seedPeers      []net.TCPAddr
 	cpuProfileFile *os.File
 	memProfileFile *os.File
+	alertRules     internal.AlertRules
+	alertSinks     []internal.NotificationSink
+	alertInterval  time.Duration
+	exportHeight   int
+	exportFormat   string
+	exportFile     string
 }
 
 func main() {
@@ -98,6 +105,10 @@ func run() error {
 		return err
 	}
 
+	if cfg.exportHeight > 0 {
+		return exportTree(cfg, reg, drawer, storage)
+	}
+
 	api, err := internal.NewAPI(interrupt, storage, reg, drawer, cfg.apiBind)
 	if err != nil {
 		zap.S().Errorf("Failed to create API server: %v", err)
@@ -124,8 +135,23 @@ func run() error {
 	dispatcher := internal.NewDispatcher(distributorDone, cfg.netBind, opts, reg)
 	dispatcherDone := dispatcher.Start()
 
+	var alerterDone <-chan struct{}
+	if len(cfg.alertSinks) > 0 {
+		alerter, err := internal.NewAlerter(interrupt, reg, drawer, cfg.alertRules, cfg.alertSinks, cfg.alertInterval)
+		if err != nil {
+			zap.S().Errorf("Failed to instantiate alerter: %v", err)
+			return err
+		}
+		alerterDone = alerter.Start()
+	}
+
 	<-interrupt
 
+	if alerterDone != nil {
+		<-alerterDone
+		zap.S().Debug("Alerter shutdown complete")
+	}
+
 	<-apiDone
 	zap.S().Debug("API shutdown complete")
 	<-dispatcherDone
@@ -173,8 +199,17 @@ func parseConfiguration() (*configuration, error) {
 		seedPeers       = flag.String("seed-peers",
 			"13.228.86.201:6868 13.229.0.149:6868 18.195.170.147:6868 34.253.153.4:6868 35.156.19.4:6868 52.50.69.247:6868 52.52.46.76:6868 52.57.147.71:6868 52.214.55.18:6868 54.176.190.226:6868",
 			"Space separated list of public peers for initial connection. Defaults to MainNet's public peers.")
-		cpuProfilePath = flag.String("cpu-profile", "", "Write CPU profile to the file.")
-		memProfilePath = flag.String("mem-profile", "", "Write memory profile to the file.")
+		cpuProfilePath  = flag.String("cpu-profile", "", "Write CPU profile to the file.")
+		memProfilePath  = flag.String("mem-profile", "", "Write memory profile to the file.")
+		alertForkLength = flag.Int("alert-fork-length", 0, "Send notification if a fork is longer than the given number of blocks. Default value is 0 (disabled).")
+		alertMinority   = flag.Float64("alert-minority-share", 0, "Send notification if more than the given percentage of peers is on a minority fork. Default value is 0 (disabled).")
+		alertNodes      = flag.String("alert-nodes", "", "Space separated list of IP addresses of nodes to send notification about if they diverged from the longest fork. Empty by default.")
+		alertWebhooks   = flag.String("alert-webhooks", "", "Space separated list of URLs to POST notifications on forks to. Empty by default.")
+		alertFile       = flag.String("alert-file", "", "Path to the file to append notifications on forks to. No default value.")
+		alertInterval   = flag.Int("alert-interval", 10, "Interval of forks checks for notifications, seconds. Default value is 10 seconds.")
+		exportTree      = flag.Int("export-tree", 0, "Export the tree of blocks from the given height up to the last blocks of all known peers and exit. Default value is 0 (disabled).")
+		exportFormat    = flag.String("export-format", "dot", "Format of the exported tree of blocks, \"dot\" or \"json\". Default value is \"dot\".")
+		exportFile      = flag.String("export-file", "", "Path to the file to export the tree of blocks to. Required to export the tree.")
 	)
 	flag.Parse()
 	if *db == "" {
@@ -221,6 +256,35 @@ func parseConfiguration() (*configuration, error) {
 		}
 	}
 
+	if *exportTree < 0 {
+		return nil, errors.Errorf("invalid export height %d", *exportTree)
+	}
+	if *exportFormat != internal.TreeFormatDOT && *exportFormat != internal.TreeFormatJSON {
+		return nil, errors.Errorf("invalid export format '%s'", *exportFormat)
+	}
+	if *exportTree > 0 && *exportFile == "" {
+		return nil, errors.New("no export file")
+	}
+
+	rules := internal.AlertRules{ForkLength: *alertForkLength, MinorityShare: *alertMinority}
+	for _, a := range strings.Fields(*alertNodes) {
+		ip := net.ParseIP(a)
+		if ip == nil {
+			return nil, errors.Errorf("invalid IP address of node to watch '%s'", a)
+		}
+		rules.WatchedNodes = append(rules.WatchedNodes, ip)
+	}
+	sinks := make([]internal.NotificationSink, 0)
+	for _, u := range strings.Fields(*alertWebhooks) {
+		sinks = append(sinks, internal.NewWebhookSink(u))
+	}
+	if *alertFile != "" {
+		sinks = append(sinks, internal.NewFileSink(*alertFile))
+	}
+	if !rules.Empty() && len(sinks) == 0 {
+		return nil, errors.New("alert rules are set but no notification sinks, use -alert-webhooks or -alert-file")
+	}
+
 	cfg := &configuration{
 		dbPath:         *db,
 		logLevel:       *logLevel,
@@ -236,10 +300,54 @@ func parseConfiguration() (*configuration, error) {
 		publicAddress:  net.TCPAddr(addr),
 		cpuProfileFile: cpuProf,
 		memProfileFile: memProf,
+		alertRules:     rules,
+		alertSinks:     sinks,
+		alertInterval:  time.Duration(*alertInterval) * time.Second,
+		exportHeight:   *exportTree,
+		exportFormat:   *exportFormat,
+		exportFile:     *exportFile,
 	}
 	return cfg, nil
 }
 
+type treeExporter interface {
+	ExportTree(w io.Writer, from int, addresses []net.IP, format string) error
+}
+
+func exportTree(cfg *configuration, reg *internal.Registry, drawer treeExporter, storage io.Closer) error {
+	defer func() {
+		if err := storage.Close(); err != nil {
+			zap.S().Errorf("Failed to close the storage: %v", err)
+		}
+	}()
+	peers, err := reg.Peers()
+	if err != nil {
+		zap.S().Errorf("Failed to collect peers: %v", err)
+		return err
+	}
+	ips := make([]net.IP, len(peers))
+	for i, p := range peers {
+		ips[i] = p.Address
+	}
+	f, err := os.Create(cfg.exportFile)
+	if err != nil {
+		zap.S().Errorf("Failed to create export file: %v", err)
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			zap.S().Errorf("Failed to close export file: %v", err)
+		}
+	}()
+	err = drawer.ExportTree(f, cfg.exportHeight, ips, cfg.exportFormat)
+	if err != nil {
+		zap.S().Errorf("Failed to export tree of blocks: %v", err)
+		return err
+	}
+	zap.S().Infof("Tree of blocks from height %d exported to '%s'", cfg.exportHeight, cfg.exportFile)
+	return nil
+}
+
 func splitPeers(s string) ([]net.TCPAddr, error) {
 	sp := strings.Fields(s)
 	r := make([]net.TCPAddr, 0)
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	forkLengthRule    = "fork-length"
+	minorityShareRule = "minority-share"
+	nodeDivergedRule  = "node-diverged"
+
+	alertFired    = "fired"
+	alertResolved = "resolved"
+
+	webhookTimeout = 10 * time.Second
+)
+
+// AlertRules is the set of conditions on forks that trigger notifications. Zero values disable the corresponding rule.
+type AlertRules struct {
+	ForkLength    int      // Fire if a fork is longer than the given number of blocks
+	MinorityShare float64  // Fire if more than the given percentage of peers is on a minority fork
+	WatchedNodes  []net.IP // Fire if any of the given nodes is not on the longest fork
+}
+
+// Empty tells that no rule is set.
+func (r AlertRules) Empty() bool {
+	return r.ForkLength <= 0 && r.MinorityShare <= 0 && len(r.WatchedNodes) == 0
+}
+
+// Notification is the structured message sent to the sinks on alert firing or resolution.
+type Notification struct {
+	Event     string    `json:"event"`
+	Rule      string    `json:"rule"`
+	Key       string    `json:"key"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+	Fork      *Fork     `json:"fork,omitempty"`
+	Node      net.IP    `json:"node,omitempty"`
+}
+
+type alert struct {
+	rule    string
+	key     string
+	message string
+	fork    *Fork
+	node    net.IP
+}
+
+func (a alert) notification(event string, ts time.Time) Notification {
+	return Notification{Event: event, Rule: a.rule, Key: a.key, Message: a.message, Timestamp: ts, Fork: a.fork, Node: a.node}
+}
+
+// evaluate applies the rules to the forks and returns the alerts that match.
+// The keys of alerts are stable while the fork exists, so they are used for deduplication.
+func evaluate(rules AlertRules, forks []Fork) []alert {
+	r := make([]alert, 0)
+	total := 0
+	for _, f := range forks {
+		total += len(f.Peers)
+	}
+	for i := range forks {
+		f := forks[i]
+		if f.Longest {
+			continue
+		}
+		if rules.ForkLength > 0 && f.Length > rules.ForkLength {
+			r = append(r, alert{
+				rule:    forkLengthRule,
+				key:     fmt.Sprintf("%s:%s", forkLengthRule, f.LastCommonBlock.String()),
+				message: fmt.Sprintf("Fork of %d blocks since block '%s' at height %d, limit is %d blocks", f.Length, f.LastCommonBlock.String(), f.LastCommonHeight, rules.ForkLength),
+				fork:    &f,
+			})
+		}
+		if rules.MinorityShare > 0 && total > 0 {
+			share := float64(len(f.Peers)) * 100 / float64(total)
+			if share > rules.MinorityShare {
+				r = append(r, alert{
+					rule:    minorityShareRule,
+					key:     fmt.Sprintf("%s:%s", minorityShareRule, f.LastCommonBlock.String()),
+					message: fmt.Sprintf("%.2f%% of peers (%d of %d) are on the fork since block '%s' at height %d, limit is %.2f%%", share, len(f.Peers), total, f.LastCommonBlock.String(), f.LastCommonHeight, rules.MinorityShare),
+					fork:    &f,
+				})
+			}
+		}
+		for _, n := range rules.WatchedNodes {
+			for _, p := range f.Peers {
+				if p.Peer.Equal(n) {
+					r = append(r, alert{
+						rule:    nodeDivergedRule,
+						key:     fmt.Sprintf("%s:%s", nodeDivergedRule, n.String()),
+						message: fmt.Sprintf("Node %s diverged from the longest fork at block '%s' at height %d", n.String(), f.LastCommonBlock.String(), f.LastCommonHeight),
+						fork:    &f,
+						node:    n,
+					})
+				}
+			}
+		}
+	}
+	return r
+}
+
+// alertsTracker keeps the active alerts to fire notifications only on changes.
+type alertsTracker struct {
+	active map[string]alert
+}
+
+func newAlertsTracker() *alertsTracker {
+	return &alertsTracker{active: make(map[string]alert)}
+}
+
+// update returns notifications about new alerts and about resolution of active alerts that are not matched anymore.
+func (t *alertsTracker) update(alerts []alert, ts time.Time) []Notification {
+	r := make([]Notification, 0)
+	matched := make(map[string]struct{}, len(alerts))
+	for _, a := range alerts {
+		if _, ok := matched[a.key]; ok {
+			continue
+		}
+		matched[a.key] = struct{}{}
+		if _, ok := t.active[a.key]; !ok {
+			r = append(r, a.notification(alertFired, ts))
+		}
+		t.active[a.key] = a
+	}
+	for k, a := range t.active {
+		if _, ok := matched[k]; !ok {
+			r = append(r, a.notification(alertResolved, ts))
+			delete(t.active, k)
+		}
+	}
+	return r
+}
+
+// NotificationSink delivers notifications to the external system.
+type NotificationSink interface {
+	Send(n Notification) error
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) NotificationSink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (s *webhookSink) Send(n Notification) error {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification")
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return errors.Wrapf(err, "failed to send notification to '%s'", s.url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook '%s' responded with status %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+type fileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink creates the sink that appends notifications to the file as JSON lines.
+func NewFileSink(path string) NotificationSink {
+	return &fileSink{path: path}
+}
+
+func (s *fileSink) Send(n Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open notifications file")
+	}
+	defer f.Close()
+	err = json.NewEncoder(f).Encode(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write notification")
+	}
+	return nil
+}
+
+type alerter struct {
+	interrupt <-chan struct{}
+	registry  *Registry
+	drawer    *drawer
+	rules     AlertRules
+	sinks     []NotificationSink
+	interval  time.Duration
+	tracker   *alertsTracker
+}
+
+func NewAlerter(interrupt <-chan struct{}, registry *Registry, drawer *drawer, rules AlertRules, sinks []NotificationSink, interval time.Duration) (*alerter, error) {
+	if rules.Empty() {
+		return nil, errors.New("no alert rules")
+	}
+	if len(sinks) == 0 {
+		return nil, errors.New("no notification sinks")
+	}
+	if interval <= 0 {
+		return nil, errors.Errorf("invalid check interval %s", interval)
+	}
+	return &alerter{
+		interrupt: interrupt,
+		registry:  registry,
+		drawer:    drawer,
+		rules:     rules,
+		sinks:     sinks,
+		interval:  interval,
+		tracker:   newAlertsTracker(),
+	}, nil
+}
+
+func (a *alerter) Start() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.interrupt:
+				zap.S().Debug("[ALR] Shutting down alerter")
+				return
+			case <-ticker.C:
+				a.check()
+			}
+		}
+	}()
+	return done
+}
+
+func (a *alerter) check() {
+	nodes := a.registry.Connections()
+	ips := make([]net.IP, len(nodes))
+	for i, n := range nodes {
+		ip := make([]byte, net.IPv6len)
+		copy(ip, n.Address.To16())
+		ips[i] = ip
+	}
+	forks, err := a.drawer.forks(ips)
+	if err != nil {
+		zap.S().Errorf("[ALR] Failed to collect forks: %v", err)
+		return
+	}
+	for _, n := range a.tracker.update(evaluate(a.rules, forks), time.Now()) {
+		zap.S().Infof("[ALR] Alert %s: %s", n.Event, n.Message)
+		for _, s := range a.sinks {
+			if err := s.Send(n); err != nil {
+				zap.S().Errorf("[ALR] Failed to send notification: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func testForks(t *testing.T) []Fork {
+	b1, err := proto.NewBlockIDFromBase58("FSH8eAAzZNqnG8xgTZtz5xuLqXySsXgAjmFEC25hXMbEufiGjqWPnGCZFt6gLiVLJny16ipxRNAkkzjjhqTjBE2")
+	require.NoError(t, err)
+	b2, err := proto.NewBlockIDFromBase58("2Gv4Jp8EQZk3NpVn3WCDFbzy9tXG6NdRZjqd3nj2ra2C8sm5FvCsfEzVJkWrhyDLJwE1MAGVfYGKSv4PwfLAUhrA")
+	require.NoError(t, err)
+	return []Fork{
+		{Longest: true, Height: 100, HeadBlock: b1, LastCommonHeight: 100, LastCommonBlock: b1, Length: 0, Peers: []PeerForkInfo{
+			{Peer: net.ParseIP("10.0.0.1")}, {Peer: net.ParseIP("10.0.0.2")}, {Peer: net.ParseIP("10.0.0.3")},
+		}},
+		{Longest: false, Height: 98, HeadBlock: b2, LastCommonHeight: 90, LastCommonBlock: b1, Length: 8, Peers: []PeerForkInfo{
+			{Peer: net.ParseIP("10.0.0.4")},
+		}},
+	}
+}
+
+func TestEvaluateAlertRules(t *testing.T) {
+	forks := testForks(t)
+	alerts := evaluate(AlertRules{ForkLength: 10, MinorityShare: 30, WatchedNodes: []net.IP{net.ParseIP("10.0.0.1")}}, forks)
+	assert.Equal(t, 0, len(alerts))
+
+	alerts = evaluate(AlertRules{ForkLength: 5}, forks)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, forkLengthRule, alerts[0].rule)
+	assert.Equal(t, 8, alerts[0].fork.Length)
+
+	alerts = evaluate(AlertRules{MinorityShare: 20}, forks)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, minorityShareRule, alerts[0].rule)
+
+	alerts = evaluate(AlertRules{WatchedNodes: []net.IP{net.ParseIP("10.0.0.4")}}, forks)
+	require.Equal(t, 1, len(alerts))
+	assert.Equal(t, nodeDivergedRule, alerts[0].rule)
+	assert.True(t, net.ParseIP("10.0.0.4").Equal(alerts[0].node))
+}
+
+func TestNewAlerterValidation(t *testing.T) {
+	assert.True(t, AlertRules{}.Empty())
+	rules := AlertRules{ForkLength: 5}
+	assert.False(t, rules.Empty())
+	sinks := []NotificationSink{NewFileSink("alerts.log")}
+
+	_, err := NewAlerter(nil, nil, nil, AlertRules{}, sinks, time.Second)
+	assert.EqualError(t, err, "no alert rules")
+	_, err = NewAlerter(nil, nil, nil, rules, nil, time.Second)
+	assert.EqualError(t, err, "no notification sinks")
+	_, err = NewAlerter(nil, nil, nil, rules, sinks, 0)
+	assert.Error(t, err)
+	_, err = NewAlerter(nil, nil, nil, rules, sinks, time.Second)
+	assert.NoError(t, err)
+}
+
+func TestAlertsTrackerDeduplicationAndResolution(t *testing.T) {
+	forks := testForks(t)
+	rules := AlertRules{ForkLength: 5, MinorityShare: 20}
+	tr := newAlertsTracker()
+	ts := time.Now()
+	ns := tr.update(evaluate(rules, forks), ts)
+	require.Equal(t, 2, len(ns))
+	for _, n := range ns {
+		assert.Equal(t, alertFired, n.Event)
+	}
+	ns = tr.update(evaluate(rules, forks), ts)
+	assert.Equal(t, 0, len(ns))
+	ns = tr.update(evaluate(rules, forks[:1]), ts)
+	require.Equal(t, 2, len(ns))
+	for _, n := range ns {
+		assert.Equal(t, alertResolved, n.Event)
+	}
+	ns = tr.update(evaluate(rules, forks[:1]), ts)
+	assert.Equal(t, 0, len(ns))
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "fd-alerts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "alerts.json")
+	s := NewFileSink(path)
+	n1 := Notification{Event: alertFired, Rule: forkLengthRule, Key: "a", Message: "fired"}
+	n2 := Notification{Event: alertResolved, Rule: forkLengthRule, Key: "a", Message: "resolved"}
+	require.NoError(t, s.Send(n1))
+	require.NoError(t, s.Send(n2))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	r := make([]Notification, 0)
+	for sc.Scan() {
+		var n Notification
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &n))
+		r = append(r, n)
+	}
+	require.Equal(t, 2, len(r))
+	assert.Equal(t, alertFired, r[0].Event)
+	assert.Equal(t, alertResolved, r[1].Event)
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Notification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		err := json.NewDecoder(r.Body).Decode(&n)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- n
+	}))
+	defer srv.Close()
+	s := NewWebhookSink(srv.URL)
+	require.NoError(t, s.Send(Notification{Event: alertFired, Rule: nodeDivergedRule, Key: "b"}))
+	n := <-received
+	assert.Equal(t, nodeDivergedRule, n.Rule)
+	assert.Equal(t, "b", n.Key)
+
+	bad := NewWebhookSink(srv.URL + "/")
+	srv.Close()
+	assert.Error(t, bad.Send(Notification{}))
+}
@@ -53,6 +53,8 @@ type status struct {
 	GoroutinesCount     int `json:"goroutines_count"`
 }
 
+const maxTreeHeights = 10000
+
 type api struct {
 	interrupt <-chan struct{}
 	storage   *storage
@@ -126,6 +128,7 @@ func (a *api) routes() chi.Router {
 	r.Get("/fork/{address}", a.fork)                    // Returns the info about fork of the given peer
 	r.Get("/height/{height:\\d+}", a.blocksAtHeight)    // Returns the list of blocks' IDs on the given height
 	r.Get("/block/{id:[a-km-zA-HJ-NP-Z1-9]+}", a.block) // Returns the block content by it's ID
+	r.Get("/tree/{height:\\d+}", a.tree)                // Returns the tree of blocks from the given height as JSON or DOT
 	return r
 }
 
@@ -317,3 +320,49 @@ func (a *api) block(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (a *api) tree(w http.ResponseWriter, r *http.Request) {
+	p := chi.URLParam(r, "height")
+	h, err := strconv.Atoi(p)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid height: %v", err), http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = TreeFormatJSON
+	}
+	if format != TreeFormatJSON && format != TreeFormatDOT {
+		http.Error(w, fmt.Sprintf("Invalid format '%s'", format), http.StatusBadRequest)
+		return
+	}
+	var nodes []PeerNode
+	if r.URL.Query().Get("peers") == "all" {
+		nodes, err = a.registry.Peers()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to complete request: %v", err), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		nodes = a.registry.Connections()
+	}
+	ips := make([]net.IP, len(nodes))
+	for i, n := range nodes {
+		ip := make([]byte, net.IPv6len)
+		copy(ip, n.Address.To16())
+		ips[i] = ip
+	}
+	roots, err := a.drawer.tree(h, ips, maxTreeHeights)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to complete request: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if format == TreeFormatDOT {
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+	}
+	err = WriteTree(w, roots, format)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to write tree: %v", err), http.StatusInternalServerError)
+		return
+	}
+}
@@ -167,6 +167,30 @@ func (g *graph) forks(vertices []uint32) []fork {
 	return r
 }
 
+type treeVertex struct {
+	parent uint32
+	height int
+}
+
+// subtree returns the vertices on paths from the given tips (mapped to its heights) down to the given height.
+func (g *graph) subtree(tips map[uint32]int, from int) map[uint32]treeVertex {
+	r := make(map[uint32]treeVertex)
+	for v, h := range tips {
+		for ; h >= from; h-- {
+			if _, ok := r[v]; ok {
+				break
+			}
+			p := g.adjacencies[v]
+			r[v] = treeVertex{parent: p, height: h}
+			if p == 0 {
+				break
+			}
+			v = p
+		}
+	}
+	return r
+}
+
 type pathsByLengthAscending []path
 
 func (a pathsByLengthAscending) Len() int {
@@ -155,6 +155,26 @@ func TestHugeRandomGraph(t *testing.T) {
 	PrintMemUsage()
 }
 
+func TestGraphSubtree(t *testing.T) {
+	g := buildGraph()
+	st := g.subtree(map[uint32]int{5: 5, 7: 5, 10: 7}, 4)
+	expected := map[uint32]treeVertex{
+		4:  {parent: 3, height: 4},
+		5:  {parent: 4, height: 5},
+		6:  {parent: 3, height: 4},
+		7:  {parent: 6, height: 5},
+		8:  {parent: 6, height: 5},
+		9:  {parent: 8, height: 6},
+		10: {parent: 9, height: 7},
+	}
+	assert.Equal(t, expected, st)
+
+	st = g.subtree(map[uint32]int{3: 3}, 1)
+	assert.Equal(t, map[uint32]treeVertex{1: {parent: 0, height: 1}, 2: {parent: 1, height: 2}, 3: {parent: 2, height: 3}}, st)
+
+	assert.Empty(t, g.subtree(map[uint32]int{5: 5}, 6))
+}
+
 func buildGraph() *graph {
 	g := newGraph()
 	g.edge(2, 1)
@@ -0,0 +1,217 @@
+package internal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"go.uber.org/zap"
+)
+
+const (
+	TreeFormatJSON = "json"
+	TreeFormatDOT  = "dot"
+
+	shortIDLength = 8
+)
+
+// tree returns the trees of blocks from the given height up to the last blocks of the given peers.
+// Usually it's a single tree, but there will be more trees if peers diverged below the given height.
+// If limit is positive, the number of heights in the trees is limited by it.
+func (d *drawer) tree(from int, addresses []net.IP, limit int) ([]*BlockNode, error) {
+	lastBlocks, err := d.storage.peersLastBlocks(d.buildFilter(addresses))
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[uint32]int, len(lastBlocks))
+	top := 0
+	for n := range lastBlocks {
+		l, err := d.storage.link(n)
+		if err != nil {
+			return nil, err
+		}
+		tips[n] = int(l.height)
+		if int(l.height) > top {
+			top = int(l.height)
+		}
+	}
+	if limit > 0 && top-from+1 > limit {
+		return nil, errors.Errorf("too many heights from %d to %d, limit is %d", from, top, limit)
+	}
+	d.mu.RLock()
+	vertices := d.graph.subtree(tips, from)
+	d.mu.RUnlock()
+
+	nodes := make(map[uint32]*BlockNode, len(vertices))
+	for n, v := range vertices {
+		node, err := d.blockNode(n, v.height)
+		if err != nil {
+			return nil, err
+		}
+		nodes[n] = node
+	}
+	for n, ips := range lastBlocks {
+		node, ok := nodes[n]
+		if !ok {
+			continue
+		}
+		for _, ip := range ips {
+			pi := PeerBlockInfo{Peer: ip}
+			peer, err := d.storage.peer(ip)
+			if err != nil {
+				if err != leveldb.ErrNotFound {
+					return nil, errors.Wrap(err, "failed to collect peers")
+				}
+				zap.S().Warnf("[DRA] Peer '%s' not found", ip.String())
+			} else {
+				pi.Name = peer.Name
+				pi.Version = peer.Version
+			}
+			node.Peers = append(node.Peers, pi)
+		}
+	}
+	roots := make([]*BlockNode, 0)
+	for n, v := range vertices {
+		if p, ok := nodes[v.parent]; ok {
+			p.Children = append(p.Children, nodes[n])
+			continue
+		}
+		roots = append(roots, nodes[n])
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].Height != roots[j].Height {
+			return roots[i].Height < roots[j].Height
+		}
+		return roots[i].ID.String() < roots[j].ID.String()
+	})
+	for _, r := range roots {
+		arrangeBranch(r, nil)
+	}
+	return roots, nil
+}
+
+func (d *drawer) blockNode(number uint32, height int) (*BlockNode, error) {
+	l, err := d.storage.link(number)
+	if err != nil {
+		return nil, err
+	}
+	node := &BlockNode{ID: l.id, Height: height}
+	b, ok, err := d.storage.block(l.id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok { // Genesis block is not stored, so it goes without details
+		return node, nil
+	}
+	parent := b.Parent
+	node.Parent = &parent
+	node.Timestamp = b.Timestamp
+	a, err := proto.NewAddressFromPublicKey(d.storage.scheme, b.GenPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build generator address")
+	}
+	node.Generator = a.String()
+	score, err := state.CalculateScore(b.BaseTarget)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to calculate score of block '%s'", l.id.String())
+	}
+	node.Score = score
+	return node, nil
+}
+
+// arrangeBranch sorts the children of the node and calculates the branch scores.
+func arrangeBranch(node *BlockNode, parentScore *big.Int) {
+	if node.Score != nil {
+		node.BranchScore = big.NewInt(0).Set(node.Score)
+		if parentScore != nil {
+			node.BranchScore.Add(node.BranchScore, parentScore)
+		}
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		return node.Children[i].ID.String() < node.Children[j].ID.String()
+	})
+	for _, c := range node.Children {
+		arrangeBranch(c, node.BranchScore)
+	}
+}
+
+// ExportTree writes the trees of blocks from the given height up to the last blocks of the given peers in the given format.
+func (d *drawer) ExportTree(w io.Writer, from int, addresses []net.IP, format string) error {
+	roots, err := d.tree(from, addresses, 0)
+	if err != nil {
+		return err
+	}
+	return WriteTree(w, roots, format)
+}
+
+// WriteTree writes the trees of blocks as nested JSON or as Graphviz DOT.
+func WriteTree(w io.Writer, roots []*BlockNode, format string) error {
+	switch format {
+	case TreeFormatJSON:
+		return json.NewEncoder(w).Encode(roots)
+	case TreeFormatDOT:
+		return writeDOT(w, roots)
+	default:
+		return errors.Errorf("unsupported tree format '%s'", format)
+	}
+}
+
+func writeDOT(w io.Writer, roots []*BlockNode) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("digraph blocks {\n")
+	bw.WriteString("\trankdir=LR;\n")
+	bw.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	var walk func(n *BlockNode)
+	walk = func(n *BlockNode) {
+		attrs := ""
+		if len(n.Peers) > 0 {
+			attrs = ", style=filled, fillcolor=lightblue"
+		}
+		fmt.Fprintf(bw, "\t\"%s\" [label=\"%s\"%s];\n", n.ID.String(), dotLabel(n), attrs)
+		for _, c := range n.Children {
+			fmt.Fprintf(bw, "\t\"%s\" -> \"%s\";\n", n.ID.String(), c.ID.String())
+			walk(c)
+		}
+	}
+	for _, r := range roots {
+		walk(r)
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func dotLabel(n *BlockNode) string {
+	id := n.ID.String()
+	if len(id) > shortIDLength {
+		id = id[:shortIDLength]
+	}
+	lines := []string{fmt.Sprintf("%s at %d", id, n.Height)}
+	if n.Generator != "" {
+		lines = append(lines, fmt.Sprintf("generator: %s", n.Generator))
+		lines = append(lines, fmt.Sprintf("time: %s", time.Unix(0, int64(n.Timestamp)*int64(time.Millisecond)).UTC().Format(time.RFC3339)))
+	}
+	if n.Score != nil {
+		lines = append(lines, fmt.Sprintf("score: %s", n.Score.String()))
+	}
+	for _, p := range n.Peers {
+		lines = append(lines, fmt.Sprintf("%s %s %s", p.Peer.String(), p.Name, p.Version.String()))
+	}
+	for i, l := range lines {
+		lines[i] = dotEscape(l)
+	}
+	return strings.Join(lines, "\\n")
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s)
+}
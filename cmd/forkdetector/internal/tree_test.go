@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func testBlockID(b byte) proto.BlockID {
+	return proto.NewBlockIDFromSignature(crypto.Signature{b})
+}
+
+func buildTestTree() []*BlockNode {
+	root := &BlockNode{ID: testBlockID(1), Height: 10, Score: big.NewInt(100)}
+	a := &BlockNode{ID: testBlockID(3), Height: 11, Score: big.NewInt(20), Generator: "3P\"quoted\"", Timestamp: 1560000000000}
+	b := &BlockNode{ID: testBlockID(2), Height: 11, Score: big.NewInt(30),
+		Peers: []PeerBlockInfo{{Peer: net.ParseIP("10.0.0.1"), Name: "node", Version: proto.Version{Major: 1, Minor: 0, Patch: 2}}}}
+	root.Children = []*BlockNode{a, b}
+	return []*BlockNode{root}
+}
+
+func TestArrangeBranch(t *testing.T) {
+	roots := buildTestTree()
+	arrangeBranch(roots[0], nil)
+	require.Len(t, roots[0].Children, 2)
+	assert.Equal(t, testBlockID(2), roots[0].Children[0].ID)
+	assert.Equal(t, testBlockID(3), roots[0].Children[1].ID)
+	assert.Equal(t, big.NewInt(100), roots[0].BranchScore)
+	assert.Equal(t, big.NewInt(130), roots[0].Children[0].BranchScore)
+	assert.Equal(t, big.NewInt(120), roots[0].Children[1].BranchScore)
+}
+
+func TestWriteTreeDOT(t *testing.T) {
+	roots := buildTestTree()
+	arrangeBranch(roots[0], nil)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteTree(buf, roots, TreeFormatDOT))
+	s := buf.String()
+	assert.True(t, strings.HasPrefix(s, "digraph blocks {\n"))
+	assert.True(t, strings.HasSuffix(s, "}\n"))
+	assert.Contains(t, s, "\""+testBlockID(1).String()+"\" -> \""+testBlockID(2).String()+"\";")
+	assert.Contains(t, s, "\""+testBlockID(1).String()+"\" -> \""+testBlockID(3).String()+"\";")
+	assert.Contains(t, s, "generator: 3P\\\"quoted\\\"")
+	assert.Contains(t, s, "time: 2019-06-08T13:20:00Z")
+	assert.Contains(t, s, "10.0.0.1 node 1.0.2")
+	assert.Contains(t, s, "style=filled")
+}
+
+func TestWriteTreeJSON(t *testing.T) {
+	roots := buildTestTree()
+	arrangeBranch(roots[0], nil)
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteTree(buf, roots, TreeFormatJSON))
+	var r []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &r))
+	require.Len(t, r, 1)
+	assert.Equal(t, float64(10), r[0]["height"])
+	children, ok := r[0]["children"].([]interface{})
+	require.True(t, ok)
+	assert.Len(t, children, 2)
+
+	assert.Error(t, WriteTree(buf, roots, "xml"))
+}
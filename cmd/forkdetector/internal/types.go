@@ -2,6 +2,7 @@ package internal
 
 import (
 	"encoding/binary"
+	"math/big"
 	"net"
 	"strconv"
 	"strings"
@@ -28,6 +29,25 @@ type Fork struct {
 	Peers            []PeerForkInfo `json:"peers"`              // Peers that seen on the fork
 }
 
+type PeerBlockInfo struct {
+	Peer    net.IP        `json:"peer"`
+	Name    string        `json:"name"`
+	Version proto.Version `json:"version"`
+}
+
+// BlockNode is a node of the blocks tree, children of the node are the blocks that refer the node as a parent.
+type BlockNode struct {
+	ID          proto.BlockID   `json:"id"`
+	Height      int             `json:"height"`
+	Parent      *proto.BlockID  `json:"parent,omitempty"`
+	Generator   string          `json:"generator,omitempty"`    // The address of block generator
+	Timestamp   uint64          `json:"timestamp,omitempty"`    // The block timestamp in milliseconds
+	Score       *big.Int        `json:"score,omitempty"`        // The score of the block
+	BranchScore *big.Int        `json:"branch_score,omitempty"` // The sum of scores of blocks from the root of the tree up to the block
+	Peers       []PeerBlockInfo `json:"peers,omitempty"`        // Peers that have the block as the last one
+	Children    []*BlockNode    `json:"children,omitempty"`
+}
+
 type ForkByHeightLengthAndPeersCount []Fork
 
 func (a ForkByHeightLengthAndPeersCount) Len() int {
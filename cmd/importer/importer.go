@@ -32,6 +32,7 @@ var (
 	verificationGoroutinesNum = flag.Int("verification-goroutines-num", runtime.NumCPU()*2, " Number of goroutines that will be run for verification of transactions/blocks signatures.")
 	writeBufferSize           = flag.Int("write-buffer", 16, "Write buffer size in MiB.")
 	buildDataForExtendedApi   = flag.Bool("build-extended-api", false, "Build and store additional data required for extended API in state. WARNING: this slows down the import, use only if you do really need extended API.")
+	buildStateHashes          = flag.Bool("build-state-hashes", false, "Calculate and store state hashes for each block height.")
 	// Debug.
 	cpuProfilePath = flag.String("cpuprofile", "", "Write cpu profile to this file.")
 	memProfilePath = flag.String("memprofile", "", "Write memory profile to this file.")
@@ -95,6 +96,7 @@ func main() {
 	params.VerificationGoroutinesNum = *verificationGoroutinesNum
 	params.DbParams.WriteBuffer = *writeBufferSize * MiB
 	params.StoreExtendedApiData = *buildDataForExtendedApi
+	params.BuildStateHashes = *buildStateHashes
 	// We do not need to provide any APIs during import.
 	params.ProvideExtendedApi = false
 	st, err := state.NewState(dataDir, params, ss)
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/cmd/inspect/internal"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+	"go.uber.org/zap"
+)
+
+var (
+	logLevel       = flag.String("log-level", "INFO", "Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	input          = flag.String("in", "-", "File to read the data from, '-' for standard input. Ignored if the data is given as an argument.")
+	scheme         = flag.String("scheme", "W", "Address scheme byte, used unless the data contains chain ID.")
+	kind           = flag.String("type", "auto", "Type of the data: auto, transaction, block or microblock.")
+	format         = flag.String("format", "auto", "Format of the data: auto, binary, protobuf or json.")
+	encoding       = flag.String("encoding", "auto", "Encoding of binary data: auto, raw, base58, base64 or hex.")
+	outputFormat   = flag.String("to", "", "Re-encode the data into the given format: binary, protobuf or json. By default the data is pretty printed.")
+	outputEncoding = flag.String("to-encoding", "base58", "Encoding of re-encoded binary data: raw, base58, base64 or hex.")
+)
+
+func main() {
+	flag.Parse()
+	common.SetupLogger(*logLevel)
+	os.Exit(run())
+}
+
+func run() int {
+	opts, err := options()
+	if err != nil {
+		zap.S().Error(err)
+		return 2
+	}
+	data, err := readInput()
+	if err != nil {
+		zap.S().Errorf("Failed to read input: %v", err)
+		return 2
+	}
+	o, err := internal.Decode(data, *opts)
+	if err != nil {
+		zap.S().Error(err)
+		return 1
+	}
+	if *outputFormat != "" {
+		if err := reencode(o); err != nil {
+			zap.S().Error(err)
+			return 1
+		}
+		return 0
+	}
+	internal.Print(os.Stdout, internal.Inspect(o))
+	js, err := internal.Encode(o, internal.JSON)
+	if err != nil {
+		zap.S().Errorf("Failed to marshal to JSON: %v", err)
+		return 1
+	}
+	fmt.Printf("Fields:\n%s\n", js)
+	return 0
+}
+
+func options() (*internal.Options, error) {
+	if len(*scheme) != 1 {
+		return nil, errors.Errorf("invalid scheme '%s', single character expected", *scheme)
+	}
+	k, err := internal.ParseKind(*kind)
+	if err != nil {
+		return nil, err
+	}
+	f, err := internal.ParseFormat(*format)
+	if err != nil {
+		return nil, err
+	}
+	e, err := internal.ParseEncoding(*encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &internal.Options{Scheme: (*scheme)[0], Kind: k, Format: f, Encoding: e}, nil
+}
+
+func readInput() ([]byte, error) {
+	if flag.NArg() > 0 {
+		return []byte(strings.Join(flag.Args(), " ")), nil
+	}
+	if *input == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(*input)
+}
+
+func reencode(o *internal.Object) error {
+	f, err := internal.ParseFormat(*outputFormat)
+	if err != nil {
+		return err
+	}
+	b, err := internal.Encode(o, f)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %s to %s", o.Kind, f)
+	}
+	if f != internal.JSON {
+		e, err := internal.ParseEncoding(*outputEncoding)
+		if err != nil {
+			return err
+		}
+		b, err = internal.EncodeText(b, e)
+		if err != nil {
+			return err
+		}
+		if e == internal.Raw {
+			_, err = os.Stdout.Write(b)
+			return err
+		}
+	}
+	fmt.Println(string(b))
+	return nil
+}
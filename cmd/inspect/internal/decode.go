@@ -0,0 +1,437 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Format is the serialization format of transactions and blocks.
+type Format byte
+
+const (
+	AutoFormat Format = iota
+	Binary            // Legacy binary format
+	Protobuf
+	JSON
+)
+
+func (f Format) String() string {
+	switch f {
+	case AutoFormat:
+		return "auto"
+	case Binary:
+		return "binary"
+	case Protobuf:
+		return "protobuf"
+	case JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("unknown format %d", f)
+	}
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return AutoFormat, nil
+	case "binary", "bin":
+		return Binary, nil
+	case "protobuf", "proto", "pb":
+		return Protobuf, nil
+	case "json":
+		return JSON, nil
+	default:
+		return 0, errors.Errorf("unsupported format '%s'", s)
+	}
+}
+
+// Kind is the kind of decoded object.
+type Kind byte
+
+const (
+	AutoKind Kind = iota
+	TransactionKind
+	BlockKind
+	MicroBlockKind
+)
+
+func (k Kind) String() string {
+	switch k {
+	case AutoKind:
+		return "auto"
+	case TransactionKind:
+		return "transaction"
+	case BlockKind:
+		return "block"
+	case MicroBlockKind:
+		return "microblock"
+	default:
+		return fmt.Sprintf("unknown kind %d", k)
+	}
+}
+
+func ParseKind(s string) (Kind, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return AutoKind, nil
+	case "transaction", "tx":
+		return TransactionKind, nil
+	case "block":
+		return BlockKind, nil
+	case "microblock", "micro":
+		return MicroBlockKind, nil
+	default:
+		return 0, errors.Errorf("unsupported kind '%s'", s)
+	}
+}
+
+// Encoding is the text encoding of binary data.
+type Encoding byte
+
+const (
+	AutoEncoding Encoding = iota
+	Raw
+	Base58
+	Base64
+	Hex
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case AutoEncoding:
+		return "auto"
+	case Raw:
+		return "raw"
+	case Base58:
+		return "base58"
+	case Base64:
+		return "base64"
+	case Hex:
+		return "hex"
+	default:
+		return fmt.Sprintf("unknown encoding %d", e)
+	}
+}
+
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return AutoEncoding, nil
+	case "raw":
+		return Raw, nil
+	case "base58":
+		return Base58, nil
+	case "base64":
+		return Base64, nil
+	case "hex":
+		return Hex, nil
+	default:
+		return 0, errors.Errorf("unsupported encoding '%s'", s)
+	}
+}
+
+// Object is a decoded transaction, block or microblock.
+type Object struct {
+	Kind     Kind
+	Format   Format
+	Encoding Encoding
+	Scheme   proto.Scheme
+	// Exact is true if encoding of the object in its original format gives exactly the input bytes.
+	Exact bool
+
+	Transaction proto.Transaction
+	Block       *proto.Block
+	MicroBlock  *proto.MicroBlock
+}
+
+// Options restrict the detection of input data. Zero values mean auto detection.
+type Options struct {
+	Scheme   proto.Scheme
+	Kind     Kind
+	Format   Format
+	Encoding Encoding
+}
+
+// Decode detects the encoding, format and kind of the data and decodes it.
+// The data could be JSON, raw bytes or base58, base64 or hex encoded bytes in legacy binary or protobuf format.
+// Encoded text may be prefixed with `base58:`, `base64:` or `0x`. If the data could be decoded in different ways,
+// the one that gives the same bytes on encoding back is preferred. Chain ID of protobuf objects takes precedence over the scheme from options.
+func Decode(data []byte, opts Options) (*Object, error) {
+	text := bytes.TrimSpace(data)
+	if opts.Format == JSON || (opts.Format == AutoFormat && opts.Encoding == AutoEncoding && len(text) > 0 && text[0] == '{') {
+		o, err := decodeJSON(text, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode JSON")
+		}
+		return o, nil
+	}
+	candidates, err := decodeText(data, text, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		inexact *Object
+		errs    []string
+	)
+	for _, c := range candidates {
+		for _, d := range decoders {
+			if opts.Kind != AutoKind && opts.Kind != d.kind || opts.Format != AutoFormat && opts.Format != d.format {
+				continue
+			}
+			o, err := safeDecode(d, c.data, opts.Scheme)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s %s %s: %v", c.encoding, d.format, d.kind, err))
+				continue
+			}
+			o.Kind = d.kind
+			o.Format = d.format
+			o.Encoding = c.encoding
+			o.Exact = exact(o, c.data)
+			if o.Exact {
+				return o, nil
+			}
+			if inexact == nil {
+				inexact = o
+			}
+		}
+	}
+	if inexact != nil {
+		return inexact, nil
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no decoders match the options")
+	}
+	return nil, errors.Errorf("failed to decode data:\n  %s", strings.Join(errs, "\n  "))
+}
+
+type candidate struct {
+	encoding Encoding
+	data     []byte
+}
+
+// decodeText returns the possible decodings of the input into bytes.
+func decodeText(data, text []byte, encoding Encoding) ([]candidate, error) {
+	s := string(text)
+	switch encoding {
+	case Raw:
+		return []candidate{{Raw, data}}, nil
+	case Base58:
+		b, err := base58.Decode(strings.TrimPrefix(s, "base58:"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base58")
+		}
+		return []candidate{{Base58, b}}, nil
+	case Base64:
+		b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(s, "base64:"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid base64")
+		}
+		return []candidate{{Base64, b}}, nil
+	case Hex:
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid hex")
+		}
+		return []candidate{{Hex, b}}, nil
+	}
+	switch {
+	case strings.HasPrefix(s, "base58:"):
+		return decodeText(data, text, Base58)
+	case strings.HasPrefix(s, "base64:"):
+		return decodeText(data, text, Base64)
+	case strings.HasPrefix(s, "0x"):
+		return decodeText(data, text, Hex)
+	}
+	var r []candidate
+	if s != "" && isPrintable(s) {
+		if b, err := base58.Decode(s); err == nil {
+			r = append(r, candidate{Base58, b})
+		}
+		if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+			r = append(r, candidate{Base64, b})
+		}
+		if b, err := hex.DecodeString(s); err == nil {
+			r = append(r, candidate{Hex, b})
+		}
+	}
+	return append(r, candidate{Raw, data}), nil
+}
+
+func isPrintable(s string) bool {
+	for _, c := range s {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+type decoder struct {
+	kind   Kind
+	format Format
+	decode func(data []byte, scheme proto.Scheme) (*Object, error)
+}
+
+var decoders = []decoder{
+	{TransactionKind, Binary, decodeBinaryTransaction},
+	{TransactionKind, Protobuf, decodeProtobufTransaction},
+	{BlockKind, Binary, decodeBinaryBlock},
+	{BlockKind, Protobuf, decodeProtobufBlock},
+	{MicroBlockKind, Binary, decodeBinaryMicroBlock},
+	{MicroBlockKind, Protobuf, decodeProtobufMicroBlock},
+}
+
+// safeDecode protects from panics of decoding functions on garbage input.
+func safeDecode(d decoder, data []byte, scheme proto.Scheme) (o *Object, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			o = nil
+			err = errors.Errorf("malformed data: %v", r)
+		}
+	}()
+	return d.decode(data, scheme)
+}
+
+func decodeBinaryTransaction(data []byte, scheme proto.Scheme) (*Object, error) {
+	tx, err := proto.BytesToTransaction(data, scheme)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: scheme, Transaction: tx}, nil
+}
+
+func decodeProtobufTransaction(data []byte, scheme proto.Scheme) (*Object, error) {
+	var pb g.SignedTransaction
+	if err := protobuf.Unmarshal(data, &pb); err != nil {
+		return nil, err
+	}
+	if pb.Transaction == nil {
+		return nil, errors.New("empty transaction")
+	}
+	var c proto.ProtobufConverter
+	tx, err := c.SignedTransaction(&pb)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: chainID(pb.Transaction.ChainId, scheme), Transaction: tx}, nil
+}
+
+func decodeBinaryBlock(data []byte, scheme proto.Scheme) (*Object, error) {
+	b := &proto.Block{}
+	if err := b.UnmarshalBinary(data, scheme); err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: scheme, Block: b}, nil
+}
+
+func decodeProtobufBlock(data []byte, scheme proto.Scheme) (*Object, error) {
+	var pb g.Block
+	if err := protobuf.Unmarshal(data, &pb); err != nil {
+		return nil, err
+	}
+	if pb.Header == nil {
+		return nil, errors.New("empty block header")
+	}
+	var c proto.ProtobufConverter
+	b, err := c.Block(&pb)
+	if err != nil {
+		return nil, err
+	}
+	scheme = chainID(pb.Header.ChainId, scheme)
+	if err := b.GenerateBlockID(scheme); err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: scheme, Block: &b}, nil
+}
+
+func decodeBinaryMicroBlock(data []byte, scheme proto.Scheme) (*Object, error) {
+	m := &proto.MicroBlock{}
+	if err := m.UnmarshalBinary(data, scheme); err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: scheme, MicroBlock: m}, nil
+}
+
+func decodeProtobufMicroBlock(data []byte, scheme proto.Scheme) (*Object, error) {
+	var pb g.SignedMicroBlock
+	if err := protobuf.Unmarshal(data, &pb); err != nil {
+		return nil, err
+	}
+	if pb.MicroBlock == nil {
+		return nil, errors.New("empty microblock")
+	}
+	var c proto.ProtobufConverter
+	m, err := c.MicroBlock(&pb)
+	if err != nil {
+		return nil, err
+	}
+	return &Object{Scheme: scheme, MicroBlock: &m}, nil
+}
+
+func chainID(id int32, scheme proto.Scheme) proto.Scheme {
+	if id == 0 {
+		return scheme
+	}
+	return proto.Scheme(id)
+}
+
+func exact(o *Object, data []byte) bool {
+	b, err := Encode(o, o.Format)
+	return err == nil && bytes.Equal(b, data)
+}
+
+func decodeJSON(data []byte, opts Options) (*Object, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	kind := opts.Kind
+	if kind == AutoKind {
+		kind = TransactionKind
+		if _, ok := fields["nxt-consensus"]; ok {
+			kind = BlockKind
+		} else if _, ok := fields["TotalResBlockSigField"]; ok {
+			kind = MicroBlockKind
+		}
+	}
+	o := &Object{Kind: kind, Format: JSON, Encoding: Raw, Scheme: opts.Scheme}
+	switch kind {
+	case TransactionKind:
+		tt := proto.TransactionTypeVersion{}
+		if err := json.Unmarshal(data, &tt); err != nil {
+			return nil, err
+		}
+		tx, err := proto.GuessTransactionType(&tt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, tx); err != nil {
+			return nil, err
+		}
+		if err := tx.GenerateID(o.Scheme); err != nil {
+			return nil, err
+		}
+		o.Transaction = tx
+	case BlockKind:
+		o.Block = &proto.Block{}
+		if err := json.Unmarshal(data, o.Block); err != nil {
+			return nil, err
+		}
+	case MicroBlockKind:
+		o.MicroBlock = &proto.MicroBlock{}
+		if err := json.Unmarshal(data, o.MicroBlock); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+)
+
+// Encode serializes the object into the given format.
+func Encode(o *Object, format Format) ([]byte, error) {
+	switch format {
+	case JSON:
+		return json.MarshalIndent(o.value(), "", "  ")
+	case Binary:
+		switch o.Kind {
+		case TransactionKind:
+			return o.Transaction.MarshalBinary()
+		case BlockKind:
+			return o.Block.MarshalBinary()
+		case MicroBlockKind:
+			buf := new(bytes.Buffer)
+			if _, err := o.MicroBlock.WriteTo(buf); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+	case Protobuf:
+		switch o.Kind {
+		case TransactionKind:
+			return o.Transaction.MarshalSignedToProtobuf(o.Scheme)
+		case BlockKind:
+			return o.Block.MarshalToProtobuf(o.Scheme)
+		case MicroBlockKind:
+			return o.MicroBlock.MarshalToProtobuf(o.Scheme)
+		}
+	}
+	return nil, errors.Errorf("can't encode %s to %s", o.Kind, format)
+}
+
+// EncodeText returns the bytes in the given text encoding.
+func EncodeText(data []byte, encoding Encoding) ([]byte, error) {
+	switch encoding {
+	case Raw:
+		return data, nil
+	case Base58:
+		return []byte(base58.Encode(data)), nil
+	case Base64:
+		return []byte(base64.StdEncoding.EncodeToString(data)), nil
+	case Hex:
+		return []byte(hex.EncodeToString(data)), nil
+	default:
+		return nil, errors.Errorf("unsupported output encoding '%s'", encoding)
+	}
+}
+
+func (o *Object) value() interface{} {
+	switch o.Kind {
+	case TransactionKind:
+		return o.Transaction
+	case BlockKind:
+		return o.Block
+	default:
+		return o.MicroBlock
+	}
+}
@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const scheme = proto.TestNetScheme
+
+func transfer(t *testing.T, kp proto.KeyPair, version byte) proto.Transaction {
+	addr, err := kp.Addr(scheme)
+	require.NoError(t, err)
+	tx := proto.NewUnsignedTransferWithProofs(version, kp.Public, proto.OptionalAsset{}, proto.OptionalAsset{}, 1600000000000, 100000000, 100000, proto.NewRecipientFromAddress(addr), &proto.LegacyAttachment{})
+	require.NoError(t, tx.Sign(scheme, kp.Secret))
+	return tx
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	kp := proto.MustKeyPair([]byte("inspect"))
+	tx := transfer(t, kp, 2)
+	id, err := tx.GetID(scheme)
+	require.NoError(t, err)
+	bin, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	pb, err := tx.MarshalSignedToProtobuf(scheme)
+	require.NoError(t, err)
+	js, err := Encode(&Object{Kind: TransactionKind, Transaction: tx}, JSON)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		data     []byte
+		format   Format
+		encoding Encoding
+	}{
+		{bin, Binary, Raw},
+		{[]byte(base58.Encode(bin)), Binary, Base58},
+		{[]byte("base64:" + base64.StdEncoding.EncodeToString(bin)), Binary, Base64},
+		{[]byte("0x" + hex.EncodeToString(bin) + "\n"), Binary, Hex},
+		{pb, Protobuf, Raw},
+		{[]byte(base64.StdEncoding.EncodeToString(pb)), Protobuf, Base64},
+		{js, JSON, Raw},
+	} {
+		o, err := Decode(tc.data, Options{Scheme: scheme})
+		require.NoError(t, err, string(tc.data))
+		assert.Equal(t, TransactionKind, o.Kind)
+		assert.Equal(t, tc.format, o.Format)
+		assert.Equal(t, tc.encoding, o.Encoding)
+		assert.Equal(t, tc.format != JSON, o.Exact)
+		r := Inspect(o)
+		require.NotNil(t, r.Transaction)
+		assert.Equal(t, "TransferWithProofs", r.Transaction.Type)
+		assert.Equal(t, base58.Encode(id), r.Transaction.ID)
+		require.NotNil(t, r.Transaction.Sender)
+		assert.Equal(t, SignatureValid, r.Transaction.Sender.Signature)
+		addr, err := kp.Addr(scheme)
+		require.NoError(t, err)
+		assert.Equal(t, addr.String(), r.Transaction.Sender.Address)
+	}
+
+	// Chain ID of protobuf transaction is used instead of the given scheme.
+	o, err := Decode(pb, Options{Scheme: proto.MainNetScheme, Format: Protobuf})
+	require.NoError(t, err)
+	assert.Equal(t, scheme, o.Scheme)
+
+	// Transaction with corrupted signature is decoded, but the signature is invalid.
+	corrupted := append([]byte(nil), bin...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	o, err = Decode(corrupted, Options{Scheme: scheme})
+	require.NoError(t, err)
+	assert.Equal(t, SignatureInvalid, Inspect(o).Transaction.Sender.Signature)
+
+	_, err = Decode([]byte("not a transaction"), Options{Scheme: scheme})
+	assert.Error(t, err)
+	_, err = Decode(bin, Options{Scheme: scheme, Kind: BlockKind})
+	assert.Error(t, err)
+}
+
+func TestDecodeProtobufOnlyTransaction(t *testing.T) {
+	tx := transfer(t, proto.MustKeyPair([]byte("inspect")), 3)
+	pb, err := tx.MarshalSignedToProtobuf(scheme)
+	require.NoError(t, err)
+	o, err := Decode([]byte(base58.Encode(pb)), Options{Scheme: proto.MainNetScheme})
+	require.NoError(t, err)
+	assert.Equal(t, Protobuf, o.Format)
+	assert.Equal(t, scheme, o.Scheme)
+	assert.Equal(t, SignatureValid, Inspect(o).Transaction.Sender.Signature)
+	_, err = Encode(o, Binary)
+	assert.Error(t, err)
+}
+
+func TestDecodeBlocks(t *testing.T) {
+	kp := proto.MustKeyPair([]byte("generator"))
+	tx := transfer(t, kp, 2)
+	nxt := proto.NxtConsensus{BaseTarget: 100, GenSignature: make([]byte, crypto.DigestSize)}
+	parent := proto.NewBlockIDFromSignature(crypto.Signature{1})
+
+	for _, version := range []proto.BlockVersion{proto.RewardBlockVersion, proto.ProtoBlockVersion} {
+		b, err := proto.CreateBlock(proto.Transactions{tx}, 1600000000000, parent, kp.Public, nxt, version, nil, -1, scheme)
+		require.NoError(t, err)
+		require.NoError(t, b.Sign(scheme, kp.Secret))
+		require.NoError(t, b.GenerateBlockID(scheme))
+		data, err := b.MarshalToProtobuf(scheme)
+		require.NoError(t, err)
+		format := Protobuf
+		if version < proto.ProtoBlockVersion {
+			data, err = b.MarshalBinary()
+			require.NoError(t, err)
+			format = Binary
+		}
+		o, err := Decode(data, Options{Scheme: scheme})
+		require.NoError(t, err)
+		assert.Equal(t, BlockKind, o.Kind)
+		assert.Equal(t, format, o.Format)
+		assert.True(t, o.Exact)
+		r := Inspect(o)
+		require.NotNil(t, r.Block)
+		assert.Equal(t, b.BlockID().String(), r.Block.ID)
+		assert.Equal(t, SignatureValid, r.Block.Generator.Signature)
+		require.Len(t, r.Block.Transactions, 1)
+		assert.Equal(t, SignatureValid, r.Block.Transactions[0].Sender.Signature)
+		if version >= proto.ProtoBlockVersion {
+			assert.Equal(t, SignatureValid, r.Block.TransactionsRoot)
+		}
+
+		micro := &proto.MicroBlock{
+			VersionField:          byte(version),
+			Reference:             parent,
+			TotalResBlockSigField: b.BlockSignature,
+			TransactionCount:      1,
+			Transactions:          proto.Transactions{tx},
+			SenderPK:              kp.Public,
+		}
+		require.NoError(t, micro.Sign(kp.Secret))
+		mo := &Object{Kind: MicroBlockKind, Scheme: scheme, MicroBlock: micro}
+		data, err = Encode(mo, format)
+		require.NoError(t, err)
+		o, err = Decode(data, Options{Scheme: scheme})
+		require.NoError(t, err)
+		assert.Equal(t, MicroBlockKind, o.Kind)
+		assert.Equal(t, format, o.Format)
+		assert.Equal(t, SignatureValid, Inspect(o).MicroBlock.Sender.Signature)
+	}
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// SignatureStatus is the result of signature verification.
+type SignatureStatus string
+
+const (
+	SignatureValid   SignatureStatus = "valid"
+	SignatureInvalid SignatureStatus = "invalid"
+	NotSigned        SignatureStatus = "not signed"
+)
+
+func verificationFailed(err error) SignatureStatus {
+	return SignatureStatus(fmt.Sprintf("verification failed: %v", err))
+}
+
+func signatureStatus(ok bool, err error) SignatureStatus {
+	switch {
+	case err != nil:
+		return verificationFailed(err)
+	case ok:
+		return SignatureValid
+	default:
+		return SignatureInvalid
+	}
+}
+
+// Party is the signer of a transaction, block or order.
+type Party struct {
+	Address   string
+	PublicKey crypto.PublicKey
+	Signature SignatureStatus
+}
+
+type OrderReport struct {
+	ID string
+	Party
+}
+
+type TransactionReport struct {
+	Type      string
+	Version   byte
+	ID        string
+	Timestamp uint64
+	Fee       uint64
+	Sender    *Party // Absent for genesis transactions
+	Orders    []OrderReport
+}
+
+type BlockReport struct {
+	Version          proto.BlockVersion
+	ID               string
+	Parent           string
+	Timestamp        uint64
+	Generator        Party
+	TransactionsRoot SignatureStatus // Only for protobuf blocks
+	Transactions     []TransactionReport
+}
+
+type MicroBlockReport struct {
+	Version        byte
+	Reference      string
+	TotalSignature string
+	Sender         Party
+	Transactions   []TransactionReport
+}
+
+// Report is the human readable summary of the decoded object with the results of its verification.
+type Report struct {
+	Kind        Kind
+	Format      Format
+	Encoding    Encoding
+	Scheme      proto.Scheme
+	Exact       bool
+	Transaction *TransactionReport
+	Block       *BlockReport
+	MicroBlock  *MicroBlockReport
+}
+
+// Inspect calculates IDs and addresses of the object and verifies its signatures.
+func Inspect(o *Object) *Report {
+	r := &Report{Kind: o.Kind, Format: o.Format, Encoding: o.Encoding, Scheme: o.Scheme, Exact: o.Exact}
+	switch o.Kind {
+	case TransactionKind:
+		tr := inspectTransaction(o.Transaction, o.Scheme)
+		r.Transaction = &tr
+	case BlockKind:
+		r.Block = inspectBlock(o.Block, o.Scheme)
+	case MicroBlockKind:
+		r.MicroBlock = inspectMicroBlock(o.MicroBlock, o.Scheme)
+	}
+	return r
+}
+
+type verifier interface {
+	Verify(scheme proto.Scheme, publicKey crypto.PublicKey) (bool, error)
+}
+
+func inspectTransaction(tx proto.Transaction, scheme proto.Scheme) TransactionReport {
+	r := TransactionReport{
+		Type:      reflect.Indirect(reflect.ValueOf(tx)).Type().Name(),
+		Version:   tx.GetVersion(),
+		Timestamp: tx.GetTimestamp(),
+		Fee:       tx.GetFee(),
+	}
+	if id, err := tx.GetID(scheme); err != nil {
+		r.ID = fmt.Sprintf("failed to calculate: %v", err)
+	} else {
+		r.ID = base58.Encode(id)
+	}
+	if v, ok := tx.(verifier); ok {
+		pk := tx.GetSenderPK()
+		p := newParty(scheme, pk)
+		p.Signature = signatureStatus(v.Verify(scheme, pk))
+		r.Sender = &p
+	}
+	if e, ok := tx.(proto.Exchange); ok {
+		for _, o := range []proto.Order{e.GetOrder1(), e.GetOrder2()} {
+			r.Orders = append(r.Orders, inspectOrder(o, scheme))
+		}
+	}
+	return r
+}
+
+func inspectOrder(o proto.Order, scheme proto.Scheme) OrderReport {
+	r := OrderReport{Party: newParty(scheme, o.GetSenderPK())}
+	if err := o.GenerateID(scheme); err != nil {
+		r.ID = fmt.Sprintf("failed to calculate: %v", err)
+	} else if id, err := o.GetID(); err != nil {
+		r.ID = fmt.Sprintf("failed to calculate: %v", err)
+	} else {
+		r.ID = base58.Encode(id)
+	}
+	r.Signature = signatureStatus(o.Verify(scheme, o.GetSenderPK()))
+	return r
+}
+
+func inspectBlock(b *proto.Block, scheme proto.Scheme) *BlockReport {
+	r := &BlockReport{
+		Version:      b.Version,
+		ID:           b.BlockID().String(),
+		Parent:       b.Parent.String(),
+		Timestamp:    b.Timestamp,
+		Generator:    newParty(scheme, b.GenPublicKey),
+		Transactions: inspectTransactions(b.Transactions, scheme),
+	}
+	r.Generator.Signature = signatureStatus(b.VerifySignature(scheme))
+	if b.Version >= proto.ProtoBlockVersion {
+		r.TransactionsRoot = signatureStatus(b.VerifyTransactionsRoot(scheme))
+	}
+	return r
+}
+
+func inspectMicroBlock(m *proto.MicroBlock, scheme proto.Scheme) *MicroBlockReport {
+	r := &MicroBlockReport{
+		Version:        m.VersionField,
+		Reference:      m.Reference.String(),
+		TotalSignature: m.TotalResBlockSigField.String(),
+		Sender:         newParty(scheme, m.SenderPK),
+		Transactions:   inspectTransactions(m.Transactions, scheme),
+	}
+	r.Sender.Signature = signatureStatus(m.VerifySignature())
+	return r
+}
+
+func inspectTransactions(txs proto.Transactions, scheme proto.Scheme) []TransactionReport {
+	r := make([]TransactionReport, len(txs))
+	for i, tx := range txs {
+		r[i] = inspectTransaction(tx, scheme)
+	}
+	return r
+}
+
+func newParty(scheme proto.Scheme, pk crypto.PublicKey) Party {
+	p := Party{PublicKey: pk, Signature: NotSigned}
+	if addr, err := proto.NewAddressFromPublicKey(scheme, pk); err != nil {
+		p.Address = fmt.Sprintf("failed to calculate: %v", err)
+	} else {
+		p.Address = addr.String()
+	}
+	return p
+}
+
+// Print writes the report in human readable form.
+func Print(w io.Writer, r *Report) {
+	fmt.Fprintf(w, "Kind:       %s\n", r.Kind)
+	fmt.Fprintf(w, "Format:     %s", r.Format)
+	if r.Format != JSON {
+		fmt.Fprintf(w, " (%s)", r.Encoding)
+		if !r.Exact {
+			fmt.Fprint(w, ", re-encoded bytes differ from the input")
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Scheme:     %c\n", r.Scheme)
+	switch r.Kind {
+	case TransactionKind:
+		printTransaction(w, "", r.Transaction)
+	case BlockKind:
+		b := r.Block
+		fmt.Fprintf(w, "Version:    %d\n", b.Version)
+		fmt.Fprintf(w, "ID:         %s\n", b.ID)
+		fmt.Fprintf(w, "Parent:     %s\n", b.Parent)
+		fmt.Fprintf(w, "Timestamp:  %s\n", formatTimestamp(b.Timestamp))
+		printParty(w, "", "Generator", b.Generator)
+		if b.TransactionsRoot != "" {
+			fmt.Fprintf(w, "Tx root:    %s\n", b.TransactionsRoot)
+		}
+		printTransactions(w, b.Transactions)
+	case MicroBlockKind:
+		m := r.MicroBlock
+		fmt.Fprintf(w, "Version:    %d\n", m.Version)
+		fmt.Fprintf(w, "Reference:  %s\n", m.Reference)
+		fmt.Fprintf(w, "Total sig:  %s\n", m.TotalSignature)
+		printParty(w, "", "Sender", m.Sender)
+		printTransactions(w, m.Transactions)
+	}
+}
+
+func printTransactions(w io.Writer, txs []TransactionReport) {
+	fmt.Fprintf(w, "Txs:        %d\n", len(txs))
+	for i := range txs {
+		fmt.Fprintf(w, "  #%d\n", i+1)
+		printTransaction(w, "    ", &txs[i])
+	}
+}
+
+func printTransaction(w io.Writer, indent string, tx *TransactionReport) {
+	fmt.Fprintf(w, "%sType:       %s v%d\n", indent, tx.Type, tx.Version)
+	fmt.Fprintf(w, "%sID:         %s\n", indent, tx.ID)
+	fmt.Fprintf(w, "%sTimestamp:  %s\n", indent, formatTimestamp(tx.Timestamp))
+	fmt.Fprintf(w, "%sFee:        %d\n", indent, tx.Fee)
+	if tx.Sender != nil {
+		printParty(w, indent, "Sender", *tx.Sender)
+	}
+	for i, o := range tx.Orders {
+		fmt.Fprintf(w, "%sOrder %d:    %s\n", indent, i+1, o.ID)
+		printParty(w, indent+"  ", "Sender", o.Party)
+	}
+}
+
+func printParty(w io.Writer, indent, role string, p Party) {
+	fmt.Fprintf(w, "%s%-11s %s (public key %s)\n", indent, role+":", p.Address, p.PublicKey.String())
+	fmt.Fprintf(w, "%sSignature:  %s\n", indent, p.Signature)
+}
+
+func formatTimestamp(ts uint64) string {
+	t := time.Unix(0, int64(ts)*int64(time.Millisecond)).UTC()
+	return fmt.Sprintf("%d (%s)", ts, t.Format(time.RFC3339Nano))
+}
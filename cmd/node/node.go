@@ -14,8 +14,10 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/mr-tron/base58/base58"
 	"github.com/wavesplatform/gowaves/pkg/api"
 	"github.com/wavesplatform/gowaves/pkg/grpc/server"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/libs/bytespool"
 	"github.com/wavesplatform/gowaves/pkg/libs/ntptime"
 	"github.com/wavesplatform/gowaves/pkg/libs/runner"
@@ -27,11 +29,14 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/node/peer_manager"
 	"github.com/wavesplatform/gowaves/pkg/node/state_changed"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/p2p/recorder"
+	"github.com/wavesplatform/gowaves/pkg/p2p/secure"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/scoresender"
 	"github.com/wavesplatform/gowaves/pkg/services"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 	"github.com/wavesplatform/gowaves/pkg/wallet"
 	"go.uber.org/zap"
@@ -51,6 +56,8 @@ var (
 	enableGrpcApi              = flag.Bool("enable-grpc-api", true, "Enables/disables gRPC API")
 	buildExtendedApi           = flag.Bool("build-extended-api", false, "Builds extended API. Note that state must be reimported in case it wasn't imported with similar flag set")
 	serveExtendedApi           = flag.Bool("serve-extended-api", false, "Serves extended API requests since the very beginning. The default behavior is to import until first block close to current time, and start serving at this point")
+	buildStateHashes           = flag.Bool("build-state-hashes", false, "Calculate and store state hashes for each block height. Note that state must be reimported in case it wasn't imported with similar flag set")
+	dbBackend                  = flag.String("db-backend", "leveldb", "State database backend: leveldb/memory. In-memory state is lost on exit and requires empty state directory")
 	bindAddress                = flag.String("bind-address", "", "Bind address for incoming connections. If empty, will be same as declared address")
 	disableOutgoingConnections = flag.Bool("no-connections", false, "Disable outgoing network connections to peers. Default value is false.")
 	minerVoteFeatures          = flag.String("vote", "", "Miner vote features")
@@ -60,6 +67,10 @@ var (
 	walletPassword             = flag.String("wallet-password", "", "Pass password for wallet. Extremely insecure")
 	limitConnectionsS          = flag.String("limit-connections", "30", "N incoming and outgoing connections")
 	profiler                   = flag.Bool("profiler", false, "Start built-in profiler on 'http://localhost:6060/debug/pprof/'")
+	recordP2P                  = flag.String("record-p2p", "", "Record connections and messages of peers to the given file for replay. Not set by default")
+	secureKey                  = flag.String("secure-key", "", "Path to the node key file, enables the encrypted transport with the peers supporting it. The key is generated if the file doesn't exist")
+	secureAllow                = flag.String("secure-allow", "", "Comma separated base58 public node keys allowed over the encrypted transport, any key if empty. Non-empty list implies -secure-required")
+	secureRequired             = flag.Bool("secure-required", false, "Reject the peers that don't support the encrypted transport")
 )
 
 func debugCommandLineParameters() {
@@ -74,6 +85,7 @@ func debugCommandLineParameters() {
 	zap.S().Debugf("enable-grpc-api: %v", *enableGrpcApi)
 	zap.S().Debugf("build-extended-api: %v", *buildExtendedApi)
 	zap.S().Debugf("serve-extended-api: %v", *serveExtendedApi)
+	zap.S().Debugf("build-state-hashes: %v", *buildStateHashes)
 	zap.S().Debugf("bind-address: %s", *bindAddress)
 	zap.S().Debugf("no-connections: %v", *disableOutgoingConnections)
 	zap.S().Debugf("vote: %s", *minerVoteFeatures)
@@ -167,9 +179,17 @@ func main() {
 
 	go ntptm.Run(ctx, 2*time.Minute)
 
+	backend, err := keyvalue.NewBackend(*dbBackend)
+	if err != nil {
+		zap.S().Error(err)
+		cancel()
+		return
+	}
 	params := state.DefaultStateParams()
+	params.DbParams.Backend = backend
 	params.StoreExtendedApiData = *buildExtendedApi
 	params.ProvideExtendedApi = *serveExtendedApi
+	params.BuildStateHashes = *buildStateHashes
 	params.Time = ntptm
 	state, err := state.NewState(path, params, cfg)
 	if err != nil {
@@ -212,7 +232,28 @@ func main() {
 
 	parent := peer.NewParent()
 
-	peerSpawnerImpl := peer_manager.NewPeerSpawner(pool, parent, conf.WavesNetwork, declAddr, "gowaves", uint64(rand.Int()), version)
+	var p2pRecorder *recorder.Writer
+	if *recordP2P != "" {
+		p2pRecorder, err = recorder.Create(*recordP2P)
+		if err != nil {
+			zap.S().Error(err)
+			cancel()
+			return
+		}
+		zap.S().Infof("Recording P2P messages to '%s'", *recordP2P)
+	}
+
+	transport, err := secure.FromOptions(*secureKey, *secureAllow, *secureRequired)
+	if err != nil {
+		zap.S().Errorf("Failed to configure secure transport: %v", err)
+		cancel()
+		return
+	}
+	if transport != nil {
+		zap.S().Infof("Secure transport enabled, node key %s", base58.Encode(transport.PublicKey()))
+	}
+
+	peerSpawnerImpl := peer_manager.NewPeerSpawner(pool, parent, conf.WavesNetwork, declAddr, "gowaves", uint64(rand.Int()), version, peerRecorder(p2pRecorder), transport)
 
 	peerManager := peer_manager.NewPeerManager(peerSpawnerImpl, state, int(limitConnections))
 	go peerManager.Run(ctx)
@@ -284,6 +325,14 @@ func main() {
 		cancel()
 		return
 	}
+	app.SetLiquid(ngState)
+
+	txTracker := txstatus.NewTracker(state, utx, ngState, cfg.AddressSchemeCharacter)
+	utx.SetEvictionListener(txTracker)
+	async.Go(func() {
+		txTracker.Run(ctx)
+	})
+	app.SetTransactionTracker(txTracker)
 
 	webApi := api.NewNodeApi(app, state, n)
 	go func() {
@@ -298,6 +347,7 @@ func main() {
 		if err != nil {
 			zap.S().Errorf("Failed to create gRPC server: %v", err)
 		}
+		grpcServer.SetTransactionTracker(txTracker)
 		go func() {
 			err := grpcServer.Run(ctx, conf.GrpcAddr)
 			if err != nil {
@@ -315,10 +365,23 @@ func main() {
 	cancel()
 	<-time.After(2 * time.Second)
 	n.Close()
+	if p2pRecorder != nil {
+		if err := p2pRecorder.Close(); err != nil {
+			zap.S().Errorf("Failed to close P2P recording: %v", err)
+		}
+	}
 
 	<-time.After(2 * time.Second)
 }
 
+// peerRecorder avoids passing typed nil to peers.
+func peerRecorder(w *recorder.Writer) peer.Recorder {
+	if w == nil {
+		return nil
+	}
+	return w
+}
+
 func FromArgs(scheme proto.Scheme) func(s *settings.NodeSettings) error {
 	return func(s *settings.NodeSettings) error {
 		s.DeclaredAddr = *declAddr
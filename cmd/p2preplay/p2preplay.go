@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/libs/runner"
+	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
+	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
+	"github.com/wavesplatform/gowaves/pkg/ng"
+	"github.com/wavesplatform/gowaves/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/node/peer_manager"
+	"github.com/wavesplatform/gowaves/pkg/node/state_changed"
+	"github.com/wavesplatform/gowaves/pkg/p2p/recorder"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/scoresender"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+	"go.uber.org/zap"
+)
+
+var (
+	logLevel       = flag.String("log-level", "INFO", "Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	recording      = flag.String("recording", "", "Path to the P2P recording made with -record-p2p option of the node.")
+	statePath      = flag.String("state-path", "", "Path to the node's state directory, the replay is done on a copy of the state.")
+	copyPath       = flag.String("copy-path", "", "Directory to copy the state into, by default a temporary directory is created and removed after replay.")
+	blockchainType = flag.String("blockchain-type", "mainnet", "Blockchain type. Allowed values: mainnet/testnet/stagenet/custom. Default is 'mainnet'.")
+	cfgPath        = flag.String("cfg-path", "", "Path to blockchain settings JSON file for custom blockchains. Not set by default.")
+	speed          = flag.Float64("speed", 1, "Replay speed relative to the recording, zero replays messages without delays.")
+	wait           = flag.Duration("wait", 5*time.Second, "Time to wait for the node to finish processing after the last message.")
+)
+
+func main() {
+	flag.Parse()
+	common.SetupLogger(*logLevel)
+	os.Exit(run())
+}
+
+func run() int {
+	if *recording == "" || *statePath == "" {
+		zap.S().Error("Both -recording and -state-path options are required")
+		return 2
+	}
+	sets, err := blockchainSettings()
+	if err != nil {
+		zap.S().Errorf("Failed to load blockchain settings: %v", err)
+		return 2
+	}
+	dir := *copyPath
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "p2preplay")
+		if err != nil {
+			zap.S().Errorf("Failed to create temporary directory: %v", err)
+			return 2
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				zap.S().Warnf("Failed to remove state copy: %v", err)
+			}
+		}()
+	}
+	zap.S().Infof("Copying state to '%s'", dir)
+	if err := copyDir(*statePath, dir); err != nil {
+		zap.S().Errorf("Failed to copy state: %v", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gracefulStop := make(chan os.Signal, 1)
+	signal.Notify(gracefulStop, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-gracefulStop
+		cancel()
+	}()
+
+	replayer := recorder.NewReplayer(*speed)
+	params := state.DefaultStateParams()
+	params.Time = replayer
+	st, err := state.NewState(dir, params, sets)
+	if err != nil {
+		zap.S().Errorf("Failed to open state: %v", err)
+		return 1
+	}
+	n, sync := newNode(st, sets, replayer)
+	defer n.Close()
+	go sync.Run(ctx)
+
+	before, err := st.Height()
+	if err != nil {
+		zap.S().Errorf("Failed to get height: %v", err)
+		return 1
+	}
+	start := time.Now()
+	stats, err := replayer.ReplayFile(ctx, *recording, n)
+	if err != nil {
+		zap.S().Errorf("Replay failed: %v", err)
+		return 1
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(*wait):
+	}
+	locked := st.Mutex().RLock()
+	after, err := st.Height()
+	locked.Unlock()
+	if err != nil {
+		zap.S().Errorf("Failed to get height: %v", err)
+		return 1
+	}
+	fmt.Printf("Replayed in %s\n", time.Since(start).Round(time.Millisecond))
+	fmt.Printf("Peers:               %d\n", stats.Peers)
+	fmt.Printf("Inbound messages:    %d\n", stats.Inbound)
+	fmt.Printf("Malformed messages:  %d\n", stats.Malformed)
+	fmt.Printf("Recorded outbound:   %d\n", stats.Outbound)
+	fmt.Printf("Height:              %d -> %d\n", before, after)
+	return 0
+}
+
+// newNode creates the node that never mines and never connects to real peers.
+func newNode(st state.State, sets *settings.BlockchainSettings, replayer *recorder.Replayer) (*node.Node, *node.StateSync) {
+	peerManager := peer_manager.NewPeerManager(noSpawner{}, st, 100)
+	peerManager.SetConnectPeers(false)
+	sch := scheduler.NewScheduler(st, nil, sets, replayer, scheduler.StubConsensus{}, proto.NewTimestampFromUSeconds(0))
+	stateChanged := state_changed.NewStateChanged()
+	async := runner.NewAsync()
+	srv := services.Services{
+		State:              st,
+		Peers:              peerManager,
+		Scheduler:          sch,
+		BlocksApplier:      node.NewBlocksApplier(st, replayer),
+		UtxPool:            utxpool.New(10000, utxpool.NewValidator(st, replayer), sets),
+		Scheme:             sets.AddressSchemeCharacter,
+		BlockAddedNotifier: stateChanged,
+		Subscribe:          node.NewSubscribeService(),
+		InvRequester:       ng.NewInvRequester(),
+		LoggableRunner:     runner.NewLogRunner(async),
+		Time:               replayer,
+	}
+	ngState := ng.NewState(srv)
+	ngRuntime := ng.NewRuntime(srv, ngState)
+	stateChanged.AddHandler(state_changed.NewFuncHandler(func() {
+		ngState.BlockApplied()
+	}))
+	stateChanged.AddHandler(utxpool.NewCleaner(srv))
+	sync := node.NewStateSync(srv, scoresender.New(peerManager, st, 5*time.Second, async), srv.BlocksApplier)
+	return node.NewNode(srv, proto.TCPAddr{}, proto.TCPAddr{}, ngRuntime, sync), sync
+}
+
+type noSpawner struct{}
+
+func (noSpawner) SpawnOutgoing(context.Context, proto.TCPAddr) error {
+	return errors.New("connections are disabled during replay")
+}
+
+func (noSpawner) SpawnIncoming(context.Context, net.Conn) error {
+	return errors.New("connections are disabled during replay")
+}
+
+func blockchainSettings() (*settings.BlockchainSettings, error) {
+	if strings.ToLower(*blockchainType) == "custom" && *cfgPath != "" {
+		f, err := os.Open(*cfgPath)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		return settings.ReadBlockchainSettings(f)
+	}
+	return settings.BlockchainSettingsByTypeName(*blockchainType)
+}
+
+// copyDir copies the regular files of the source directory tree into the destination directory.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
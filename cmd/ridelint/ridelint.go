@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/analysis"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+)
+
+const base64Prefix = "base64:"
+
+var (
+	jsonOutput = flag.Bool("json", false, "Write the reports in JSON format.")
+	werror     = flag.Bool("Werror", false, "Treat warnings as errors.")
+)
+
+type result struct {
+	Script string           `json:"script"`
+	Error  string           `json:"error,omitempty"`
+	Report *analysis.Report `json:"report,omitempty"`
+}
+
+func main() {
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] script...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	os.Exit(run())
+}
+
+func run() int {
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return 2
+	}
+	results := make([]result, flag.NArg())
+	code := 0
+	for i, arg := range flag.Args() {
+		results[i].Script = arg
+		script, err := loadScript(arg)
+		if err != nil {
+			results[i].Error = err.Error()
+			code = 2
+			continue
+		}
+		r := analysis.Analyze(script)
+		results[i].Report = &r
+		if code == 0 && (r.Count(analysis.Error) > 0 || *werror && r.Count(analysis.Warning) > 0) {
+			code = 1
+		}
+	}
+	if *jsonOutput {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		if err := e.Encode(results); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to write reports: %v\n", err)
+			return 2
+		}
+		return code
+	}
+	printResults(os.Stdout, results)
+	return code
+}
+
+// loadScript reads the script from file or from the argument prefixed with "base64:".
+// Script file contains either raw bytes or base64 as returned by the compiler.
+func loadScript(arg string) (*ast.Script, error) {
+	data := []byte(arg)
+	if !strings.HasPrefix(arg, base64Prefix) {
+		var err error
+		data, err = ioutil.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(data)), base64Prefix)
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		data = b
+	}
+	return ast.BuildScript(reader.NewBytesReader(data))
+}
+
+func printResults(w io.Writer, results []result) {
+	for _, r := range results {
+		name := r.Script
+		if strings.HasPrefix(name, base64Prefix) {
+			name = "<inline>"
+		}
+		if r.Error != "" {
+			_, _ = fmt.Fprintf(w, "%s: failed to load script: %s\n", name, r.Error)
+			continue
+		}
+		for _, i := range r.Report.Issues {
+			fn := ""
+			if i.Function != "" {
+				fn = i.Function + ": "
+			}
+			_, _ = fmt.Fprintf(w, "%s: %-7s [%s] %s%s\n", name, i.Severity, i.Check, fn, i.Message)
+		}
+		if c := r.Report.Complexity; c != nil {
+			if r.Report.DApp {
+				_, _ = fmt.Fprintf(w, "%s: complexity %d (verifier %d, limit %d)\n", name, c.DApp, c.Verifier, c.Limit)
+			} else {
+				_, _ = fmt.Fprintf(w, "%s: complexity %d (limit %d)\n", name, c.Verifier, c.Limit)
+			}
+		}
+	}
+}
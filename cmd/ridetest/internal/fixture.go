@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"github.com/wavesplatform/gowaves/pkg/ride/mockstate"
+	"gopkg.in/yaml.v2"
+)
+
+const base64Prefix = "base64:"
+
+// Fixture is the file of test cases of one script.
+type Fixture struct {
+	Scheme string `json:"scheme"`
+	Script string `json:"script"` // Base64 encoded script prefixed with "base64:" or the path to the file relative to the fixture
+	State  State  `json:"state"`  // State shared by all test cases
+	Tests  []Case `json:"tests"`
+
+	path   string
+	script *ast.Script
+}
+
+// State describes the blockchain the script is evaluated against.
+type State struct {
+	Height       uint64                       `json:"height"`
+	Balances     map[string]map[string]uint64 `json:"balances"` // Address to asset ID or WAVES to balance
+	Data         map[string]proto.DataEntries `json:"data"`     // Address to data entries
+	Aliases      map[string]string            `json:"aliases"`  // Alias to address
+	Assets       []Asset                      `json:"assets"`
+	Blocks       []proto.BlockHeader          `json:"blocks"`
+	Transactions []json.RawMessage            `json:"transactions"` // Transactions with their heights
+}
+
+type Asset struct {
+	ID         crypto.Digest    `json:"id"`
+	Issuer     crypto.PublicKey `json:"issuer"`
+	Quantity   uint64           `json:"quantity"`
+	Decimals   byte             `json:"decimals"`
+	Reissuable bool             `json:"reissuable"`
+	Scripted   bool             `json:"scripted"`
+	Sponsored  bool             `json:"sponsored"`
+}
+
+// Case is the evaluation of the script with one transaction.
+type Case struct {
+	Name        string          `json:"name"`
+	State       State           `json:"state"` // Applied over the shared state
+	Transaction json.RawMessage `json:"transaction"`
+	This        string          `json:"this"`   // Address of the account, the sender or the invoked dApp by default
+	Asset       *crypto.Digest  `json:"asset"`  // Evaluate the script as the script of the asset
+	Verify      bool            `json:"verify"` // Evaluate the verifier of dApp with invoke script transaction
+	Expect      Expect          `json:"expect"`
+}
+
+// Expect lists the checks of the evaluation result, missing fields are not checked.
+type Expect struct {
+	Result    *bool              `json:"result"` // Result of verifier
+	Throws    *string            `json:"throws"` // Message of the thrown exception
+	Error     string             `json:"error"`  // Substring of the evaluation error
+	Writes    *proto.DataEntries `json:"writes"`
+	Transfers *[]Transfer        `json:"transfers"`
+}
+
+type Transfer struct {
+	Recipient proto.Recipient     `json:"recipient"`
+	Amount    int64               `json:"amount"`
+	Asset     proto.OptionalAsset `json:"asset"`
+}
+
+// LoadFixture reads the fixture in YAML or JSON format and the script it refers to.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = yamlToJSON(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid YAML")
+		}
+	}
+	f := &Fixture{path: path}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, errors.Wrap(err, "invalid fixture")
+	}
+	if f.Scheme == "" {
+		f.Scheme = string(proto.MainNetScheme)
+	}
+	if len(f.Scheme) != 1 {
+		return nil, errors.Errorf("invalid scheme '%s', single character expected", f.Scheme)
+	}
+	f.script, err = f.loadScript()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load script")
+	}
+	return f, nil
+}
+
+func (f *Fixture) loadScript() (*ast.Script, error) {
+	var data []byte
+	if strings.HasPrefix(f.Script, base64Prefix) {
+		data = []byte(f.Script)
+	} else {
+		if f.Script == "" {
+			return nil, errors.New("no script")
+		}
+		var err error
+		data, err = ioutil.ReadFile(filepath.Join(filepath.Dir(f.path), f.Script))
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Script file contains either raw bytes or base64 as returned by the compiler.
+	s := strings.TrimPrefix(strings.TrimSpace(string(data)), base64Prefix)
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		data = b
+	}
+	return ast.BuildScript(reader.NewBytesReader(data))
+}
+
+func (f *Fixture) scheme() proto.Scheme {
+	return f.Scheme[0]
+}
+
+// build creates the state from the shared state and the state of test case.
+func (f *Fixture) build(c *Case) (mockstate.State, error) {
+	st := mockstate.New()
+	for _, s := range []State{f.State, c.State} {
+		if err := s.apply(st, f.scheme()); err != nil {
+			return mockstate.State{}, err
+		}
+		if s.Height != 0 {
+			st.NewestHeightVal = s.Height
+		}
+	}
+	return st, nil
+}
+
+func (s State) apply(st mockstate.State, scheme proto.Scheme) error {
+	for addr, balances := range s.Balances {
+		acc, err := account(st, addr)
+		if err != nil {
+			return err
+		}
+		for asset, balance := range balances {
+			a, err := proto.NewOptionalAssetFromString(asset)
+			if err != nil {
+				return errors.Errorf("invalid asset '%s'", asset)
+			}
+			if a.Present {
+				acc.AssetsBalances[a.ID] = balance
+			} else {
+				acc.WavesBalance = balance
+			}
+		}
+	}
+	for addr, entries := range s.Data {
+		acc, err := account(st, addr)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			acc.DataEntries[e.GetKey()] = e
+		}
+	}
+	for alias, addr := range s.Aliases {
+		a, err := proto.NewAddressFromString(addr)
+		if err != nil {
+			return errors.Errorf("invalid address '%s' of alias '%s'", addr, alias)
+		}
+		if strings.HasPrefix(alias, proto.AliasPrefix) {
+			full, err := proto.NewAliasFromString(alias)
+			if err != nil {
+				return err
+			}
+			alias = full.Alias
+		}
+		st.Aliases[alias] = a
+	}
+	for _, asset := range s.Assets {
+		issuer, err := proto.NewAddressFromPublicKey(scheme, asset.Issuer)
+		if err != nil {
+			return err
+		}
+		st.Assets[asset.ID] = proto.AssetInfo{
+			ID:              asset.ID,
+			Quantity:        asset.Quantity,
+			Decimals:        asset.Decimals,
+			Issuer:          issuer,
+			IssuerPublicKey: asset.Issuer,
+			Reissuable:      asset.Reissuable,
+			Scripted:        asset.Scripted,
+			Sponsored:       asset.Sponsored,
+		}
+	}
+	for i := range s.Blocks {
+		h := s.Blocks[i]
+		if h.Height == 0 {
+			return errors.Errorf("no height of block %d", i)
+		}
+		st.BlockHeaders[h.Height] = &h
+	}
+	for _, data := range s.Transactions {
+		tx, err := decodeTransaction(data, scheme)
+		if err != nil {
+			return err
+		}
+		var h struct {
+			Height uint64 `json:"height"`
+		}
+		if err := json.Unmarshal(data, &h); err != nil {
+			return err
+		}
+		id, err := tx.GetID(scheme)
+		if err != nil {
+			return err
+		}
+		st.TransactionsByID[base58.Encode(id)] = tx
+		st.TransactionsHeightByID[base58.Encode(id)] = h.Height
+	}
+	return nil
+}
+
+func account(st mockstate.State, addr string) (*mockstate.Account, error) {
+	a, err := proto.NewAddressFromString(addr)
+	if err != nil {
+		return nil, errors.Errorf("invalid address '%s'", addr)
+	}
+	return st.Account(a), nil
+}
+
+func decodeTransaction(data []byte, scheme proto.Scheme) (proto.Transaction, error) {
+	tt := proto.TransactionTypeVersion{}
+	if err := json.Unmarshal(data, &tt); err != nil {
+		return nil, errors.Wrap(err, "invalid transaction")
+	}
+	tx, err := proto.GuessTransactionType(&tt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, tx); err != nil {
+		return nil, errors.Wrap(err, "invalid transaction")
+	}
+	if err := tx.GenerateID(scheme); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// yamlToJSON converts YAML document into JSON, so the fixtures are decoded with JSON unmarshalers of proto types.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(v))
+}
+
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = jsonValue(v)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = jsonValue(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Suite is the results of one fixture file, Error is set if the fixture can't be loaded.
+type Suite struct {
+	Path    string
+	Results []CaseResult
+	Error   error
+}
+
+func (s Suite) counts() (failures, errs int) {
+	if s.Error != nil {
+		return 0, 1
+	}
+	for _, r := range s.Results {
+		switch {
+		case r.Error != nil:
+			errs++
+		case r.Failure != "":
+			failures++
+		}
+	}
+	return failures, errs
+}
+
+// Passed returns true if all suites passed.
+func Passed(suites []Suite) bool {
+	for _, s := range suites {
+		if f, e := s.counts(); f+e > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PrintResults writes human readable results and the summary.
+func PrintResults(w io.Writer, suites []Suite, verbose bool) {
+	var total, failed, broken int
+	for _, s := range suites {
+		if s.Error != nil {
+			broken++
+			_, _ = fmt.Fprintf(w, "ERROR %s: %v\n", s.Path, s.Error)
+			continue
+		}
+		for _, r := range s.Results {
+			total++
+			switch {
+			case r.Error != nil:
+				failed++
+				_, _ = fmt.Fprintf(w, "ERROR %s: %s: %v\n", s.Path, r.Name, r.Error)
+			case r.Failure != "":
+				failed++
+				_, _ = fmt.Fprintf(w, "FAIL  %s: %s: %s\n", s.Path, r.Name, r.Failure)
+			case verbose:
+				_, _ = fmt.Fprintf(w, "PASS  %s: %s (%v)\n", s.Path, r.Name, r.Duration)
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(w, "%d tests, %d passed, %d failed", total, total-failed, failed)
+	if broken > 0 {
+		_, _ = fmt.Fprintf(w, ", %d fixtures not loaded", broken)
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+	Error    *junitError `xml:"error,omitempty"`
+}
+
+type junitCase struct {
+	Name      string      `xml:"name,attr"`
+	ClassName string      `xml:"classname,attr"`
+	Time      string      `xml:"time,attr"`
+	Failure   *junitError `xml:"failure,omitempty"`
+	Error     *junitError `xml:"error,omitempty"`
+}
+
+type junitError struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the results in JUnit XML format understood by CI servers.
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	r := junitSuites{Suites: make([]junitSuite, len(suites))}
+	for i, s := range suites {
+		failures, errs := s.counts()
+		js := junitSuite{Name: s.Path, Tests: len(s.Results), Failures: failures, Errors: errs}
+		if s.Error != nil {
+			js.Error = &junitError{Message: s.Error.Error()}
+		}
+		var d time.Duration
+		for _, c := range s.Results {
+			d += c.Duration
+			jc := junitCase{Name: c.Name, ClassName: s.Path, Time: seconds(c.Duration)}
+			switch {
+			case c.Error != nil:
+				jc.Error = &junitError{Message: c.Error.Error()}
+			case c.Failure != "":
+				jc.Failure = &junitError{Message: c.Failure}
+			}
+			js.Cases = append(js.Cases, jc)
+		}
+		js.Time = seconds(d)
+		r.Suites[i] = js
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(r); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
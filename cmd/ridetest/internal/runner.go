@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/mockstate"
+)
+
+// Outcome is the result of script evaluation.
+type Outcome struct {
+	Result       *bool               // Result of verifier
+	ScriptResult *proto.ScriptResult // Result of callable function
+	Thrown       *string             // Message of exception thrown by the script
+	Err          error               // Evaluation error other than exception
+}
+
+func (o Outcome) String() string {
+	switch {
+	case o.Thrown != nil:
+		return fmt.Sprintf("thrown '%s'", *o.Thrown)
+	case o.Err != nil:
+		return fmt.Sprintf("error '%v'", o.Err)
+	case o.Result != nil:
+		return fmt.Sprintf("result %t", *o.Result)
+	default:
+		return "script result"
+	}
+}
+
+// CaseResult is the result of one test case. Failure is the failed check, Error is set if the case can't be evaluated.
+type CaseResult struct {
+	Name     string
+	Duration time.Duration
+	Failure  string
+	Error    error
+}
+
+// Run evaluates all test cases of the fixture.
+func (f *Fixture) Run() []CaseResult {
+	r := make([]CaseResult, len(f.Tests))
+	for i := range f.Tests {
+		c := &f.Tests[i]
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("test %d", i+1)
+		}
+		start := time.Now()
+		o, err := f.evaluate(c)
+		r[i] = CaseResult{Name: name, Duration: time.Since(start), Error: err}
+		if err == nil {
+			r[i].Failure = check(c.Expect, o)
+		}
+	}
+	return r
+}
+
+func (f *Fixture) evaluate(c *Case) (Outcome, error) {
+	if len(c.Transaction) == 0 {
+		return Outcome{}, errors.New("no transaction")
+	}
+	tx, err := decodeTransaction(c.Transaction, f.scheme())
+	if err != nil {
+		return Outcome{}, err
+	}
+	st, err := f.build(c)
+	if err != nil {
+		return Outcome{}, errors.Wrap(err, "invalid state")
+	}
+	lastBlock, err := f.lastBlock(st)
+	if err != nil {
+		return Outcome{}, err
+	}
+	if invoke, ok := tx.(*proto.InvokeScriptWithProofs); ok && f.script.IsDapp() && c.Asset == nil && !c.Verify {
+		this, err := f.this(st, c, invoke.ScriptRecipient)
+		if err != nil {
+			return Outcome{}, err
+		}
+		sr, err := f.script.CallFunction(f.scheme(), st, invoke, ast.NewAddressFromProtoAddress(this), lastBlock)
+		if err != nil {
+			return failed(err), nil
+		}
+		return Outcome{ScriptResult: sr}, nil
+	}
+	var this ast.Expr
+	if c.Asset != nil {
+		info, err := st.NewestAssetInfo(*c.Asset)
+		if err != nil {
+			return Outcome{}, errors.Errorf("asset %s is not in state", c.Asset.String())
+		}
+		this = ast.NewObjectFromAssetInfo(*info)
+	} else {
+		sender, err := proto.NewAddressFromPublicKey(f.scheme(), tx.GetSenderPK())
+		if err != nil {
+			return Outcome{}, err
+		}
+		addr, err := f.this(st, c, proto.NewRecipientFromAddress(sender))
+		if err != nil {
+			return Outcome{}, err
+		}
+		this = ast.NewAddressFromProtoAddress(addr)
+	}
+	obj, err := ast.NewVariablesFromTransaction(f.scheme(), tx)
+	if err != nil {
+		return Outcome{}, errors.Wrap(err, "failed to convert transaction")
+	}
+	ok, err := f.verify(st, obj, this, lastBlock)
+	if err != nil {
+		return failed(err), nil
+	}
+	return Outcome{Result: &ok}, nil
+}
+
+// verify evaluates the verifier like ast.Script.Verify does, but keeps the message of thrown exception.
+func (f *Fixture) verify(st mockstate.State, obj map[string]ast.Expr, this, lastBlock ast.Expr) (bool, error) {
+	var scope *ast.ScopeImpl
+	var body ast.Expr
+	if f.script.IsDapp() {
+		fn := f.script.DApp.Verifier
+		if fn == nil {
+			return false, errors.New("verify function not defined")
+		}
+		scope = ast.NewScope(3, f.scheme(), st)
+		scope.AddValue(fn.AnnotationInvokeName, ast.NewObject(obj))
+		body = fn.FuncDecl.Body
+	} else {
+		scope = ast.NewScope(f.script.Version, f.scheme(), st)
+		scope.SetTransaction(obj)
+		body = f.script.Verifier
+	}
+	scope.SetThis(this)
+	scope.SetLastBlockInfo(lastBlock)
+	scope.SetHeight(st.NewestHeightVal)
+	for _, expr := range f.script.DApp.Declarations {
+		if _, err := expr.Evaluate(scope); err != nil {
+			return false, err
+		}
+	}
+	rs, err := body.Evaluate(scope)
+	if err != nil {
+		return false, err
+	}
+	b, ok := rs.(*ast.BooleanExpr)
+	if !ok {
+		return false, errors.Errorf("verifier returned %T instead of boolean", rs)
+	}
+	return b.Value, nil
+}
+
+func (f *Fixture) this(st mockstate.State, c *Case, def proto.Recipient) (proto.Address, error) {
+	if c.This != "" {
+		addr, err := proto.NewAddressFromString(c.This)
+		if err != nil {
+			return proto.Address{}, errors.Errorf("invalid address '%s'", c.This)
+		}
+		return addr, nil
+	}
+	if def.Address != nil {
+		return *def.Address, nil
+	}
+	addr, err := st.NewestAddrByAlias(*def.Alias)
+	if err != nil {
+		return proto.Address{}, errors.Errorf("alias '%s' is not in state", def.Alias.Alias)
+	}
+	return addr, nil
+}
+
+// lastBlock returns the header of the block at current height or the block with only the height set.
+func (f *Fixture) lastBlock(st mockstate.State) (ast.Expr, error) {
+	info := &proto.BlockInfo{Height: st.NewestHeightVal}
+	if h, ok := st.BlockHeaders[st.NewestHeightVal]; ok {
+		var err error
+		info, err = proto.BlockInfoFromHeader(f.scheme(), h, st.NewestHeightVal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ast.NewObjectFromBlockInfo(*info), nil
+}
+
+func failed(err error) Outcome {
+	if t, ok := errors.Cause(err).(ast.Throw); ok {
+		return Outcome{Thrown: &t.Message}
+	}
+	return Outcome{Err: err}
+}
+
+// check returns the description of the first failed expectation or empty string if all of them are met.
+func check(e Expect, o Outcome) string {
+	switch {
+	case e.Throws != nil:
+		if o.Thrown == nil || *o.Thrown != *e.Throws {
+			return fmt.Sprintf("expected thrown '%s', got %s", *e.Throws, o)
+		}
+		return ""
+	case e.Error != "":
+		if o.Err == nil || !strings.Contains(o.Err.Error(), e.Error) {
+			return fmt.Sprintf("expected error containing '%s', got %s", e.Error, o)
+		}
+		return ""
+	case o.Thrown != nil || o.Err != nil:
+		return fmt.Sprintf("unexpected %s", o)
+	}
+	if e.Result != nil && (o.Result == nil || *o.Result != *e.Result) {
+		return fmt.Sprintf("expected result %t, got %s", *e.Result, o)
+	}
+	if e.Writes != nil || e.Transfers != nil {
+		if o.ScriptResult == nil {
+			return fmt.Sprintf("expected script result, got %s", o)
+		}
+	}
+	if e.Writes != nil {
+		expected := append([]proto.DataEntry{}, *e.Writes...)
+		actual := append([]proto.DataEntry{}, o.ScriptResult.Writes...)
+		if msg := compare("writes", expected, actual); msg != "" {
+			return msg
+		}
+	}
+	if e.Transfers != nil {
+		actual := make([]Transfer, len(o.ScriptResult.Transfers))
+		for i, t := range o.ScriptResult.Transfers {
+			actual[i] = Transfer{Recipient: t.Recipient, Amount: t.Amount, Asset: t.Asset}
+		}
+		if msg := compare("transfers", *e.Transfers, actual); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// compare compares the JSON representations of values, so the difference is reported the way it's written in fixture.
+func compare(what string, expected, actual interface{}) string {
+	eb, err := json.Marshal(expected)
+	if err != nil {
+		return err.Error()
+	}
+	ab, err := json.Marshal(actual)
+	if err != nil {
+		return err.Error()
+	}
+	if string(eb) != string(ab) {
+		return fmt.Sprintf("expected %s %s, got %s", what, eb, ab)
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	// true && throw("mess")
+	throwScript = "AQMGCQAAAgAAAAECAAAABG1lc3MH7PDwAQ=="
+	// assetBalance(tx.sender, base58'BXBUNddxTGTQc3G4qHYn5E67SBwMj18zLncUr871iuRD') == 5
+	balanceScript = "AQkAAAAAAAACCQAD6wAAAAIIBQAAAAJ0eAAAAAZzZW5kZXIBAAAAIJxQIls8iGUc1935JolBz6bYc37eoPDtScOAM0lTNhY0AAAAAAAAAAAFjp6PBg=="
+	asset         = "BXBUNddxTGTQc3G4qHYn5E67SBwMj18zLncUr871iuRD"
+	// @Callable(i) func tellme(question: String) = WriteSet([DataEntry(question + "_q", question), DataEntry(question + "_a", question)])
+	dAppScript = "AAIDAAAAAAAAAAAAAAABAQAAABFnZXRQcmV2aW91c0Fuc3dlcgAAAAEAAAAHYWRkcmVzcwUAAAAHYWRkcmVzcwAAAAEAAAABaQEAAAAGdGVsbG1lAAAAAQAAAAhxdWVzdGlvbgQAAAAGYW5zd2VyCQEAAAARZ2V0UHJldmlvdXNBbnN3ZXIAAAABBQAAAAhxdWVzdGlvbgkBAAAACFdyaXRlU2V0AAAAAQkABEwAAAACCQEAAAAJRGF0YUVudHJ5AAAAAgkAASwAAAACBQAAAAZhbnN3ZXICAAAAAl9xBQAAAAhxdWVzdGlvbgkABEwAAAACCQEAAAAJRGF0YUVudHJ5AAAAAgkAASwAAAACBQAAAAZhbnN3ZXICAAAAAl9hBQAAAAZhbnN3ZXIFAAAAA25pbAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAAJAAAAAAAAAgkBAAAAEWdldFByZXZpb3VzQW5zd2VyAAAAAQkABCUAAAABCAUAAAACdHgAAAAGc2VuZGVyAgAAAAEx7gicPQ=="
+	// @Callable(i) func tellme(question: String) = TransferSet([ScriptTransfer(i.caller, 100, unit)])
+	transferScript = "AAIDAAAAAAAAAAAAAAAAAAAAAQAAAAFpAQAAAAZ0ZWxsbWUAAAABAAAACHF1ZXN0aW9uCQEAAAALVHJhbnNmZXJTZXQAAAABCQAETAAAAAIJAQAAAA5TY3JpcHRUcmFuc2ZlcgAAAAMIBQAAAAFpAAAABmNhbGxlcgAAAAAAAAAAZAUAAAAEdW5pdAUAAAADbmlsAAAAAH5a2L0="
+)
+
+var (
+	kp   = proto.MustKeyPair([]byte("ridetest"))
+	dApp = proto.MustKeyPair([]byte("dapp"))
+)
+
+func writeFixture(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "ridetest")
+	require.NoError(t, err)
+	return dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func txJSON(t *testing.T, tx proto.Transaction) string {
+	b, err := json.Marshal(tx)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func transfer(t *testing.T) string {
+	addr, err := kp.Addr(proto.MainNetScheme)
+	require.NoError(t, err)
+	tx := proto.NewUnsignedTransferWithProofs(2, kp.Public, proto.OptionalAsset{}, proto.OptionalAsset{}, 1600000000000, 100000000, 100000, proto.NewRecipientFromAddress(addr), &proto.LegacyAttachment{})
+	require.NoError(t, tx.Sign(proto.MainNetScheme, kp.Secret))
+	return txJSON(t, tx)
+}
+
+func invoke(t *testing.T) string {
+	addr, err := dApp.Addr(proto.MainNetScheme)
+	require.NoError(t, err)
+	fc := proto.FunctionCall{Name: "tellme", Arguments: proto.Arguments{proto.NewStringArgument("abc")}}
+	tx := proto.NewUnsignedInvokeScriptWithProofs(1, proto.MainNetScheme, kp.Public, proto.NewRecipientFromAddress(addr), fc, nil, proto.OptionalAsset{}, 500000, 1600000000000)
+	require.NoError(t, tx.Sign(proto.MainNetScheme, kp.Secret))
+	return txJSON(t, tx)
+}
+
+func run(t *testing.T, path string) []CaseResult {
+	f, err := LoadFixture(path)
+	require.NoError(t, err)
+	return f.Run()
+}
+
+func TestRunVerifier(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	sender, err := kp.Addr(proto.MainNetScheme)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "balance.txt"), []byte("base64:"+balanceScript+"\n"), 0644))
+
+	path := writeFixture(t, dir, "balance.yaml", fmt.Sprintf(`
+script: balance.txt
+state:
+  height: 10
+  balances:
+    %[1]s:
+      %[2]s: 5
+tests:
+  - name: enough
+    transaction: %[3]s
+    expect:
+      result: true
+  - name: not enough
+    state:
+      balances:
+        %[1]s:
+          %[2]s: 4
+    transaction: %[3]s
+    expect:
+      result: false
+  - name: wrong expectation
+    transaction: %[3]s
+    expect:
+      result: false
+  - name: no transaction
+`, sender.String(), asset, transfer(t)))
+	rs := run(t, path)
+	require.Len(t, rs, 4)
+	assert.Empty(t, rs[0].Failure)
+	assert.NoError(t, rs[0].Error)
+	assert.Empty(t, rs[1].Failure)
+	assert.Equal(t, "expected result false, got result true", rs[2].Failure)
+	assert.Error(t, rs[3].Error)
+
+	path = writeFixture(t, dir, "throw.json", fmt.Sprintf(`{"script": "base64:%s", "tests": [
+		{"transaction": %s, "expect": {"throws": "mess"}},
+		{"transaction": %s, "expect": {"result": true}}
+	]}`, throwScript, transfer(t), transfer(t)))
+	rs = run(t, path)
+	require.Len(t, rs, 2)
+	assert.Empty(t, rs[0].Failure)
+	assert.Equal(t, "unexpected thrown 'mess'", rs[1].Failure)
+}
+
+func TestRunCallable(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	caller, err := kp.Addr(proto.MainNetScheme)
+	require.NoError(t, err)
+
+	path := writeFixture(t, dir, "writes.yaml", fmt.Sprintf(`
+script: base64:%s
+tests:
+  - name: writes
+    transaction: %s
+    expect:
+      writes:
+        - {key: abc_q, type: string, value: abc}
+        - {key: abc_a, type: string, value: abc}
+  - name: wrong writes
+    transaction: %s
+    expect:
+      writes: []
+`, dAppScript, invoke(t), invoke(t)))
+	rs := run(t, path)
+	require.Len(t, rs, 2)
+	assert.Empty(t, rs[0].Failure)
+	assert.NoError(t, rs[0].Error)
+	assert.Contains(t, rs[1].Failure, "expected writes []")
+
+	path = writeFixture(t, dir, "transfers.yaml", fmt.Sprintf(`
+script: base64:%s
+tests:
+  - transaction: %s
+    expect:
+      transfers:
+        - {recipient: %s, amount: 100}
+`, transferScript, invoke(t), caller.String()))
+	rs = run(t, path)
+	require.Len(t, rs, 1)
+	assert.Equal(t, "test 1", rs[0].Name)
+	assert.Empty(t, rs[0].Failure)
+	assert.NoError(t, rs[0].Error)
+}
+
+func TestReport(t *testing.T) {
+	suites := []Suite{
+		{Path: "a.yaml", Results: []CaseResult{{Name: "ok"}, {Name: "bad", Failure: "expected result true, got result false"}}},
+		{Path: "b.yaml", Error: fmt.Errorf("no script")},
+	}
+	assert.False(t, Passed(suites))
+	assert.True(t, Passed([]Suite{{Path: "c.yaml", Results: []CaseResult{{Name: "ok"}}}}))
+
+	var out bytes.Buffer
+	PrintResults(&out, suites, true)
+	assert.Contains(t, out.String(), "FAIL  a.yaml: bad: expected result true, got result false")
+	assert.Contains(t, out.String(), "ERROR b.yaml: no script")
+	assert.Contains(t, out.String(), "2 tests, 1 passed, 1 failed, 1 fixtures not loaded")
+
+	out.Reset()
+	require.NoError(t, WriteJUnit(&out, suites))
+	assert.Contains(t, out.String(), `<testsuite name="a.yaml" tests="2" failures="1" errors="0"`)
+	assert.Contains(t, out.String(), `<failure message="expected result true, got result false"></failure>`)
+	assert.Contains(t, out.String(), `<testsuite name="b.yaml" tests="0" failures="0" errors="1"`)
+}
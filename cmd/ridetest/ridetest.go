@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/wavesplatform/gowaves/cmd/ridetest/internal"
+)
+
+var (
+	junit   = flag.String("junit", "", "Path to the file to write the results in JUnit XML format.")
+	verbose = flag.Bool("v", false, "Print passed tests too.")
+)
+
+func main() {
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options] fixture.yaml...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	os.Exit(run())
+}
+
+func run() int {
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return 2
+	}
+	suites := make([]internal.Suite, flag.NArg())
+	for i, path := range flag.Args() {
+		suites[i].Path = path
+		f, err := internal.LoadFixture(path)
+		if err != nil {
+			suites[i].Error = err
+			continue
+		}
+		suites[i].Results = f.Run()
+	}
+	internal.PrintResults(os.Stdout, suites, *verbose)
+	if *junit != "" {
+		if err := writeJUnit(*junit, suites); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "Failed to write JUnit report: %v\n", err)
+			return 2
+		}
+	}
+	if !internal.Passed(suites) {
+		return 1
+	}
+	return 0
+}
+
+func writeJUnit(path string, suites []internal.Suite) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := internal.WriteJUnit(f, suites); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
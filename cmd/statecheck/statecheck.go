@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+	"go.uber.org/zap"
+)
+
+const (
+	exitConsistent = 0
+	exitProblems   = 1
+	exitFailure    = 2
+)
+
+var (
+	logLevel       = flag.String("log-level", "INFO", "Logging level. Supported levels: DEBUG, INFO, WARN, ERROR, FATAL. Default logging level INFO.")
+	statePath      = flag.String("state-path", "", "Path to node's state directory.")
+	blockchainType = flag.String("blockchain-type", "mainnet", "Blockchain type. Allowed values: mainnet/testnet/stagenet/custom. Default is 'mainnet'.")
+	cfgPath        = flag.String("cfg-path", "", "Path to blockchain settings JSON file for custom blockchains. Not set by default.")
+	skipTxs        = flag.Bool("skip-txs", false, "Do not read every transaction from block storage, speeds up the check.")
+	truncate       = flag.Bool("truncate", false, "Truncate the state to the last consistent height if the problems can be fixed this way.")
+)
+
+func main() {
+	flag.Parse()
+	common.SetupLogger(*logLevel)
+	os.Exit(run())
+}
+
+func run() int {
+	if *statePath == "" {
+		zap.S().Error("You must specify state-path option.")
+		return exitFailure
+	}
+	sets, err := blockchainSettings()
+	if err != nil {
+		zap.S().Errorf("Failed to load blockchain settings: %v", err)
+		return exitFailure
+	}
+	params := state.DefaultStorageParams()
+	report, err := state.CheckState(*statePath, params, sets, !*skipTxs)
+	if err != nil {
+		zap.S().Errorf("Failed to check state: %v", err)
+		return exitFailure
+	}
+	printReport(report)
+	if report.Consistent() {
+		return exitConsistent
+	}
+	if !report.Repairable() {
+		fmt.Println("The problems can't be fixed by truncation, the state must be reimported")
+		return exitProblems
+	}
+	if !*truncate {
+		fmt.Printf("Run with -truncate option to truncate the state to height %d\n", report.LastConsistentHeight)
+		return exitProblems
+	}
+	zap.S().Infof("Truncating state to height %d...", report.LastConsistentHeight)
+	if err := state.TruncateState(*statePath, params, sets, report.LastConsistentHeight); err != nil {
+		zap.S().Errorf("Failed to truncate state: %v", err)
+		return exitFailure
+	}
+	report, err = state.CheckState(*statePath, params, sets, !*skipTxs)
+	if err != nil {
+		zap.S().Errorf("Failed to check state after truncation: %v", err)
+		return exitFailure
+	}
+	printReport(report)
+	if !report.Consistent() {
+		return exitProblems
+	}
+	return exitConsistent
+}
+
+func blockchainSettings() (*settings.BlockchainSettings, error) {
+	if strings.ToLower(*blockchainType) == "custom" && *cfgPath != "" {
+		f, err := os.Open(*cfgPath)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		return settings.ReadBlockchainSettings(f)
+	}
+	return settings.BlockchainSettingsByTypeName(*blockchainType)
+}
+
+func printReport(r *state.CheckReport) {
+	fmt.Printf("Database height:         %d\n", r.DBHeight)
+	fmt.Printf("Block storage height:    %d\n", r.StorageHeight)
+	fmt.Printf("Rollback min height:     %d\n", r.RollbackMinHeight)
+	if r.Consistent() {
+		fmt.Println("No problems found")
+		return
+	}
+	fmt.Printf("Last consistent height:  %d\n", r.LastConsistentHeight)
+	fmt.Printf("Problems found: %d\n", len(r.Problems)+r.OmittedProblems)
+	for _, p := range r.Problems {
+		fmt.Printf("  %s\n", p.String())
+	}
+	if r.OmittedProblems > 0 {
+		fmt.Printf("  ... and %d more\n", r.OmittedProblems)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"net/http"
 	"sort"
 	"strconv"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
+	"github.com/pkg/errors"
 	"github.com/rakyll/statik/fs"
 	"github.com/wavesplatform/gowaves/cmd/wmd/internal/data"
 	"github.com/wavesplatform/gowaves/cmd/wmd/internal/state"
@@ -31,6 +34,7 @@ const (
 	timeFramePlaceholder   = "TimeFrame"
 	fromPlaceholder        = "From"
 	toPlaceholder          = "To"
+	quoteAssetPlaceholder  = "QuoteAsset"
 )
 
 // Logger is a middleware that logs the start and end of each request, along
@@ -68,10 +72,12 @@ type DataFeedAPI struct {
 	done      chan struct{}
 	Storage   *state.Storage
 	Symbols   *data.Symbols
+	Streamer  *Streamer
 }
 
 func NewDataFeedAPI(interrupt <-chan struct{}, logger *zap.Logger, storage *state.Storage, address string, symbols *data.Symbols) *DataFeedAPI {
 	a := DataFeedAPI{interrupt: interrupt, done: make(chan struct{}), Storage: storage, Symbols: symbols}
+	a.Streamer = NewStreamer(&a)
 	fileSystem, err := fs.New()
 	if err != nil {
 		log.Fatalf("Failed to initialise Swagger: %v", err)
@@ -131,6 +137,11 @@ func (a *DataFeedAPI) routes() chi.Router {
 	r.Get(fmt.Sprintf("/trades/{%s}/{%s}/{%s:[1-9A-Za-z]+}/{%s:\\d+}", amountAssetPlaceholder, priceAssetPlaceholder, addressPlaceHolder, limitPlaceholder), a.tradesByAddress)
 	r.Get(fmt.Sprintf("/candles/{%s}/{%s}/{%s:\\d+}/{%s:\\d+}", amountAssetPlaceholder, priceAssetPlaceholder, timeFramePlaceholder, limitPlaceholder), a.candles)
 	r.Get(fmt.Sprintf("/candles/{%s}/{%s}/{%s:\\d+}/{%s:\\d+}/{%s:\\d+}", amountAssetPlaceholder, priceAssetPlaceholder, timeFramePlaceholder, fromPlaceholder, toPlaceholder), a.candlesRange)
+	r.Get(fmt.Sprintf("/address/{%s:[1-9A-Za-z]+}/trades/{%s:\\d+}", addressPlaceHolder, limitPlaceholder), a.addressTrades)
+	r.Get(fmt.Sprintf("/address/{%s:[1-9A-Za-z]+}/trades/{%s:\\d+}/{%s:\\d+}", addressPlaceHolder, fromPlaceholder, toPlaceholder), a.addressTradesRange)
+	r.Get(fmt.Sprintf("/address/{%s:[1-9A-Za-z]+}/volumes/{%s:\\d+}/{%s:\\d+}", addressPlaceHolder, fromPlaceholder, toPlaceholder), a.addressVolumes)
+	r.Get(fmt.Sprintf("/address/{%s:[1-9A-Za-z]+}/pnl/{%s}", addressPlaceHolder, quoteAssetPlaceholder), a.addressPnL)
+	r.Handle("/stream", a.Streamer.Handler())
 	return r
 }
 
@@ -615,6 +626,274 @@ func (a *DataFeedAPI) candlesRange(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (a *DataFeedAPI) addressTrades(w http.ResponseWriter, r *http.Request) {
+	address, err := proto.NewAddressFromString(chi.URLParam(r, addressPlaceHolder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	limit, err := strconv.Atoi(chi.URLParam(r, limitPlaceholder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if limit < 1 || limit > 1000 {
+		http.Error(w, fmt.Sprintf("Bad request: %d is invalid limit value, allowed between 1 and 1000", limit), http.StatusBadRequest)
+		return
+	}
+	ts, err := a.Storage.AddressTrades(address, 0, math.MaxInt64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	sort.Sort(sort.Reverse(data.TradesByTimestamp(ts)))
+	if len(ts) > limit {
+		ts = ts[:limit]
+	}
+	tis, err := a.convertToMarketTradesInfos(ts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to convert Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(tis)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to marshal Trades to JSON: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *DataFeedAPI) addressTradesRange(w http.ResponseWriter, r *http.Request) {
+	address, err := proto.NewAddressFromString(chi.URLParam(r, addressPlaceHolder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	f, t, err := parseRange(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	ts, err := a.Storage.AddressTrades(address, f, t)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	sort.Sort(sort.Reverse(data.TradesByTimestamp(ts)))
+	if len(ts) > 1000 {
+		ts = ts[:1000]
+	}
+	tis, err := a.convertToMarketTradesInfos(ts)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to convert Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(tis)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to marshal Trades to JSON: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *DataFeedAPI) addressVolumes(w http.ResponseWriter, r *http.Request) {
+	address, err := proto.NewAddressFromString(chi.URLParam(r, addressPlaceHolder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	f, t, err := parseRange(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	ts, err := a.Storage.AddressTrades(address, f, t)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	volumes := make(map[data.MarketID]data.Volume)
+	for _, t := range ts {
+		m := data.MarketID{AmountAsset: t.AmountAsset, PriceAsset: t.PriceAsset}
+		v := volumes[m]
+		if err := v.UpdateFromTrade(address, t); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to calculate Volumes: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		volumes[m] = v
+	}
+	vis := make([]data.VolumeInfo, 0, len(volumes))
+	for m, v := range volumes {
+		aai, err := a.Storage.AssetInfo(m.AmountAsset)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load AssetInfo: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		pai, err := a.Storage.AssetInfo(m.PriceAsset)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load AssetInfo: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		vis = append(vis, data.NewVolumeInfo(m, v, uint(aai.Decimals), uint(pai.Decimals)))
+	}
+	err = json.NewEncoder(w).Encode(vis)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to marshal Volumes to JSON: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (a *DataFeedAPI) addressPnL(w http.ResponseWriter, r *http.Request) {
+	address, err := proto.NewAddressFromString(chi.URLParam(r, addressPlaceHolder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	quoteAsset, err := a.Symbols.ParseTicker(chi.URLParam(r, quoteAssetPlaceholder))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Bad request: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	qai, err := a.Storage.AssetInfo(quoteAsset)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load AssetInfo: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	ts, err := a.Storage.AddressTrades(address, 0, math.MaxInt64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to load Trades: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	sort.Sort(data.TradesByTimestamp(ts))
+	positions := make(map[data.MarketID]*data.Position)
+	quoted := make(map[data.MarketID]*data.Position)
+	unconverted := make(map[data.MarketID]struct{})
+	for _, t := range ts {
+		if t.Buyer == t.Seller {
+			continue
+		}
+		m := data.MarketID{AmountAsset: t.AmountAsset, PriceAsset: t.PriceAsset}
+		if _, ok := positions[m]; !ok {
+			positions[m] = &data.Position{}
+			quoted[m] = &data.Position{}
+		}
+		pv := data.PriceAssetAmount(t.Amount, t.Price)
+		qv, ok, err := a.convert(pv, t.PriceAsset, quoteAsset, t.Timestamp)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to convert to quote asset: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			unconverted[m] = struct{}{}
+		}
+		if t.Buyer == address {
+			positions[m].Buy(t.Amount, pv)
+			quoted[m].Buy(t.Amount, qv)
+		} else {
+			positions[m].Sell(t.Amount, pv)
+			quoted[m].Sell(t.Amount, qv)
+		}
+	}
+	total := big.NewInt(0)
+	pis := make([]data.PositionInfo, 0, len(positions))
+	for m, p := range positions {
+		aai, err := a.Storage.AssetInfo(m.AmountAsset)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to load AssetInfo: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+		if _, ok := unconverted[m]; ok {
+			pai, err := a.Storage.AssetInfo(m.PriceAsset)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to load AssetInfo: %s", err.Error()), http.StatusInternalServerError)
+				return
+			}
+			pis = append(pis, data.NewPositionInfo(m, data.AssetID(m.PriceAsset), false, *p, uint(aai.Decimals), uint(pai.Decimals)))
+			continue
+		}
+		q := quoted[m]
+		total.Add(total, q.RealisedPnL())
+		pis = append(pis, data.NewPositionInfo(m, data.AssetID(quoteAsset), true, *q, uint(aai.Decimals), uint(qai.Decimals)))
+	}
+	pi := data.PortfolioInfo{
+		Address:     address,
+		QuoteAsset:  data.AssetID(quoteAsset),
+		RealisedPnL: data.NewSignedDecimal(total, uint(qai.Decimals)),
+		Positions:   pis,
+	}
+	err = json.NewEncoder(w).Encode(pi)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to marshal Portfolio to JSON: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+}
+
+// convert converts the value of the asset into the quote asset using the close price of the latest candle of the market
+// of the two assets at the given timestamp. Returns false if there is no market or no candles to calculate the rate.
+func (a *DataFeedAPI) convert(value *big.Int, asset, quote crypto.Digest, timestamp uint64) (*big.Int, bool, error) {
+	if asset == quote {
+		return value, true, nil
+	}
+	tf := data.TimeFrameFromTimestampMS(timestamp)
+	c, ok, err := a.Storage.LastCandle(asset, quote, tf)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok && c.Close > 0 {
+		return data.PriceAssetAmount(value.Uint64(), c.Close), true, nil
+	}
+	c, ok, err = a.Storage.LastCandle(quote, asset, tf)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok && c.Close > 0 {
+		r := big.NewInt(0).Mul(value, big.NewInt(100000000))
+		return r.Quo(r, big.NewInt(0).SetUint64(c.Close)), true, nil
+	}
+	return value, false, nil
+}
+
+func (a *DataFeedAPI) convertToMarketTradesInfos(trades []data.Trade) ([]data.MarketTradeInfo, error) {
+	decimals := make(map[crypto.Digest]uint)
+	assetDecimals := func(asset crypto.Digest) (uint, error) {
+		if d, ok := decimals[asset]; ok {
+			return d, nil
+		}
+		ai, err := a.Storage.AssetInfo(asset)
+		if err != nil {
+			return 0, err
+		}
+		decimals[asset] = uint(ai.Decimals)
+		return uint(ai.Decimals), nil
+	}
+	r := make([]data.MarketTradeInfo, len(trades))
+	for i, t := range trades {
+		ad, err := assetDecimals(t.AmountAsset)
+		if err != nil {
+			return nil, err
+		}
+		pd, err := assetDecimals(t.PriceAsset)
+		if err != nil {
+			return nil, err
+		}
+		r[i] = data.NewMarketTradeInfo(t, ad, pd)
+	}
+	return r, nil
+}
+
+func parseRange(r *http.Request) (uint64, uint64, error) {
+	f, err := strconv.ParseUint(chi.URLParam(r, fromPlaceholder), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	t, err := strconv.ParseUint(chi.URLParam(r, toPlaceholder), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if f > t {
+		return 0, 0, errors.Errorf("start of the range %d should be less than %d", f, t)
+	}
+	return f, t, nil
+}
+
 func (a *DataFeedAPI) convertToTradesInfos(trades []data.Trade, amountAssetDecimals, priceAssetDecimals byte) ([]data.TradeInfo, error) {
 	var r []data.TradeInfo
 	for i := 0; i < len(trades); i++ {
@@ -640,6 +919,22 @@ func (a *DataFeedAPI) convertToTickerInfo(aa, pa *data.AssetInfo, aaBalance, paB
 	return data.NewTickerInfo(sb.String(), *aa, *pa, aaBalance, paBalance, c)
 }
 
+func (a *DataFeedAPI) tickerInfo(aai, pai *data.AssetInfo) (data.TickerInfo, error) {
+	c, err := a.Storage.DayCandle(aai.ID, pai.ID)
+	if err != nil {
+		return data.TickerInfo{}, errors.Wrap(err, "failed to load DayCandle")
+	}
+	aab, err := a.getIssuerBalance(aai.IssuerAddress, aai.ID)
+	if err != nil {
+		return data.TickerInfo{}, errors.Wrap(err, "failed to get issuer's balance")
+	}
+	pab, err := a.getIssuerBalance(pai.IssuerAddress, pai.ID)
+	if err != nil {
+		return data.TickerInfo{}, errors.Wrap(err, "failed to get issuer's balance")
+	}
+	return a.convertToTickerInfo(aai, pai, aab, pab, c), nil
+}
+
 func (a *DataFeedAPI) getIssuerBalance(issuer proto.Address, asset crypto.Digest) (uint64, error) {
 	if bytes.Equal(issuer[:], data.WavesIssuerAddress[:]) {
 		return 0, nil
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/cmd/wmd/internal/data"
+	"github.com/wavesplatform/gowaves/cmd/wmd/internal/state"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type apiTestObjects struct {
+	api     *DataFeedAPI
+	storage *state.Storage
+	asset   crypto.Digest
+	market  data.MarketID
+	trade   data.Trade
+}
+
+func createTestAPI(t *testing.T) (*apiTestObjects, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "wmd-api")
+	require.NoError(t, err)
+	asset := crypto.MustDigestFromBase58("3Janbh2r7ZQjiUM3sWVswVGHWyQB2TPxm348QvuX5v6c")
+	symbolsFile := filepath.Join(dir, "symbols")
+	err = ioutil.WriteFile(symbolsFile, []byte("USD "+asset.String()+"\n"), 0644)
+	require.NoError(t, err)
+	symbols, err := data.NewSymbolsFromFile(symbolsFile, proto.Address{})
+	require.NoError(t, err)
+	storage := &state.Storage{Path: filepath.Join(dir, "db"), Scheme: proto.MainNetScheme}
+	err = storage.Open()
+	require.NoError(t, err)
+
+	interrupt := make(chan struct{})
+	api := &DataFeedAPI{interrupt: interrupt, Storage: storage, Symbols: symbols}
+	api.Streamer = NewStreamer(api)
+	storage.Observer = api.Streamer
+
+	pk, err := crypto.NewPublicKeyFromBase58("J9tmfpmsP5akzyBdsExFEuE1ceVAtW3kWadqZxchXHnY")
+	require.NoError(t, err)
+	issue := data.IssueChange{AssetID: asset, Name: "USD", Issuer: pk, Decimals: 2, Quantity: 1000000}
+	err = storage.PutBalances(1, proto.NewBlockIDFromSignature(crypto.Signature{1}), []data.IssueChange{issue}, nil, nil, nil)
+	require.NoError(t, err)
+
+	buyer, err := proto.NewAddressFromString("3P4KdaNYJq7BBcsgrsAPArc66LyLQAQvJc2")
+	require.NoError(t, err)
+	seller, err := proto.NewAddressFromString("3PAmhzHgxzxqVttGFRgVCFUFHoGHqmuchec")
+	require.NoError(t, err)
+	matcher, err := proto.NewAddressFromString("3PJaDyprvekvPXPuAtxrapacuDJopgJRaU3")
+	require.NoError(t, err)
+	trade := data.Trade{
+		AmountAsset:   data.WavesID,
+		PriceAsset:    asset,
+		TransactionID: crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd"),
+		OrderType:     proto.Buy,
+		Buyer:         buyer,
+		Seller:        seller,
+		Matcher:       matcher,
+		Price:         12345,
+		Amount:        67890,
+		Timestamp:     1548230341666,
+	}
+	to := &apiTestObjects{
+		api:     api,
+		storage: storage,
+		asset:   asset,
+		market:  data.MarketID{AmountAsset: data.WavesID, PriceAsset: asset},
+		trade:   trade,
+	}
+	return to, func() {
+		close(interrupt)
+		err := storage.Close()
+		assert.NoError(t, err)
+		err = os.RemoveAll(dir)
+		assert.NoError(t, err)
+	}
+}
+
+func get(t *testing.T, a *DataFeedAPI, path string) (int, string) {
+	w := httptest.NewRecorder()
+	a.routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+// putAddressTrades stores two trades of the buyer of the test trade: buying of 2 WAVES for 1.00 USD each
+// and selling of 1 WAVES for 3.00 USD an hour later.
+func putAddressTrades(t *testing.T, to *apiTestObjects) proto.Address {
+	address := to.trade.Buyer
+	buy := to.trade
+	buy.Amount = 200000000
+	buy.Price = 100
+	sell := to.trade
+	sell.TransactionID = crypto.MustDigestFromBase58("F2fdfc2kxgV9ugBeuuYFAgHYnXtc6uvKnQdc5eRXcrMv")
+	sell.OrderType = proto.Sell
+	sell.Buyer, sell.Seller = to.trade.Seller, address
+	sell.Amount = 100000000
+	sell.Price = 300
+	sell.Timestamp += 60 * 60 * 1000
+	err := to.storage.PutBalances(2, proto.NewBlockIDFromSignature(crypto.Signature{2}), nil, nil, nil, nil)
+	require.NoError(t, err)
+	err = to.storage.PutTrades(2, proto.NewBlockIDFromSignature(crypto.Signature{2}), []data.Trade{buy, sell})
+	require.NoError(t, err)
+	return address
+}
+
+func TestAddressVolumes(t *testing.T) {
+	to, cleanup := createTestAPI(t)
+	defer cleanup()
+	address := putAddressTrades(t, to)
+
+	code, body := get(t, to.api, "/address/"+address.String()+"/volumes/0/9999999999999")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `[{
+		"amountAssetID": "WAVES",
+		"priceAssetID": "`+to.asset.String()+`",
+		"trades": 2,
+		"buyVolume": "2.00000000",
+		"sellVolume": "1.00000000",
+		"buyPriceVolume": "2.00",
+		"sellPriceVolume": "3.00"
+	}]`, body)
+
+	// The second trade is out of range.
+	code, body = get(t, to.api, "/address/"+address.String()+"/volumes/0/1548230341666")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `[{
+		"amountAssetID": "WAVES",
+		"priceAssetID": "`+to.asset.String()+`",
+		"trades": 1,
+		"buyVolume": "2.00000000",
+		"sellVolume": "0.00000000",
+		"buyPriceVolume": "2.00",
+		"sellPriceVolume": "0.00"
+	}]`, body)
+
+	code, _ = get(t, to.api, "/address/"+address.String()+"/volumes/2/1")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(t, to.api, "/address/invalid/volumes/0/1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestAddressPnL(t *testing.T) {
+	to, cleanup := createTestAPI(t)
+	defer cleanup()
+	address := putAddressTrades(t, to)
+
+	// Half of the position bought for 2.00 USD is sold for 3.00 USD.
+	code, body := get(t, to.api, "/address/"+address.String()+"/pnl/USD")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `{
+		"address": "`+address.String()+`",
+		"quoteAssetID": "`+to.asset.String()+`",
+		"realisedPnL": "2.00",
+		"positions": [{
+			"amountAssetID": "WAVES",
+			"priceAssetID": "`+to.asset.String()+`",
+			"quoteAssetID": "`+to.asset.String()+`",
+			"converted": true,
+			"position": "1.00000000",
+			"averageEntryPrice": "1.00",
+			"realisedPnL": "2.00"
+		}]
+	}`, body)
+
+	// In WAVES the trades are converted at the prices of the trades, so there is no profit.
+	code, body = get(t, to.api, "/address/"+address.String()+"/pnl/WAVES")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `{
+		"address": "`+address.String()+`",
+		"quoteAssetID": "WAVES",
+		"realisedPnL": "0.00000000",
+		"positions": [{
+			"amountAssetID": "WAVES",
+			"priceAssetID": "`+to.asset.String()+`",
+			"quoteAssetID": "WAVES",
+			"converted": true,
+			"position": "1.00000000",
+			"averageEntryPrice": "1.00000000",
+			"realisedPnL": "0.00000000"
+		}]
+	}`, body)
+
+	code, _ = get(t, to.api, "/address/"+address.String()+"/pnl/UNKNOWN")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(t, to.api, "/address/invalid/pnl/USD")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
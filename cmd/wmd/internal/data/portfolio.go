@@ -0,0 +1,194 @@
+package data
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+)
+
+const (
+	priceConstant = 100000000
+)
+
+// PriceAssetAmount returns the amount of price asset that corresponds to the given amount of amount asset at the given price.
+func PriceAssetAmount(amount, price uint64) *big.Int {
+	a := big.NewInt(0).SetUint64(amount)
+	p := big.NewInt(0).SetUint64(price)
+	r := a.Mul(a, p)
+	return r.Quo(r, big.NewInt(priceConstant))
+}
+
+// SignedDecimal is a Decimal that could be negative.
+type SignedDecimal struct {
+	Decimal
+	Negative bool
+}
+
+func NewSignedDecimal(value *big.Int, scale uint) SignedDecimal {
+	v := big.NewInt(0).Abs(value)
+	return SignedDecimal{Decimal: Decimal{v.Uint64(), scale}, Negative: value.Sign() < 0}
+}
+
+func (d SignedDecimal) MarshalJSON() ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteRune('"')
+	sb.WriteString(d.String())
+	sb.WriteRune('"')
+	return []byte(sb.String()), nil
+}
+
+func (d *SignedDecimal) String() string {
+	if d.Negative && d.value != 0 {
+		return "-" + d.Decimal.String()
+	}
+	return d.Decimal.String()
+}
+
+// Volume accumulates the trading volume of an address on a market.
+type Volume struct {
+	Trades          int
+	BuyAmount       uint64
+	SellAmount      uint64
+	BuyPriceVolume  uint64
+	SellPriceVolume uint64
+}
+
+// UpdateFromTrade adds the trade to the volume. The volume is left unchanged if it overflows.
+func (v *Volume) UpdateFromTrade(address proto.Address, t Trade) error {
+	pv := PriceAssetAmount(t.Amount, t.Price)
+	if !pv.IsUint64() {
+		return errors.Errorf("price volume of trade '%s' overflows", t.TransactionID.String())
+	}
+	r := *v
+	var err error
+	if t.Buyer == address {
+		if r.BuyAmount, err = common.AddUint64(r.BuyAmount, t.Amount); err != nil {
+			return errors.Wrap(err, "buy volume overflows")
+		}
+		if r.BuyPriceVolume, err = common.AddUint64(r.BuyPriceVolume, pv.Uint64()); err != nil {
+			return errors.Wrap(err, "buy price volume overflows")
+		}
+	}
+	if t.Seller == address {
+		if r.SellAmount, err = common.AddUint64(r.SellAmount, t.Amount); err != nil {
+			return errors.Wrap(err, "sell volume overflows")
+		}
+		if r.SellPriceVolume, err = common.AddUint64(r.SellPriceVolume, pv.Uint64()); err != nil {
+			return errors.Wrap(err, "sell price volume overflows")
+		}
+	}
+	r.Trades++
+	*v = r
+	return nil
+}
+
+// Position accumulates the trades of an address on a market to calculate the average entry price and
+// the realised profit and loss using the average cost method. Costs and proceeds are accounted in quote asset,
+// which could differ from the price asset of the market. Only long positions are tracked, so the part of
+// the sell that exceeds the opened position does not affect the realised PnL.
+type Position struct {
+	Amount   uint64
+	cost     big.Int
+	realised big.Int
+}
+
+// Buy increases the position by the amount of amount asset bought for the value in quote asset.
+func (p *Position) Buy(amount uint64, value *big.Int) {
+	p.Amount += amount
+	p.cost.Add(&p.cost, value)
+}
+
+// Sell decreases the position by the amount of amount asset sold for the value in quote asset.
+func (p *Position) Sell(amount uint64, value *big.Int) {
+	if p.Amount == 0 || amount == 0 {
+		return
+	}
+	matched := amount
+	if matched > p.Amount {
+		matched = p.Amount
+	}
+	m := big.NewInt(0).SetUint64(matched)
+	// Proceeds of the matched part of the sell
+	proceeds := big.NewInt(0).Mul(value, m)
+	proceeds.Quo(proceeds, big.NewInt(0).SetUint64(amount))
+	// Cost of the matched part of the position
+	cost := big.NewInt(0).Mul(&p.cost, m)
+	cost.Quo(cost, big.NewInt(0).SetUint64(p.Amount))
+	p.realised.Add(&p.realised, proceeds.Sub(proceeds, cost))
+	p.cost.Sub(&p.cost, cost)
+	p.Amount -= matched
+}
+
+// AverageEntryPrice returns the average price of the opened position in quote asset for one whole unit of amount asset.
+func (p *Position) AverageEntryPrice(amountAssetDecimals uint) *big.Int {
+	if p.Amount == 0 {
+		return big.NewInt(0)
+	}
+	r := big.NewInt(0).Mul(&p.cost, big.NewInt(0).Exp(big.NewInt(10), big.NewInt(int64(amountAssetDecimals)), nil))
+	return r.Quo(r, big.NewInt(0).SetUint64(p.Amount))
+}
+
+// RealisedPnL returns the realised profit (or loss if negative) in quote asset.
+func (p *Position) RealisedPnL() *big.Int {
+	return big.NewInt(0).Set(&p.realised)
+}
+
+// VolumeInfo is an API representation of the address' Volume on a market.
+type VolumeInfo struct {
+	AmountAssetID   AssetID `json:"amountAssetID"`
+	PriceAssetID    AssetID `json:"priceAssetID"`
+	Trades          int     `json:"trades"`
+	BuyVolume       Decimal `json:"buyVolume"`
+	SellVolume      Decimal `json:"sellVolume"`
+	BuyPriceVolume  Decimal `json:"buyPriceVolume"`
+	SellPriceVolume Decimal `json:"sellPriceVolume"`
+}
+
+func NewVolumeInfo(market MarketID, volume Volume, amountAssetDecimals, priceAssetDecimals uint) VolumeInfo {
+	return VolumeInfo{
+		AmountAssetID:   AssetID(market.AmountAsset),
+		PriceAssetID:    AssetID(market.PriceAsset),
+		Trades:          volume.Trades,
+		BuyVolume:       Decimal{volume.BuyAmount, amountAssetDecimals},
+		SellVolume:      Decimal{volume.SellAmount, amountAssetDecimals},
+		BuyPriceVolume:  Decimal{volume.BuyPriceVolume, priceAssetDecimals},
+		SellPriceVolume: Decimal{volume.SellPriceVolume, priceAssetDecimals},
+	}
+}
+
+// PositionInfo is an API representation of the address' Position on a market.
+type PositionInfo struct {
+	AmountAssetID     AssetID       `json:"amountAssetID"`
+	PriceAssetID      AssetID       `json:"priceAssetID"`
+	QuoteAssetID      AssetID       `json:"quoteAssetID"`
+	Converted         bool          `json:"converted"`
+	Position          Decimal       `json:"position"`
+	AverageEntryPrice Decimal       `json:"averageEntryPrice"`
+	RealisedPnL       SignedDecimal `json:"realisedPnL"`
+}
+
+// NewPositionInfo creates PositionInfo, if the position was not converted to the quote asset, the price asset of the market
+// should be used as the quote asset.
+func NewPositionInfo(market MarketID, quoteAsset AssetID, converted bool, position Position, amountAssetDecimals, quoteAssetDecimals uint) PositionInfo {
+	return PositionInfo{
+		AmountAssetID:     AssetID(market.AmountAsset),
+		PriceAssetID:      AssetID(market.PriceAsset),
+		QuoteAssetID:      quoteAsset,
+		Converted:         converted,
+		Position:          Decimal{position.Amount, amountAssetDecimals},
+		AverageEntryPrice: Decimal{position.AverageEntryPrice(amountAssetDecimals).Uint64(), quoteAssetDecimals},
+		RealisedPnL:       NewSignedDecimal(position.RealisedPnL(), quoteAssetDecimals),
+	}
+}
+
+// PortfolioInfo is an API representation of the address' positions on all markets.
+// Total realised PnL includes only the positions converted to the quote asset.
+type PortfolioInfo struct {
+	Address     proto.Address  `json:"address"`
+	QuoteAsset  AssetID        `json:"quoteAssetID"`
+	RealisedPnL SignedDecimal  `json:"realisedPnL"`
+	Positions   []PositionInfo `json:"positions"`
+}
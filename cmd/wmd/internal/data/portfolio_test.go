@@ -0,0 +1,71 @@
+package data
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestPriceAssetAmount(t *testing.T) {
+	assert.Equal(t, int64(50000000), PriceAssetAmount(100000000, 50000000).Int64())
+	assert.Equal(t, int64(123), PriceAssetAmount(100, 123000000).Int64())
+}
+
+func TestSignedDecimalJSON(t *testing.T) {
+	tests := []struct {
+		value    int64
+		scale    uint
+		expected string
+	}{
+		{12345, 2, "\"123.45\""},
+		{-12345, 2, "\"-123.45\""},
+		{0, 2, "\"0.00\""},
+		{-5, 8, "\"-0.00000005\""},
+	}
+	for _, tc := range tests {
+		d := NewSignedDecimal(big.NewInt(tc.value), tc.scale)
+		b, err := json.Marshal(d)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, string(b))
+	}
+}
+
+func TestPosition(t *testing.T) {
+	p := Position{}
+	p.Buy(100, big.NewInt(1000))
+	p.Buy(100, big.NewInt(3000))
+	assert.Equal(t, uint64(200), p.Amount)
+	assert.Equal(t, int64(2000), p.AverageEntryPrice(2).Int64())
+	p.Sell(50, big.NewInt(1500))
+	assert.Equal(t, uint64(150), p.Amount)
+	assert.Equal(t, int64(500), p.RealisedPnL().Int64())
+	assert.Equal(t, int64(2000), p.AverageEntryPrice(2).Int64())
+	p.Sell(300, big.NewInt(3000))
+	assert.Equal(t, uint64(0), p.Amount)
+	assert.Equal(t, int64(-1000), p.RealisedPnL().Int64())
+	assert.Equal(t, int64(0), p.AverageEntryPrice(2).Int64())
+	p.Sell(100, big.NewInt(1000))
+	assert.Equal(t, int64(-1000), p.RealisedPnL().Int64())
+}
+
+func TestVolumeUpdateFromTrade(t *testing.T) {
+	a, err := proto.NewAddressFromString("3P4KdaNYJq7BBcsgrsAPArc66LyLQAQvJc2")
+	require.NoError(t, err)
+	o, err := proto.NewAddressFromString("3PAmhzHgxzxqVttGFRgVCFUFHoGHqmuchec")
+	require.NoError(t, err)
+	v := Volume{}
+	require.NoError(t, v.UpdateFromTrade(a, Trade{Buyer: a, Seller: o, Price: 200000000, Amount: 10}))
+	require.NoError(t, v.UpdateFromTrade(a, Trade{Buyer: o, Seller: a, Price: 300000000, Amount: 5}))
+	expected := Volume{Trades: 2, BuyAmount: 10, SellAmount: 5, BuyPriceVolume: 20, SellPriceVolume: 15}
+	assert.Equal(t, expected, v)
+
+	// Overflowing trades are rejected and don't change the volume.
+	assert.Error(t, v.UpdateFromTrade(a, Trade{Buyer: a, Seller: o, Price: math.MaxUint64, Amount: math.MaxUint64}))
+	assert.Error(t, v.UpdateFromTrade(a, Trade{Buyer: o, Seller: a, Price: 1, Amount: math.MaxUint64}))
+	assert.Equal(t, expected, v)
+}
@@ -204,3 +204,32 @@ func (a TradesByTimestampBackward) Swap(i, j int) {
 func (a TradesByTimestampBackward) Less(i, j int) bool {
 	return a[i].Timestamp > a[j].Timestamp
 }
+
+type TradesByTimestamp []Trade
+
+func (a TradesByTimestamp) Len() int {
+	return len(a)
+}
+
+func (a TradesByTimestamp) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+func (a TradesByTimestamp) Less(i, j int) bool {
+	return a[i].Timestamp < a[j].Timestamp
+}
+
+// MarketTradeInfo is an API representation of the Trade supplemented with the assets of the market.
+type MarketTradeInfo struct {
+	AmountAssetID AssetID `json:"amountAssetID"`
+	PriceAssetID  AssetID `json:"priceAssetID"`
+	TradeInfo
+}
+
+func NewMarketTradeInfo(trade Trade, amountAssetPrecision, priceAssetPrecision uint) MarketTradeInfo {
+	return MarketTradeInfo{
+		AmountAssetID: AssetID(trade.AmountAsset),
+		PriceAssetID:  AssetID(trade.PriceAsset),
+		TradeInfo:     NewTradeInfo(trade, amountAssetPrecision, priceAssetPrecision),
+	}
+}
@@ -17,10 +17,17 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/opt"
 )
 
+// TradesObserver is notified by the Storage about trades that was stored or removed on rollback.
+type TradesObserver interface {
+	TradesAdded(height int, trades []data.Trade)
+	TradesRemoved(height int, trades []data.Trade)
+}
+
 type Storage struct {
-	Path   string
-	Scheme byte
-	db     *leveldb.DB
+	Path     string
+	Scheme   byte
+	Observer TradesObserver
+	db       *leveldb.DB
 }
 
 func (s *Storage) Open() error {
@@ -60,6 +67,9 @@ func (s *Storage) PutTrades(height int, block proto.BlockID, trades []data.Trade
 	if err != nil {
 		return wrapError(err)
 	}
+	if s.Observer != nil && len(trades) > 0 {
+		s.Observer.TradesAdded(height, trades)
+	}
 	return nil
 }
 
@@ -140,7 +150,7 @@ func (s *Storage) Rollback(removeHeight int) error {
 	}
 	batch := new(leveldb.Batch)
 	rh := uint32(removeHeight)
-	err = rollbackTrades(snapshot, batch, rh)
+	removed, err := rollbackTrades(snapshot, batch, rh)
 	if err != nil {
 		return err
 	}
@@ -164,6 +174,9 @@ func (s *Storage) Rollback(removeHeight int) error {
 	if err != nil {
 		return err
 	}
+	if s.Observer != nil && len(removed) > 0 {
+		s.Observer.TradesRemoved(removeHeight, removed)
+	}
 	return nil
 }
 
@@ -230,6 +243,16 @@ func (s *Storage) TradesByAddress(amountAsset, priceAsset crypto.Digest, address
 	return addressTrades(snapshot, amountAsset, priceAsset, address, limit)
 }
 
+// AddressTrades returns the trades of the address on all markets within the given time range.
+func (s *Storage) AddressTrades(address proto.Address, from, to uint64) ([]data.Trade, error) {
+	snapshot, err := s.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+	return allAddressTrades(snapshot, address, from, to)
+}
+
 func (s *Storage) CandlesRange(amountAsset, priceAsset crypto.Digest, from, to uint32, timeFrameScale int) ([]data.Candle, error) {
 	limit := timeFrameScale * maxLimit
 	snapshot, err := s.db.GetSnapshot()
@@ -240,6 +263,16 @@ func (s *Storage) CandlesRange(amountAsset, priceAsset crypto.Digest, from, to u
 	return candles(snapshot, amountAsset, priceAsset, from, to+uint32(timeFrameScale), limit)
 }
 
+// LastCandle returns the latest candle of the market at or before the given time frame.
+func (s *Storage) LastCandle(amountAsset, priceAsset crypto.Digest, timeFrame uint32) (data.Candle, bool, error) {
+	snapshot, err := s.db.GetSnapshot()
+	if err != nil {
+		return data.Candle{}, false, err
+	}
+	defer snapshot.Release()
+	return lastCandle(snapshot, amountAsset, priceAsset, timeFrame)
+}
+
 func (s *Storage) DayCandle(amountAsset, priceAsset crypto.Digest) (data.Candle, error) {
 	now := uint64(time.Now().Unix() * 1000)
 	ttf := data.TimeFrameFromTimestampMS(now)
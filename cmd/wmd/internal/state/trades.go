@@ -171,33 +171,35 @@ func putTrades(bs *blockState, batch *leveldb.Batch, height uint32, trades []dat
 	return nil
 }
 
-func rollbackTrades(snapshot *leveldb.Snapshot, batch *leveldb.Batch, removeHeight uint32) error {
+func rollbackTrades(snapshot *leveldb.Snapshot, batch *leveldb.Batch, removeHeight uint32) ([]data.Trade, error) {
 	wrapError := func(err error) error { return errors.Wrap(err, "failed to rollback trades") }
 	//remove Trades that comes with the removed blocks
 	s := uint32Key{prefix: tradeHistoryKeyPrefix, key: removeHeight}
 	l := uint32Key{prefix: tradeHistoryKeyPrefix, key: math.MaxInt32}
 	it := snapshot.NewIterator(&util.Range{Start: s.bytes(), Limit: l.bytes()}, nil)
 	minTradeTimestamp := uint64(math.MaxUint64)
+	removed := make([]data.Trade, 0)
 	if it.Last() {
 		for {
 			var thk tradeHistoryKey
 			err := thk.fromBytes(it.Key())
 			if err != nil {
-				return wrapError(err)
+				return nil, wrapError(err)
 			}
 			tk := tradeKey{id: thk.trade}
 			tb, err := snapshot.Get(tk.bytes(), nil)
 			if err != nil {
-				return wrapError(err)
+				return nil, wrapError(err)
 			}
 			var t data.Trade
 			err = t.UnmarshalBinary(tb)
 			if err != nil {
-				return wrapError(err)
+				return nil, wrapError(err)
 			}
 			if t.Timestamp < minTradeTimestamp {
 				minTradeTimestamp = t.Timestamp
 			}
+			removed = append(removed, t)
 			batch.Delete(thk.bytes())
 			batch.Delete(tk.bytes())
 			tf := data.TimeFrameFromTimestampMS(t.Timestamp)
@@ -222,7 +224,7 @@ func rollbackTrades(snapshot *leveldb.Snapshot, batch *leveldb.Batch, removeHeig
 		var chk candleHistoryKey
 		err := chk.fromBytes(it.Key())
 		if err != nil {
-			return wrapError(err)
+			return nil, wrapError(err)
 		}
 		ck := candleKey{timeFrame: chk.timeFrame, amountAsset: chk.amountAsset, priceAsset: chk.priceAsset}
 		batch.Delete(it.Key())
@@ -242,11 +244,11 @@ func rollbackTrades(snapshot *leveldb.Snapshot, batch *leveldb.Batch, removeHeig
 			var mk marketKey
 			err := mhk.fromBytes(it.Key())
 			if err != nil {
-				return wrapError(err)
+				return nil, wrapError(err)
 			}
 			err = pm.UnmarshalBinary(it.Value())
 			if err != nil {
-				return wrapError(err)
+				return nil, wrapError(err)
 			}
 			mk = marketKey{amountAsset: mhk.amountAsset, priceAsset: mhk.priceAsset}
 			if pm.TotalTradesCount == 0 {
@@ -276,14 +278,14 @@ func rollbackTrades(snapshot *leveldb.Snapshot, batch *leveldb.Batch, removeHeig
 	for k, v := range downgradeMarkets {
 		b, err := v.MarshalBinary()
 		if err != nil {
-			return wrapError(err)
+			return nil, wrapError(err)
 		}
 		batch.Put(k.bytes(), b)
 	}
 	for _, k := range removeMarkets {
 		batch.Delete(k.bytes())
 	}
-	return nil
+	return removed, nil
 }
 
 func trade(snapshot *leveldb.Snapshot, id crypto.Digest) (data.Trade, error) {
@@ -405,6 +407,41 @@ func addressTrades(snapshot *leveldb.Snapshot, amountAsset, priceAsset crypto.Di
 	return trades, nil
 }
 
+// allAddressTrades collects the trades of the address on all markets within the time range
+func allAddressTrades(snapshot *leveldb.Snapshot, address proto.Address, from, to uint64) ([]data.Trade, error) {
+	wrapError := func(err error) error {
+		return errors.Wrapf(err, "failed to collect trades for address '%s'", address.String())
+	}
+	markets, err := marketsMap(snapshot)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var trades []data.Trade
+	for m := range markets {
+		s := addressTradesKey{m.AmountAsset, m.PriceAsset, address, minDigest}
+		l := addressTradesKey{m.AmountAsset, m.PriceAsset, address, maxDigest}
+		it := snapshot.NewIterator(&util.Range{Start: s.bytes(), Limit: l.bytes()}, nil)
+		for it.Next() {
+			var k addressTradesKey
+			err := k.fromBytes(it.Key())
+			if err != nil {
+				it.Release()
+				return nil, wrapError(err)
+			}
+			t, err := trade(snapshot, k.trade)
+			if err != nil {
+				it.Release()
+				return nil, wrapError(err)
+			}
+			if t.Timestamp >= from && t.Timestamp <= to {
+				trades = append(trades, t)
+			}
+		}
+		it.Release()
+	}
+	return trades, nil
+}
+
 type candleKey struct {
 	amountAsset crypto.Digest
 	priceAsset  crypto.Digest
@@ -470,6 +507,23 @@ func candles(snapshot *leveldb.Snapshot, amountAsset, priceAsset crypto.Digest,
 	return r, nil
 }
 
+// lastCandle returns the latest candle of the market at or before the time frame
+func lastCandle(snapshot *leveldb.Snapshot, amountAsset, priceAsset crypto.Digest, timeFrame uint32) (data.Candle, bool, error) {
+	sk := candleKey{amountAsset, priceAsset, 0}
+	ek := candleKey{amountAsset, priceAsset, timeFrame + 1}
+	it := snapshot.NewIterator(&util.Range{Start: sk.bytes(), Limit: ek.bytes()}, nil)
+	defer it.Release()
+	if !it.Last() {
+		return data.Candle{}, false, nil
+	}
+	var c data.Candle
+	err := c.UnmarshalBinary(it.Value())
+	if err != nil {
+		return data.Candle{}, false, errors.Wrap(err, "failed to load last candle")
+	}
+	return c, true, nil
+}
+
 type marketKey struct {
 	amountAsset crypto.Digest
 	priceAsset  crypto.Digest
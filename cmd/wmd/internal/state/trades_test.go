@@ -9,6 +9,7 @@ import (
 	"github.com/wavesplatform/gowaves/cmd/wmd/internal/data"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"math"
 	"testing"
 )
 
@@ -156,8 +157,9 @@ func TestTradesState1(t *testing.T) {
 	snapshot, err = db.GetSnapshot()
 	require.NoError(t, err)
 	batch = new(leveldb.Batch)
-	err = rollbackTrades(snapshot, batch, 3)
+	removed, err := rollbackTrades(snapshot, batch, 3)
 	require.NoError(t, err)
+	assert.ElementsMatch(t, []data.Trade{t3, t4}, removed)
 	err = db.Write(batch, nil)
 	require.NoError(t, err)
 	if snapshot, err := db.GetSnapshot(); assert.NoError(t, err) {
@@ -191,8 +193,9 @@ func TestTradesState1(t *testing.T) {
 	snapshot, err = db.GetSnapshot()
 	require.NoError(t, err)
 	batch = new(leveldb.Batch)
-	err = rollbackTrades(snapshot, batch, 1)
+	removed, err = rollbackTrades(snapshot, batch, 1)
 	require.NoError(t, err)
+	assert.ElementsMatch(t, []data.Trade{t1, t2}, removed)
 	err = db.Write(batch, nil)
 	require.NoError(t, err)
 	if snapshot, err := db.GetSnapshot(); assert.NoError(t, err) {
@@ -215,6 +218,65 @@ func TestTradesState1(t *testing.T) {
 	}
 }
 
+func TestAllAddressTradesAndLastCandle(t *testing.T) {
+	db, closeDB := openDB(t, "wmd-address-trades-state-db")
+	defer closeDB()
+
+	b, err := proto.NewAddressFromString("3P4KdaNYJq7BBcsgrsAPArc66LyLQAQvJc2")
+	require.NoError(t, err)
+	s, err := proto.NewAddressFromString("3PAmhzHgxzxqVttGFRgVCFUFHoGHqmuchec")
+	require.NoError(t, err)
+	m, err := proto.NewAddressFromString("3PJaDyprvekvPXPuAtxrapacuDJopgJRaU3")
+	require.NoError(t, err)
+	pa1, err := randomDigest()
+	require.NoError(t, err)
+	pa2, err := randomDigest()
+	require.NoError(t, err)
+	tID1, err := randomDigest()
+	require.NoError(t, err)
+	tID2, err := randomDigest()
+	require.NoError(t, err)
+	tID3, err := randomDigest()
+	require.NoError(t, err)
+	ts1 := uint64(1548230341666)
+	ts2 := uint64(1548230642000)
+	ts3 := uint64(1548240642000)
+	t1 := data.Trade{AmountAsset: data.WavesID, PriceAsset: pa1, TransactionID: tID1, OrderType: proto.Buy, Buyer: b, Seller: s, Matcher: m, Price: 100, Amount: 10, Timestamp: ts1}
+	t2 := data.Trade{AmountAsset: data.WavesID, PriceAsset: pa2, TransactionID: tID2, OrderType: proto.Sell, Buyer: s, Seller: b, Matcher: m, Price: 200, Amount: 20, Timestamp: ts2}
+	t3 := data.Trade{AmountAsset: data.WavesID, PriceAsset: pa1, TransactionID: tID3, OrderType: proto.Sell, Buyer: s, Seller: m, Matcher: m, Price: 300, Amount: 30, Timestamp: ts3}
+	snapshot, err := db.GetSnapshot()
+	require.NoError(t, err)
+	batch := new(leveldb.Batch)
+	bs := newBlockState(snapshot)
+	err = putTrades(bs, batch, 1, []data.Trade{t1, t2, t3})
+	require.NoError(t, err)
+	err = db.Write(batch, nil)
+	require.NoError(t, err)
+	if snapshot, err := db.GetSnapshot(); assert.NoError(t, err) {
+		tds, err := allAddressTrades(snapshot, b, 0, math.MaxInt64)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []data.Trade{t1, t2}, tds)
+		tds, err = allAddressTrades(snapshot, s, ts2, ts3)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []data.Trade{t2, t3}, tds)
+		tds, err = allAddressTrades(snapshot, m, 0, ts2)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(tds))
+
+		_, ok, err := lastCandle(snapshot, data.WavesID, pa1, data.TimeFrameFromTimestampMS(ts1)-1)
+		require.NoError(t, err)
+		assert.False(t, ok)
+		c, ok, err := lastCandle(snapshot, data.WavesID, pa1, data.TimeFrameFromTimestampMS(ts3)-1)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(100), c.Close)
+		c, ok, err = lastCandle(snapshot, data.WavesID, pa1, data.TimeFrameFromTimestampMS(ts3)+10)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(300), c.Close)
+	}
+}
+
 func TestAddressTradesKeyBinaryRoundTrip(t *testing.T) {
 	addr, err := proto.NewAddressFromString("3PAmhzHgxzxqVttGFRgVCFUFHoGHqmuchec")
 	require.NoError(t, err)
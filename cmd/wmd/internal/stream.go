@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/cmd/wmd/internal/data"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	subscribeOperation   = "subscribe"
+	unsubscribeOperation = "unsubscribe"
+
+	tradesMessageType   = "trades"
+	rollbackMessageType = "rollback"
+	errorMessageType    = "error"
+
+	subscriberQueueSize = 64
+)
+
+// streamRequest is a message sent by a WebSocket client to (un)subscribe to the market updates.
+type streamRequest struct {
+	Operation   string `json:"op"`
+	AmountAsset string `json:"amountAsset"`
+	PriceAsset  string `json:"priceAsset"`
+}
+
+// streamMessage is a message pushed to the subscribers of the market.
+// Messages of type "trades" carry the new trades of the block, messages of type "rollback" carry
+// the IDs of trades removed by the rollback. Both types of messages carry the actual ticker and the actual
+// state of candles affected by the change, so "rollback" messages should be used to correct the previously received data.
+type streamMessage struct {
+	Type        string            `json:"type"`
+	Height      int               `json:"height,omitempty"`
+	AmountAsset data.AssetID      `json:"amountAsset"`
+	PriceAsset  data.AssetID      `json:"priceAsset"`
+	Trades      []data.TradeInfo  `json:"trades,omitempty"`
+	Removed     []crypto.Digest   `json:"removed,omitempty"`
+	Ticker      *data.TickerInfo  `json:"ticker,omitempty"`
+	Candles     []data.CandleInfo `json:"candles,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type subscriber struct {
+	markets map[data.MarketID]struct{}
+	queue   chan streamMessage
+}
+
+// Streamer implements state.TradesObserver and pushes the updates of markets to the WebSocket subscribers.
+type Streamer struct {
+	api         *DataFeedAPI
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewStreamer(api *DataFeedAPI) *Streamer {
+	return &Streamer{api: api, subscribers: make(map[*subscriber]struct{})}
+}
+
+func (s *Streamer) TradesAdded(height int, trades []data.Trade) {
+	for m, ts := range groupTradesByMarket(trades) {
+		if !s.hasSubscribers(m) {
+			continue
+		}
+		msg, err := s.message(tradesMessageType, height, m, ts)
+		if err != nil {
+			zap.S().Errorf("Failed to prepare trades update: %v", err)
+			continue
+		}
+		s.broadcast(m, msg)
+	}
+}
+
+func (s *Streamer) TradesRemoved(height int, trades []data.Trade) {
+	for m, ts := range groupTradesByMarket(trades) {
+		if !s.hasSubscribers(m) {
+			continue
+		}
+		msg, err := s.message(rollbackMessageType, height, m, ts)
+		if err != nil {
+			zap.S().Errorf("Failed to prepare rollback update: %v", err)
+			continue
+		}
+		s.broadcast(m, msg)
+	}
+}
+
+// Handler returns the WebSocket server that serves the subscribers' connections.
+// Origin of requests is not checked to allow non-browser clients.
+func (s *Streamer) Handler() websocket.Server {
+	return websocket.Server{Handler: s.serve}
+}
+
+func (s *Streamer) serve(ws *websocket.Conn) {
+	sub := &subscriber{markets: make(map[data.MarketID]struct{}), queue: make(chan streamMessage, subscriberQueueSize)}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	defer s.remove(sub)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var req streamRequest
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				return
+			}
+			if err := s.handleRequest(sub, req); err != nil {
+				s.send(sub, streamMessage{Type: errorMessageType, Error: err.Error()})
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		case <-s.api.interrupt:
+			return
+		case msg, ok := <-sub.queue:
+			if !ok {
+				zap.S().Debugf("Closing WebSocket connection of slow subscriber %s", ws.Request().RemoteAddr)
+				return
+			}
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Streamer) handleRequest(sub *subscriber, req streamRequest) error {
+	amountAsset, err := s.api.Symbols.ParseTicker(req.AmountAsset)
+	if err != nil {
+		return errors.Wrap(err, "invalid amount asset")
+	}
+	priceAsset, err := s.api.Symbols.ParseTicker(req.PriceAsset)
+	if err != nil {
+		return errors.Wrap(err, "invalid price asset")
+	}
+	m := data.MarketID{AmountAsset: amountAsset, PriceAsset: priceAsset}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch req.Operation {
+	case subscribeOperation:
+		sub.markets[m] = struct{}{}
+	case unsubscribeOperation:
+		delete(sub.markets, m)
+	default:
+		return errors.Errorf("unsupported operation '%s'", req.Operation)
+	}
+	return nil
+}
+
+func (s *Streamer) hasSubscribers(m data.MarketID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if _, ok := sub.markets[m]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Streamer) broadcast(m data.MarketID, msg streamMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if _, ok := sub.markets[m]; !ok {
+			continue
+		}
+		select {
+		case sub.queue <- msg:
+		default:
+			// The subscriber is unable to keep up with the updates, closing the queue drops the connection
+			close(sub.queue)
+			delete(s.subscribers, sub)
+		}
+	}
+}
+
+func (s *Streamer) send(sub *subscriber, msg streamMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	select {
+	case sub.queue <- msg:
+	default:
+	}
+}
+
+func (s *Streamer) remove(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers, sub)
+}
+
+func (s *Streamer) message(typ string, height int, m data.MarketID, trades []data.Trade) (streamMessage, error) {
+	aai, err := s.api.Storage.AssetInfo(m.AmountAsset)
+	if err != nil {
+		return streamMessage{}, err
+	}
+	pai, err := s.api.Storage.AssetInfo(m.PriceAsset)
+	if err != nil {
+		return streamMessage{}, err
+	}
+	ti, err := s.api.tickerInfo(aai, pai)
+	if err != nil {
+		return streamMessage{}, err
+	}
+	msg := streamMessage{Type: typ, Height: height, AmountAsset: data.AssetID(m.AmountAsset), PriceAsset: data.AssetID(m.PriceAsset), Ticker: &ti}
+	tfs := make(map[uint32]struct{})
+	for _, t := range trades {
+		tfs[data.TimeFrameFromTimestampMS(t.Timestamp)] = struct{}{}
+		switch typ {
+		case rollbackMessageType:
+			msg.Removed = append(msg.Removed, t.TransactionID)
+		default:
+			msg.Trades = append(msg.Trades, data.NewTradeInfo(t, uint(aai.Decimals), uint(pai.Decimals)))
+		}
+	}
+	for tf := range tfs {
+		cs, err := s.api.Storage.CandlesRange(m.AmountAsset, m.PriceAsset, tf, tf, 1)
+		if err != nil {
+			return streamMessage{}, err
+		}
+		if len(cs) == 0 {
+			msg.Candles = append(msg.Candles, data.EmptyCandleInfo(uint(aai.Decimals), uint(pai.Decimals), data.TimestampMSFromTimeFrame(tf)))
+			continue
+		}
+		msg.Candles = append(msg.Candles, data.CandleInfoFromCandle(cs[0], uint(aai.Decimals), uint(pai.Decimals), 1))
+	}
+	return msg, nil
+}
+
+func groupTradesByMarket(trades []data.Trade) map[data.MarketID][]data.Trade {
+	r := make(map[data.MarketID][]data.Trade)
+	for _, t := range trades {
+		m := data.MarketID{AmountAsset: t.AmountAsset, PriceAsset: t.PriceAsset}
+		r[m] = append(r[m], t)
+	}
+	return r
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/cmd/wmd/internal/data"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"golang.org/x/net/websocket"
+)
+
+// receivedMessage is the part of streamMessage checked by tests.
+type receivedMessage struct {
+	Type    string            `json:"type"`
+	Height  int               `json:"height"`
+	Trades  []data.TradeInfo  `json:"trades"`
+	Removed []crypto.Digest   `json:"removed"`
+	Ticker  json.RawMessage   `json:"ticker"`
+	Candles []json.RawMessage `json:"candles"`
+	Error   string            `json:"error"`
+}
+
+func dialStreamer(t *testing.T, s *Streamer) (*websocket.Conn, func()) {
+	server := httptest.NewServer(s.Handler())
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	ws, err := websocket.Dial(url, "", server.URL)
+	require.NoError(t, err)
+	return ws, func() {
+		_ = ws.Close()
+		server.Close()
+	}
+}
+
+// request sends the request and waits for its processing by sending an invalid request after it.
+// All messages received before the error message are returned.
+func request(t *testing.T, ws *websocket.Conn, req streamRequest) []receivedMessage {
+	err := websocket.JSON.Send(ws, req)
+	require.NoError(t, err)
+	err = websocket.JSON.Send(ws, streamRequest{Operation: "unknown", AmountAsset: "WAVES", PriceAsset: "WAVES"})
+	require.NoError(t, err)
+	var r []receivedMessage
+	for {
+		var msg receivedMessage
+		err := websocket.JSON.Receive(ws, &msg)
+		require.NoError(t, err)
+		if msg.Type == errorMessageType {
+			assert.Equal(t, "unsupported operation 'unknown'", msg.Error)
+			return r
+		}
+		r = append(r, msg)
+	}
+}
+
+func TestStreamerTrades(t *testing.T) {
+	to, cleanup := createTestAPI(t)
+	defer cleanup()
+	ws, closeWS := dialStreamer(t, to.api.Streamer)
+	defer closeWS()
+
+	msgs := request(t, ws, streamRequest{Operation: subscribeOperation, AmountAsset: "WAVES", PriceAsset: "usd"})
+	assert.Empty(t, msgs)
+	err := to.storage.PutBalances(2, proto.NewBlockIDFromSignature(crypto.Signature{2}), nil, nil, nil, nil)
+	require.NoError(t, err)
+	err = to.storage.PutTrades(2, proto.NewBlockIDFromSignature(crypto.Signature{2}), []data.Trade{to.trade})
+	require.NoError(t, err)
+
+	var msg receivedMessage
+	err = websocket.JSON.Receive(ws, &msg)
+	require.NoError(t, err)
+	assert.Equal(t, tradesMessageType, msg.Type)
+	assert.Equal(t, 2, msg.Height)
+	require.Len(t, msg.Trades, 1)
+	assert.Equal(t, to.trade.TransactionID, msg.Trades[0].ID)
+	assert.Empty(t, msg.Removed)
+	assert.NotEmpty(t, msg.Ticker)
+	assert.Len(t, msg.Candles, 1)
+
+	// Rollback removes the trade and updates the ticker and the candle.
+	err = to.storage.Rollback(2)
+	require.NoError(t, err)
+	msg = receivedMessage{}
+	err = websocket.JSON.Receive(ws, &msg)
+	require.NoError(t, err)
+	assert.Equal(t, rollbackMessageType, msg.Type)
+	assert.Equal(t, 2, msg.Height)
+	assert.Empty(t, msg.Trades)
+	assert.Equal(t, []crypto.Digest{to.trade.TransactionID}, msg.Removed)
+	assert.NotEmpty(t, msg.Ticker)
+	assert.Len(t, msg.Candles, 1)
+
+	// No updates are sent after unsubscribing.
+	msgs = request(t, ws, streamRequest{Operation: unsubscribeOperation, AmountAsset: data.WavesID.String(), PriceAsset: to.asset.String()})
+	assert.Empty(t, msgs)
+	to.api.Streamer.TradesAdded(2, []data.Trade{to.trade})
+	msgs = request(t, ws, streamRequest{Operation: unsubscribeOperation, AmountAsset: "WAVES", PriceAsset: "USD"})
+	assert.Empty(t, msgs)
+}
+
+func TestStreamerInvalidRequest(t *testing.T) {
+	to, cleanup := createTestAPI(t)
+	defer cleanup()
+	ws, closeWS := dialStreamer(t, to.api.Streamer)
+	defer closeWS()
+
+	err := websocket.JSON.Send(ws, streamRequest{Operation: subscribeOperation, AmountAsset: "WAVES", PriceAsset: "UNKNOWN"})
+	require.NoError(t, err)
+	var msg receivedMessage
+	err = websocket.JSON.Receive(ws, &msg)
+	require.NoError(t, err)
+	assert.Equal(t, errorMessageType, msg.Type)
+	assert.Contains(t, msg.Error, "invalid price asset")
+}
+
+func TestStreamerBroadcast(t *testing.T) {
+	s := NewStreamer(&DataFeedAPI{})
+	m1 := data.MarketID{AmountAsset: data.WavesID, PriceAsset: crypto.Digest{1}}
+	m2 := data.MarketID{AmountAsset: data.WavesID, PriceAsset: crypto.Digest{2}}
+	sub1 := &subscriber{markets: map[data.MarketID]struct{}{m1: {}}, queue: make(chan streamMessage, subscriberQueueSize)}
+	sub2 := &subscriber{markets: map[data.MarketID]struct{}{m2: {}}, queue: make(chan streamMessage, subscriberQueueSize)}
+	s.subscribers[sub1] = struct{}{}
+	s.subscribers[sub2] = struct{}{}
+
+	assert.True(t, s.hasSubscribers(m1))
+	assert.False(t, s.hasSubscribers(data.MarketID{}))
+	s.broadcast(m1, streamMessage{Type: tradesMessageType, Height: 1})
+	require.Len(t, sub1.queue, 1)
+	assert.Equal(t, streamMessage{Type: tradesMessageType, Height: 1}, <-sub1.queue)
+	assert.Empty(t, sub2.queue)
+
+	// The subscriber that doesn't read the updates is dropped, others keep receiving them.
+	for i := 0; i <= subscriberQueueSize; i++ {
+		s.broadcast(m1, streamMessage{Type: tradesMessageType, Height: i})
+	}
+	assert.Len(t, sub1.queue, subscriberQueueSize)
+	for range sub1.queue {
+	}
+	assert.NotContains(t, s.subscribers, sub1)
+	assert.False(t, s.hasSubscribers(m1))
+	s.send(sub1, streamMessage{Type: errorMessageType})
+	s.broadcast(m2, streamMessage{Type: rollbackMessageType, Height: 1})
+	assert.Len(t, sub2.queue, 1)
+}
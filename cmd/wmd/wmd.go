@@ -205,6 +205,7 @@ func run() error {
 	var apiDone <-chan struct{}
 	if *address != "" {
 		api := internal.NewDataFeedAPI(interrupt, logger, &storage, *address, symbols)
+		storage.Observer = api.Streamer
 		apiDone = api.Done()
 	}
 
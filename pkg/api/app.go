@@ -10,6 +10,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/services"
 	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
 	"github.com/wavesplatform/gowaves/pkg/types"
 )
 
@@ -26,6 +27,9 @@ type App struct {
 	peers         peer_manager.PeerManager
 	sync          types.StateSync
 	services      services.Services
+	devMiner      DevMiner
+	liquid        Liquid
+	txTracker     *txstatus.Tracker
 }
 
 func NewApp(apiKey string, scheduler SchedulerEmits, sync types.StateSync, services services.Services) (*App, error) {
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+type AliasOwner struct {
+	Address proto.Address `json:"address"`
+}
+
+// AddressByAlias returns the owner of the alias, given either as the alias name or as the full "alias:<scheme>:<name>" string.
+func (a *App) AddressByAlias(name string) (*AliasOwner, error) {
+	settings, err := a.state.BlockchainSettings()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	alias := proto.NewAlias(settings.AddressSchemeCharacter, name)
+	if strings.HasPrefix(name, proto.AliasPrefix+":") {
+		alias, err = proto.NewAliasFromString(name)
+		if err != nil {
+			return nil, &BadRequestError{err}
+		}
+	}
+	if ok, err := alias.Valid(); !ok {
+		return nil, &BadRequestError{errors.Wrapf(err, "invalid alias '%s'", name)}
+	}
+	addr, err := a.state.AddrByAlias(*alias)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("alias '%s' does not exist", name)}
+		}
+		return nil, &InternalError{err}
+	}
+	return &AliasOwner{Address: addr}, nil
+}
+
+// AliasesByAddress returns the aliases owned by the address.
+func (a *App) AliasesByAddress(address string) ([]proto.Alias, error) {
+	addr, err := proto.NewAddressFromString(address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", address)}
+	}
+	r, err := a.state.AliasesByAddr(addr)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	if r == nil {
+		r = []proto.Alias{}
+	}
+	return r, nil
+}
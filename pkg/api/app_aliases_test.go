@@ -0,0 +1,65 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestApp_AddressByAlias(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().BlockchainSettings().Return(settings.MainNetSettings, nil).AnyTimes()
+	s.EXPECT().AddrByAlias(*proto.NewAlias('W', "frozen")).Return(addr, nil).Times(2)
+	s.EXPECT().AddrByAlias(*proto.NewAlias('W', "unknown")).Return(proto.Address{}, state.NewStateError(state.RetrievalError, proto.ErrNotFound))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AddressByAlias("frozen")
+	require.NoError(t, err)
+	assert.Equal(t, &AliasOwner{Address: addr}, rs)
+	rs, err = app.AddressByAlias("alias:W:frozen")
+	require.NoError(t, err)
+	assert.Equal(t, &AliasOwner{Address: addr}, rs)
+
+	_, err = app.AddressByAlias("unknown")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressByAlias("Invalid!")
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_AliasesByAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	aliases := []proto.Alias{*proto.NewAlias('W', "first"), *proto.NewAlias('W', "second")}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().AliasesByAddr(addr).Return(aliases, nil)
+	s.EXPECT().AliasesByAddr(addr).Return(nil, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AliasesByAddress(addr.String())
+	require.NoError(t, err)
+	assert.Equal(t, aliases, rs)
+	rs, err = app.AliasesByAddress(addr.String())
+	require.NoError(t, err)
+	assert.NotNil(t, rs)
+	assert.Empty(t, rs)
+
+	_, err = app.AliasesByAddress("invalid")
+	assert.IsType(t, &BadRequestError{}, err)
+}
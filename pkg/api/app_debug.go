@@ -1,5 +1,32 @@
 package api
 
+import (
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
 func (a *App) DebugSyncEnabled(enabled bool) {
 	a.sync.SetEnabled(enabled)
 }
+
+func (a *App) DebugStateHash(height proto.Height) (*proto.StateHash, error) {
+	provides, err := a.state.ProvidesStateHashes()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	if !provides {
+		return nil, &BadRequestError{errors.New("state hashes are not built, node must be started with -build-state-hashes")}
+	}
+	h, err := a.state.Height()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	if height < 1 || height > h {
+		return nil, &BadRequestError{errors.Errorf("invalid height %d, current height is %d", height, h)}
+	}
+	sh, err := a.state.StateHashAtHeight(height)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return sh, nil
+}
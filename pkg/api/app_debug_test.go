@@ -0,0 +1,47 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+)
+
+func TestApp_DebugStateHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sh := &proto.StateHash{}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ProvidesStateHashes().Return(true, nil).Times(3)
+	s.EXPECT().Height().Return(proto.Height(10), nil).Times(3)
+	s.EXPECT().StateHashAtHeight(proto.Height(5)).Return(sh, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.DebugStateHash(5)
+	require.NoError(t, err)
+	assert.Equal(t, sh, rs)
+
+	_, err = app.DebugStateHash(0)
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.DebugStateHash(11)
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_DebugStateHashNotProvided(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ProvidesStateHashes().Return(false, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	_, err = app.DebugStateHash(1)
+	assert.IsType(t, &BadRequestError{}, err)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// DevMiner produces blocks and microblocks on demand in development mode and controls the node's time.
+type DevMiner interface {
+	MineBlock() (*proto.Block, error)
+	MineMicroblock() (*proto.MicroBlock, error)
+	Now() time.Time
+	AdvanceTime(d time.Duration) time.Time
+}
+
+type DevBlock struct {
+	ID               proto.BlockID    `json:"id"`
+	Height           proto.Height     `json:"height"`
+	Timestamp        proto.Timestamp  `json:"timestamp"`
+	Generator        crypto.PublicKey `json:"generator"`
+	TransactionCount int              `json:"transaction_count"`
+}
+
+type DevMicroblock struct {
+	TotalBlockID     proto.BlockID `json:"total_block_id"`
+	Reference        proto.BlockID `json:"reference"`
+	TransactionCount int           `json:"transaction_count"`
+}
+
+type DevTime struct {
+	Timestamp proto.Timestamp `json:"timestamp"`
+	Time      time.Time       `json:"time"`
+}
+
+// EnableDevMode enables the API to produce blocks on demand and to control the node's time.
+func (a *App) EnableDevMode(miner DevMiner) {
+	a.devMiner = miner
+}
+
+func (a *App) checkDevMode() error {
+	if a.devMiner == nil {
+		return &BadRequestError{errors.New("development mode is disabled")}
+	}
+	return nil
+}
+
+func (a *App) DevMineBlock(apiKey string) (*DevBlock, error) {
+	if err := a.checkAuth(apiKey); err != nil {
+		return nil, err
+	}
+	if err := a.checkDevMode(); err != nil {
+		return nil, err
+	}
+	b, err := a.devMiner.MineBlock()
+	if err != nil {
+		return nil, &BadRequestError{err}
+	}
+	locked := a.state.Mutex().RLock()
+	height, err := a.state.BlockIDToHeight(b.BlockID())
+	locked.Unlock()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return &DevBlock{
+		ID:               b.BlockID(),
+		Height:           height,
+		Timestamp:        b.Timestamp,
+		Generator:        b.GenPublicKey,
+		TransactionCount: b.TransactionCount,
+	}, nil
+}
+
+func (a *App) DevMineMicroblock(apiKey string) (*DevMicroblock, error) {
+	if err := a.checkAuth(apiKey); err != nil {
+		return nil, err
+	}
+	if err := a.checkDevMode(); err != nil {
+		return nil, err
+	}
+	micro, err := a.devMiner.MineMicroblock()
+	if err != nil {
+		return nil, &BadRequestError{err}
+	}
+	return &DevMicroblock{
+		TotalBlockID:     micro.TotalBlockID,
+		Reference:        micro.Reference,
+		TransactionCount: int(micro.TransactionCount),
+	}, nil
+}
+
+func (a *App) DevTime() (*DevTime, error) {
+	if err := a.checkDevMode(); err != nil {
+		return nil, err
+	}
+	return newDevTime(a.devMiner.Now()), nil
+}
+
+func (a *App) DevAdvanceTime(apiKey string, d time.Duration) (*DevTime, error) {
+	if err := a.checkAuth(apiKey); err != nil {
+		return nil, err
+	}
+	if err := a.checkDevMode(); err != nil {
+		return nil, err
+	}
+	if d < 0 {
+		return nil, &BadRequestError{errors.New("time can't be moved back")}
+	}
+	return newDevTime(a.devMiner.AdvanceTime(d)), nil
+}
+
+func newDevTime(t time.Time) *DevTime {
+	return &DevTime{Timestamp: proto.NewTimestampFromTime(t), Time: t}
+}
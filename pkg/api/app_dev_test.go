@@ -0,0 +1,87 @@
+package api
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/util/lock"
+)
+
+type stubDevMiner struct {
+	block *proto.Block
+	err   error
+	now   time.Time
+}
+
+func (a *stubDevMiner) MineBlock() (*proto.Block, error) {
+	return a.block, a.err
+}
+
+func (a *stubDevMiner) MineMicroblock() (*proto.MicroBlock, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &proto.MicroBlock{TransactionCount: 2, Reference: a.block.BlockID()}, nil
+}
+
+func (a *stubDevMiner) Now() time.Time {
+	return a.now
+}
+
+func (a *stubDevMiner) AdvanceTime(d time.Duration) time.Time {
+	a.now = a.now.Add(d)
+	return a.now
+}
+
+func TestApp_DevMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	block := &proto.Block{BlockHeader: proto.BlockHeader{Timestamp: 1000, TransactionCount: 3}}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().Mutex().Return(lock.NewRwMutex(&sync.RWMutex{}))
+	s.EXPECT().BlockIDToHeight(block.BlockID()).Return(proto.Height(5), nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	_, err = app.DevMineBlock("api-key")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.DevTime()
+	assert.IsType(t, &BadRequestError{}, err)
+
+	m := &stubDevMiner{block: block, now: time.Unix(1600000000, 0)}
+	app.EnableDevMode(m)
+	_, err = app.DevMineBlock("wrong-key")
+	assert.IsType(t, &AuthError{}, err)
+	b, err := app.DevMineBlock("api-key")
+	require.NoError(t, err)
+	assert.Equal(t, proto.Height(5), b.Height)
+	assert.Equal(t, proto.Timestamp(1000), b.Timestamp)
+	assert.Equal(t, 3, b.TransactionCount)
+	micro, err := app.DevMineMicroblock("api-key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, micro.TransactionCount)
+
+	tm, err := app.DevAdvanceTime("api-key", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, proto.Timestamp(1600003600000), tm.Timestamp)
+	_, err = app.DevAdvanceTime("api-key", -time.Hour)
+	assert.IsType(t, &BadRequestError{}, err)
+	tm, err = app.DevTime()
+	require.NoError(t, err)
+	assert.Equal(t, proto.Timestamp(1600003600000), tm.Timestamp)
+
+	m.err = errors.New("no miners")
+	_, err = app.DevMineBlock("api-key")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.DevMineMicroblock("api-key")
+	assert.IsType(t, &BadRequestError{}, err)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const (
+	defaultLeasesLimit = 100
+	maxLeasesLimit     = 1000
+)
+
+// LeasesRequest selects the page of address' leases.
+// Empty status selects leases of any status, empty After selects the first page.
+type LeasesRequest struct {
+	Address  string
+	Incoming bool
+	Status   string
+	After    string
+	Limit    string
+}
+
+// LeaseInfo returns the lease created by the transaction with the given ID.
+func (a *App) LeaseInfo(leaseID string) (*proto.LeaseInfo, error) {
+	id, err := crypto.NewDigestFromBase58(leaseID)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid lease ID '%s'", leaseID)}
+	}
+	r, err := a.state.LeaseInfo(id)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("lease '%s' does not exist", leaseID)}
+		}
+		return nil, &InternalError{err}
+	}
+	return r, nil
+}
+
+// Leases returns the page of leases sent or received by the address, the newest first.
+func (a *App) Leases(req LeasesRequest) ([]proto.LeaseInfo, error) {
+	addr, err := proto.NewAddressFromString(req.Address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", req.Address)}
+	}
+	status := proto.LeaseStatus(req.Status)
+	if status != "" && status != proto.LeaseActive && status != proto.LeaseCanceled {
+		return nil, &BadRequestError{errors.Errorf("invalid status '%s', must be '%s' or '%s'", req.Status, proto.LeaseActive, proto.LeaseCanceled)}
+	}
+	var after *crypto.Digest
+	if req.After != "" {
+		id, err := crypto.NewDigestFromBase58(req.After)
+		if err != nil {
+			return nil, &BadRequestError{errors.Errorf("invalid lease ID '%s'", req.After)}
+		}
+		after = &id
+	}
+	limit := defaultLeasesLimit
+	if req.Limit != "" {
+		limit, err = strconv.Atoi(req.Limit)
+		if err != nil || limit <= 0 || limit > maxLeasesLimit {
+			return nil, &BadRequestError{errors.Errorf("invalid limit '%s', must be from 1 to %d", req.Limit, maxLeasesLimit)}
+		}
+	}
+	leases, err := a.state.LeasesByAddr(addr, req.Incoming, status, after, limit)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("lease '%s' is not found among the leases of address", req.After)}
+		}
+		return nil, &InternalError{err}
+	}
+	return leases, nil
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestApp_LeaseInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd")
+	unknown := crypto.MustDigestFromBase58("F2fdfc2kxgV9ugBeuuYFAgHYnXtc6uvKnQdc5eRXcrMv")
+	info := &proto.LeaseInfo{ID: id, Amount: 100, Status: proto.LeaseCanceled, Height: 10, CancelHeight: 20}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().LeaseInfo(id).Return(info, nil)
+	s.EXPECT().LeaseInfo(unknown).Return(nil, state.NewStateError(state.RetrievalError, proto.ErrNotFound))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.LeaseInfo(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, info, rs)
+	_, err = app.LeaseInfo(unknown.String())
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.LeaseInfo("invalid")
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_Leases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	other, err := proto.NewAddressFromString("3PDdGex1meSUf4Yq5bjPBpyAbx6us9PaLfo")
+	require.NoError(t, err)
+	ids := []crypto.Digest{
+		crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd"),
+		crypto.MustDigestFromBase58("F2fdfc2kxgV9ugBeuuYFAgHYnXtc6uvKnQdc5eRXcrMv"),
+		crypto.MustDigestFromBase58("CqgrRX6PwhsPWEU1oFXW4XNjkBfbMU5W8n2QX8NhHxoD"),
+		crypto.MustDigestFromBase58("2R9H5ARbKG7UGHgRXs5A8aV7fZQUvbiPfq8yWpGQPcd1"),
+	}
+	leases := []proto.LeaseInfo{
+		{ID: ids[0], Sender: addr, Recipient: other, Status: proto.LeaseActive, Height: 4},
+		{ID: ids[1], Sender: other, Recipient: addr, Status: proto.LeaseActive, Height: 3},
+		{ID: ids[2], Sender: addr, Recipient: other, Status: proto.LeaseCanceled, Height: 2},
+		{ID: ids[3], Sender: addr, Recipient: other, Status: proto.LeaseActive, Height: 1},
+	}
+	unknown := crypto.MustDigestFromBase58("2R9H5ARbKG7UGHgRXs5A8aV7fZQUvbiPfq8yWpGQPcd2")
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().LeasesByAddr(addr, true, proto.LeaseStatus(""), nil, defaultLeasesLimit).Return([]proto.LeaseInfo{leases[1]}, nil)
+	s.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), nil, defaultLeasesLimit).Return([]proto.LeaseInfo{leases[0], leases[2], leases[3]}, nil)
+	s.EXPECT().LeasesByAddr(addr, false, proto.LeaseActive, nil, defaultLeasesLimit).Return([]proto.LeaseInfo{leases[0], leases[3]}, nil)
+	s.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), nil, 2).Return([]proto.LeaseInfo{leases[0], leases[2]}, nil)
+	s.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), &ids[2], 2).Return([]proto.LeaseInfo{leases[3]}, nil)
+	s.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), &unknown, defaultLeasesLimit).Return(nil, state.NewStateError(state.NotFoundError, proto.ErrNotFound))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.Leases(LeasesRequest{Address: addr.String(), Incoming: true})
+	require.NoError(t, err)
+	assert.Equal(t, []proto.LeaseInfo{leases[1]}, rs)
+	rs, err = app.Leases(LeasesRequest{Address: addr.String()})
+	require.NoError(t, err)
+	assert.Equal(t, []proto.LeaseInfo{leases[0], leases[2], leases[3]}, rs)
+	rs, err = app.Leases(LeasesRequest{Address: addr.String(), Status: "active"})
+	require.NoError(t, err)
+	assert.Equal(t, []proto.LeaseInfo{leases[0], leases[3]}, rs)
+
+	// Pages of outgoing leases.
+	rs, err = app.Leases(LeasesRequest{Address: addr.String(), Limit: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, []proto.LeaseInfo{leases[0], leases[2]}, rs)
+	rs, err = app.Leases(LeasesRequest{Address: addr.String(), Limit: "2", After: ids[2].String()})
+	require.NoError(t, err)
+	assert.Equal(t, []proto.LeaseInfo{leases[3]}, rs)
+
+	for _, req := range []LeasesRequest{
+		{Address: "invalid"},
+		{Address: addr.String(), Status: "unknown"},
+		{Address: addr.String(), Limit: "0"},
+		{Address: addr.String(), Limit: "1001"},
+		{Address: addr.String(), After: "invalid"},
+		{Address: addr.String(), After: unknown.String()},
+	} {
+		_, err = app.Leases(req)
+		assert.IsType(t, &BadRequestError{}, err)
+	}
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// Liquid provides the last key block and the microblocks on top of it, it's implemented by ng.State.
+// LiquidBlock returns only the microblocks applied to the state, LiquidRow returns all microblocks known to NG.
+type Liquid interface {
+	LiquidBlock() (*proto.Block, []*proto.MicroBlock, bool)
+	LiquidRow() (*proto.Block, []*proto.MicroBlock, bool)
+}
+
+type LiquidMicroblock struct {
+	TotalBlockID proto.BlockID `json:"total_block_id"`
+	Reference    proto.BlockID `json:"reference"`
+	Transactions []string      `json:"transactions"`
+}
+
+type LiquidBlock struct {
+	ID               proto.BlockID      `json:"id"`
+	KeyBlockID       proto.BlockID      `json:"key_block_id"`
+	Height           proto.Height       `json:"height"`
+	Timestamp        proto.Timestamp    `json:"timestamp"`
+	TransactionCount int                `json:"transaction_count"`
+	Microblocks      []LiquidMicroblock `json:"microblocks"`
+}
+
+// SetLiquid makes the API aware of the microblocks applied to the state.
+func (a *App) SetLiquid(l Liquid) {
+	a.liquid = l
+}
+
+// LiquidBlock returns the top block of the state with the microblocks it's made of.
+func (a *App) LiquidBlock() (*LiquidBlock, error) {
+	if a.liquid == nil {
+		return nil, &BadRequestError{errors.New("liquid block is not available")}
+	}
+	key, micros, ok := a.liquid.LiquidBlock()
+	if !ok {
+		return nil, &InternalError{errors.New("liquid block is not ready")}
+	}
+	h, err := a.state.Height()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	r := &LiquidBlock{
+		ID:               key.BlockID(),
+		KeyBlockID:       key.BlockID(),
+		Height:           h,
+		Timestamp:        key.Timestamp,
+		TransactionCount: len(key.Transactions),
+		Microblocks:      make([]LiquidMicroblock, 0, len(micros)),
+	}
+	for _, m := range micros {
+		lm := LiquidMicroblock{TotalBlockID: m.TotalBlockID, Reference: m.Reference, Transactions: make([]string, 0, len(m.Transactions))}
+		for _, tx := range m.Transactions {
+			id, err := tx.GetID(a.services.Scheme)
+			if err != nil {
+				return nil, &InternalError{err}
+			}
+			lm.Transactions = append(lm.Transactions, base58.Encode(id))
+		}
+		r.ID = m.TotalBlockID
+		r.TransactionCount += len(m.Transactions)
+		r.Microblocks = append(r.Microblocks, lm)
+	}
+	return r, nil
+}
+
+type AddressBalance struct {
+	Address proto.Address  `json:"address"`
+	AssetID *crypto.Digest `json:"assetId,omitempty"`
+	Balance uint64         `json:"balance"`
+	Height  proto.Height   `json:"height"`
+}
+
+type TransactionInfo struct {
+	Transaction proto.Transaction `json:"transaction"`
+	Height      proto.Height      `json:"height"`
+	Microblock  *proto.BlockID    `json:"microblock,omitempty"`
+}
+
+type liquidTransaction struct {
+	tx         proto.Transaction
+	microblock proto.BlockID
+}
+
+// liquidOverlay is the liquid block known to NG relative to the state.
+type liquidOverlay struct {
+	key          *proto.Block
+	pending      []proto.Transaction          // Transactions of microblocks that are not applied to the state yet
+	transactions map[string]liquidTransaction // Transactions of all microblocks by IDs
+}
+
+func (a *App) liquidRow() (*proto.Block, []*proto.MicroBlock, error) {
+	if a.liquid == nil {
+		return nil, nil, &BadRequestError{errors.New("liquid block is not available")}
+	}
+	key, micros, ok := a.liquid.LiquidRow()
+	if !ok {
+		return nil, nil, &InternalError{errors.New("liquid block is not ready")}
+	}
+	return key, micros, nil
+}
+
+// overlay compares the liquid block with the top block of the state. It must be called with the state locked,
+// but the liquid block must be taken before the lock because NG locks the state while holding its own lock.
+func (a *App) overlay(key *proto.Block, micros []*proto.MicroBlock) (*liquidOverlay, error) {
+	top := a.state.TopBlock()
+	if top == nil {
+		return nil, &InternalError{errors.New("liquid block is not ready")}
+	}
+	topID := top.BlockID()
+	applied := -1
+	if topID == key.BlockID() {
+		applied = 0
+	}
+	r := &liquidOverlay{key: key, transactions: make(map[string]liquidTransaction)}
+	for i, m := range micros {
+		if m.TotalBlockID == topID {
+			applied = i + 1
+		}
+		for _, tx := range m.Transactions {
+			id, err := tx.GetID(a.services.Scheme)
+			if err != nil {
+				return nil, &InternalError{err}
+			}
+			r.transactions[string(id)] = liquidTransaction{tx: tx, microblock: m.TotalBlockID}
+		}
+	}
+	if applied < 0 {
+		// The state is rolled back to reapply the liquid block with the new microblock.
+		return nil, &InternalError{errors.New("liquid block is being applied to the state")}
+	}
+	for _, m := range micros[applied:] {
+		r.pending = append(r.pending, m.Transactions...)
+	}
+	return r, nil
+}
+
+// Balance returns the balance of the address in Waves or in the asset if the asset ID is not empty.
+// In liquid mode the transactions of the microblocks that are known to NG, but not applied to the state yet,
+// are applied on top of the state for the time of request.
+func (a *App) Balance(address, asset string, liquid bool) (*AddressBalance, error) {
+	addr, err := proto.NewAddressFromString(address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", address)}
+	}
+	r := &AddressBalance{Address: addr}
+	var assetID []byte
+	if asset != "" {
+		id, err := crypto.NewDigestFromBase58(asset)
+		if err != nil {
+			return nil, &BadRequestError{errors.Errorf("invalid asset ID '%s'", asset)}
+		}
+		r.AssetID = &id
+		assetID = id.Bytes()
+	}
+	rcp := proto.NewRecipientFromAddress(addr)
+	if !liquid {
+		locked := a.state.Mutex().RLock()
+		defer locked.Unlock()
+		return a.balance(r, rcp, assetID, a.state.AccountBalance)
+	}
+	key, micros, err := a.liquidRow()
+	if err != nil {
+		return nil, err
+	}
+	// Validation of transactions changes the state's diff storage, so exclusive lock is required.
+	locked := a.state.Mutex().Lock()
+	defer locked.Unlock()
+	o, err := a.overlay(key, micros)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.pending) == 0 {
+		return a.balance(r, rcp, assetID, a.state.AccountBalance)
+	}
+	parent, err := a.state.Header(key.Parent)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	defer a.state.ResetValidationList()
+	for _, tx := range o.pending {
+		if err := a.state.ValidateNextTx(tx, key.Timestamp, parent.Timestamp, key.Version); err != nil {
+			return nil, &InternalError{errors.Wrap(err, "failed to apply transaction of microblock")}
+		}
+	}
+	return a.balance(r, rcp, assetID, a.state.NewestAccountBalance)
+}
+
+func (a *App) balance(r *AddressBalance, rcp proto.Recipient, assetID []byte, get func(proto.Recipient, []byte) (uint64, error)) (*AddressBalance, error) {
+	balance, err := get(rcp, assetID)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	height, err := a.state.Height()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	r.Balance = balance
+	r.Height = height
+	return r, nil
+}
+
+// TransactionInfo returns the transaction with the given base58 ID and the height of its block.
+// In liquid mode the transactions of the microblocks that are known to NG are returned with the ID of
+// the microblock, even if the microblock is not applied to the state yet.
+func (a *App) TransactionInfo(id string, liquid bool) (*TransactionInfo, error) {
+	bid, err := base58.Decode(id)
+	if err != nil || len(bid) == 0 {
+		return nil, &BadRequestError{errors.Errorf("invalid transaction ID '%s'", id)}
+	}
+	var o *liquidOverlay
+	if liquid {
+		key, micros, err := a.liquidRow()
+		if err != nil {
+			return nil, err
+		}
+		locked := a.state.Mutex().RLock()
+		defer locked.Unlock()
+		o, err = a.overlay(key, micros)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		locked := a.state.Mutex().RLock()
+		defer locked.Unlock()
+	}
+	height, err := a.state.Height()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	if o != nil {
+		if lt, ok := o.transactions[string(bid)]; ok {
+			return &TransactionInfo{Transaction: lt.tx, Height: height, Microblock: &lt.microblock}, nil
+		}
+	}
+	tx, err := a.state.TransactionByID(bid)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("transaction '%s' does not exist", id)}
+		}
+		return nil, &InternalError{err}
+	}
+	txHeight, err := a.state.TransactionHeightByID(bid)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return &TransactionInfo{Transaction: tx, Height: txHeight}, nil
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
+	"github.com/wavesplatform/gowaves/pkg/util/byte_helpers"
+	"github.com/wavesplatform/gowaves/pkg/util/lock"
+)
+
+type liquidMock struct {
+	key    *proto.Block
+	micros []*proto.MicroBlock
+}
+
+func (a *liquidMock) LiquidBlock() (*proto.Block, []*proto.MicroBlock, bool) {
+	return a.key, a.micros, a.key != nil
+}
+
+func (a *liquidMock) LiquidRow() (*proto.Block, []*proto.MicroBlock, bool) {
+	return a.key, a.micros, a.key != nil
+}
+
+func txID(t *testing.T, tx proto.Transaction) []byte {
+	id, err := tx.GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+	return id
+}
+
+func TestApp_TransactionsStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inMicro := byte_helpers.TransferWithSig.Transaction
+	confirmed := byte_helpers.IssueWithSig.Transaction
+	unconfirmed := byte_helpers.BurnWithSig.Transaction
+	unknown := byte_helpers.ReissueWithSig.Transaction
+
+	st := mock.NewMockState(ctrl)
+	st.EXPECT().Mutex().Return(lock.NewRwMutex(&sync.RWMutex{})).AnyTimes()
+	st.EXPECT().Height().Return(proto.Height(10), nil).AnyTimes()
+	st.EXPECT().TransactionHeightByID(txID(t, confirmed)).Return(proto.Height(7), nil)
+	st.EXPECT().TransactionHeightByID(gomock.Any()).Return(proto.Height(0), errors.New("not found")).AnyTimes()
+
+	utx := utxpool.New(1000, utxpool.NoOpValidator{}, settings.MainNetSettings)
+	require.NoError(t, utx.AddWithBytes(unconfirmed, []byte{1}))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: st, UtxPool: utx, Scheme: proto.MainNetScheme})
+	require.NoError(t, err)
+
+	key := &proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: crypto.Signature{1}}}
+	micro := &proto.MicroBlock{
+		Reference:    key.BlockID(),
+		TotalBlockID: proto.NewBlockIDFromSignature(crypto.Signature{2}),
+		Transactions: proto.Transactions{inMicro},
+	}
+	liquid := &liquidMock{key: key, micros: []*proto.MicroBlock{micro}}
+	app.SetLiquid(liquid)
+
+	_, err = app.TransactionsStatus([]string{"1"})
+	assert.IsType(t, &BadRequestError{}, err)
+	app.SetTransactionTracker(txstatus.NewTracker(st, utx, liquid, proto.MainNetScheme))
+
+	ids := []string{
+		base58.Encode(txID(t, inMicro)),
+		base58.Encode(txID(t, confirmed)),
+		base58.Encode(txID(t, unconfirmed)),
+		base58.Encode(txID(t, unknown)),
+	}
+	rs, err := app.TransactionsStatus(ids)
+	require.NoError(t, err)
+	microID := micro.TotalBlockID
+	assert.Equal(t, []txstatus.TransactionStatus{
+		{ID: ids[0], Status: txstatus.InMicroblock, Height: 10, Microblock: &microID},
+		{ID: ids[1], Status: txstatus.Confirmed, Height: 7, Confirmations: 3},
+		{ID: ids[2], Status: txstatus.Unconfirmed},
+		{ID: ids[3], Status: txstatus.NotFound},
+	}, rs)
+
+	lb, err := app.LiquidBlock()
+	require.NoError(t, err)
+	assert.Equal(t, microID, lb.ID)
+	assert.Equal(t, key.BlockID(), lb.KeyBlockID)
+	assert.Equal(t, 1, lb.TransactionCount)
+	require.Len(t, lb.Microblocks, 1)
+	assert.Equal(t, []string{ids[0]}, lb.Microblocks[0].Transactions)
+
+	_, err = app.TransactionsStatus(nil)
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.TransactionsStatus([]string{"0OIl"})
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func newLiquidBlock() (*proto.Block, *proto.MicroBlock) {
+	key := &proto.Block{BlockHeader: proto.BlockHeader{
+		BlockSignature: crypto.Signature{1},
+		Parent:         proto.NewBlockIDFromSignature(crypto.Signature{3}),
+		Timestamp:      1000,
+		Version:        proto.NgBlockVersion,
+	}}
+	micro := &proto.MicroBlock{
+		Reference:    key.BlockID(),
+		TotalBlockID: proto.NewBlockIDFromSignature(crypto.Signature{2}),
+		Transactions: proto.Transactions{byte_helpers.TransferWithSig.Transaction},
+	}
+	return key, micro
+}
+
+func TestApp_Balance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	rcp := proto.NewRecipientFromAddress(addr)
+	asset := crypto.Digest{5}
+	key, micro := newLiquidBlock()
+	tx := micro.Transactions[0]
+
+	st := mock.NewMockState(ctrl)
+	st.EXPECT().Mutex().Return(lock.NewRwMutex(&sync.RWMutex{})).AnyTimes()
+	st.EXPECT().Height().Return(proto.Height(10), nil).AnyTimes()
+	app, err := NewApp("api-key", nil, nil, services.Services{State: st, Scheme: proto.MainNetScheme})
+	require.NoError(t, err)
+
+	st.EXPECT().AccountBalance(rcp, nil).Return(uint64(100), nil)
+	rs, err := app.Balance(addr.String(), "", false)
+	require.NoError(t, err)
+	assert.Equal(t, &AddressBalance{Address: addr, Balance: 100, Height: 10}, rs)
+	st.EXPECT().AccountBalance(rcp, asset.Bytes()).Return(uint64(5), nil)
+	rs, err = app.Balance(addr.String(), asset.String(), false)
+	require.NoError(t, err)
+	assert.Equal(t, &AddressBalance{Address: addr, AssetID: &asset, Balance: 5, Height: 10}, rs)
+
+	_, err = app.Balance(addr.String(), "", true)
+	assert.IsType(t, &BadRequestError{}, err, "liquid block is not available")
+	_, err = app.Balance("invalid", "", false)
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.Balance(addr.String(), "0OIl", false)
+	assert.IsType(t, &BadRequestError{}, err)
+
+	app.SetLiquid(&liquidMock{key: key, micros: []*proto.MicroBlock{micro}})
+
+	// The microblock is applied to the state, nothing to overlay.
+	st.EXPECT().TopBlock().Return(&proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: crypto.Signature{2}}})
+	st.EXPECT().AccountBalance(rcp, nil).Return(uint64(74), nil)
+	rs, err = app.Balance(addr.String(), "", true)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(74), rs.Balance)
+
+	// The microblock is known to NG, but not applied to the state yet.
+	gomock.InOrder(
+		st.EXPECT().TopBlock().Return(key),
+		st.EXPECT().Header(key.Parent).Return(&proto.BlockHeader{Timestamp: 900}, nil),
+		st.EXPECT().ValidateNextTx(tx, uint64(1000), uint64(900), proto.NgBlockVersion).Return(nil),
+		st.EXPECT().NewestAccountBalance(rcp, nil).Return(uint64(74), nil),
+		st.EXPECT().ResetValidationList(),
+	)
+	rs, err = app.Balance(addr.String(), "", true)
+	require.NoError(t, err)
+	assert.Equal(t, &AddressBalance{Address: addr, Balance: 74, Height: 10}, rs)
+
+	gomock.InOrder(
+		st.EXPECT().TopBlock().Return(key),
+		st.EXPECT().Header(key.Parent).Return(&proto.BlockHeader{Timestamp: 900}, nil),
+		st.EXPECT().ValidateNextTx(tx, uint64(1000), uint64(900), proto.NgBlockVersion).Return(errors.New("invalid")),
+		st.EXPECT().ResetValidationList(),
+	)
+	_, err = app.Balance(addr.String(), "", true)
+	assert.IsType(t, &InternalError{}, err)
+
+	// The state is rolled back to reapply the liquid block.
+	st.EXPECT().TopBlock().Return(&proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: crypto.Signature{3}}})
+	_, err = app.Balance(addr.String(), "", true)
+	assert.IsType(t, &InternalError{}, err)
+}
+
+func TestApp_TransactionInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key, micro := newLiquidBlock()
+	inMicro := micro.Transactions[0]
+	confirmed := byte_helpers.IssueWithSig.Transaction
+	unknown := byte_helpers.ReissueWithSig.Transaction
+	microID := micro.TotalBlockID
+
+	st := mock.NewMockState(ctrl)
+	st.EXPECT().Mutex().Return(lock.NewRwMutex(&sync.RWMutex{})).AnyTimes()
+	st.EXPECT().Height().Return(proto.Height(10), nil).AnyTimes()
+	st.EXPECT().TransactionByID(txID(t, confirmed)).Return(confirmed, nil).Times(2)
+	st.EXPECT().TransactionHeightByID(txID(t, confirmed)).Return(proto.Height(7), nil).Times(2)
+	st.EXPECT().TransactionByID(txID(t, unknown)).Return(nil, state.NewStateError(state.RetrievalError, proto.ErrNotFound)).Times(2)
+	app, err := NewApp("api-key", nil, nil, services.Services{State: st, Scheme: proto.MainNetScheme})
+	require.NoError(t, err)
+	app.SetLiquid(&liquidMock{key: key, micros: []*proto.MicroBlock{micro}})
+
+	rs, err := app.TransactionInfo(base58.Encode(txID(t, confirmed)), false)
+	require.NoError(t, err)
+	assert.Equal(t, &TransactionInfo{Transaction: confirmed, Height: 7}, rs)
+	_, err = app.TransactionInfo(base58.Encode(txID(t, unknown)), false)
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.TransactionInfo("0OIl", false)
+	assert.IsType(t, &BadRequestError{}, err)
+
+	// The microblock is not applied to the state yet, its transaction is taken from NG.
+	st.EXPECT().TopBlock().Return(key).Times(3)
+	rs, err = app.TransactionInfo(base58.Encode(txID(t, inMicro)), true)
+	require.NoError(t, err)
+	assert.Equal(t, &TransactionInfo{Transaction: inMicro, Height: 10, Microblock: &microID}, rs)
+	rs, err = app.TransactionInfo(base58.Encode(txID(t, confirmed)), true)
+	require.NoError(t, err)
+	assert.Equal(t, &TransactionInfo{Transaction: confirmed, Height: 7}, rs)
+	_, err = app.TransactionInfo(base58.Encode(txID(t, unknown)), true)
+	assert.IsType(t, &BadRequestError{}, err)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+const (
+	defaultScriptHistoryLimit = 100
+	maxScriptHistoryLimit     = 1000
+)
+
+// parseScriptHistoryPage parses the height the page of scripts history starts after, empty after selects the first page.
+func parseScriptHistoryPage(after, limit string) (uint64, int, error) {
+	var h uint64
+	if after != "" {
+		var err error
+		h, err = strconv.ParseUint(after, 10, 64)
+		if err != nil {
+			return 0, 0, &BadRequestError{errors.Errorf("invalid height '%s'", after)}
+		}
+	}
+	l := defaultScriptHistoryLimit
+	if limit != "" {
+		var err error
+		l, err = strconv.Atoi(limit)
+		if err != nil || l <= 0 || l > maxScriptHistoryLimit {
+			return 0, 0, &BadRequestError{errors.Errorf("invalid limit '%s', must be from 1 to %d", limit, maxScriptHistoryLimit)}
+		}
+	}
+	return h, l, nil
+}
+
+// AddressScriptHistory returns the page of scripts the account has had, from the first to the current one.
+// The page starts after the given height.
+func (a *App) AddressScriptHistory(address, after, limit string) ([]proto.ScriptHistoryEntry, error) {
+	addr, err := proto.NewAddressFromString(address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", address)}
+	}
+	h, l, err := parseScriptHistoryPage(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.state.ScriptHistoryByAccount(proto.NewRecipientFromAddress(addr), h, l)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{err}
+		}
+		return nil, &InternalError{err}
+	}
+	if r == nil {
+		r = []proto.ScriptHistoryEntry{}
+	}
+	return r, nil
+}
+
+// AssetScriptHistory returns the page of scripts the asset has had, from the first to the current one.
+// The page starts after the given height.
+func (a *App) AssetScriptHistory(assetID, after, limit string) ([]proto.ScriptHistoryEntry, error) {
+	id, err := crypto.NewDigestFromBase58(assetID)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid asset ID '%s'", assetID)}
+	}
+	h, l, err := parseScriptHistoryPage(after, limit)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.state.ScriptHistoryByAsset(id, h, l)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{err}
+		}
+		return nil, &InternalError{err}
+	}
+	if r == nil {
+		r = []proto.ScriptHistoryEntry{}
+	}
+	return r, nil
+}
+
+// AddressScriptMeta returns the signatures of callable functions of the account's script.
+func (a *App) AddressScriptMeta(address string) (*proto.ScriptMeta, error) {
+	addr, err := proto.NewAddressFromString(address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", address)}
+	}
+	info, err := a.state.ScriptInfoByAccount(proto.NewRecipientFromAddress(addr))
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("address '%s' has no script", address)}
+		}
+		return nil, &InternalError{err}
+	}
+	if len(info.Bytes) == 0 {
+		return nil, &BadRequestError{errors.Errorf("address '%s' has no script", address)}
+	}
+	script, err := ast.BuildScript(reader.NewBytesReader(info.Bytes))
+	if err != nil {
+		return nil, &InternalError{errors.Wrap(err, "failed to parse script")}
+	}
+	meta := &proto.ScriptMeta{
+		Address:   addr,
+		Version:   int32(script.Version),
+		Callables: []proto.CallableFuncSignature{},
+		Verifier:  script.HasVerifier(),
+	}
+	if !script.IsDapp() {
+		return meta, nil
+	}
+	callables, err := script.DApp.Callables()
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	for _, c := range callables {
+		args := make([]proto.CallableFuncArgument, len(c.Arguments))
+		for i, arg := range c.Arguments {
+			args[i] = proto.CallableFuncArgument{Name: arg.Name, Type: arg.Type.String()}
+		}
+		meta.Callables = append(meta.Callables, proto.CallableFuncSignature{Name: c.Name, Arguments: args})
+	}
+	return meta, nil
+}
+
+// Windows of last blocks for scripts statistics, if none are requested.
+var defaultScriptStatsWindows = []uint64{100, 1000, 10000}
+
+const maxScriptStatsWindow = 10000
+
+func parseScriptStatsWindows(windows []string) ([]uint64, error) {
+	if len(windows) == 0 {
+		return defaultScriptStatsWindows, nil
+	}
+	r := make([]uint64, len(windows))
+	for i, w := range windows {
+		v, err := strconv.ParseUint(w, 10, 64)
+		if err != nil || v == 0 || v > maxScriptStatsWindow {
+			return nil, &BadRequestError{errors.Errorf("invalid window '%s', must be from 1 to %d blocks", w, maxScriptStatsWindow)}
+		}
+		r[i] = v
+	}
+	return r, nil
+}
+
+// AddressScriptStats returns the statistics of account's script executions for each window of last blocks.
+func (a *App) AddressScriptStats(address string, windows []string) ([]proto.ScriptStats, error) {
+	addr, err := proto.NewAddressFromString(address)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid address '%s'", address)}
+	}
+	w, err := parseScriptStatsWindows(windows)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.state.ScriptStatsByAccount(proto.NewRecipientFromAddress(addr), w)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return r, nil
+}
+
+// AssetScriptStats returns the statistics of asset's script executions for each window of last blocks.
+func (a *App) AssetScriptStats(assetID string, windows []string) ([]proto.ScriptStats, error) {
+	id, err := crypto.NewDigestFromBase58(assetID)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid asset ID '%s'", assetID)}
+	}
+	w, err := parseScriptStatsWindows(windows)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.state.ScriptStatsByAsset(id, w)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return r, nil
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestApp_AddressScriptHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	history := []proto.ScriptHistoryEntry{{Height: 10, Version: 3, Complexity: 42}, {Height: 20}}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ScriptHistoryByAccount(proto.NewRecipientFromAddress(addr), uint64(0), defaultScriptHistoryLimit).Return(history, nil)
+	s.EXPECT().ScriptHistoryByAccount(proto.NewRecipientFromAddress(addr), uint64(20), 1).Return(nil, nil)
+	s.EXPECT().ScriptHistoryByAccount(proto.NewRecipientFromAddress(addr), uint64(1000), 1).Return(nil, state.NewStateError(state.NotFoundError, proto.ErrNotFound))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AddressScriptHistory(addr.String(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, history, rs)
+	rs, err = app.AddressScriptHistory(addr.String(), "20", "1")
+	require.NoError(t, err)
+	assert.Empty(t, rs)
+	_, err = app.AddressScriptHistory(addr.String(), "1000", "1")
+	assert.IsType(t, &BadRequestError{}, err)
+
+	_, err = app.AddressScriptHistory("invalid", "", "")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptHistory(addr.String(), "-1", "")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptHistory(addr.String(), "", "0")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptHistory(addr.String(), "", "1001")
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_AssetScriptHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, err := crypto.NewDigestFromBase58("8LQW8f7P5d5PZM7GtZEBgaqRPGSzS3DfPuiXrURJ4AJS")
+	require.NoError(t, err)
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ScriptHistoryByAsset(id, uint64(0), defaultScriptHistoryLimit).Return(nil, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AssetScriptHistory(id.String(), "", "")
+	require.NoError(t, err)
+	assert.Empty(t, rs)
+	assert.NotNil(t, rs)
+
+	_, err = app.AssetScriptHistory("invalid", "", "")
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_AddressScriptMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	dApp, err := base64.StdEncoding.DecodeString("AAIDAAAAAAAAAAAAAAAAAAAAAQAAAAFpAQAAAAZ0ZWxsbWUAAAABAAAACHF1ZXN0aW9uCQEAAAALVHJhbnNmZXJTZXQAAAABCQAETAAAAAIJAQAAAA5TY3JpcHRUcmFuc2ZlcgAAAAMIBQAAAAFpAAAABmNhbGxlcgAAAAAAAAAAZAUAAAAEdW5pdAUAAAADbmlsAAAAAH5a2L0=")
+	require.NoError(t, err)
+	// Put the meta that declares the argument of function "tellme" as String.
+	meta := []byte{0x08, 0x01, 0x12, 0x03, 0x0a, 0x01, 0x08}
+	withMeta := append(append(append(append([]byte{}, dApp[:7]...), 0, 0, 0, byte(len(meta))), meta...), dApp[11:]...)
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ScriptInfoByAccount(proto.NewRecipientFromAddress(addr)).Return(&proto.ScriptInfo{Bytes: withMeta}, nil)
+	s.EXPECT().ScriptInfoByAccount(proto.NewRecipientFromAddress(addr)).Return(&proto.ScriptInfo{Bytes: dApp}, nil)
+	s.EXPECT().ScriptInfoByAccount(proto.NewRecipientFromAddress(addr)).Return(&proto.ScriptInfo{}, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AddressScriptMeta(addr.String())
+	require.NoError(t, err)
+	expected := &proto.ScriptMeta{
+		Address: addr,
+		Version: 3,
+		Callables: []proto.CallableFuncSignature{
+			{Name: "tellme", Arguments: []proto.CallableFuncArgument{{Name: "question", Type: "String"}}},
+		},
+	}
+	assert.Equal(t, expected, rs)
+
+	rs, err = app.AddressScriptMeta(addr.String())
+	require.NoError(t, err)
+	expected.Callables[0].Arguments[0].Type = "Any"
+	assert.Equal(t, expected, rs)
+
+	_, err = app.AddressScriptMeta(addr.String())
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptMeta("invalid")
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_AddressScriptStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	stats := []proto.ScriptStats{{Window: 10, FromHeight: 91, ToHeight: 100, CollectedFrom: 50, Invocations: 5, Failures: 1}}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ScriptStatsByAccount(proto.NewRecipientFromAddress(addr), defaultScriptStatsWindows).Return(nil, nil)
+	s.EXPECT().ScriptStatsByAccount(proto.NewRecipientFromAddress(addr), []uint64{10}).Return(stats, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	_, err = app.AddressScriptStats(addr.String(), nil)
+	require.NoError(t, err)
+	rs, err := app.AddressScriptStats(addr.String(), []string{"10"})
+	require.NoError(t, err)
+	assert.Equal(t, stats, rs)
+
+	_, err = app.AddressScriptStats(addr.String(), []string{"0"})
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptStats(addr.String(), []string{"10001"})
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AddressScriptStats("invalid", nil)
+	assert.IsType(t, &BadRequestError{}, err)
+}
+
+func TestApp_AssetScriptStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, err := crypto.NewDigestFromBase58("8LQW8f7P5d5PZM7GtZEBgaqRPGSzS3DfPuiXrURJ4AJS")
+	require.NoError(t, err)
+	stats := []proto.ScriptStats{{Window: 100, FromHeight: 1, ToHeight: 100, Invocations: 1, DistinctCallers: 1}}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().ScriptStatsByAsset(id, []uint64{100, 1000}).Return(stats, nil)
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AssetScriptStats(id.String(), []string{"100", "1000"})
+	require.NoError(t, err)
+	assert.Equal(t, stats, rs)
+
+	_, err = app.AssetScriptStats("invalid", nil)
+	assert.IsType(t, &BadRequestError{}, err)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+// SponsorshipInfo is the sponsorship of the asset along with the fee in Waves converted to the asset, if requested.
+type SponsorshipInfo struct {
+	proto.SponsorshipInfo
+	WavesFee *uint64 `json:"wavesFee,omitempty"`
+	AssetFee *uint64 `json:"assetFee,omitempty"`
+}
+
+// AssetSponsorship returns the sponsorship of the asset.
+// If the fee in Waves is given, it is converted to the minimal fee in the asset by the current rate.
+func (a *App) AssetSponsorship(assetID string, wavesFee string) (*SponsorshipInfo, error) {
+	id, err := crypto.NewDigestFromBase58(assetID)
+	if err != nil {
+		return nil, &BadRequestError{errors.Errorf("invalid asset ID '%s'", assetID)}
+	}
+	var fee uint64
+	if wavesFee != "" {
+		fee, err = strconv.ParseUint(wavesFee, 10, 64)
+		if err != nil {
+			return nil, &BadRequestError{errors.Errorf("invalid fee '%s'", wavesFee)}
+		}
+	}
+	info, err := a.state.SponsorshipInfo(id)
+	if err != nil {
+		if state.IsNotFound(err) {
+			return nil, &BadRequestError{errors.Errorf("asset '%s' does not exist", assetID)}
+		}
+		return nil, &InternalError{err}
+	}
+	if info.History == nil {
+		info.History = []proto.SponsorshipHistoryEntry{}
+	}
+	r := &SponsorshipInfo{SponsorshipInfo: *info}
+	if wavesFee == "" {
+		return r, nil
+	}
+	if !info.Sponsored {
+		return nil, &BadRequestError{errors.Errorf("asset '%s' is not sponsored", assetID)}
+	}
+	assetFee, err := state.WavesToSponsoredAsset(info.MinAssetFee, fee)
+	if err != nil {
+		return nil, &BadRequestError{err}
+	}
+	r.WavesFee = &fee
+	r.AssetFee = &assetFee
+	return r, nil
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/state"
+)
+
+func TestApp_AssetSponsorship(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id := crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd")
+	notSponsored := crypto.MustDigestFromBase58("CqgrRX6PwhsPWEU1oFXW4XNjkBfbMU5W8n2QX8NhHxoD")
+	unknown := crypto.MustDigestFromBase58("F2fdfc2kxgV9ugBeuuYFAgHYnXtc6uvKnQdc5eRXcrMv")
+	sponsor, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	info := &proto.SponsorshipInfo{
+		AssetID:               id,
+		Sponsor:               sponsor,
+		Sponsored:             true,
+		MinAssetFee:           5,
+		SponsorBalance:        1000000,
+		CanCoverFees:          true,
+		SponsoredTransactions: 10,
+		History:               []proto.SponsorshipHistoryEntry{{Height: 10, MinAssetFee: 10}, {Height: 20, MinAssetFee: 5}},
+	}
+	s := mock.NewMockState(ctrl)
+	s.EXPECT().SponsorshipInfo(id).Return(info, nil).Times(2)
+	s.EXPECT().SponsorshipInfo(notSponsored).Return(&proto.SponsorshipInfo{AssetID: notSponsored, Sponsor: sponsor}, nil).Times(2)
+	s.EXPECT().SponsorshipInfo(unknown).Return(nil, state.NewStateError(state.RetrievalError, proto.ErrNotFound))
+
+	app, err := NewApp("api-key", nil, nil, services.Services{State: s})
+	require.NoError(t, err)
+	rs, err := app.AssetSponsorship(id.String(), "")
+	require.NoError(t, err)
+	assert.Equal(t, &SponsorshipInfo{SponsorshipInfo: *info}, rs)
+
+	// Fee of 0.003 Waves costs 15 units of asset.
+	rs, err = app.AssetSponsorship(id.String(), "300000")
+	require.NoError(t, err)
+	require.NotNil(t, rs.AssetFee)
+	assert.Equal(t, uint64(300000), *rs.WavesFee)
+	assert.Equal(t, uint64(15), *rs.AssetFee)
+
+	rs, err = app.AssetSponsorship(notSponsored.String(), "")
+	require.NoError(t, err)
+	assert.False(t, rs.Sponsored)
+	assert.NotNil(t, rs.History)
+	_, err = app.AssetSponsorship(notSponsored.String(), "300000")
+	assert.IsType(t, &BadRequestError{}, err)
+
+	_, err = app.AssetSponsorship(unknown.String(), "")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AssetSponsorship("invalid", "")
+	assert.IsType(t, &BadRequestError{}, err)
+	_, err = app.AssetSponsorship(id.String(), "-1")
+	assert.IsType(t, &BadRequestError{}, err)
+}
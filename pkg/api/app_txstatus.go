@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
+)
+
+// SetTransactionTracker enables the API to report the statuses of transactions.
+func (a *App) SetTransactionTracker(t *txstatus.Tracker) {
+	a.txTracker = t
+}
+
+func (a *App) transactionIDs(ids []string) ([][]byte, error) {
+	if a.txTracker == nil {
+		return nil, &BadRequestError{errors.New("transaction statuses are not available")}
+	}
+	if len(ids) == 0 {
+		return nil, &BadRequestError{errors.New("no transaction IDs")}
+	}
+	if len(ids) > txstatus.MaxIDs {
+		return nil, &BadRequestError{errors.Errorf("too many transaction IDs, at most %d allowed", txstatus.MaxIDs)}
+	}
+	r := make([][]byte, len(ids))
+	for i, s := range ids {
+		id, err := base58.Decode(s)
+		if err != nil || len(id) == 0 {
+			return nil, &BadRequestError{errors.Errorf("invalid transaction ID '%s'", s)}
+		}
+		r[i] = id
+	}
+	return r, nil
+}
+
+// TransactionsStatus returns the statuses of transactions with the given base58 IDs.
+func (a *App) TransactionsStatus(ids []string) ([]txstatus.TransactionStatus, error) {
+	bids, err := a.transactionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	r, err := a.txTracker.Statuses(bids)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return r, nil
+}
+
+// SubscribeTransactions subscribes to the changes of statuses of transactions with the given base58 IDs.
+func (a *App) SubscribeTransactions(ids []string) (*txstatus.Subscription, error) {
+	bids, err := a.transactionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	s, err := a.txTracker.Subscribe(bids)
+	if err != nil {
+		return nil, &InternalError{err}
+	}
+	return s, nil
+}
@@ -193,6 +193,156 @@ func (a *NodeApi) BlockScoreAt(w http.ResponseWriter, r *http.Request) {
 	sendJson(w, rs)
 }
 
+func (a *NodeApi) stateHash(w http.ResponseWriter, r *http.Request) {
+	s := chi.URLParam(r, "height")
+	height, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		handleError(w, &BadRequestError{err})
+		return
+	}
+	sh, err := a.app.DebugStateHash(height)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, sh)
+}
+
+func (a *NodeApi) addressScriptHistory(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AddressScriptHistory(chi.URLParam(r, "address"), r.URL.Query().Get("after"), r.URL.Query().Get("limit"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) assetScriptHistory(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AssetScriptHistory(chi.URLParam(r, "id"), r.URL.Query().Get("after"), r.URL.Query().Get("limit"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) addressScriptMeta(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AddressScriptMeta(chi.URLParam(r, "address"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) addressScriptStats(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AddressScriptStats(chi.URLParam(r, "address"), r.URL.Query()["window"])
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) assetScriptStats(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AssetScriptStats(chi.URLParam(r, "id"), r.URL.Query()["window"])
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) assetSponsorship(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AssetSponsorship(chi.URLParam(r, "id"), r.URL.Query().Get("fee"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) aliasByAlias(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AddressByAlias(chi.URLParam(r, "alias"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) aliasByAddress(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.AliasesByAddress(chi.URLParam(r, "address"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) leasingInfo(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.LeaseInfo(chi.URLParam(r, "id"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) addressBalance(w http.ResponseWriter, r *http.Request) {
+	a.balance(w, r, "")
+}
+
+func (a *NodeApi) assetBalance(w http.ResponseWriter, r *http.Request) {
+	a.balance(w, r, chi.URLParam(r, "id"))
+}
+
+// balance reads the balance from the state, with "liquid=true" query parameter the microblocks known to NG
+// are taken into account.
+func (a *NodeApi) balance(w http.ResponseWriter, r *http.Request, asset string) {
+	liquid := r.URL.Query().Get("liquid") == "true"
+	rs, err := a.app.Balance(chi.URLParam(r, "address"), asset, liquid)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) transactionInfo(w http.ResponseWriter, r *http.Request) {
+	liquid := r.URL.Query().Get("liquid") == "true"
+	rs, err := a.app.TransactionInfo(chi.URLParam(r, "id"), liquid)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) leasingIn(w http.ResponseWriter, r *http.Request) {
+	a.leases(w, r, true)
+}
+
+func (a *NodeApi) leasingOut(w http.ResponseWriter, r *http.Request) {
+	a.leases(w, r, false)
+}
+
+func (a *NodeApi) leases(w http.ResponseWriter, r *http.Request, incoming bool) {
+	q := r.URL.Query()
+	rs, err := a.app.Leases(LeasesRequest{
+		Address:  chi.URLParam(r, "address"),
+		Incoming: incoming,
+		Status:   q.Get("status"),
+		After:    q.Get("after"),
+		Limit:    q.Get("limit"),
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
 func Run(ctx context.Context, address string, n *NodeApi) error {
 	apiServer := &http.Server{Addr: address, Handler: n.routes()}
 	go func() {
@@ -343,6 +493,127 @@ func (a *NodeApi) Minerinfo(w http.ResponseWriter, r *http.Request) {
 	sendJson(w, rs)
 }
 
+func (a *NodeApi) devMineBlock(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.DevMineBlock(r.Header.Get(API_KEY))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) devMineMicroblock(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.DevMineMicroblock(r.Header.Get(API_KEY))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) devTime(w http.ResponseWriter, r *http.Request) {
+	rs, err := a.app.DevTime()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+type devAdvanceTimeRequest struct {
+	Duration string `json:"duration"`
+}
+
+func (a *NodeApi) devAdvanceTime(w http.ResponseWriter, r *http.Request) {
+	req := &devAdvanceTimeRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		handleError(w, &BadRequestError{err})
+		return
+	}
+	d, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		handleError(w, &BadRequestError{err})
+		return
+	}
+	rs, err := a.app.DevAdvanceTime(r.Header.Get(API_KEY), d)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+func (a *NodeApi) blocksLiquid(w http.ResponseWriter, _ *http.Request) {
+	rs, err := a.app.LiquidBlock()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+type transactionsStatusRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// transactionsStatus accepts the IDs as repeated "id" query parameters or in JSON body of POST request.
+func (a *NodeApi) transactionsStatus(w http.ResponseWriter, r *http.Request) {
+	ids := r.URL.Query()["id"]
+	if r.Method == http.MethodPost {
+		req := &transactionsStatusRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			handleError(w, &BadRequestError{err})
+			return
+		}
+		ids = req.IDs
+	}
+	rs, err := a.app.TransactionsStatus(ids)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJson(w, rs)
+}
+
+// transactionsSubscribe streams the statuses of transactions given as "id" query parameters as server-sent events
+// until the client disconnects.
+func (a *NodeApi) transactionsSubscribe(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	sub, err := a.app.SubscribeTransactions(r.URL.Query()["id"])
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	defer sub.Close()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case st, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			bts, err := json.Marshal(st)
+			if err != nil {
+				zap.S().Errorf("Failed to marshal transaction status: %v", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", bts); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
 func (a *NodeApi) nodeProcesses(w http.ResponseWriter, r *http.Request) {
 	rs := a.app.NodeProcesses()
 	sendJson(w, rs)
@@ -11,8 +11,22 @@ func (a *NodeApi) routes() chi.Router {
 	r.Get("/blocks/score/at/{id:\\d+}", a.BlockScoreAt)
 	r.Get("/blocks/id/{id}", a.BlockIDAt)
 	r.Get("/blocks/generators", a.BlocksGenerators)
+	r.Get("/blocks/liquid", a.blocksLiquid)
 	r.Post("/blocks/rollback", RollbackToHeight(a.app))
 	r.Get("/pool/transactions", a.poolTransactions)
+	r.Get("/addresses/scriptHistory/{address}", a.addressScriptHistory)
+	r.Get("/assets/scriptHistory/{id}", a.assetScriptHistory)
+	r.Get("/addresses/scriptInfo/{address}/meta", a.addressScriptMeta)
+	r.Get("/addresses/scriptStats/{address}", a.addressScriptStats)
+	r.Get("/assets/scriptStats/{id}", a.assetScriptStats)
+	r.Get("/assets/sponsorship/{id}", a.assetSponsorship)
+	r.Get("/addresses/balance/{address}", a.addressBalance)
+	r.Get("/assets/balance/{address}/{id}", a.assetBalance)
+	r.Get("/alias/by-alias/{alias}", a.aliasByAlias)
+	r.Get("/alias/by-address/{address}", a.aliasByAddress)
+	r.Get("/leasing/info/{id}", a.leasingInfo)
+	r.Get("/leasing/in/{address}", a.leasingIn)
+	r.Get("/leasing/out/{address}", a.leasingOut)
 	r.Route("/peers", func(r chi.Router) {
 		r.Get("/known", a.PeersAll)
 		r.Get("/connected", a.PeersConnected)
@@ -22,10 +36,21 @@ func (a *NodeApi) routes() chi.Router {
 	})
 	r.Get("/miner/info", a.Minerinfo)
 	r.Post("/transactions/broadcast", a.TransactionsBroadcast)
+	r.Get("/transactions/info/{id}", a.transactionInfo)
+	r.Get("/transactions/status", a.transactionsStatus)
+	r.Post("/transactions/status", a.transactionsStatus)
+	r.Get("/transactions/status/subscribe", a.transactionsSubscribe)
 
 	r.Post("/wallet/load", WalletLoadKeys(a.app))
 
 	r.Get("/node/processes", a.nodeProcesses)
+	r.Get("/debug/stateHash/{height:\\d+}", a.stateHash)
+	r.Route("/dev", func(r chi.Router) {
+		r.Post("/blocks/mine", a.devMineBlock)
+		r.Post("/microblocks/mine", a.devMineMicroblock)
+		r.Get("/time", a.devTime)
+		r.Post("/time/advance", a.devAdvanceTime)
+	})
 	// enable or disable history sync
 	//r.Get("/debug/sync/{enabled:\\d+}", a.DebugSyncEnabled)
 
@@ -8,7 +8,9 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -106,6 +108,65 @@ func (a *Addresses) ScriptInfo(ctx context.Context, address proto.Address) (*Add
 	return out, response, nil
 }
 
+// ScriptMeta gets signatures of callable functions of account's dApp
+func (a *Addresses) ScriptMeta(ctx context.Context, address proto.Address) (*proto.ScriptMeta, *Response, error) {
+	url, err := joinUrl(a.options.BaseUrl, fmt.Sprintf("/addresses/scriptInfo/%s/meta", address.String()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := new(proto.ScriptMeta)
+	response, err := doHttp(ctx, a.options, req, out)
+	if err != nil {
+		return nil, response, err
+	}
+
+	return out, response, nil
+}
+
+var argumentTypes = map[proto.ArgumentValueType]ast.ArgumentType{
+	proto.ArgumentInteger: ast.ArgumentInt,
+	proto.ArgumentBinary:  ast.ArgumentByteVector,
+	proto.ArgumentBoolean: ast.ArgumentBoolean,
+	proto.ArgumentString:  ast.ArgumentString,
+}
+
+// CheckFunctionCall checks that dApp has the callable function and accepts the arguments of the call, before the invoke is broadcasted.
+// Types of arguments are not checked if the dApp has no meta.
+func CheckFunctionCall(meta *proto.ScriptMeta, call proto.FunctionCall) error {
+	name := call.Name
+	if call.Default {
+		name = "default"
+	}
+	for _, f := range meta.Callables {
+		if f.Name != name {
+			continue
+		}
+		if len(call.Arguments) != len(f.Arguments) {
+			return errors.Errorf("function '%s' takes %d arguments, %d given", name, len(f.Arguments), len(call.Arguments))
+		}
+		for i, arg := range call.Arguments {
+			expected, err := ast.ParseArgumentType(f.Arguments[i].Type)
+			if err != nil {
+				return errors.Wrapf(err, "invalid type of argument '%s' of function '%s'", f.Arguments[i].Name, name)
+			}
+			if expected == 0 {
+				continue
+			}
+			if argumentTypes[arg.GetValueType()]&expected == 0 {
+				return errors.Errorf("argument '%s' of function '%s' must be %s, %s given", f.Arguments[i].Name, name, expected, arg.GetValueType())
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("dApp %s has no callable function '%s'", meta.Address.String(), name)
+}
+
 // Get wallet accounts addresses
 func (a *Addresses) Addresses(ctx context.Context) ([]proto.Address, *Response, error) {
 	url, err := joinUrl(a.options.BaseUrl, "/addresses")
@@ -313,3 +374,34 @@ func (a *Addresses) BalanceAfterConfirmations(
 
 	return out, response, nil
 }
+
+// DataKey returns the entry of account's data storage by the given key
+func (a *Addresses) DataKey(ctx context.Context, address proto.Address, key string) (proto.DataEntry, *Response, error) {
+	url, err := joinUrl(a.options.BaseUrl, fmt.Sprintf("/addresses/data/%s/%s", address.String(), url.PathEscape(key)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteRune('[')
+	response, err := doHttp(ctx, a.options, req, buf)
+	if err != nil {
+		return nil, response, err
+	}
+	buf.WriteRune(']')
+
+	var entries proto.DataEntries
+	if err = json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		return nil, response, &ParseError{Err: err}
+	}
+	if len(entries) != 1 {
+		return nil, response, &ParseError{Err: errors.Errorf("unexpected number of entries %d", len(entries))}
+	}
+
+	return entries[0], response, nil
+}
@@ -66,6 +66,53 @@ func TestAddresses_ScriptInfo(t *testing.T) {
 		resp.Request.URL.String())
 }
 
+var addressesScriptMetaJson = `
+{
+  "address": "3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8",
+  "version": 3,
+  "callableFuncTypes": [
+    {"name": "deposit", "args": []},
+    {"name": "withdraw", "args": [{"name": "amount", "type": "Int"}, {"name": "asset", "type": "ByteVector|String"}]},
+    {"name": "default", "args": [{"name": "x", "type": "Any"}]}
+  ],
+  "verifier": true
+}`
+
+func TestAddresses_ScriptMeta(t *testing.T) {
+	address, _ := proto.NewAddressFromString("3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8")
+	client, err := NewClient(Options{
+		BaseUrl: "https://testnode1.wavesnodes.com/",
+		Client:  NewMockHttpRequestFromString(addressesScriptMetaJson, 200),
+	})
+	require.NoError(t, err)
+	body, resp, err :=
+		client.Addresses.ScriptMeta(context.Background(), address)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, address, body.Address)
+	assert.True(t, body.Verifier)
+	assert.Len(t, body.Callables, 3)
+	assert.Equal(t, proto.CallableFuncArgument{Name: "amount", Type: "Int"}, body.Callables[1].Arguments[0])
+	assert.Equal(t,
+		"https://testnode1.wavesnodes.com/addresses/scriptInfo/3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8/meta",
+		resp.Request.URL.String())
+
+	assert.NoError(t, CheckFunctionCall(body, proto.FunctionCall{Name: "deposit"}))
+	assert.NoError(t, CheckFunctionCall(body, proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{
+		&proto.IntegerArgument{Value: 100}, &proto.StringArgument{Value: "WAVES"},
+	}}))
+	assert.NoError(t, CheckFunctionCall(body, proto.FunctionCall{Default: true, Arguments: proto.Arguments{
+		&proto.BooleanArgument{Value: true},
+	}}))
+	assert.Error(t, CheckFunctionCall(body, proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{
+		&proto.StringArgument{Value: "100"}, &proto.StringArgument{Value: "WAVES"},
+	}}))
+	assert.Error(t, CheckFunctionCall(body, proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{
+		&proto.IntegerArgument{Value: 100},
+	}}))
+	assert.Error(t, CheckFunctionCall(body, proto.FunctionCall{Name: "unknown"}))
+}
+
 var addressesAddressesJson = `
 [
   "3MzemqBzJ9h844PparHU1EzGC5SQmtH5pNp"
@@ -272,3 +319,27 @@ func TestAddresses_BalanceAfterConfirmations(t *testing.T) {
 	assert.EqualValues(t, 37983102983592, body.Balance)
 	assert.Equal(t, "https://testnode1.wavesnodes.com/addresses/balance/3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8/1", resp.Request.URL.String())
 }
+
+var addressesDataKeyJson = `
+{
+  "key": "some?key",
+  "type": "integer",
+  "value": 100500
+}`
+
+func TestAddresses_DataKey(t *testing.T) {
+	address, _ := proto.NewAddressFromString("3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8")
+	client, err := NewClient(Options{
+		BaseUrl: "https://testnode1.wavesnodes.com/",
+		Client:  NewMockHttpRequestFromString(addressesDataKeyJson, 200),
+	})
+	require.NoError(t, err)
+	body, resp, err :=
+		client.Addresses.DataKey(context.Background(), address, "some?key")
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, &proto.IntegerDataEntry{Key: "some?key", Value: 100500}, body)
+	assert.Equal(t,
+		"https://testnode1.wavesnodes.com/addresses/data/3NBVqYXrapgJP9atQccdBPAgJPwHDKkh6A8/some%3Fkey",
+		resp.Request.URL.String())
+}
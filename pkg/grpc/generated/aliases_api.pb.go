@@ -0,0 +1,152 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: aliases_api.proto
+
+package generated
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+func init() { proto.RegisterFile("aliases_api.proto", fileDescriptor_5a1f0d318ef8611c) }
+
+var fileDescriptor_5a1f0d318ef8611c = []byte{
+	// 166 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x8d, 0xbd, 0xaa, 0x02, 0x31,
+	0x10, 0x46, 0xb9, 0xcd, 0x05, 0x63, 0x21, 0xa6, 0x5c, 0x44, 0xdf, 0x60, 0x56, 0xf4, 0x09, 0xd6,
+	0xc6, 0xca, 0x46, 0xc1, 0x42, 0x0b, 0x99, 0xdd, 0xfd, 0x0c, 0x81, 0x90, 0xc4, 0xfc, 0xb8, 0xaf,
+	0x2f, 0x6c, 0x14, 0xc4, 0x72, 0xe6, 0x3b, 0x9c, 0x23, 0xe6, 0x6c, 0x34, 0x47, 0xc4, 0x1b, 0x7b,
+	0x4d, 0x3e, 0xb8, 0xe4, 0xe4, 0x6c, 0xe0, 0x27, 0x22, 0x59, 0xd7, 0x83, 0x54, 0xf0, 0x5d, 0x25,
+	0xb9, 0xeb, 0x5c, 0xb6, 0xe9, 0x0b, 0xaa, 0x96, 0xca, 0x39, 0x65, 0x50, 0x8f, 0x57, 0x9b, 0xef,
+	0xf5, 0x10, 0xd8, 0x7b, 0x84, 0x58, 0xf6, 0xcd, 0x55, 0x88, 0xa6, 0x98, 0x1b, 0xaf, 0xe5, 0x41,
+	0x88, 0x3d, 0xd2, 0xfb, 0x21, 0x57, 0xf4, 0x53, 0xa0, 0xa6, 0x04, 0x8e, 0x78, 0x64, 0xc4, 0x54,
+	0x2d, 0xa8, 0xd8, 0xe9, 0x63, 0xa7, 0x53, 0x0a, 0xda, 0xaa, 0x33, 0x9b, 0x8c, 0xf5, 0xdf, 0x6e,
+	0x7a, 0x99, 0x28, 0x58, 0x04, 0x4e, 0xe8, 0xdb, 0xff, 0x11, 0xda, 0xbe, 0x06, 0x00, 0x47, 0xc1,
+	0x98, 0x4f, 0xca, 0x00, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AliasesApiClient is the client API for AliasesApi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AliasesApiClient interface {
+	// Streams the names of aliases owned by the address.
+	GetAliases(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (AliasesApi_GetAliasesClient, error)
+}
+
+type aliasesApiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAliasesApiClient(cc *grpc.ClientConn) AliasesApiClient {
+	return &aliasesApiClient{cc}
+}
+
+func (c *aliasesApiClient) GetAliases(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (AliasesApi_GetAliasesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_AliasesApi_serviceDesc.Streams[0], "/waves.node.grpc.AliasesApi/GetAliases", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aliasesApiGetAliasesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AliasesApi_GetAliasesClient interface {
+	Recv() (*wrappers.StringValue, error)
+	grpc.ClientStream
+}
+
+type aliasesApiGetAliasesClient struct {
+	grpc.ClientStream
+}
+
+func (x *aliasesApiGetAliasesClient) Recv() (*wrappers.StringValue, error) {
+	m := new(wrappers.StringValue)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AliasesApiServer is the server API for AliasesApi service.
+type AliasesApiServer interface {
+	// Streams the names of aliases owned by the address.
+	GetAliases(*AccountRequest, AliasesApi_GetAliasesServer) error
+}
+
+// UnimplementedAliasesApiServer can be embedded to have forward compatible implementations.
+type UnimplementedAliasesApiServer struct {
+}
+
+func (*UnimplementedAliasesApiServer) GetAliases(req *AccountRequest, srv AliasesApi_GetAliasesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetAliases not implemented")
+}
+
+func RegisterAliasesApiServer(s *grpc.Server, srv AliasesApiServer) {
+	s.RegisterService(&_AliasesApi_serviceDesc, srv)
+}
+
+func _AliasesApi_GetAliases_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AccountRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AliasesApiServer).GetAliases(m, &aliasesApiGetAliasesServer{stream})
+}
+
+type AliasesApi_GetAliasesServer interface {
+	Send(*wrappers.StringValue) error
+	grpc.ServerStream
+}
+
+type aliasesApiGetAliasesServer struct {
+	grpc.ServerStream
+}
+
+func (x *aliasesApiGetAliasesServer) Send(m *wrappers.StringValue) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _AliasesApi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "waves.node.grpc.AliasesApi",
+	HandlerType: (*AliasesApiServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetAliases",
+			Handler:       _AliasesApi_GetAliases_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "aliases_api.proto",
+}
@@ -0,0 +1,293 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: dapp_api.proto
+
+package generated
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type CallableArgument struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Bitmask of accepted types: 1 - Int, 2 - ByteVector, 4 - Boolean, 8 - String. Zero if dApp has no meta.
+	Type                 uint32   `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallableArgument) Reset()         { *m = CallableArgument{} }
+func (m *CallableArgument) String() string { return proto.CompactTextString(m) }
+func (*CallableArgument) ProtoMessage()    {}
+func (*CallableArgument) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6cb66e6ac9ee12a6, []int{0}
+}
+
+func (m *CallableArgument) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallableArgument.Unmarshal(m, b)
+}
+func (m *CallableArgument) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallableArgument.Marshal(b, m, deterministic)
+}
+func (m *CallableArgument) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallableArgument.Merge(m, src)
+}
+func (m *CallableArgument) XXX_Size() int {
+	return xxx_messageInfo_CallableArgument.Size(m)
+}
+func (m *CallableArgument) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallableArgument.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallableArgument proto.InternalMessageInfo
+
+func (m *CallableArgument) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CallableArgument) GetType() uint32 {
+	if m != nil {
+		return m.Type
+	}
+	return 0
+}
+
+type CallableSignature struct {
+	Name                 string              `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Arguments            []*CallableArgument `protobuf:"bytes,2,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *CallableSignature) Reset()         { *m = CallableSignature{} }
+func (m *CallableSignature) String() string { return proto.CompactTextString(m) }
+func (*CallableSignature) ProtoMessage()    {}
+func (*CallableSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6cb66e6ac9ee12a6, []int{1}
+}
+
+func (m *CallableSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallableSignature.Unmarshal(m, b)
+}
+func (m *CallableSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallableSignature.Marshal(b, m, deterministic)
+}
+func (m *CallableSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallableSignature.Merge(m, src)
+}
+func (m *CallableSignature) XXX_Size() int {
+	return xxx_messageInfo_CallableSignature.Size(m)
+}
+func (m *CallableSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallableSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallableSignature proto.InternalMessageInfo
+
+func (m *CallableSignature) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CallableSignature) GetArguments() []*CallableArgument {
+	if m != nil {
+		return m.Arguments
+	}
+	return nil
+}
+
+type CallablesResponse struct {
+	Address              []byte               `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Version              int32                `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Callables            []*CallableSignature `protobuf:"bytes,3,rep,name=callables,proto3" json:"callables,omitempty"`
+	Verifier             bool                 `protobuf:"varint,4,opt,name=verifier,proto3" json:"verifier,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *CallablesResponse) Reset()         { *m = CallablesResponse{} }
+func (m *CallablesResponse) String() string { return proto.CompactTextString(m) }
+func (*CallablesResponse) ProtoMessage()    {}
+func (*CallablesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6cb66e6ac9ee12a6, []int{2}
+}
+
+func (m *CallablesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallablesResponse.Unmarshal(m, b)
+}
+func (m *CallablesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallablesResponse.Marshal(b, m, deterministic)
+}
+func (m *CallablesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallablesResponse.Merge(m, src)
+}
+func (m *CallablesResponse) XXX_Size() int {
+	return xxx_messageInfo_CallablesResponse.Size(m)
+}
+func (m *CallablesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallablesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallablesResponse proto.InternalMessageInfo
+
+func (m *CallablesResponse) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *CallablesResponse) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *CallablesResponse) GetCallables() []*CallableSignature {
+	if m != nil {
+		return m.Callables
+	}
+	return nil
+}
+
+func (m *CallablesResponse) GetVerifier() bool {
+	if m != nil {
+		return m.Verifier
+	}
+	return false
+}
+
+func init() {
+	proto.RegisterType((*CallableArgument)(nil), "waves.node.grpc.CallableArgument")
+	proto.RegisterType((*CallableSignature)(nil), "waves.node.grpc.CallableSignature")
+	proto.RegisterType((*CallablesResponse)(nil), "waves.node.grpc.CallablesResponse")
+}
+
+func init() { proto.RegisterFile("dapp_api.proto", fileDescriptor_6cb66e6ac9ee12a6) }
+
+var fileDescriptor_6cb66e6ac9ee12a6 = []byte{
+	// 285 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x3f, 0x4f, 0xc3, 0x30,
+	0x10, 0xc5, 0x95, 0xb6, 0xd0, 0xc6, 0x2d, 0xff, 0x3c, 0x59, 0x59, 0x08, 0x99, 0x32, 0x65, 0x28,
+	0x1b, 0x0b, 0x04, 0x90, 0xd8, 0xdd, 0x8d, 0x01, 0x74, 0x4d, 0x8e, 0x60, 0x29, 0xb5, 0x8d, 0xed,
+	0x04, 0xf1, 0x81, 0xf8, 0x9e, 0xa8, 0x86, 0x24, 0x52, 0x69, 0xb7, 0x7b, 0xf6, 0xbd, 0x7b, 0xbf,
+	0xd3, 0x91, 0xd3, 0x12, 0xb4, 0x7e, 0x05, 0x2d, 0x32, 0x6d, 0x94, 0x53, 0xf4, 0xec, 0x13, 0x5a,
+	0xb4, 0x99, 0x54, 0x25, 0x66, 0x95, 0xd1, 0x45, 0x44, 0xa1, 0x28, 0x54, 0x23, 0x9d, 0x1d, 0x9a,
+	0x92, 0x1b, 0x72, 0xfe, 0x00, 0x75, 0x0d, 0xeb, 0x1a, 0x73, 0x53, 0x35, 0x1b, 0x94, 0x8e, 0x52,
+	0x32, 0x91, 0xb0, 0x41, 0x16, 0xc4, 0x41, 0x1a, 0x72, 0x5f, 0x6f, 0xdf, 0xdc, 0x97, 0x46, 0x36,
+	0x8a, 0x83, 0xf4, 0x84, 0xfb, 0x3a, 0x79, 0x27, 0x17, 0x9d, 0x77, 0x25, 0x2a, 0x09, 0xae, 0x31,
+	0xb8, 0xd7, 0x7c, 0x4b, 0x42, 0xf8, 0x1b, 0x6e, 0xd9, 0x28, 0x1e, 0xa7, 0xf3, 0xe5, 0x55, 0xb6,
+	0x43, 0x97, 0xed, 0x62, 0xf0, 0xc1, 0x93, 0x7c, 0x07, 0x43, 0x94, 0xe5, 0x68, 0xb5, 0x92, 0x16,
+	0x29, 0x23, 0x53, 0x28, 0x4b, 0x83, 0xd6, 0xfa, 0xb4, 0x05, 0xef, 0xe4, 0xf6, 0xa7, 0x45, 0x63,
+	0x85, 0x92, 0x1e, 0xf8, 0x88, 0x77, 0x92, 0xde, 0x91, 0xb0, 0xe8, 0x06, 0xb1, 0xb1, 0x47, 0x49,
+	0x0e, 0xa2, 0xf4, 0x5b, 0xf1, 0xc1, 0x44, 0x23, 0x32, 0x6b, 0xd1, 0x88, 0x37, 0x81, 0x86, 0x4d,
+	0xe2, 0x20, 0x9d, 0xf1, 0x5e, 0x2f, 0x5f, 0xc8, 0xf4, 0x31, 0xd7, 0x3a, 0xd7, 0x82, 0xae, 0xc8,
+	0xe2, 0x09, 0x5d, 0x0f, 0x4d, 0x2f, 0xff, 0xa5, 0xe4, 0xbf, 0xd7, 0xe0, 0xf8, 0xd1, 0xa0, 0x75,
+	0xd1, 0x61, 0x8c, 0x7e, 0xe3, 0xfb, 0xf9, 0x73, 0x58, 0xa1, 0x44, 0x03, 0x0e, 0xcb, 0xf5, 0xb1,
+	0xbf, 0xe0, 0xf5, 0xcf, 0x00, 0xb2, 0xe4, 0xec, 0xaf, 0xf8, 0x01, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DAppApiClient is the client API for DAppApi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DAppApiClient interface {
+	GetCallables(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*CallablesResponse, error)
+}
+
+type dAppApiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDAppApiClient(cc *grpc.ClientConn) DAppApiClient {
+	return &dAppApiClient{cc}
+}
+
+func (c *dAppApiClient) GetCallables(ctx context.Context, in *AccountRequest, opts ...grpc.CallOption) (*CallablesResponse, error) {
+	out := new(CallablesResponse)
+	err := c.cc.Invoke(ctx, "/waves.node.grpc.DAppApi/GetCallables", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DAppApiServer is the server API for DAppApi service.
+type DAppApiServer interface {
+	GetCallables(context.Context, *AccountRequest) (*CallablesResponse, error)
+}
+
+// UnimplementedDAppApiServer can be embedded to have forward compatible implementations.
+type UnimplementedDAppApiServer struct {
+}
+
+func (*UnimplementedDAppApiServer) GetCallables(ctx context.Context, req *AccountRequest) (*CallablesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCallables not implemented")
+}
+
+func RegisterDAppApiServer(s *grpc.Server, srv DAppApiServer) {
+	s.RegisterService(&_DAppApi_serviceDesc, srv)
+}
+
+func _DAppApi_GetCallables_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DAppApiServer).GetCallables(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/waves.node.grpc.DAppApi/GetCallables",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DAppApiServer).GetCallables(ctx, req.(*AccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DAppApi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "waves.node.grpc.DAppApi",
+	HandlerType: (*DAppApiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCallables",
+			Handler:    _DAppApi_GetCallables_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dapp_api.proto",
+}
@@ -0,0 +1,296 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: debug_api.proto
+
+package generated
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type StateHashRequest struct {
+	Height               uint32   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateHashRequest) Reset()         { *m = StateHashRequest{} }
+func (m *StateHashRequest) String() string { return proto.CompactTextString(m) }
+func (*StateHashRequest) ProtoMessage()    {}
+func (*StateHashRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5bc71a5fbe4c3a3f, []int{0}
+}
+
+func (m *StateHashRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateHashRequest.Unmarshal(m, b)
+}
+func (m *StateHashRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateHashRequest.Marshal(b, m, deterministic)
+}
+func (m *StateHashRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateHashRequest.Merge(m, src)
+}
+func (m *StateHashRequest) XXX_Size() int {
+	return xxx_messageInfo_StateHashRequest.Size(m)
+}
+func (m *StateHashRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateHashRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateHashRequest proto.InternalMessageInfo
+
+func (m *StateHashRequest) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type StateHashResponse struct {
+	BlockId              []byte   `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	StateHash            []byte   `protobuf:"bytes,2,opt,name=state_hash,json=stateHash,proto3" json:"state_hash,omitempty"`
+	WavesBalanceHash     []byte   `protobuf:"bytes,3,opt,name=waves_balance_hash,json=wavesBalanceHash,proto3" json:"waves_balance_hash,omitempty"`
+	AssetBalanceHash     []byte   `protobuf:"bytes,4,opt,name=asset_balance_hash,json=assetBalanceHash,proto3" json:"asset_balance_hash,omitempty"`
+	DataEntryHash        []byte   `protobuf:"bytes,5,opt,name=data_entry_hash,json=dataEntryHash,proto3" json:"data_entry_hash,omitempty"`
+	AccountScriptHash    []byte   `protobuf:"bytes,6,opt,name=account_script_hash,json=accountScriptHash,proto3" json:"account_script_hash,omitempty"`
+	AssetScriptHash      []byte   `protobuf:"bytes,7,opt,name=asset_script_hash,json=assetScriptHash,proto3" json:"asset_script_hash,omitempty"`
+	LeaseBalanceHash     []byte   `protobuf:"bytes,8,opt,name=lease_balance_hash,json=leaseBalanceHash,proto3" json:"lease_balance_hash,omitempty"`
+	LeaseStatusHash      []byte   `protobuf:"bytes,9,opt,name=lease_status_hash,json=leaseStatusHash,proto3" json:"lease_status_hash,omitempty"`
+	SponsorshipHash      []byte   `protobuf:"bytes,10,opt,name=sponsorship_hash,json=sponsorshipHash,proto3" json:"sponsorship_hash,omitempty"`
+	AliasHash            []byte   `protobuf:"bytes,11,opt,name=alias_hash,json=aliasHash,proto3" json:"alias_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StateHashResponse) Reset()         { *m = StateHashResponse{} }
+func (m *StateHashResponse) String() string { return proto.CompactTextString(m) }
+func (*StateHashResponse) ProtoMessage()    {}
+func (*StateHashResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5bc71a5fbe4c3a3f, []int{1}
+}
+
+func (m *StateHashResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StateHashResponse.Unmarshal(m, b)
+}
+func (m *StateHashResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StateHashResponse.Marshal(b, m, deterministic)
+}
+func (m *StateHashResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StateHashResponse.Merge(m, src)
+}
+func (m *StateHashResponse) XXX_Size() int {
+	return xxx_messageInfo_StateHashResponse.Size(m)
+}
+func (m *StateHashResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_StateHashResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StateHashResponse proto.InternalMessageInfo
+
+func (m *StateHashResponse) GetBlockId() []byte {
+	if m != nil {
+		return m.BlockId
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetStateHash() []byte {
+	if m != nil {
+		return m.StateHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetWavesBalanceHash() []byte {
+	if m != nil {
+		return m.WavesBalanceHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetAssetBalanceHash() []byte {
+	if m != nil {
+		return m.AssetBalanceHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetDataEntryHash() []byte {
+	if m != nil {
+		return m.DataEntryHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetAccountScriptHash() []byte {
+	if m != nil {
+		return m.AccountScriptHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetAssetScriptHash() []byte {
+	if m != nil {
+		return m.AssetScriptHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetLeaseBalanceHash() []byte {
+	if m != nil {
+		return m.LeaseBalanceHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetLeaseStatusHash() []byte {
+	if m != nil {
+		return m.LeaseStatusHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetSponsorshipHash() []byte {
+	if m != nil {
+		return m.SponsorshipHash
+	}
+	return nil
+}
+
+func (m *StateHashResponse) GetAliasHash() []byte {
+	if m != nil {
+		return m.AliasHash
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*StateHashRequest)(nil), "waves.node.grpc.StateHashRequest")
+	proto.RegisterType((*StateHashResponse)(nil), "waves.node.grpc.StateHashResponse")
+}
+
+func init() { proto.RegisterFile("debug_api.proto", fileDescriptor_5bc71a5fbe4c3a3f) }
+
+var fileDescriptor_5bc71a5fbe4c3a3f = []byte{
+	// 349 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0xd2, 0x4f, 0x4b, 0xf3, 0x40,
+	0x10, 0x06, 0x70, 0xfa, 0xf6, 0xb5, 0x7f, 0xa6, 0x2d, 0x69, 0x56, 0x90, 0x2a, 0x14, 0xb4, 0x07,
+	0xd1, 0x52, 0x72, 0xd0, 0x4f, 0x60, 0x51, 0xd4, 0x6b, 0x8b, 0x17, 0x2f, 0x61, 0x92, 0x0c, 0x49,
+	0x30, 0x24, 0x6b, 0x66, 0xa3, 0xf8, 0x31, 0xfd, 0x46, 0x92, 0xd9, 0x54, 0xd2, 0x1e, 0x3c, 0xee,
+	0x3c, 0x3f, 0x96, 0x61, 0xf7, 0x01, 0x27, 0xa2, 0xa0, 0x8a, 0x7d, 0xd4, 0xa9, 0xa7, 0xcb, 0xc2,
+	0x14, 0xca, 0xf9, 0xc4, 0x0f, 0x62, 0x2f, 0x2f, 0x22, 0xf2, 0xe2, 0x52, 0x87, 0x8b, 0x25, 0x4c,
+	0xb7, 0x06, 0x0d, 0x3d, 0x21, 0x27, 0x1b, 0x7a, 0xaf, 0x88, 0x8d, 0x3a, 0x81, 0x5e, 0x42, 0x69,
+	0x9c, 0x98, 0x59, 0xe7, 0xbc, 0x73, 0x35, 0xd9, 0x34, 0xa7, 0xc5, 0x77, 0x17, 0xdc, 0x16, 0x66,
+	0x5d, 0xe4, 0x4c, 0xea, 0x14, 0x06, 0x41, 0x56, 0x84, 0x6f, 0x7e, 0x1a, 0x89, 0x1f, 0x6f, 0xfa,
+	0x72, 0x7e, 0x8e, 0xd4, 0x1c, 0x80, 0x6b, 0xef, 0x27, 0xc8, 0xc9, 0xec, 0x9f, 0x84, 0x43, 0xde,
+	0xdd, 0xa0, 0x56, 0xa0, 0x64, 0x1d, 0x3f, 0xc0, 0x0c, 0xf3, 0xb0, 0x61, 0x5d, 0x61, 0x53, 0x49,
+	0xd6, 0x36, 0xd8, 0x69, 0x64, 0x26, 0xb3, 0xaf, 0xff, 0x5b, 0x2d, 0x49, 0x5b, 0x5f, 0x82, 0x13,
+	0xa1, 0x41, 0x9f, 0x72, 0x53, 0x7e, 0x59, 0x7a, 0x24, 0x74, 0x52, 0x8f, 0x1f, 0xea, 0xa9, 0x38,
+	0x0f, 0x8e, 0x31, 0x0c, 0x8b, 0x2a, 0x37, 0x3e, 0x87, 0x65, 0xaa, 0x8d, 0xb5, 0x3d, 0xb1, 0x6e,
+	0x13, 0x6d, 0x25, 0x11, 0xbf, 0x04, 0xd7, 0x6e, 0xd1, 0xd6, 0x7d, 0xd1, 0x8e, 0x04, 0x2d, 0xbb,
+	0x02, 0x95, 0x11, 0x32, 0xed, 0x6f, 0x3c, 0xb0, 0x1b, 0x4b, 0xd2, 0xde, 0x78, 0x09, 0xae, 0xd5,
+	0xf5, 0x03, 0x55, 0x6c, 0xf1, 0xd0, 0xde, 0x2c, 0xc1, 0x56, 0xe6, 0x62, 0xaf, 0x61, 0x2a, 0xaf,
+	0x5f, 0x94, 0x9c, 0xa4, 0xda, 0x52, 0xb0, 0xb4, 0x35, 0x17, 0x3a, 0x07, 0xc0, 0x2c, 0xc5, 0xe6,
+	0xbe, 0x91, 0xfd, 0x03, 0x99, 0xd4, 0xf1, 0x0d, 0xc2, 0xe0, 0xbe, 0xee, 0xc8, 0x9d, 0x4e, 0xd5,
+	0x0b, 0x8c, 0x1f, 0xc9, 0xfc, 0xfe, 0xb0, 0xba, 0xf0, 0x0e, 0xda, 0xe2, 0x1d, 0x56, 0xe5, 0x6c,
+	0xf1, 0x17, 0xb1, 0x05, 0x59, 0x8f, 0x5e, 0x87, 0x31, 0xe5, 0x54, 0xa2, 0xa1, 0x28, 0xe8, 0x49,
+	0x0f, 0x6f, 0x7f, 0x06, 0x00, 0xf4, 0xf6, 0x6b, 0xfa, 0x9a, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// DebugApiClient is the client API for DebugApi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DebugApiClient interface {
+	GetStateHash(ctx context.Context, in *StateHashRequest, opts ...grpc.CallOption) (*StateHashResponse, error)
+}
+
+type debugApiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDebugApiClient(cc *grpc.ClientConn) DebugApiClient {
+	return &debugApiClient{cc}
+}
+
+func (c *debugApiClient) GetStateHash(ctx context.Context, in *StateHashRequest, opts ...grpc.CallOption) (*StateHashResponse, error) {
+	out := new(StateHashResponse)
+	err := c.cc.Invoke(ctx, "/waves.node.grpc.DebugApi/GetStateHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DebugApiServer is the server API for DebugApi service.
+type DebugApiServer interface {
+	GetStateHash(context.Context, *StateHashRequest) (*StateHashResponse, error)
+}
+
+// UnimplementedDebugApiServer can be embedded to have forward compatible implementations.
+type UnimplementedDebugApiServer struct {
+}
+
+func (*UnimplementedDebugApiServer) GetStateHash(ctx context.Context, req *StateHashRequest) (*StateHashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStateHash not implemented")
+}
+
+func RegisterDebugApiServer(s *grpc.Server, srv DebugApiServer) {
+	s.RegisterService(&_DebugApi_serviceDesc, srv)
+}
+
+func _DebugApi_GetStateHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StateHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DebugApiServer).GetStateHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/waves.node.grpc.DebugApi/GetStateHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DebugApiServer).GetStateHash(ctx, req.(*StateHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DebugApi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "waves.node.grpc.DebugApi",
+	HandlerType: (*DebugApiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStateHash",
+			Handler:    _DebugApi_GetStateHash_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "debug_api.proto",
+}
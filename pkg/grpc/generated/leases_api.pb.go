@@ -0,0 +1,503 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: leases_api.proto
+
+package generated
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type LeasesRequest_Direction int32
+
+const (
+	LeasesRequest_OUTGOING LeasesRequest_Direction = 0
+	LeasesRequest_INCOMING LeasesRequest_Direction = 1
+)
+
+var LeasesRequest_Direction_name = map[int32]string{
+	0: "OUTGOING",
+	1: "INCOMING",
+}
+
+var LeasesRequest_Direction_value = map[string]int32{
+	"OUTGOING": 0,
+	"INCOMING": 1,
+}
+
+func (x LeasesRequest_Direction) String() string {
+	return proto.EnumName(LeasesRequest_Direction_name, int32(x))
+}
+
+func (LeasesRequest_Direction) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{1, 0}
+}
+
+type LeasesRequest_StatusFilter int32
+
+const (
+	LeasesRequest_ANY      LeasesRequest_StatusFilter = 0
+	LeasesRequest_ACTIVE   LeasesRequest_StatusFilter = 1
+	LeasesRequest_CANCELED LeasesRequest_StatusFilter = 2
+)
+
+var LeasesRequest_StatusFilter_name = map[int32]string{
+	0: "ANY",
+	1: "ACTIVE",
+	2: "CANCELED",
+}
+
+var LeasesRequest_StatusFilter_value = map[string]int32{
+	"ANY":      0,
+	"ACTIVE":   1,
+	"CANCELED": 2,
+}
+
+func (x LeasesRequest_StatusFilter) String() string {
+	return proto.EnumName(LeasesRequest_StatusFilter_name, int32(x))
+}
+
+func (LeasesRequest_StatusFilter) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{1, 1}
+}
+
+type LeaseInfo_Status int32
+
+const (
+	LeaseInfo_ACTIVE   LeaseInfo_Status = 0
+	LeaseInfo_CANCELED LeaseInfo_Status = 1
+)
+
+var LeaseInfo_Status_name = map[int32]string{
+	0: "ACTIVE",
+	1: "CANCELED",
+}
+
+var LeaseInfo_Status_value = map[string]int32{
+	"ACTIVE":   0,
+	"CANCELED": 1,
+}
+
+func (x LeaseInfo_Status) String() string {
+	return proto.EnumName(LeaseInfo_Status_name, int32(x))
+}
+
+func (LeaseInfo_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{2, 0}
+}
+
+type LeaseRequest struct {
+	LeaseId              []byte   `protobuf:"bytes,1,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseRequest) Reset()         { *m = LeaseRequest{} }
+func (m *LeaseRequest) String() string { return proto.CompactTextString(m) }
+func (*LeaseRequest) ProtoMessage()    {}
+func (*LeaseRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{0}
+}
+
+func (m *LeaseRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseRequest.Unmarshal(m, b)
+}
+func (m *LeaseRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseRequest.Marshal(b, m, deterministic)
+}
+func (m *LeaseRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseRequest.Merge(m, src)
+}
+func (m *LeaseRequest) XXX_Size() int {
+	return xxx_messageInfo_LeaseRequest.Size(m)
+}
+func (m *LeaseRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseRequest proto.InternalMessageInfo
+
+func (m *LeaseRequest) GetLeaseId() []byte {
+	if m != nil {
+		return m.LeaseId
+	}
+	return nil
+}
+
+type LeasesRequest struct {
+	Address   []byte                     `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Direction LeasesRequest_Direction    `protobuf:"varint,2,opt,name=direction,proto3,enum=waves.node.grpc.LeasesRequest_Direction" json:"direction,omitempty"`
+	Status    LeasesRequest_StatusFilter `protobuf:"varint,3,opt,name=status,proto3,enum=waves.node.grpc.LeasesRequest_StatusFilter" json:"status,omitempty"`
+	// ID of the last lease of the previous page, empty for the first page.
+	After []byte `protobuf:"bytes,4,opt,name=after,proto3" json:"after,omitempty"`
+	// Maximal number of leases to send, zero for all the leases.
+	Limit                uint32   `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeasesRequest) Reset()         { *m = LeasesRequest{} }
+func (m *LeasesRequest) String() string { return proto.CompactTextString(m) }
+func (*LeasesRequest) ProtoMessage()    {}
+func (*LeasesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{1}
+}
+
+func (m *LeasesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeasesRequest.Unmarshal(m, b)
+}
+func (m *LeasesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeasesRequest.Marshal(b, m, deterministic)
+}
+func (m *LeasesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeasesRequest.Merge(m, src)
+}
+func (m *LeasesRequest) XXX_Size() int {
+	return xxx_messageInfo_LeasesRequest.Size(m)
+}
+func (m *LeasesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeasesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeasesRequest proto.InternalMessageInfo
+
+func (m *LeasesRequest) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *LeasesRequest) GetDirection() LeasesRequest_Direction {
+	if m != nil {
+		return m.Direction
+	}
+	return LeasesRequest_OUTGOING
+}
+
+func (m *LeasesRequest) GetStatus() LeasesRequest_StatusFilter {
+	if m != nil {
+		return m.Status
+	}
+	return LeasesRequest_ANY
+}
+
+func (m *LeasesRequest) GetAfter() []byte {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+func (m *LeasesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type LeaseInfo struct {
+	LeaseId   []byte           `protobuf:"bytes,1,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
+	Sender    []byte           `protobuf:"bytes,2,opt,name=sender,proto3" json:"sender,omitempty"`
+	Recipient []byte           `protobuf:"bytes,3,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Amount    int64            `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Status    LeaseInfo_Status `protobuf:"varint,5,opt,name=status,proto3,enum=waves.node.grpc.LeaseInfo_Status" json:"status,omitempty"`
+	Height    int32            `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	// Zero for active lease.
+	CancelHeight int32 `protobuf:"varint,7,opt,name=cancel_height,json=cancelHeight,proto3" json:"cancel_height,omitempty"`
+	// Empty if the lease was cancelled by the node itself.
+	CancelTransactionId  []byte   `protobuf:"bytes,8,opt,name=cancel_transaction_id,json=cancelTransactionId,proto3" json:"cancel_transaction_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *LeaseInfo) Reset()         { *m = LeaseInfo{} }
+func (m *LeaseInfo) String() string { return proto.CompactTextString(m) }
+func (*LeaseInfo) ProtoMessage()    {}
+func (*LeaseInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_48be0d5b028b4d0e, []int{2}
+}
+
+func (m *LeaseInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LeaseInfo.Unmarshal(m, b)
+}
+func (m *LeaseInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LeaseInfo.Marshal(b, m, deterministic)
+}
+func (m *LeaseInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LeaseInfo.Merge(m, src)
+}
+func (m *LeaseInfo) XXX_Size() int {
+	return xxx_messageInfo_LeaseInfo.Size(m)
+}
+func (m *LeaseInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_LeaseInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LeaseInfo proto.InternalMessageInfo
+
+func (m *LeaseInfo) GetLeaseId() []byte {
+	if m != nil {
+		return m.LeaseId
+	}
+	return nil
+}
+
+func (m *LeaseInfo) GetSender() []byte {
+	if m != nil {
+		return m.Sender
+	}
+	return nil
+}
+
+func (m *LeaseInfo) GetRecipient() []byte {
+	if m != nil {
+		return m.Recipient
+	}
+	return nil
+}
+
+func (m *LeaseInfo) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *LeaseInfo) GetStatus() LeaseInfo_Status {
+	if m != nil {
+		return m.Status
+	}
+	return LeaseInfo_ACTIVE
+}
+
+func (m *LeaseInfo) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *LeaseInfo) GetCancelHeight() int32 {
+	if m != nil {
+		return m.CancelHeight
+	}
+	return 0
+}
+
+func (m *LeaseInfo) GetCancelTransactionId() []byte {
+	if m != nil {
+		return m.CancelTransactionId
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("waves.node.grpc.LeasesRequest_Direction", LeasesRequest_Direction_name, LeasesRequest_Direction_value)
+	proto.RegisterEnum("waves.node.grpc.LeasesRequest_StatusFilter", LeasesRequest_StatusFilter_name, LeasesRequest_StatusFilter_value)
+	proto.RegisterEnum("waves.node.grpc.LeaseInfo_Status", LeaseInfo_Status_name, LeaseInfo_Status_value)
+	proto.RegisterType((*LeaseRequest)(nil), "waves.node.grpc.LeaseRequest")
+	proto.RegisterType((*LeasesRequest)(nil), "waves.node.grpc.LeasesRequest")
+	proto.RegisterType((*LeaseInfo)(nil), "waves.node.grpc.LeaseInfo")
+}
+
+func init() { proto.RegisterFile("leases_api.proto", fileDescriptor_48be0d5b028b4d0e) }
+
+var fileDescriptor_48be0d5b028b4d0e = []byte{
+	// 468 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x93, 0xc1, 0x6e, 0xd3, 0x4e,
+	0x10, 0xc6, 0xb3, 0xc9, 0x3f, 0x4e, 0x3c, 0x7f, 0x07, 0xac, 0x05, 0x2a, 0x53, 0x01, 0x0a, 0xe6,
+	0x80, 0x11, 0x92, 0x05, 0xe1, 0xc4, 0x31, 0xb8, 0x69, 0xb0, 0x54, 0x12, 0xc9, 0x04, 0x24, 0xb8,
+	0x44, 0x8b, 0x3d, 0x4d, 0x57, 0x4a, 0xd7, 0x66, 0x77, 0x03, 0xcf, 0xc3, 0x8b, 0x70, 0xe2, 0xc1,
+	0x90, 0xd7, 0x9b, 0xa4, 0x45, 0x25, 0x1c, 0xbf, 0x99, 0x6f, 0xc6, 0xdf, 0xfc, 0xb4, 0x06, 0x7f,
+	0x8d, 0x4c, 0xa1, 0x5a, 0xb2, 0x8a, 0xc7, 0x95, 0x2c, 0x75, 0x49, 0x6f, 0x7f, 0x67, 0xdf, 0x50,
+	0xc5, 0xa2, 0x2c, 0x30, 0x5e, 0xc9, 0x2a, 0x0f, 0x9f, 0x81, 0x77, 0x56, 0x9b, 0x32, 0xfc, 0xba,
+	0x41, 0xa5, 0xe9, 0x7d, 0xe8, 0x9b, 0xa1, 0x25, 0x2f, 0x02, 0x32, 0x24, 0x91, 0x97, 0xf5, 0x8c,
+	0x4e, 0x8b, 0xf0, 0x67, 0x1b, 0x06, 0xc6, 0xab, 0xb6, 0xe6, 0x00, 0x7a, 0xac, 0x28, 0x24, 0x2a,
+	0xb5, 0xf5, 0x5a, 0x49, 0x4f, 0xc1, 0x2d, 0xb8, 0xc4, 0x5c, 0xf3, 0x52, 0x04, 0xed, 0x21, 0x89,
+	0x6e, 0x8d, 0xa2, 0xf8, 0x8f, 0x6f, 0xc7, 0xd7, 0x96, 0xc5, 0x27, 0x5b, 0x7f, 0xb6, 0x1f, 0xa5,
+	0x09, 0x38, 0x4a, 0x33, 0xbd, 0x51, 0x41, 0xc7, 0x2c, 0x79, 0xfe, 0x8f, 0x25, 0xef, 0x8d, 0xf9,
+	0x94, 0xaf, 0x35, 0xca, 0xcc, 0x8e, 0xd2, 0xbb, 0xd0, 0x65, 0xe7, 0x1a, 0x65, 0xf0, 0x9f, 0x09,
+	0xd9, 0x88, 0xba, 0xba, 0xe6, 0x97, 0x5c, 0x07, 0xdd, 0x21, 0x89, 0x06, 0x59, 0x23, 0xc2, 0xa7,
+	0xe0, 0xee, 0x82, 0x50, 0x0f, 0xfa, 0xf3, 0x0f, 0x8b, 0xe9, 0x3c, 0x9d, 0x4d, 0xfd, 0x56, 0xad,
+	0xd2, 0x59, 0x32, 0x7f, 0x57, 0x2b, 0x12, 0xbe, 0x04, 0xef, 0xea, 0xc7, 0x68, 0x0f, 0x3a, 0xe3,
+	0xd9, 0x27, 0xbf, 0x45, 0x01, 0x9c, 0x71, 0xb2, 0x48, 0x3f, 0x4e, 0x7c, 0x52, 0x8f, 0x24, 0xe3,
+	0x59, 0x32, 0x39, 0x9b, 0x9c, 0xf8, 0xed, 0xf0, 0x57, 0x1b, 0x5c, 0x13, 0x37, 0x15, 0xe7, 0xe5,
+	0x01, 0xd2, 0xf4, 0x08, 0x1c, 0x85, 0xa2, 0x40, 0x69, 0xd0, 0x79, 0x99, 0x55, 0xf4, 0x01, 0xb8,
+	0x12, 0x73, 0x5e, 0x71, 0x14, 0xda, 0x00, 0xf1, 0xb2, 0x7d, 0xa1, 0x9e, 0x62, 0x97, 0xe5, 0x46,
+	0x68, 0x73, 0x67, 0x27, 0xb3, 0x8a, 0xbe, 0xde, 0x31, 0xec, 0x1a, 0x86, 0x8f, 0x6f, 0x66, 0x58,
+	0x87, 0xb2, 0xfc, 0x76, 0xe4, 0x8e, 0xc0, 0xb9, 0x40, 0xbe, 0xba, 0xd0, 0x81, 0x33, 0x24, 0x51,
+	0x37, 0xb3, 0x8a, 0x3e, 0x81, 0x41, 0xce, 0x44, 0x8e, 0xeb, 0xa5, 0x6d, 0xf7, 0x4c, 0xdb, 0x6b,
+	0x8a, 0x6f, 0x1b, 0xd3, 0x08, 0xee, 0x59, 0x93, 0x96, 0x4c, 0x28, 0x66, 0x98, 0xd6, 0xd7, 0xf6,
+	0x4d, 0xf2, 0x3b, 0x4d, 0x73, 0xb1, 0xef, 0xa5, 0x45, 0x18, 0x82, 0xd3, 0x44, 0xb8, 0x82, 0xb1,
+	0x75, 0x0d, 0x23, 0x19, 0xfd, 0x20, 0x16, 0xa3, 0x1a, 0x57, 0x9c, 0xa6, 0xe0, 0x4d, 0x51, 0xef,
+	0xb1, 0x3e, 0xbc, 0xf9, 0x3a, 0xfb, 0x40, 0x8e, 0x8f, 0xff, 0x7e, 0x3c, 0x4d, 0xc1, 0xdd, 0xae,
+	0x52, 0xf4, 0xd1, 0xe1, 0x97, 0x76, 0x68, 0xd1, 0x0b, 0xf2, 0xe6, 0xff, 0xcf, 0xee, 0x0a, 0x05,
+	0x4a, 0xa6, 0xb1, 0xf8, 0xe2, 0x98, 0x7f, 0xef, 0xd5, 0xef, 0x01, 0x00, 0xa9, 0x5f, 0x98, 0x6a,
+	0x8f, 0x03, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// LeasesApiClient is the client API for LeasesApi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LeasesApiClient interface {
+	GetLeaseInfo(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseInfo, error)
+	// Streams the leases sent or received by the address, the newest first.
+	GetLeases(ctx context.Context, in *LeasesRequest, opts ...grpc.CallOption) (LeasesApi_GetLeasesClient, error)
+}
+
+type leasesApiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLeasesApiClient(cc *grpc.ClientConn) LeasesApiClient {
+	return &leasesApiClient{cc}
+}
+
+func (c *leasesApiClient) GetLeaseInfo(ctx context.Context, in *LeaseRequest, opts ...grpc.CallOption) (*LeaseInfo, error) {
+	out := new(LeaseInfo)
+	err := c.cc.Invoke(ctx, "/waves.node.grpc.LeasesApi/GetLeaseInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leasesApiClient) GetLeases(ctx context.Context, in *LeasesRequest, opts ...grpc.CallOption) (LeasesApi_GetLeasesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LeasesApi_serviceDesc.Streams[0], "/waves.node.grpc.LeasesApi/GetLeases", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &leasesApiGetLeasesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LeasesApi_GetLeasesClient interface {
+	Recv() (*LeaseInfo, error)
+	grpc.ClientStream
+}
+
+type leasesApiGetLeasesClient struct {
+	grpc.ClientStream
+}
+
+func (x *leasesApiGetLeasesClient) Recv() (*LeaseInfo, error) {
+	m := new(LeaseInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LeasesApiServer is the server API for LeasesApi service.
+type LeasesApiServer interface {
+	GetLeaseInfo(context.Context, *LeaseRequest) (*LeaseInfo, error)
+	// Streams the leases sent or received by the address, the newest first.
+	GetLeases(*LeasesRequest, LeasesApi_GetLeasesServer) error
+}
+
+// UnimplementedLeasesApiServer can be embedded to have forward compatible implementations.
+type UnimplementedLeasesApiServer struct {
+}
+
+func (*UnimplementedLeasesApiServer) GetLeaseInfo(ctx context.Context, req *LeaseRequest) (*LeaseInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLeaseInfo not implemented")
+}
+func (*UnimplementedLeasesApiServer) GetLeases(req *LeasesRequest, srv LeasesApi_GetLeasesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetLeases not implemented")
+}
+
+func RegisterLeasesApiServer(s *grpc.Server, srv LeasesApiServer) {
+	s.RegisterService(&_LeasesApi_serviceDesc, srv)
+}
+
+func _LeasesApi_GetLeaseInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeasesApiServer).GetLeaseInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/waves.node.grpc.LeasesApi/GetLeaseInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeasesApiServer).GetLeaseInfo(ctx, req.(*LeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LeasesApi_GetLeases_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LeasesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LeasesApiServer).GetLeases(m, &leasesApiGetLeasesServer{stream})
+}
+
+type LeasesApi_GetLeasesServer interface {
+	Send(*LeaseInfo) error
+	grpc.ServerStream
+}
+
+type leasesApiGetLeasesServer struct {
+	grpc.ServerStream
+}
+
+func (x *leasesApiGetLeasesServer) Send(m *LeaseInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _LeasesApi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "waves.node.grpc.LeasesApi",
+	HandlerType: (*LeasesApiServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLeaseInfo",
+			Handler:    _LeasesApi_GetLeaseInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetLeases",
+			Handler:       _LeasesApi_GetLeases_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "leases_api.proto",
+}
@@ -0,0 +1,353 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: transaction_status_api.proto
+
+package generated
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type TrackedStatus_Status int32
+
+const (
+	TrackedStatus_NOT_FOUND     TrackedStatus_Status = 0
+	TrackedStatus_UNCONFIRMED   TrackedStatus_Status = 1
+	TrackedStatus_EVICTED       TrackedStatus_Status = 2
+	TrackedStatus_IN_MICROBLOCK TrackedStatus_Status = 3
+	TrackedStatus_CONFIRMED     TrackedStatus_Status = 4
+	TrackedStatus_ROLLED_BACK   TrackedStatus_Status = 5
+)
+
+var TrackedStatus_Status_name = map[int32]string{
+	0: "NOT_FOUND",
+	1: "UNCONFIRMED",
+	2: "EVICTED",
+	3: "IN_MICROBLOCK",
+	4: "CONFIRMED",
+	5: "ROLLED_BACK",
+}
+
+var TrackedStatus_Status_value = map[string]int32{
+	"NOT_FOUND":     0,
+	"UNCONFIRMED":   1,
+	"EVICTED":       2,
+	"IN_MICROBLOCK": 3,
+	"CONFIRMED":     4,
+	"ROLLED_BACK":   5,
+}
+
+func (x TrackedStatus_Status) String() string {
+	return proto.EnumName(TrackedStatus_Status_name, int32(x))
+}
+
+func (TrackedStatus_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_61ae55f70f93d023, []int{0, 0}
+}
+
+type TrackedStatus struct {
+	Id     []byte               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status TrackedStatus_Status `protobuf:"varint,2,opt,name=status,proto3,enum=waves.node.grpc.TrackedStatus_Status" json:"status,omitempty"`
+	// Height of the block, for ROLLED_BACK status the height of the block that was rolled back.
+	Height        int64 `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Confirmations int64 `protobuf:"varint,4,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	// ID of the microblock for IN_MICROBLOCK status.
+	Microblock []byte `protobuf:"bytes,5,opt,name=microblock,proto3" json:"microblock,omitempty"`
+	// Validation error for EVICTED status.
+	EvictionReason       string   `protobuf:"bytes,6,opt,name=eviction_reason,json=evictionReason,proto3" json:"eviction_reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *TrackedStatus) Reset()         { *m = TrackedStatus{} }
+func (m *TrackedStatus) String() string { return proto.CompactTextString(m) }
+func (*TrackedStatus) ProtoMessage()    {}
+func (*TrackedStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61ae55f70f93d023, []int{0}
+}
+
+func (m *TrackedStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TrackedStatus.Unmarshal(m, b)
+}
+func (m *TrackedStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TrackedStatus.Marshal(b, m, deterministic)
+}
+func (m *TrackedStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TrackedStatus.Merge(m, src)
+}
+func (m *TrackedStatus) XXX_Size() int {
+	return xxx_messageInfo_TrackedStatus.Size(m)
+}
+func (m *TrackedStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_TrackedStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TrackedStatus proto.InternalMessageInfo
+
+func (m *TrackedStatus) GetId() []byte {
+	if m != nil {
+		return m.Id
+	}
+	return nil
+}
+
+func (m *TrackedStatus) GetStatus() TrackedStatus_Status {
+	if m != nil {
+		return m.Status
+	}
+	return TrackedStatus_NOT_FOUND
+}
+
+func (m *TrackedStatus) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *TrackedStatus) GetConfirmations() int64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *TrackedStatus) GetMicroblock() []byte {
+	if m != nil {
+		return m.Microblock
+	}
+	return nil
+}
+
+func (m *TrackedStatus) GetEvictionReason() string {
+	if m != nil {
+		return m.EvictionReason
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("waves.node.grpc.TrackedStatus_Status", TrackedStatus_Status_name, TrackedStatus_Status_value)
+	proto.RegisterType((*TrackedStatus)(nil), "waves.node.grpc.TrackedStatus")
+}
+
+func init() { proto.RegisterFile("transaction_status_api.proto", fileDescriptor_61ae55f70f93d023) }
+
+var fileDescriptor_61ae55f70f93d023 = []byte{
+	// 379 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x92, 0x4f, 0x6f, 0xd3, 0x30,
+	0x18, 0xc6, 0x71, 0xba, 0x05, 0xf5, 0x2d, 0x69, 0x33, 0x0b, 0x4d, 0xd1, 0x84, 0xa6, 0x68, 0x02,
+	0x91, 0x53, 0x84, 0xc6, 0x99, 0xc3, 0xf2, 0x67, 0x28, 0x5a, 0x97, 0x48, 0x5e, 0xca, 0x81, 0x4b,
+	0x70, 0x12, 0xd3, 0x5a, 0xa5, 0x71, 0xb0, 0xdd, 0x22, 0x3e, 0x25, 0x67, 0xbe, 0x0d, 0x6a, 0x52,
+	0x68, 0x8b, 0x10, 0xb7, 0x9d, 0x2c, 0x3f, 0x7e, 0x9f, 0xe7, 0x7d, 0xfd, 0xb3, 0xe1, 0x85, 0x96,
+	0xb4, 0x51, 0xb4, 0xd2, 0x5c, 0x34, 0x85, 0xd2, 0x54, 0xaf, 0x55, 0x41, 0x5b, 0xee, 0xb7, 0x52,
+	0x68, 0x81, 0x27, 0xdf, 0xe8, 0x86, 0x29, 0xbf, 0x11, 0x35, 0xf3, 0xe7, 0xb2, 0xad, 0x2e, 0xce,
+	0x0f, 0xca, 0x0f, 0x0a, 0xaf, 0x7e, 0x18, 0x60, 0xe5, 0x92, 0x56, 0x4b, 0x56, 0x3f, 0x74, 0x21,
+	0x78, 0x0c, 0x06, 0xaf, 0x1d, 0xe4, 0x22, 0xef, 0x19, 0x31, 0x78, 0x8d, 0xdf, 0x81, 0xd9, 0xc7,
+	0x3b, 0x86, 0x8b, 0xbc, 0xf1, 0xf5, 0x2b, 0xff, 0xaf, 0x6c, 0xff, 0xc8, 0xef, 0xf7, 0x0b, 0xd9,
+	0x99, 0xf0, 0x39, 0x98, 0x0b, 0xc6, 0xe7, 0x0b, 0xed, 0x0c, 0x5c, 0xe4, 0x0d, 0xc8, 0x6e, 0x87,
+	0x5f, 0x82, 0x55, 0x89, 0xe6, 0x33, 0x97, 0x2b, 0xda, 0xcd, 0xe4, 0x9c, 0x74, 0xc7, 0xc7, 0x22,
+	0xbe, 0x04, 0x58, 0xf1, 0x4a, 0x8a, 0xf2, 0x8b, 0xa8, 0x96, 0xce, 0x69, 0x37, 0xd4, 0x81, 0x82,
+	0x5f, 0xc3, 0x84, 0x6d, 0x78, 0x0f, 0x41, 0x32, 0xaa, 0x44, 0xe3, 0x98, 0x2e, 0xf2, 0x86, 0x64,
+	0xfc, 0x5b, 0x26, 0x9d, 0x7a, 0xb5, 0x00, 0x73, 0x77, 0x3f, 0x0b, 0x86, 0x69, 0x96, 0x17, 0xb7,
+	0xd9, 0x2c, 0x8d, 0xec, 0x27, 0x78, 0x02, 0xa3, 0x59, 0x1a, 0x66, 0xe9, 0x6d, 0x42, 0xee, 0xe3,
+	0xc8, 0x46, 0x78, 0x04, 0x4f, 0xe3, 0x0f, 0x49, 0x98, 0xc7, 0x91, 0x6d, 0xe0, 0x33, 0xb0, 0x92,
+	0xb4, 0xb8, 0x4f, 0x42, 0x92, 0x05, 0xd3, 0x2c, 0xbc, 0xb3, 0x07, 0x5b, 0xff, 0xbe, 0xfc, 0x64,
+	0xeb, 0x27, 0xd9, 0x74, 0x1a, 0x47, 0x45, 0x70, 0x13, 0xde, 0xd9, 0xa7, 0xd7, 0x3f, 0x11, 0x3c,
+	0xcf, 0xf7, 0xb0, 0xfb, 0xae, 0x37, 0x2d, 0xc7, 0x9f, 0x00, 0xbf, 0x67, 0xfa, 0x08, 0x16, 0x53,
+	0xd8, 0xfb, 0x17, 0xce, 0x3f, 0x2f, 0x15, 0x7c, 0x4f, 0x6a, 0xc2, 0xbe, 0xae, 0x99, 0xd2, 0x17,
+	0x97, 0xff, 0x07, 0xff, 0x06, 0xe1, 0x02, 0xce, 0x1e, 0xd6, 0xa5, 0xaa, 0x24, 0x2f, 0xd9, 0x63,
+	0x34, 0x08, 0x46, 0x1f, 0x87, 0x73, 0xd6, 0x30, 0x49, 0x35, 0xab, 0x4b, 0xb3, 0xfb, 0x41, 0x6f,
+	0x7f, 0x0d, 0x00, 0x2f, 0x00, 0xf7, 0xf9, 0x8a, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TransactionStatusApiClient is the client API for TransactionStatusApi service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TransactionStatusApiClient interface {
+	// Streams the current statuses of transactions.
+	GetTrackedStatuses(ctx context.Context, in *TransactionsByIdRequest, opts ...grpc.CallOption) (TransactionStatusApi_GetTrackedStatusesClient, error)
+	// Streams the current statuses of transactions and then every change of them until the call is canceled.
+	SubscribeStatuses(ctx context.Context, in *TransactionsByIdRequest, opts ...grpc.CallOption) (TransactionStatusApi_SubscribeStatusesClient, error)
+}
+
+type transactionStatusApiClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTransactionStatusApiClient(cc *grpc.ClientConn) TransactionStatusApiClient {
+	return &transactionStatusApiClient{cc}
+}
+
+func (c *transactionStatusApiClient) GetTrackedStatuses(ctx context.Context, in *TransactionsByIdRequest, opts ...grpc.CallOption) (TransactionStatusApi_GetTrackedStatusesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TransactionStatusApi_serviceDesc.Streams[0], "/waves.node.grpc.TransactionStatusApi/GetTrackedStatuses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transactionStatusApiGetTrackedStatusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransactionStatusApi_GetTrackedStatusesClient interface {
+	Recv() (*TrackedStatus, error)
+	grpc.ClientStream
+}
+
+type transactionStatusApiGetTrackedStatusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *transactionStatusApiGetTrackedStatusesClient) Recv() (*TrackedStatus, error) {
+	m := new(TrackedStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *transactionStatusApiClient) SubscribeStatuses(ctx context.Context, in *TransactionsByIdRequest, opts ...grpc.CallOption) (TransactionStatusApi_SubscribeStatusesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_TransactionStatusApi_serviceDesc.Streams[1], "/waves.node.grpc.TransactionStatusApi/SubscribeStatuses", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transactionStatusApiSubscribeStatusesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransactionStatusApi_SubscribeStatusesClient interface {
+	Recv() (*TrackedStatus, error)
+	grpc.ClientStream
+}
+
+type transactionStatusApiSubscribeStatusesClient struct {
+	grpc.ClientStream
+}
+
+func (x *transactionStatusApiSubscribeStatusesClient) Recv() (*TrackedStatus, error) {
+	m := new(TrackedStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TransactionStatusApiServer is the server API for TransactionStatusApi service.
+type TransactionStatusApiServer interface {
+	// Streams the current statuses of transactions.
+	GetTrackedStatuses(*TransactionsByIdRequest, TransactionStatusApi_GetTrackedStatusesServer) error
+	// Streams the current statuses of transactions and then every change of them until the call is canceled.
+	SubscribeStatuses(*TransactionsByIdRequest, TransactionStatusApi_SubscribeStatusesServer) error
+}
+
+// UnimplementedTransactionStatusApiServer can be embedded to have forward compatible implementations.
+type UnimplementedTransactionStatusApiServer struct {
+}
+
+func (*UnimplementedTransactionStatusApiServer) GetTrackedStatuses(req *TransactionsByIdRequest, srv TransactionStatusApi_GetTrackedStatusesServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetTrackedStatuses not implemented")
+}
+func (*UnimplementedTransactionStatusApiServer) SubscribeStatuses(req *TransactionsByIdRequest, srv TransactionStatusApi_SubscribeStatusesServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeStatuses not implemented")
+}
+
+func RegisterTransactionStatusApiServer(s *grpc.Server, srv TransactionStatusApiServer) {
+	s.RegisterService(&_TransactionStatusApi_serviceDesc, srv)
+}
+
+func _TransactionStatusApi_GetTrackedStatuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransactionsByIdRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionStatusApiServer).GetTrackedStatuses(m, &transactionStatusApiGetTrackedStatusesServer{stream})
+}
+
+type TransactionStatusApi_GetTrackedStatusesServer interface {
+	Send(*TrackedStatus) error
+	grpc.ServerStream
+}
+
+type transactionStatusApiGetTrackedStatusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *transactionStatusApiGetTrackedStatusesServer) Send(m *TrackedStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TransactionStatusApi_SubscribeStatuses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransactionsByIdRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransactionStatusApiServer).SubscribeStatuses(m, &transactionStatusApiSubscribeStatusesServer{stream})
+}
+
+type TransactionStatusApi_SubscribeStatusesServer interface {
+	Send(*TrackedStatus) error
+	grpc.ServerStream
+}
+
+type transactionStatusApiSubscribeStatusesServer struct {
+	grpc.ServerStream
+}
+
+func (x *transactionStatusApiSubscribeStatusesServer) Send(m *TrackedStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _TransactionStatusApi_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "waves.node.grpc.TransactionStatusApi",
+	HandlerType: (*TransactionStatusApiServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetTrackedStatuses",
+			Handler:       _TransactionStatusApi_GetTrackedStatuses_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeStatuses",
+			Handler:       _TransactionStatusApi_SubscribeStatuses_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "transaction_status_api.proto",
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/golang/protobuf/ptypes/wrappers"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetAliases(req *g.AccountRequest, srv g.AliasesApi_GetAliasesServer) error {
+	var c proto.ProtobufConverter
+	addr, err := c.Address(s.scheme, req.Address)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	aliases, err := s.state.AliasesByAddr(addr)
+	if err != nil {
+		return status.Errorf(codes.Internal, err.Error())
+	}
+	for _, alias := range aliases {
+		if err := srv.Send(&wrappers.StringValue{Value: alias.Alias}); err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+func TestGetAliases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	addrBody, err := addr.Body()
+	require.NoError(t, err)
+	st := mock.NewMockStateInfo(ctrl)
+	st.EXPECT().BlockchainSettings().Return(settings.MainNetSettings, nil)
+	st.EXPECT().AliasesByAddr(addr).Return([]proto.Alias{*proto.NewAlias('W', "first"), *proto.NewAlias('W', "second")}, nil)
+	err = server.initServer(st, nil, nil)
+	require.NoError(t, err)
+
+	conn := connect(t, grpcTestAddr)
+	defer conn.Close()
+
+	cl := g.NewAliasesApiClient(conn)
+	stream, err := cl.GetAliases(context.Background(), &g.AccountRequest{Address: addrBody})
+	require.NoError(t, err)
+	var names []string
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, res.Value)
+	}
+	assert.Equal(t, []string{"first", "second"}, names)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetCallables(ctx context.Context, req *g.AccountRequest) (*g.CallablesResponse, error) {
+	var c proto.ProtobufConverter
+	addr, err := c.Address(s.scheme, req.Address)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	scriptInfo, err := s.state.ScriptInfoByAccount(proto.NewRecipientFromAddress(addr))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, err.Error())
+	}
+	if len(scriptInfo.Bytes) == 0 {
+		return nil, status.Errorf(codes.NotFound, "address %s has no script", addr.String())
+	}
+	script, err := ast.BuildScript(reader.NewBytesReader(scriptInfo.Bytes))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	res := &g.CallablesResponse{
+		Address:  req.Address,
+		Version:  int32(script.Version),
+		Verifier: script.HasVerifier(),
+	}
+	if !script.IsDapp() {
+		return res, nil
+	}
+	callables, err := script.DApp.Callables()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	for _, cs := range callables {
+		args := make([]*g.CallableArgument, len(cs.Arguments))
+		for i, arg := range cs.Arguments {
+			args[i] = &g.CallableArgument{Name: arg.Name, Type: uint32(arg.Type)}
+		}
+		res.Callables = append(res.Callables, &g.CallableSignature{Name: cs.Name, Arguments: args})
+	}
+	return res, nil
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetCallables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	addrBody, err := addr.Body()
+	require.NoError(t, err)
+	dApp, err := base64.StdEncoding.DecodeString("AAIDAAAAAAAAAAAAAAABAQAAABFnZXRQcmV2aW91c0Fuc3dlcgAAAAEAAAAHYWRkcmVzcwUAAAAHYWRkcmVzcwAAAAEAAAABaQEAAAAGdGVsbG1lAAAAAQAAAAhxdWVzdGlvbgQAAAAGYW5zd2VyCQEAAAARZ2V0UHJldmlvdXNBbnN3ZXIAAAABBQAAAAhxdWVzdGlvbgkBAAAACFdyaXRlU2V0AAAAAQkABEwAAAACCQEAAAAJRGF0YUVudHJ5AAAAAgkAASwAAAACBQAAAAZhbnN3ZXICAAAAAl9xBQAAAAhxdWVzdGlvbgkABEwAAAACCQEAAAAJRGF0YUVudHJ5AAAAAgkAASwAAAACBQAAAAZhbnN3ZXICAAAAAl9hBQAAAAZhbnN3ZXIFAAAAA25pbAAAAAEAAAACdHgBAAAABnZlcmlmeQAAAAAJAAAAAAAAAgkBAAAAEWdldFByZXZpb3VzQW5zd2VyAAAAAQkABCUAAAABCAUAAAACdHgAAAAGc2VuZGVyAgAAAAEx7gicPQ==")
+	require.NoError(t, err)
+	st := mock.NewMockStateInfo(ctrl)
+	st.EXPECT().BlockchainSettings().Return(settings.MainNetSettings, nil)
+	st.EXPECT().ScriptInfoByAccount(proto.NewRecipientFromAddress(addr)).Return(&proto.ScriptInfo{Bytes: dApp}, nil)
+	st.EXPECT().ScriptInfoByAccount(proto.NewRecipientFromAddress(addr)).Return(&proto.ScriptInfo{}, nil)
+	err = server.initServer(st, nil, nil)
+	require.NoError(t, err)
+
+	conn := connect(t, grpcTestAddr)
+	defer conn.Close()
+
+	cl := g.NewDAppApiClient(conn)
+	res, err := cl.GetCallables(context.Background(), &g.AccountRequest{Address: addrBody})
+	require.NoError(t, err)
+	assert.Equal(t, addrBody, res.Address)
+	assert.Equal(t, int32(3), res.Version)
+	assert.True(t, res.Verifier)
+	require.Len(t, res.Callables, 1)
+	assert.Equal(t, "tellme", res.Callables[0].Name)
+	require.Len(t, res.Callables[0].Arguments, 1)
+	assert.Equal(t, "question", res.Callables[0].Arguments[0].Name)
+	assert.Equal(t, uint32(0), res.Callables[0].Arguments[0].Type)
+
+	_, err = cl.GetCallables(context.Background(), &g.AccountRequest{Address: addrBody})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
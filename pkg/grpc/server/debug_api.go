@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetStateHash(ctx context.Context, req *g.StateHashRequest) (*g.StateHashResponse, error) {
+	provides, err := s.state.ProvidesStateHashes()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	if !provides {
+		return nil, status.Errorf(codes.FailedPrecondition, "node does not build state hashes")
+	}
+	height, err := s.state.Height()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	if req.Height < 1 || uint64(req.Height) > height {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid height %d, current height is %d", req.Height, height)
+	}
+	sh, err := s.state.StateHashAtHeight(uint64(req.Height))
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, err.Error())
+	}
+	return &g.StateHashResponse{
+		BlockId:           sh.BlockID.Bytes(),
+		StateHash:         sh.SumHash.Bytes(),
+		WavesBalanceHash:  sh.WavesBalanceHash.Bytes(),
+		AssetBalanceHash:  sh.AssetBalanceHash.Bytes(),
+		DataEntryHash:     sh.DataEntryHash.Bytes(),
+		AccountScriptHash: sh.AccountScriptHash.Bytes(),
+		AssetScriptHash:   sh.AssetScriptHash.Bytes(),
+		LeaseBalanceHash:  sh.LeaseBalanceHash.Bytes(),
+		LeaseStatusHash:   sh.LeaseStatusHash.Bytes(),
+		SponsorshipHash:   sh.SponsorshipHash.Bytes(),
+		AliasHash:         sh.AliasesHash.Bytes(),
+	}, nil
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetStateHash(t *testing.T) {
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	require.NoError(t, err)
+	params := defaultStateParams()
+	params.BuildStateHashes = true
+	st, err := state.NewState(dataDir, params, settings.MainNetSettings)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	err = server.initServer(st, nil, nil)
+	require.NoError(t, err)
+
+	conn := connect(t, grpcTestAddr)
+	defer func() {
+		cancel()
+		conn.Close()
+		err = st.Close()
+		assert.NoError(t, err)
+		err = os.RemoveAll(dataDir)
+		assert.NoError(t, err)
+	}()
+
+	blocks, err := state.ReadMainnetBlocksToHeight(proto.Height(3))
+	require.NoError(t, err)
+	err = st.AddOldDeserializedBlocks(blocks)
+	require.NoError(t, err)
+
+	cl := g.NewDebugApiClient(conn)
+	res, err := cl.GetStateHash(ctx, &g.StateHashRequest{Height: 3})
+	require.NoError(t, err)
+	sh, err := st.StateHashAtHeight(3)
+	require.NoError(t, err)
+	assert.Equal(t, sh.BlockID.Bytes(), res.BlockId)
+	assert.Equal(t, sh.SumHash.Bytes(), res.StateHash)
+	assert.Equal(t, sh.WavesBalanceHash.Bytes(), res.WavesBalanceHash)
+	assert.Equal(t, sh.AliasesHash.Bytes(), res.AliasHash)
+
+	_, err = cl.GetStateHash(ctx, &g.StateHashRequest{Height: 4})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
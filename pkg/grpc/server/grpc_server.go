@@ -9,16 +9,18 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/services"
 	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
 	"github.com/wavesplatform/gowaves/pkg/types"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
 
 type Server struct {
-	state  state.StateInfo
-	scheme proto.Scheme
-	utx    types.UtxPool
-	wallet types.EmbeddedWallet
+	state     state.StateInfo
+	scheme    proto.Scheme
+	utx       types.UtxPool
+	wallet    types.EmbeddedWallet
+	txTracker *txstatus.Tracker
 }
 
 func NewServer(services services.Services) (*Server, error) {
@@ -38,16 +40,22 @@ func (s *Server) initServer(state state.StateInfo, utx types.UtxPool, sch types.
 	s.scheme = settings.AddressSchemeCharacter
 	s.utx = utx
 	s.wallet = sch
+	s.txTracker = nil
 	return nil
 }
 
 func (s *Server) Run(ctx context.Context, address string) error {
 	grpcServer := grpc.NewServer()
 	g.RegisterAccountsApiServer(grpcServer, s)
+	g.RegisterAliasesApiServer(grpcServer, s)
 	g.RegisterAssetsApiServer(grpcServer, s)
 	g.RegisterBlockchainApiServer(grpcServer, s)
 	g.RegisterBlocksApiServer(grpcServer, s)
+	g.RegisterDAppApiServer(grpcServer, s)
+	g.RegisterDebugApiServer(grpcServer, s)
+	g.RegisterLeasesApiServer(grpcServer, s)
 	g.RegisterTransactionsApiServer(grpcServer, s)
+	g.RegisterTransactionStatusApiServer(grpcServer, s)
 
 	go func() {
 		<-ctx.Done()
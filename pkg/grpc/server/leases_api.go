@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func leaseInfoToProtobuf(l *proto.LeaseInfo) (*g.LeaseInfo, error) {
+	sender, err := l.Sender.Body()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get sender's address body")
+	}
+	recipient, err := l.Recipient.Body()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get recipient's address body")
+	}
+	res := &g.LeaseInfo{
+		LeaseId:      l.ID.Bytes(),
+		Sender:       sender,
+		Recipient:    recipient,
+		Amount:       int64(l.Amount),
+		Status:       g.LeaseInfo_ACTIVE,
+		Height:       int32(l.Height),
+		CancelHeight: int32(l.CancelHeight),
+	}
+	if l.Status == proto.LeaseCanceled {
+		res.Status = g.LeaseInfo_CANCELED
+	}
+	if l.CancelTransactionID != nil {
+		res.CancelTransactionId = l.CancelTransactionID.Bytes()
+	}
+	return res, nil
+}
+
+func (s *Server) GetLeaseInfo(ctx context.Context, req *g.LeaseRequest) (*g.LeaseInfo, error) {
+	id, err := crypto.NewDigestFromBytes(req.LeaseId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	l, err := s.state.LeaseInfo(id)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, err.Error())
+	}
+	res, err := leaseInfoToProtobuf(l)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, err.Error())
+	}
+	return res, nil
+}
+
+func leaseStatusFromFilter(filter g.LeasesRequest_StatusFilter) proto.LeaseStatus {
+	switch filter {
+	case g.LeasesRequest_ACTIVE:
+		return proto.LeaseActive
+	case g.LeasesRequest_CANCELED:
+		return proto.LeaseCanceled
+	default:
+		return ""
+	}
+}
+
+func (s *Server) GetLeases(req *g.LeasesRequest, srv g.LeasesApi_GetLeasesServer) error {
+	var c proto.ProtobufConverter
+	addr, err := c.Address(s.scheme, req.Address)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, err.Error())
+	}
+	var after *crypto.Digest
+	if len(req.After) != 0 {
+		id, err := crypto.NewDigestFromBytes(req.After)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, err.Error())
+		}
+		after = &id
+	}
+	incoming := req.Direction == g.LeasesRequest_INCOMING
+	leases, err := s.state.LeasesByAddr(addr, incoming, leaseStatusFromFilter(req.Status), after, int(req.Limit))
+	if err != nil {
+		if state.IsNotFound(err) {
+			return status.Errorf(codes.NotFound, err.Error())
+		}
+		return status.Errorf(codes.Internal, err.Error())
+	}
+	for i := range leases {
+		res, err := leaseInfoToProtobuf(&leases[i])
+		if err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+		if err := srv.Send(res); err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetLeases(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	addr, err := proto.NewAddressFromString("3P8pGyzZL9AUuFs9YRYPDV3vm73T48ptZxs")
+	require.NoError(t, err)
+	addrBody, err := addr.Body()
+	require.NoError(t, err)
+	other, err := proto.NewAddressFromString("3PDdGex1meSUf4Yq5bjPBpyAbx6us9PaLfo")
+	require.NoError(t, err)
+	otherBody, err := other.Body()
+	require.NoError(t, err)
+	ids := []crypto.Digest{
+		crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd"),
+		crypto.MustDigestFromBase58("F2fdfc2kxgV9ugBeuuYFAgHYnXtc6uvKnQdc5eRXcrMv"),
+		crypto.MustDigestFromBase58("CqgrRX6PwhsPWEU1oFXW4XNjkBfbMU5W8n2QX8NhHxoD"),
+	}
+	leases := []proto.LeaseInfo{
+		{ID: ids[0], Sender: addr, Recipient: other, Amount: 10, Status: proto.LeaseActive, Height: 3},
+		{ID: ids[1], Sender: other, Recipient: addr, Amount: 20, Status: proto.LeaseActive, Height: 2},
+		{ID: ids[2], Sender: addr, Recipient: other, Amount: 30, Status: proto.LeaseCanceled, Height: 1, CancelHeight: 2, CancelTransactionID: &ids[1]},
+	}
+	st := mock.NewMockStateInfo(ctrl)
+	st.EXPECT().BlockchainSettings().Return(settings.MainNetSettings, nil)
+	unknown := crypto.MustDigestFromBase58("2R9H5ARbKG7UGHgRXs5A8aV7fZQUvbiPfq8yWpGQPcd1")
+	st.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), nil, 0).Return([]proto.LeaseInfo{leases[0], leases[2]}, nil)
+	st.EXPECT().LeasesByAddr(addr, true, proto.LeaseStatus(""), nil, 0).Return([]proto.LeaseInfo{leases[1]}, nil)
+	st.EXPECT().LeasesByAddr(addr, false, proto.LeaseActive, nil, 0).Return([]proto.LeaseInfo{leases[0]}, nil)
+	st.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), &ids[0], 1).Return([]proto.LeaseInfo{leases[2]}, nil)
+	st.EXPECT().LeasesByAddr(addr, false, proto.LeaseStatus(""), &unknown, 0).Return(nil, state.NewStateError(state.NotFoundError, proto.ErrNotFound))
+	st.EXPECT().LeaseInfo(ids[2]).Return(&leases[2], nil)
+	err = server.initServer(st, nil, nil)
+	require.NoError(t, err)
+
+	conn := connect(t, grpcTestAddr)
+	defer conn.Close()
+
+	cl := g.NewLeasesApiClient(conn)
+	receive := func(req *g.LeasesRequest) ([]*g.LeaseInfo, error) {
+		stream, err := cl.GetLeases(context.Background(), req)
+		require.NoError(t, err)
+		var res []*g.LeaseInfo
+		for {
+			l, err := stream.Recv()
+			if err == io.EOF {
+				return res, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, l)
+		}
+	}
+	cancelled := &g.LeaseInfo{
+		LeaseId:             ids[2].Bytes(),
+		Sender:              addrBody,
+		Recipient:           otherBody,
+		Amount:              30,
+		Status:              g.LeaseInfo_CANCELED,
+		Height:              1,
+		CancelHeight:        2,
+		CancelTransactionId: ids[1].Bytes(),
+	}
+
+	res, err := receive(&g.LeasesRequest{Address: addrBody})
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	assert.Equal(t, ids[0].Bytes(), res[0].LeaseId)
+	assert.Equal(t, cancelled, res[1])
+
+	res, err = receive(&g.LeasesRequest{Address: addrBody, Direction: g.LeasesRequest_INCOMING})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, ids[1].Bytes(), res[0].LeaseId)
+	assert.Equal(t, g.LeaseInfo_ACTIVE, res[0].Status)
+
+	res, err = receive(&g.LeasesRequest{Address: addrBody, Status: g.LeasesRequest_ACTIVE})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, ids[0].Bytes(), res[0].LeaseId)
+
+	res, err = receive(&g.LeasesRequest{Address: addrBody, Limit: 1, After: ids[0].Bytes()})
+	require.NoError(t, err)
+	assert.Equal(t, []*g.LeaseInfo{cancelled}, res)
+
+	_, err = receive(&g.LeasesRequest{Address: addrBody, After: unknown.Bytes()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	info, err := cl.GetLeaseInfo(context.Background(), &g.LeaseRequest{LeaseId: ids[2].Bytes()})
+	require.NoError(t, err)
+	assert.Equal(t, cancelled, info)
+}
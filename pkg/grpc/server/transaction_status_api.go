@@ -0,0 +1,103 @@
+package server
+
+import (
+	"github.com/mr-tron/base58/base58"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var trackedStatuses = map[txstatus.Status]g.TrackedStatus_Status{
+	txstatus.NotFound:     g.TrackedStatus_NOT_FOUND,
+	txstatus.Unconfirmed:  g.TrackedStatus_UNCONFIRMED,
+	txstatus.Evicted:      g.TrackedStatus_EVICTED,
+	txstatus.InMicroblock: g.TrackedStatus_IN_MICROBLOCK,
+	txstatus.Confirmed:    g.TrackedStatus_CONFIRMED,
+	txstatus.RolledBack:   g.TrackedStatus_ROLLED_BACK,
+}
+
+// SetTransactionTracker enables the services that report the statuses of transactions tracked by the node.
+func (s *Server) SetTransactionTracker(t *txstatus.Tracker) {
+	s.txTracker = t
+}
+
+func (s *Server) checkTrackedIDs(ids [][]byte) error {
+	if s.txTracker == nil {
+		return status.Errorf(codes.Unavailable, "transaction statuses are not tracked")
+	}
+	if len(ids) == 0 {
+		return status.Errorf(codes.InvalidArgument, "no transaction IDs")
+	}
+	if len(ids) > txstatus.MaxIDs {
+		return status.Errorf(codes.InvalidArgument, "too many transaction IDs, at most %d allowed", txstatus.MaxIDs)
+	}
+	return nil
+}
+
+func trackedStatusToProtobuf(st txstatus.TransactionStatus) (*g.TrackedStatus, error) {
+	id, err := base58.Decode(st.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := &g.TrackedStatus{
+		Id:             id,
+		Status:         trackedStatuses[st.Status],
+		Height:         int64(st.Height),
+		Confirmations:  int64(st.Confirmations),
+		EvictionReason: st.Reason,
+	}
+	if st.Microblock != nil {
+		res.Microblock = st.Microblock.Bytes()
+	}
+	return res, nil
+}
+
+func (s *Server) GetTrackedStatuses(req *g.TransactionsByIdRequest, srv g.TransactionStatusApi_GetTrackedStatusesServer) error {
+	if err := s.checkTrackedIDs(req.TransactionIds); err != nil {
+		return err
+	}
+	statuses, err := s.txTracker.Statuses(req.TransactionIds)
+	if err != nil {
+		return status.Errorf(codes.Internal, err.Error())
+	}
+	for _, st := range statuses {
+		res, err := trackedStatusToProtobuf(st)
+		if err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+		if err := srv.Send(res); err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+func (s *Server) SubscribeStatuses(req *g.TransactionsByIdRequest, srv g.TransactionStatusApi_SubscribeStatusesServer) error {
+	if err := s.checkTrackedIDs(req.TransactionIds); err != nil {
+		return err
+	}
+	sub, err := s.txTracker.Subscribe(req.TransactionIds)
+	if err != nil {
+		return status.Errorf(codes.Internal, err.Error())
+	}
+	defer sub.Close()
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case st, ok := <-sub.C():
+			if !ok {
+				// The subscriber was too slow or the node is stopping.
+				return status.Errorf(codes.Aborted, "subscription is closed")
+			}
+			res, err := trackedStatusToProtobuf(st)
+			if err != nil {
+				return status.Errorf(codes.Internal, err.Error())
+			}
+			if err := srv.Send(res); err != nil {
+				return status.Errorf(codes.Internal, err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
+	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func signedTransfer(t *testing.T, seed string) *proto.TransferWithSig {
+	addr, err := proto.NewAddressFromString("3PAWwWa6GbwcJaFzwqXQN5KQm7H96Y7SHTQ")
+	require.NoError(t, err)
+	sk, pk, err := crypto.GenerateKeyPair([]byte(seed))
+	require.NoError(t, err)
+	waves := proto.OptionalAsset{Present: false}
+	tx := proto.NewUnsignedTransferWithSig(pk, waves, waves, 100, 1, 100, proto.NewRecipientFromAddress(addr), &proto.LegacyAttachment{})
+	err = tx.Sign(proto.MainNetScheme, sk)
+	require.NoError(t, err)
+	return tx
+}
+
+func TestGetTrackedStatuses(t *testing.T) {
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	assert.NoError(t, err)
+	params := defaultStateParams()
+	st, err := state.NewState(dataDir, params, settings.MainNetSettings)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	sch := createWallet(ctx, st, settings.MainNetSettings)
+	utx := utxpool.New(utxSize, utxpool.NoOpValidator{}, settings.MainNetSettings)
+	err = server.initServer(st, utx, sch)
+	assert.NoError(t, err)
+
+	conn := connect(t, grpcTestAddr)
+	defer func() {
+		cancel()
+		conn.Close()
+		err = st.Close()
+		assert.NoError(t, err)
+		err = os.RemoveAll(dataDir)
+		assert.NoError(t, err)
+	}()
+
+	// id0 is from Mainnet genesis block.
+	id0 := crypto.MustSignatureFromBase58("2DVtfgXjpMeFf2PQCqvwxAiaGbiDsxDjSdNQkc5JQ74eWxjWFYgwvqzC4dn7iB1AhuM32WxEiVi1SGijsBtYQwn8").Bytes()
+	unconfirmed := signedTransfer(t, "unconfirmed")
+	bts, err := unconfirmed.MarshalBinary()
+	require.NoError(t, err)
+	err = utx.AddWithBytes(unconfirmed, bts)
+	require.NoError(t, err)
+	id1, err := unconfirmed.GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+	evicted := signedTransfer(t, "evicted")
+	id2, err := evicted.GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+	id3 := []byte{3}
+	req := &g.TransactionsByIdRequest{TransactionIds: [][]byte{id0, id1, id2, id3}}
+
+	cl := g.NewTransactionStatusApiClient(conn)
+	stream, err := cl.GetTrackedStatuses(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	tracker := txstatus.NewTracker(st, utx, nil, proto.MainNetScheme)
+	tracker.TransactionEvicted(evicted, errors.New("insufficient balance"))
+	server.SetTransactionTracker(tracker)
+
+	stream, err = cl.GetTrackedStatuses(ctx, req)
+	require.NoError(t, err)
+	correctResults := []*g.TrackedStatus{
+		{Id: id0, Status: g.TrackedStatus_CONFIRMED, Height: 1},
+		{Id: id1, Status: g.TrackedStatus_UNCONFIRMED},
+		{Id: id2, Status: g.TrackedStatus_EVICTED, EvictionReason: "insufficient balance"},
+		{Id: id3, Status: g.TrackedStatus_NOT_FOUND},
+	}
+	for _, correctRes := range correctResults {
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, correctRes, res)
+	}
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	// TransactionsApi reports the statuses of the tracker in its own terms.
+	txStream, err := g.NewTransactionsApiClient(conn).GetStatuses(ctx, req)
+	require.NoError(t, err)
+	for _, correctRes := range []*g.TransactionStatus{
+		{Id: id0, Status: g.TransactionStatus_CONFIRMED, Height: 1},
+		{Id: id1, Status: g.TransactionStatus_UNCONFIRMED},
+		{Id: id2, Status: g.TransactionStatus_NOT_EXISTS},
+		{Id: id3, Status: g.TransactionStatus_NOT_EXISTS},
+	} {
+		res, err := txStream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, correctRes, res)
+	}
+	_, err = txStream.Recv()
+	assert.Equal(t, io.EOF, err)
+
+	stream, err = cl.GetTrackedStatuses(ctx, &g.TransactionsByIdRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSubscribeStatuses(t *testing.T) {
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	assert.NoError(t, err)
+	params := defaultStateParams()
+	st, err := state.NewState(dataDir, params, settings.MainNetSettings)
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	sch := createWallet(ctx, st, settings.MainNetSettings)
+	utx := utxpool.New(utxSize, utxpool.NoOpValidator{}, settings.MainNetSettings)
+	err = server.initServer(st, utx, sch)
+	assert.NoError(t, err)
+	server.SetTransactionTracker(txstatus.NewTracker(st, utx, nil, proto.MainNetScheme))
+
+	conn := connect(t, grpcTestAddr)
+	defer func() {
+		cancel()
+		conn.Close()
+		err = st.Close()
+		assert.NoError(t, err)
+		err = os.RemoveAll(dataDir)
+		assert.NoError(t, err)
+	}()
+
+	tx := signedTransfer(t, "unconfirmed")
+	bts, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	err = utx.AddWithBytes(tx, bts)
+	require.NoError(t, err)
+	id, err := tx.GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+
+	cl := g.NewTransactionStatusApiClient(conn)
+	subCtx, subCancel := context.WithCancel(ctx)
+	stream, err := cl.SubscribeStatuses(subCtx, &g.TransactionsByIdRequest{TransactionIds: [][]byte{id}})
+	require.NoError(t, err)
+	res, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, &g.TrackedStatus{Id: id, Status: g.TrackedStatus_UNCONFIRMED}, res)
+	subCancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	stream, err = cl.SubscribeStatuses(ctx, &g.TransactionsByIdRequest{TransactionIds: make([][]byte, txstatus.MaxIDs+1)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
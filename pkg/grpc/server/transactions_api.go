@@ -7,6 +7,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	g "github.com/wavesplatform/gowaves/pkg/grpc/generated"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/txstatus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -110,6 +111,9 @@ func (s *Server) GetStateChanges(req *g.TransactionsRequest, srv g.TransactionsA
 }
 
 func (s *Server) GetStatuses(req *g.TransactionsByIdRequest, srv g.TransactionsApi_GetStatusesServer) error {
+	if s.txTracker != nil {
+		return s.getStatusesFromTracker(req, srv)
+	}
 	for _, id := range req.TransactionIds {
 		res := &g.TransactionStatus{Id: id}
 		if _, err := s.state.TransactionByID(id); err == nil {
@@ -133,6 +137,34 @@ func (s *Server) GetStatuses(req *g.TransactionsByIdRequest, srv g.TransactionsA
 	return nil
 }
 
+// getStatusesFromTracker reports the statuses known to the tracker in terms of TransactionsApi,
+// transactions in microblocks are confirmed and evicted or rolled back transactions don't exist.
+func (s *Server) getStatusesFromTracker(req *g.TransactionsByIdRequest, srv g.TransactionsApi_GetStatusesServer) error {
+	if len(req.TransactionIds) > txstatus.MaxIDs {
+		return status.Errorf(codes.InvalidArgument, "too many transaction IDs, at most %d allowed", txstatus.MaxIDs)
+	}
+	statuses, err := s.txTracker.Statuses(req.TransactionIds)
+	if err != nil {
+		return status.Errorf(codes.Internal, err.Error())
+	}
+	for i, st := range statuses {
+		res := &g.TransactionStatus{Id: req.TransactionIds[i]}
+		switch st.Status {
+		case txstatus.Confirmed, txstatus.InMicroblock:
+			res.Status = g.TransactionStatus_CONFIRMED
+			res.Height = int64(st.Height)
+		case txstatus.Unconfirmed:
+			res.Status = g.TransactionStatus_UNCONFIRMED
+		default:
+			res.Status = g.TransactionStatus_NOT_EXISTS
+		}
+		if err := srv.Send(res); err != nil {
+			return status.Errorf(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
 type getUnconfirmedHandler struct {
 	srv g.TransactionsApi_GetUnconfirmedServer
 	s   *Server
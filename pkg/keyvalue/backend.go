@@ -0,0 +1,48 @@
+package keyvalue
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Backend is the name of key-value storage implementation.
+type Backend string
+
+const (
+	LevelDBBackend Backend = "leveldb"
+	MemoryBackend  Backend = "memory"
+)
+
+func Backends() []Backend {
+	return []Backend{LevelDBBackend, MemoryBackend}
+}
+
+func NewBackend(name string) (Backend, error) {
+	for _, b := range Backends() {
+		if string(b) == name {
+			return b, nil
+		}
+	}
+	return "", errors.Errorf("unknown key-value storage backend '%s'", name)
+}
+
+// Persistent tells if the data of backend survives reopening.
+func (b Backend) Persistent() bool {
+	return b != MemoryBackend
+}
+
+// NewIterableKeyVal opens the storage of the backend set in params.
+// LevelDB is used if no backend is set.
+func NewIterableKeyVal(path string, params KeyValParams) (IterableKeyVal, error) {
+	switch params.Backend {
+	case LevelDBBackend, "":
+		kv, err := NewKeyVal(path, params)
+		if err != nil {
+			return nil, err
+		}
+		return kv, nil
+	case MemoryBackend:
+		return NewMemKeyVal(), nil
+	default:
+		return nil, errors.Errorf("unknown key-value storage backend '%s'", params.Backend)
+	}
+}
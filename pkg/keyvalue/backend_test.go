@@ -0,0 +1,307 @@
+package keyvalue
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type backendOpener func(t *testing.T) (kv IterableKeyVal, reopen func() IterableKeyVal, cleanup func())
+
+func testBackendParams() KeyValParams {
+	return KeyValParams{
+		CacheParams:         CacheParams{cacheSize},
+		BloomFilterParams:   BloomFilterParams{n, falsePositiveProbability, NoOpStore{}},
+		WriteBuffer:         writeBuffer,
+		CompactionTableSize: sstableSize,
+		CompactionTotalSize: compactionTotalSize,
+	}
+}
+
+func openBackend(t *testing.T, backend Backend) (IterableKeyVal, func() IterableKeyVal, func()) {
+	dbDir, err := ioutil.TempDir(os.TempDir(), "dbDir")
+	require.NoError(t, err)
+	params := testBackendParams()
+	params.Backend = backend
+	kv, err := NewIterableKeyVal(dbDir, params)
+	require.NoError(t, err)
+	reopen := func() IterableKeyVal {
+		require.NoError(t, kv.Close())
+		kv, err = NewIterableKeyVal(dbDir, params)
+		require.NoError(t, err)
+		return kv
+	}
+	cleanup := func() {
+		assert.NoError(t, kv.Close())
+		assert.NoError(t, os.RemoveAll(dbDir))
+	}
+	return kv, reopen, cleanup
+}
+
+// backendTests is the conformance suite, every backend has to pass it.
+var backendTests = []struct {
+	name string
+	test func(t *testing.T, open backendOpener, persistent bool)
+}{
+	{"BasicOperations", testBasicOperations},
+	{"BatchOperations", testBatchOperations},
+	{"BatchAtomicity", testBatchAtomicity},
+	{"PrefixIteration", testPrefixIteration},
+	{"IteratorDirections", testIteratorDirections},
+	{"IteratorSnapshot", testIteratorSnapshot},
+	{"Persistence", testPersistence},
+}
+
+func TestBackends(t *testing.T) {
+	for _, backend := range Backends() {
+		b := backend
+		open := func(t *testing.T) (IterableKeyVal, func() IterableKeyVal, func()) {
+			return openBackend(t, b)
+		}
+		for _, tc := range backendTests {
+			t.Run(fmt.Sprintf("%s/%s", b, tc.name), func(t *testing.T) {
+				tc.test(t, open, b.Persistent())
+			})
+		}
+	}
+}
+
+func testBasicOperations(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	_, err := kv.Get([]byte("absent"))
+	assert.Equal(t, ErrNotFound, err)
+	has, err := kv.Has([]byte("absent"))
+	require.NoError(t, err)
+	assert.False(t, has)
+	require.NoError(t, kv.Delete([]byte("absent")))
+
+	key, val := []byte("key"), []byte("value")
+	require.NoError(t, kv.Put(key, val))
+	val[0] = 'V' // Storage must not depend on the caller's buffer.
+	received, err := kv.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), received)
+	require.NoError(t, kv.Put(key, []byte("other")))
+	received, err = kv.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other"), received)
+	require.NoError(t, kv.Put([]byte("empty"), nil))
+	has, err = kv.Has([]byte("empty"))
+	require.NoError(t, err)
+	assert.True(t, has)
+	require.NoError(t, kv.Delete(key))
+	_, err = kv.Get(key)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func testBatchOperations(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	require.NoError(t, kv.Put([]byte("k0"), []byte("v0")))
+	b, err := kv.NewBatch()
+	require.NoError(t, err)
+	b.Put([]byte("k1"), []byte("v1"))
+	b.Put([]byte("k2"), []byte("v2"))
+	b.Delete([]byte("k0"))
+	b.Delete([]byte("k2"))
+	b.Delete([]byte("absent"))
+	// Nothing is visible until flush.
+	has, err := kv.Has([]byte("k1"))
+	require.NoError(t, err)
+	assert.False(t, has)
+	require.NoError(t, kv.Flush(b))
+	for key, expected := range map[string]bool{"k0": false, "k1": true, "k2": false} {
+		has, err := kv.Has([]byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, expected, has, key)
+	}
+	// Flushed batch is empty and can be reused.
+	b.Put([]byte("k3"), []byte("v3"))
+	require.NoError(t, kv.Flush(b))
+	has, err = kv.Has([]byte("k3"))
+	require.NoError(t, err)
+	assert.True(t, has)
+	// The batch of unknown type is rejected.
+	assert.Error(t, kv.Flush(&foreignBatch{}))
+	// Reset batch flushes nothing.
+	b.Put([]byte("k4"), []byte("v4"))
+	b.Reset()
+	require.NoError(t, kv.Flush(b))
+	has, err = kv.Has([]byte("k4"))
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+type foreignBatch struct{}
+
+func (*foreignBatch) Delete([]byte)      {}
+func (*foreignBatch) Put([]byte, []byte) {}
+func (*foreignBatch) Reset()             {}
+
+// testBatchAtomicity checks that readers never see the batch applied partially.
+func testBatchAtomicity(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	const keysNum = 50
+	const rounds = 50
+	write := func(round int) {
+		b, err := kv.NewBatch()
+		require.NoError(t, err)
+		for i := 0; i < keysNum; i++ {
+			b.Put([]byte(fmt.Sprintf("key%03d", i)), []byte{byte(round)})
+		}
+		require.NoError(t, kv.Flush(b))
+	}
+	write(0)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			iter, err := kv.NewKeyIterator([]byte("key"))
+			if !assert.NoError(t, err) {
+				return
+			}
+			count := 0
+			var round byte
+			for iter.Next() {
+				if count == 0 {
+					round = iter.Value()[0]
+				}
+				assert.Equal(t, round, iter.Value()[0], "partially applied batch")
+				count++
+			}
+			iter.Release()
+			assert.NoError(t, iter.Error())
+			assert.Equal(t, keysNum, count)
+		}
+	}()
+	for r := 1; r <= rounds; r++ {
+		write(r)
+	}
+	close(done)
+	wg.Wait()
+}
+
+func testPrefixIteration(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	keys := []string{"a", "ab", "abc", "abd", "b", "ba", "\xff", "\xff\xff"}
+	b, err := kv.NewBatch()
+	require.NoError(t, err)
+	// Put in reverse order to check the ordering of iteration.
+	for i := len(keys) - 1; i >= 0; i-- {
+		b.Put([]byte(keys[i]), []byte("v"+keys[i]))
+	}
+	require.NoError(t, kv.Flush(b))
+
+	collect := func(prefix []byte) []string {
+		iter, err := kv.NewKeyIterator(prefix)
+		require.NoError(t, err)
+		defer iter.Release()
+		var res []string
+		for iter.Next() {
+			assert.Equal(t, "v"+string(iter.Key()), string(iter.Value()))
+			res = append(res, string(iter.Key()))
+		}
+		require.NoError(t, iter.Error())
+		return res
+	}
+	assert.Equal(t, keys, collect(nil))
+	assert.Equal(t, keys, collect([]byte{}))
+	assert.Equal(t, []string{"ab", "abc", "abd"}, collect([]byte("ab")))
+	assert.Equal(t, []string{"b", "ba"}, collect([]byte("b")))
+	assert.Equal(t, []string{"\xff", "\xff\xff"}, collect([]byte("\xff")))
+	assert.Empty(t, collect([]byte("c")))
+}
+
+func testIteratorDirections(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	for _, k := range []string{"p0", "p1", "p2", "q0"} {
+		require.NoError(t, kv.Put([]byte(k), []byte(k)))
+	}
+	iter, err := kv.NewKeyIterator([]byte("p"))
+	require.NoError(t, err)
+	defer iter.Release()
+
+	// Prev() on fresh iterator doesn't move.
+	assert.False(t, iter.Prev())
+	// Last() and backward iteration stays in the prefix range.
+	require.True(t, iter.Last())
+	assert.Equal(t, "p2", string(iter.Key()))
+	var backward []string
+	for ok := true; ok; ok = iter.Prev() {
+		backward = append(backward, string(iter.Key()))
+	}
+	assert.Equal(t, []string{"p2", "p1", "p0"}, backward)
+	// Next() after moving before the first element starts from the first one.
+	require.True(t, iter.Next())
+	assert.Equal(t, "p0", string(iter.Key()))
+	// Prev() after the forward iteration is exhausted moves to the last element.
+	for iter.Next() {
+	}
+	require.True(t, iter.Prev())
+	assert.Equal(t, "p2", string(iter.Key()))
+	require.True(t, iter.First())
+	assert.Equal(t, "p0", string(iter.Key()))
+	assert.False(t, iter.Prev())
+	require.NoError(t, iter.Error())
+
+	empty, err := kv.NewKeyIterator([]byte("z"))
+	require.NoError(t, err)
+	defer empty.Release()
+	assert.False(t, empty.Last())
+	assert.False(t, empty.First())
+	assert.False(t, empty.Next())
+	assert.False(t, empty.Prev())
+}
+
+func testIteratorSnapshot(t *testing.T, open backendOpener, _ bool) {
+	kv, _, cleanup := open(t)
+	defer cleanup()
+
+	require.NoError(t, kv.Put([]byte("k0"), []byte("old")))
+	iter, err := kv.NewKeyIterator(nil)
+	require.NoError(t, err)
+	defer iter.Release()
+	require.NoError(t, kv.Put([]byte("k0"), []byte("new")))
+	require.NoError(t, kv.Put([]byte("k1"), []byte("new")))
+	require.True(t, iter.Next())
+	assert.Equal(t, "old", string(iter.Value()))
+	assert.False(t, iter.Next())
+}
+
+func testPersistence(t *testing.T, open backendOpener, persistent bool) {
+	kv, reopen, cleanup := open(t)
+	defer cleanup()
+
+	b, err := kv.NewBatch()
+	require.NoError(t, err)
+	b.Put([]byte("k0"), []byte("v0"))
+	require.NoError(t, kv.Flush(b))
+	require.NoError(t, kv.Put([]byte("k1"), []byte("v1")))
+	kv = reopen()
+	for _, k := range []string{"k0", "k1"} {
+		has, err := kv.Has([]byte(k))
+		require.NoError(t, err)
+		assert.Equal(t, persistent, has)
+	}
+}
@@ -30,6 +30,8 @@ type Iterator interface {
 
 	First() bool
 	Last() bool
+	// Seek moves the iterator to the first key that is greater or equal to the given key.
+	Seek(key []byte) bool
 
 	Error() error
 	Release()
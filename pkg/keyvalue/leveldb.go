@@ -130,6 +130,8 @@ func initBloomFilter(kv *KeyVal, params BloomFilterParams) error {
 }
 
 type KeyValParams struct {
+	// Backend selects the storage implementation for NewIterableKeyVal(), the parameters below are LevelDB's.
+	Backend Backend
 	CacheParams
 	BloomFilterParams
 	WriteBuffer         int
@@ -0,0 +1,120 @@
+package keyvalue
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb/comparer"
+	"github.com/syndtr/goleveldb/leveldb/memdb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// MemKeyVal is the in-memory key-value storage.
+// It keeps nothing on disk, so the data is lost on Close().
+// Memory occupied by overwritten and deleted values is not reclaimed until Close(),
+// so it suits tests and short-living networks rather than the long running nodes.
+type MemKeyVal struct {
+	db *memdb.DB
+	mu *sync.RWMutex
+}
+
+func NewMemKeyVal() *MemKeyVal {
+	return &MemKeyVal{db: memdb.New(comparer.DefaultComparer, 0), mu: &sync.RWMutex{}}
+}
+
+func (k *MemKeyVal) NewBatch() (Batch, error) {
+	return &batch{mu: &sync.Mutex{}}, nil
+}
+
+func (k *MemKeyVal) Get(key []byte) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	val, err := k.db.Get(key)
+	if err == memdb.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, len(val))
+	copy(res, val)
+	return res, nil
+}
+
+func (k *MemKeyVal) Has(key []byte) (bool, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.db.Contains(key), nil
+}
+
+func (k *MemKeyVal) Delete(key []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	// Deletion of absent key is not an error, like in LevelDB.
+	if err := k.db.Delete(key); err != nil && err != memdb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func (k *MemKeyVal) Put(key, val []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.db.Put(key, val)
+}
+
+func (k *MemKeyVal) Flush(b1 Batch) error {
+	b, ok := b1.(*batch)
+	if !ok {
+		return errors.New("can't convert batch interface to memory batch")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	b.mu.Lock()
+	for _, pair := range b.pairs {
+		if pair.deletion {
+			if err := k.db.Delete(pair.key); err != nil && err != memdb.ErrNotFound {
+				b.mu.Unlock()
+				return err
+			}
+			continue
+		}
+		if err := k.db.Put(pair.key, pair.value); err != nil {
+			b.mu.Unlock()
+			return err
+		}
+	}
+	b.pairs = nil
+	b.mu.Unlock()
+	return nil
+}
+
+// NewKeyIterator returns the iterator over the snapshot of keys with given prefix,
+// so the changes made after the creation of iterator are not visible to it, like in LevelDB.
+func (k *MemKeyVal) NewKeyIterator(prefix []byte) (Iterator, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	snapshot := memdb.New(comparer.DefaultComparer, 0)
+	var r *util.Range
+	if prefix != nil {
+		r = util.BytesPrefix(prefix)
+	}
+	it := k.db.NewIterator(r)
+	defer it.Release()
+	for it.Next() {
+		if err := snapshot.Put(it.Key(), it.Value()); err != nil {
+			return nil, err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return snapshot.NewIterator(nil), nil
+}
+
+func (k *MemKeyVal) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.db.Reset()
+	return nil
+}
@@ -0,0 +1,52 @@
+package ntptime
+
+import (
+	"sync"
+	"time"
+)
+
+// DevTime is a controllable time for tests and development networks.
+// It follows the local clock shifted by offset, the offset only grows, so the time never goes back.
+type DevTime struct {
+	mu     sync.RWMutex
+	offset time.Duration
+}
+
+func NewDevTime() *DevTime {
+	return &DevTime{}
+}
+
+func (a *DevTime) Now() time.Time {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return time.Now().Add(a.offset)
+}
+
+// Advance moves the time forward by given duration and returns the new current time.
+// Negative durations are ignored.
+func (a *DevTime) Advance(d time.Duration) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d > 0 {
+		a.offset += d
+	}
+	return time.Now().Add(a.offset)
+}
+
+// AdvanceTo moves the time forward to the given moment if it's in the future, and returns the new current time.
+func (a *DevTime) AdvanceTo(t time.Time) time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now().Add(a.offset)
+	if t.After(now) {
+		a.offset += t.Sub(now)
+		return t
+	}
+	return now
+}
+
+func (a *DevTime) Offset() time.Duration {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.offset
+}
@@ -0,0 +1,34 @@
+package ntptime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevTime(t *testing.T) {
+	tm := NewDevTime()
+	start := time.Now()
+	assert.False(t, tm.Now().Before(start))
+	assert.Equal(t, time.Duration(0), tm.Offset())
+
+	now := tm.Advance(time.Hour)
+	assert.False(t, now.Before(start.Add(time.Hour)))
+	assert.False(t, tm.Now().Before(now))
+	assert.Equal(t, time.Hour, tm.Offset())
+
+	tm.Advance(-time.Minute)
+	assert.Equal(t, time.Hour, tm.Offset())
+
+	// Moving to the past does nothing.
+	now = tm.AdvanceTo(start)
+	assert.Equal(t, time.Hour, tm.Offset())
+	assert.True(t, now.After(start))
+
+	target := tm.Now().Add(24 * time.Hour)
+	now = tm.AdvanceTo(target)
+	assert.Equal(t, target, now)
+	assert.False(t, tm.Now().Before(target))
+	assert.True(t, tm.Offset() > 24*time.Hour+59*time.Minute)
+}
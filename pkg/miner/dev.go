@@ -0,0 +1,225 @@
+package miner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/consensus"
+	"github.com/wavesplatform/gowaves/pkg/libs/ntptime"
+	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
+	"github.com/wavesplatform/gowaves/pkg/ng"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/types"
+	"go.uber.org/zap"
+)
+
+type emitsProvider interface {
+	Reschedule()
+	Emits() []scheduler.Emit
+}
+
+// devKeyBlock is the last key block mined by DevMiner with the state of its microblocks.
+type devKeyBlock struct {
+	block   *proto.Block // The last total block
+	blocks  ng.Blocks
+	keyPair proto.KeyPair
+	rest    restLimits
+	fresh   bool // No microblocks were mined on top of the key block yet
+}
+
+// DevMiner produces blocks and microblocks on demand, it's used in development mode of custom blockchains to run
+// tests without waiting for blocks. Each block gets the earliest timestamp allowed by consensus for the best miner from
+// the wallet, and the controllable time of the node is moved forward to it, so the blocks pass consensus validation
+// without actual waiting. Scheduler should be created with scheduler.NewManualScheduler.
+type DevMiner struct {
+	miner     *MicroblockMiner
+	scheduler emitsProvider
+	tm        *ntptime.DevTime
+	mu        sync.Mutex
+	last      *devKeyBlock
+}
+
+func NewDevMiner(services services.Services, ngRuntime ng.Runtime, scheduler emitsProvider, tm *ntptime.DevTime, features Features, reward int64) *DevMiner {
+	return &DevMiner{
+		miner:     NewMicroblockMiner(services, ngRuntime, services.Scheme, features, reward),
+		scheduler: scheduler,
+		tm:        tm,
+	}
+}
+
+// MineBlock mines new key block on top of the last block.
+func (a *DevMiner) MineBlock() (*proto.Block, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.mineBlock()
+}
+
+// MineMicroblock puts transactions from UTX pool into new microblock on top of the last key block mined by DevMiner.
+func (a *DevMiner) MineMicroblock() (*proto.MicroBlock, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.last == nil {
+		return nil, errors.New("no key block to put microblock on, mine block first")
+	}
+	micro, err := a.mineMicroblock()
+	switch err {
+	case errKeyBlockChanged:
+		return nil, errors.New("last block was not mined by this node, mine block first")
+	case errNoTransactions:
+		return nil, errors.New("no valid transactions in UTX pool")
+	}
+	return micro, err
+}
+
+// Now returns current time of the node.
+func (a *DevMiner) Now() time.Time {
+	return a.tm.Now()
+}
+
+// AdvanceTime moves the time of the node forward and returns the new current time.
+func (a *DevMiner) AdvanceTime(d time.Duration) time.Time {
+	return a.tm.Advance(d)
+}
+
+// Run mines transactions from UTX pool as soon as they appear, checking the pool with the given interval.
+func (a *DevMiner) Run(ctx context.Context, interval time.Duration) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+			if a.miner.utx.Count() == 0 {
+				continue
+			}
+			if err := a.mineTransactions(); err != nil {
+				zap.S().Errorf("DevMiner: %v", err)
+			}
+		}
+	}
+}
+
+func (a *DevMiner) mineTransactions() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.last != nil {
+		_, err := a.mineMicroblock()
+		switch err {
+		case nil:
+			return nil
+		case errNoTransactions:
+			if a.last.fresh {
+				// Transactions don't fit even into empty block, they stay in UTX pool until cleaned up.
+				return nil
+			}
+		case errKeyBlockChanged:
+		default:
+			return err
+		}
+	}
+	// Microblocks limits are exhausted or there is no key block to put microblock on.
+	if _, err := a.mineBlock(); err != nil {
+		return err
+	}
+	_, err := a.mineMicroblock()
+	if err != nil && err != errNoTransactions {
+		return err
+	}
+	return nil
+}
+
+func (a *DevMiner) mineBlock() (*proto.Block, error) {
+	a.scheduler.Reschedule()
+	emits := a.scheduler.Emits()
+	if len(emits) == 0 {
+		return nil, errors.New("no miners, wallet is empty or generating balances are insufficient")
+	}
+	emit := emits[0]
+	for _, e := range emits[1:] {
+		if e.Timestamp < emit.Timestamp {
+			emit = e
+		}
+	}
+	ts := emit.Timestamp
+	baseTarget := emit.BaseTarget
+	if now := proto.NewTimestampFromTime(a.tm.Now()); now > ts {
+		// Time was moved forward beyond the earliest timestamp, the block is mined with current time.
+		bt, err := a.baseTarget(now)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to calculate base target")
+		}
+		ts = now
+		baseTarget = bt
+	}
+	a.tm.AdvanceTo(timestampToTime(ts))
+	b, err := a.miner.mineKeyBlock(ts, emit.KeyPair, emit.Parent, baseTarget, emit.GenSignature)
+	if err != nil {
+		return nil, err
+	}
+	a.last = &devKeyBlock{
+		block:   b,
+		blocks:  ng.NewBlocksFromBlock(b),
+		keyPair: emit.KeyPair,
+		rest:    a.miner.restLimits(),
+		fresh:   true,
+	}
+	a.scheduler.Reschedule()
+	return b, nil
+}
+
+func (a *DevMiner) mineMicroblock() (*proto.MicroBlock, error) {
+	micro, block, blocks, rest, err := a.miner.mineMicroblock(a.last.rest, a.last.block, a.last.blocks, a.last.keyPair)
+	if err != nil {
+		if err == errKeyBlockChanged {
+			a.last = nil
+		}
+		return nil, err
+	}
+	a.last.block = block
+	a.last.blocks = blocks
+	a.last.rest = rest
+	a.last.fresh = false
+	return micro, nil
+}
+
+// baseTarget calculates the base target of the block on top of the last block with given timestamp.
+func (a *DevMiner) baseTarget(timestamp proto.Timestamp) (types.BaseTarget, error) {
+	st := a.miner.state
+	defer st.Mutex().RLock().Unlock()
+	height, err := st.Height()
+	if err != nil {
+		return 0, err
+	}
+	parent, err := st.HeaderByHeight(height)
+	if err != nil {
+		return 0, err
+	}
+	var greatGrandParentTimestamp proto.Timestamp
+	if height > 2 {
+		greatGrandParent, err := st.HeaderByHeight(height - 2)
+		if err != nil {
+			return 0, err
+		}
+		greatGrandParentTimestamp = greatGrandParent.Timestamp
+	}
+	fair, err := st.IsActiveAtHeight(int16(settings.FairPoS), height)
+	if err != nil {
+		return 0, err
+	}
+	var pos consensus.PosCalculator = &consensus.NxtPosCalculator{}
+	if fair {
+		pos = &consensus.FairPosCalculator{}
+	}
+	s, err := st.BlockchainSettings()
+	if err != nil {
+		return 0, err
+	}
+	return pos.CalculateBaseTarget(s.AverageBlockDelaySeconds, height, parent.BaseTarget, parent.Timestamp, greatGrandParentTimestamp, timestamp)
+}
+
+func timestampToTime(ts proto.Timestamp) time.Time {
+	return time.Unix(0, int64(ts)*int64(time.Millisecond))
+}
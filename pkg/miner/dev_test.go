@@ -0,0 +1,159 @@
+package miner
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/consensus"
+	"github.com/wavesplatform/gowaves/pkg/libs/ntptime"
+	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
+	"github.com/wavesplatform/gowaves/pkg/miner/utxpool"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/state"
+	"github.com/wavesplatform/gowaves/pkg/util/genesis_generator"
+	"github.com/wavesplatform/gowaves/pkg/wallet"
+)
+
+type stubRuntime struct {
+	micros []*proto.MicroBlock
+}
+
+func (a *stubRuntime) MinedBlock(*proto.Block) {}
+
+func (a *stubRuntime) MinedMicroblock(block *proto.MicroBlock, inv *proto.MicroBlockInv) {
+	a.micros = append(a.micros, block)
+}
+
+// validatorState provides stable state to ConsensusValidator.
+type validatorState struct {
+	state.State
+}
+
+func (a validatorState) EffectiveBalance(addr proto.Recipient, startHeight, endHeight uint64) (uint64, error) {
+	return a.EffectiveBalanceStable(addr, startHeight, endHeight)
+}
+
+func TestDevMiner(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "dev_miner")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	scheme := proto.CustomNetScheme
+	minerKP := proto.MustKeyPair([]byte("dev-miner"))
+	userKP := proto.MustKeyPair([]byte("dev-user"))
+	userAddr, err := userKP.Addr(scheme)
+	require.NoError(t, err)
+	genesis, err := genesis_generator.Generate(proto.NewTimestampFromTime(time.Now()), scheme, minerKP, 100000000000000)
+	require.NoError(t, err)
+	sets := *settings.DefaultDevSettings
+	sets.Genesis = *genesis
+
+	tm := ntptime.NewDevTime()
+	params := state.DefaultTestingStateParams()
+	params.Time = tm
+	st, err := state.NewState(dir, params, &sets)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, st.Close())
+	}()
+
+	w := wallet.NewWallet()
+	require.NoError(t, w.AddSeed([]byte("dev-miner")))
+	sch := scheduler.NewManualScheduler(st, w, &sets, tm)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	peers := mock.NewMockPeerManager(ctrl)
+	peers.EXPECT().EachConnected(gomock.Any()).AnyTimes()
+	runtime := &stubRuntime{}
+	utx := utxpool.New(1000, utxpool.NoOpValidator{}, &sets)
+	srv := services.Services{
+		State:         st,
+		Peers:         peers,
+		Scheduler:     sch,
+		BlocksApplier: node.NewBlocksApplier(st, tm),
+		UtxPool:       utx,
+		Scheme:        scheme,
+		Time:          tm,
+	}
+	m := NewDevMiner(srv, runtime, sch, tm, nil, 0)
+
+	_, err = m.MineMicroblock()
+	assert.Error(t, err)
+
+	start := time.Now()
+	var headers []proto.BlockHeader
+	for i := 0; i < 5; i++ {
+		b, err := m.MineBlock()
+		require.NoError(t, err)
+		headers = append(headers, b.BlockHeader)
+	}
+	assert.True(t, time.Since(start) < 30*time.Second)
+	height, err := st.Height()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), height)
+	assert.True(t, tm.Offset() > 0)
+	for i := 1; i < len(headers); i++ {
+		assert.True(t, headers[i].Timestamp > headers[i-1].Timestamp)
+	}
+	cv, err := consensus.NewConsensusValidator(validatorState{st}, tm)
+	require.NoError(t, err)
+	require.NoError(t, cv.ValidateHeaders(headers, 1))
+
+	// Block after fast-forward gets the current time.
+	now := m.AdvanceTime(time.Hour)
+	b, err := m.MineBlock()
+	require.NoError(t, err)
+	assert.True(t, b.Timestamp >= proto.NewTimestampFromTime(now))
+	assert.True(t, b.Timestamp < proto.NewTimestampFromTime(now.Add(time.Minute)))
+	headers = append(headers, b.BlockHeader)
+	cv, err = consensus.NewConsensusValidator(validatorState{st}, tm)
+	require.NoError(t, err)
+	require.NoError(t, cv.ValidateHeaders(headers, 1))
+
+	_, err = m.MineMicroblock()
+	assert.Error(t, err)
+
+	transfer := func(amount uint64) {
+		tx := proto.NewUnsignedTransferWithSig(minerKP.Public, proto.OptionalAsset{}, proto.OptionalAsset{}, proto.NewTimestampFromTime(tm.Now()), amount, 100000, proto.NewRecipientFromAddress(userAddr), &proto.LegacyAttachment{})
+		require.NoError(t, tx.Sign(scheme, minerKP.Secret))
+		bts, err := tx.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, utx.AddWithBytes(tx, bts))
+	}
+	transfer(1000)
+	micro, err := m.MineMicroblock()
+	require.NoError(t, err)
+	assert.Equal(t, 1, micro.Transactions.Count())
+	require.Len(t, runtime.micros, 1)
+	height, err = st.Height()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), height)
+	assert.Equal(t, 1, st.TopBlock().Transactions.Count())
+
+	// Transactions are mined on top of the last key block, new key block is mined when needed.
+	transfer(2000)
+	require.NoError(t, m.mineTransactions())
+	assert.Equal(t, 2, st.TopBlock().Transactions.Count())
+	assert.Equal(t, 0, utx.Count())
+	m.last = nil
+	transfer(3000)
+	require.NoError(t, m.mineTransactions())
+	height, err = st.Height()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), height)
+	assert.Equal(t, 1, st.TopBlock().Transactions.Count())
+	balance, err := st.AccountBalance(proto.NewRecipientFromAddress(userAddr), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6000), balance)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/miner/scheduler"
 	"github.com/wavesplatform/gowaves/pkg/ng"
 	"github.com/wavesplatform/gowaves/pkg/node/peer_manager"
@@ -52,9 +53,23 @@ func NewMicroblockMiner(services services.Services, ngRuntime ng.Runtime, scheme
 	}
 }
 
+var (
+	errKeyBlockChanged = errors.New("key block changed")
+	errNoTransactions  = errors.New("no transactions to put into microblock")
+)
+
 func (a *MicroblockMiner) Mine(ctx context.Context, t proto.Timestamp, k proto.KeyPair, parent proto.BlockID, baseTarget types.BaseTarget, GenSignature []byte) {
 	defer a.scheduler.Reschedule()
+	b, err := a.mineKeyBlock(t, k, parent, baseTarget, GenSignature)
+	if err != nil {
+		zap.S().Error(err)
+		return
+	}
+	go a.mineMicro(ctx, a.restLimits(), b, ng.NewBlocksFromBlock(b), k)
+}
 
+// mineKeyBlock creates new key block, applies it and sends it to connected peers.
+func (a *MicroblockMiner) mineKeyBlock(t proto.Timestamp, k proto.KeyPair, parent proto.BlockID, baseTarget types.BaseTarget, GenSignature []byte) (*proto.Block, error) {
 	nxt := proto.NxtConsensus{
 		BaseTarget:   baseTarget,
 		GenSignature: GenSignature,
@@ -77,22 +92,20 @@ func (a *MicroblockMiner) Mine(ctx context.Context, t proto.Timestamp, k proto.K
 		return b, nil
 	}()
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, err
 	}
 
 	err = a.services.BlocksApplier.Apply([]*proto.Block{b})
 	if err != nil {
-		zap.S().Errorf("Miner: applying created block: %q, timestamp %d", err, t)
-		return
+		return nil, errors.Errorf("Miner: applying created block: %q, timestamp %d", err, t)
 	}
+	a.ngRuntime.MinedBlock(b)
 
 	locked := a.state.Mutex().RLock()
 	curScore, err := a.state.CurrentScore()
 	locked.Unlock()
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, err
 	}
 
 	zap.S().Debugf("Miner: generated new block id: %s, time: %d", b.BlockID().String(), t)
@@ -104,20 +117,21 @@ func (a *MicroblockMiner) Mine(ctx context.Context, t proto.Timestamp, k proto.K
 	})
 	msg, err := proto.MessageByBlock(b, a.scheme)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, err
 	}
 	a.peer.EachConnected(func(peer peer.Peer, score *proto.Score) {
 		peer.SendMessage(msg)
 	})
+	return b, nil
+}
 
-	rest := restLimits{
+func (a *MicroblockMiner) restLimits() restLimits {
+	return restLimits{
 		MaxScriptRunsInBlock:        a.constraints.MaxScriptRunsInBlock,
 		MaxScriptsComplexityInBlock: a.constraints.MaxScriptsComplexityInBlock,
 		ClassicAmountOfTxsInBlock:   a.constraints.ClassicAmountOfTxsInBlock,
 		MaxTxsSizeInBytes:           a.constraints.MaxTxsSizeInBytes - 4,
 	}
-	go a.mineMicro(ctx, rest, b, ng.NewBlocksFromBlock(b), k)
 }
 
 func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockApplyOn *proto.Block, blocks ng.Blocks, keyPair proto.KeyPair) {
@@ -132,28 +146,41 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 		return
 	}
 
+	_, newBlock, newBlocks, newRest, err := a.mineMicroblock(rest, blockApplyOn, blocks, keyPair)
+	switch err {
+	case nil:
+		go a.mineMicro(ctx, newRest, newBlock, newBlocks, keyPair)
+	case errNoTransactions:
+		go a.mineMicro(ctx, rest, blockApplyOn, blocks, keyPair)
+	case errKeyBlockChanged:
+		// block changed, exit
+	default:
+		zap.S().Error(err)
+	}
+}
+
+// mineMicroblock puts transactions from UTX pool into new microblock on top of the given block, applies it and
+// sends it to connected peers. It returns the microblock, the new total block, the row of blocks and the rest limits
+// to use for the next microblock.
+func (a *MicroblockMiner) mineMicroblock(rest restLimits, blockApplyOn *proto.Block, blocks ng.Blocks, keyPair proto.KeyPair) (*proto.MicroBlock, *proto.Block, ng.Blocks, restLimits, error) {
 	height, err := a.state.Height()
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	lastBlock, err := a.state.BlockByHeight(height)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	if lastBlock.BlockID() != blockApplyOn.BlockID() {
-		// block changed, exit
-		return
+		return nil, nil, nil, rest, errKeyBlockChanged
 	}
 	parentTimestamp := lastBlock.Timestamp
 	if height > 1 {
 		parent, err := a.state.BlockByHeight(height - 1)
 		if err != nil {
-			zap.S().Error(err)
-			return
+			return nil, nil, nil, rest, err
 		}
 		parentTimestamp = parent.Timestamp
 	}
@@ -197,19 +224,19 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 
 	// return unapplied transactions
 	for _, unapplied := range unAppliedTransactions {
-		_ = a.utx.AddWithBytes(unapplied.T, unapplied.B)
+		if err := a.utx.AddWithBytes(unapplied.T, unapplied.B); err != nil {
+			a.utx.Evicted(unapplied.T, err)
+		}
 	}
 
 	// no transactions applied, skip
 	if cnt == 0 {
-		go a.mineMicro(ctx, rest, blockApplyOn, blocks, keyPair)
-		return
+		return nil, nil, nil, rest, errNoTransactions
 	}
 
 	row, err := blocks.Row()
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	var ref proto.BlockID
@@ -233,15 +260,13 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 		a.scheme,
 	)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	sk := keyPair.Secret
 	err = newBlock.Sign(a.scheme, keyPair.Secret)
 	if err != nil {
-		zap.S().Errorf("Failed to sing a block: %v", err)
-		return
+		return nil, nil, nil, rest, errors.Errorf("Failed to sing a block: %v", err)
 	}
 
 	locked = mu.Lock()
@@ -250,8 +275,7 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 
 	err = a.services.BlocksApplier.Apply([]*proto.Block{newBlock})
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	micro := proto.MicroBlock{
@@ -266,15 +290,13 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 
 	err = micro.Sign(sk)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	inv := proto.NewUnsignedMicroblockInv(micro.SenderPK, micro.TotalBlockID, micro.Reference)
 	err = inv.Sign(sk, a.scheme)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
 
 	a.ngRuntime.MinedMicroblock(&micro, inv)
@@ -288,11 +310,9 @@ func (a *MicroblockMiner) mineMicro(ctx context.Context, rest restLimits, blockA
 
 	newBlocks, err := blocks.AddMicro(&micro)
 	if err != nil {
-		zap.S().Error(err)
-		return
+		return nil, nil, nil, rest, err
 	}
-
-	go a.mineMicro(ctx, newRest, newBlock, newBlocks, keyPair)
+	return &micro, newBlock, newBlocks, newRest, nil
 }
 
 func blockVersion(state state.State) (proto.BlockVersion, error) {
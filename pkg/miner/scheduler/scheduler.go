@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"math"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// Depth of generating balance calculation in blocks.
+const generatingBalanceDepth = 1000
+
 type Emit struct {
 	Timestamp    uint64
 	KeyPair      proto.KeyPair
@@ -36,6 +40,8 @@ type SchedulerImpl struct {
 	tm         types.Time
 	consensus  types.MinerConsensus
 	minerDelay proto.Timestamp
+	// manual scheduler only calculates emits and never sends them to mine channel
+	manual bool
 }
 
 type internal interface {
@@ -45,6 +51,15 @@ type internal interface {
 type internalImpl struct {
 }
 
+// generatingBalanceStartHeight returns the first height of generating balance range, for young blockchains
+// the range starts from the genesis block.
+func generatingBalanceStartHeight(height uint64) uint64 {
+	if height <= generatingBalanceDepth {
+		return 1
+	}
+	return height - generatingBalanceDepth
+}
+
 func (a internalImpl) schedule(state state.State, keyPairs []proto.KeyPair, schema proto.Scheme, AverageBlockDelaySeconds uint64, confirmedBlock *proto.Block, confirmedBlockHeight uint64) []Emit {
 	var greatGrandParentTimestamp proto.Timestamp = 0
 	if confirmedBlockHeight > 2 {
@@ -120,7 +135,7 @@ func (a internalImpl) schedule(state state.State, keyPairs []proto.KeyPair, sche
 			continue
 		}
 		locked := state.Mutex().RLock()
-		effectiveBalance, err := state.EffectiveBalanceStable(proto.NewRecipientFromAddress(addr), confirmedBlockHeight-1000, confirmedBlockHeight)
+		effectiveBalance, err := state.EffectiveBalanceStable(proto.NewRecipientFromAddress(addr), generatingBalanceStartHeight(confirmedBlockHeight), confirmedBlockHeight)
 		locked.Unlock()
 		if err != nil {
 			zap.S().Error(err)
@@ -158,6 +173,14 @@ func NewScheduler(state state.State, seeder seeder, settings *settings.Blockchai
 	return newScheduler(internalImpl{}, state, seeder, settings, tm, consensus, minerDelay)
 }
 
+// NewManualScheduler creates the scheduler that only calculates the emits, without mining them in time.
+// It's used to produce blocks on demand in development mode, the mining is allowed without connected peers.
+func NewManualScheduler(state state.State, seeder seeder, settings *settings.BlockchainSettings, tm types.Time) *SchedulerImpl {
+	s := newScheduler(internalImpl{}, state, seeder, settings, tm, StubConsensus{}, math.MaxUint64)
+	s.manual = true
+	return s
+}
+
 func newScheduler(internal internal, state state.State, seeder seeder, settings *settings.BlockchainSettings, tm types.Time, consensus types.MinerConsensus, minerDelay proto.Timestamp) *SchedulerImpl {
 	if seeder == nil {
 		seeder = wallet.NewWallet()
@@ -236,6 +259,9 @@ func (a *SchedulerImpl) reschedule(state state.State, confirmedBlock *proto.Bloc
 
 	emits := a.internal.schedule(state, keyPairs, a.settings.AddressSchemeCharacter, a.settings.AverageBlockDelaySeconds, confirmedBlock, confirmedBlockHeight)
 	a.emits = emits
+	if a.manual {
+		return
+	}
 	now := proto.NewTimestampFromTime(a.tm.Now())
 	for _, emit := range emits {
 		if emit.Timestamp > now { // timestamp in future
@@ -22,3 +22,10 @@ func TestSchedulerImpl_Emits(t *testing.T) {
 
 	require.EqualValues(t, []Emit([]Emit(nil)), rs)
 }
+
+func TestGeneratingBalanceStartHeight(t *testing.T) {
+	require.EqualValues(t, 1, generatingBalanceStartHeight(1))
+	require.EqualValues(t, 1, generatingBalanceStartHeight(1000))
+	require.EqualValues(t, 1, generatingBalanceStartHeight(1001))
+	require.EqualValues(t, 1000, generatingBalanceStartHeight(2000))
+}
@@ -39,6 +39,11 @@ func (a *transactionsHeap) Pop() interface{} {
 	return item
 }
 
+// EvictionListener is notified about the transactions dropped from the pool without getting into a block.
+type EvictionListener interface {
+	TransactionEvicted(t proto.Transaction, reason error)
+}
+
 type UtxImpl struct {
 	mu             sync.Mutex
 	transactions   transactionsHeap
@@ -47,6 +52,7 @@ type UtxImpl struct {
 	curSize        uint64
 	validator      Validator
 	settings       *settings.BlockchainSettings
+	listener       EvictionListener
 }
 
 func New(sizeLimit uint64, validator Validator, settings *settings.BlockchainSettings) *UtxImpl {
@@ -106,6 +112,22 @@ func (a *UtxImpl) addWithBytes(t proto.Transaction, b []byte) error {
 	return nil
 }
 
+// SetEvictionListener sets the listener of evicted transactions.
+func (a *UtxImpl) SetEvictionListener(l EvictionListener) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.listener = l
+}
+
+func (a *UtxImpl) Evicted(t proto.Transaction, reason error) {
+	a.mu.Lock()
+	l := a.listener
+	a.mu.Unlock()
+	if l != nil {
+		l.TransactionEvicted(t, reason)
+	}
+}
+
 func (a *UtxImpl) Count() int {
 	a.mu.Lock()
 	defer a.mu.Unlock()
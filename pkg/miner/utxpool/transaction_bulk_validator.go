@@ -31,7 +31,9 @@ func (a bulkValidator) Validate() {
 		return
 	}
 	for _, t := range transactions {
-		_ = a.utx.AddWithBytes(t.T, t.B)
+		if err := a.utx.AddWithBytes(t.T, t.B); err != nil {
+			a.utx.Evicted(t.T, err)
+		}
 	}
 }
 
@@ -52,9 +54,11 @@ func (a bulkValidator) validate() ([]*types.TransactionWithBytes, error) {
 		if t == nil {
 			break
 		}
-		if err := a.state.ValidateNextTx(t.T, currentTimestamp, lastKnownBlock.Timestamp, lastKnownBlock.Version); err == nil {
-			transactions = append(transactions, t)
+		if err := a.state.ValidateNextTx(t.T, currentTimestamp, lastKnownBlock.Timestamp, lastKnownBlock.Version); err != nil {
+			a.utx.Evicted(t.T, err)
+			continue
 		}
+		transactions = append(transactions, t)
 	}
 	a.state.ResetValidationList()
 	return transactions, nil
@@ -14,6 +14,14 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/util/lock"
 )
 
+type evictionListener struct {
+	evicted map[proto.Transaction]error
+}
+
+func (a *evictionListener) TransactionEvicted(t proto.Transaction, reason error) {
+	a.evicted[t] = reason
+}
+
 func TestBulkValidator_Validate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -37,9 +45,12 @@ func TestBulkValidator_Validate(t *testing.T) {
 	require.NoError(t, utx.AddWithBytes(byte_helpers.TransferWithSig.Transaction, byte_helpers.TransferWithSig.TransactionBytes))
 	require.NoError(t, utx.AddWithBytes(byte_helpers.BurnWithSig.Transaction, byte_helpers.BurnWithSig.TransactionBytes))
 	require.Equal(t, 2, utx.Len())
+	listener := &evictionListener{evicted: make(map[proto.Transaction]error)}
+	utx.SetEvictionListener(listener)
 
 	validator := newBulkValidator(m, utx, tm(now))
 	validator.Validate()
 
 	require.Equal(t, 1, utx.Len())
+	require.Equal(t, map[proto.Transaction]error{byte_helpers.TransferWithSig.Transaction: errors.New("some err")}, listener.evicted)
 }
@@ -505,6 +505,21 @@ func (mr *MockStateInfoMockRecorder) AddrByAlias(alias interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddrByAlias", reflect.TypeOf((*MockStateInfo)(nil).AddrByAlias), alias)
 }
 
+// AliasesByAddr mocks base method
+func (m *MockStateInfo) AliasesByAddr(addr proto.Address) ([]proto.Alias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AliasesByAddr", addr)
+	ret0, _ := ret[0].([]proto.Alias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AliasesByAddr indicates an expected call of AliasesByAddr
+func (mr *MockStateInfoMockRecorder) AliasesByAddr(addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AliasesByAddr", reflect.TypeOf((*MockStateInfo)(nil).AliasesByAddr), addr)
+}
+
 // RetrieveEntries mocks base method
 func (m *MockStateInfo) RetrieveEntries(account proto.Recipient) ([]proto.DataEntry, error) {
 	m.ctrl.T.Helper()
@@ -655,6 +670,21 @@ func (mr *MockStateInfoMockRecorder) AssetIsSponsored(assetID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetIsSponsored", reflect.TypeOf((*MockStateInfo)(nil).AssetIsSponsored), assetID)
 }
 
+// SponsorshipInfo mocks base method
+func (m *MockStateInfo) SponsorshipInfo(assetID crypto.Digest) (*proto.SponsorshipInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SponsorshipInfo", assetID)
+	ret0, _ := ret[0].(*proto.SponsorshipInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SponsorshipInfo indicates an expected call of SponsorshipInfo
+func (mr *MockStateInfoMockRecorder) SponsorshipInfo(assetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SponsorshipInfo", reflect.TypeOf((*MockStateInfo)(nil).SponsorshipInfo), assetID)
+}
+
 // AssetInfo mocks base method
 func (m *MockStateInfo) AssetInfo(assetID crypto.Digest) (*proto.AssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -715,6 +745,66 @@ func (mr *MockStateInfoMockRecorder) ScriptInfoByAsset(assetID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptInfoByAsset", reflect.TypeOf((*MockStateInfo)(nil).ScriptInfoByAsset), assetID)
 }
 
+// ScriptHistoryByAccount mocks base method
+func (m *MockStateInfo) ScriptHistoryByAccount(account proto.Recipient, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptHistoryByAccount", account, after, limit)
+	ret0, _ := ret[0].([]proto.ScriptHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptHistoryByAccount indicates an expected call of ScriptHistoryByAccount
+func (mr *MockStateInfoMockRecorder) ScriptHistoryByAccount(account, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptHistoryByAccount", reflect.TypeOf((*MockStateInfo)(nil).ScriptHistoryByAccount), account, after, limit)
+}
+
+// ScriptHistoryByAsset mocks base method
+func (m *MockStateInfo) ScriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptHistoryByAsset", assetID, after, limit)
+	ret0, _ := ret[0].([]proto.ScriptHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptHistoryByAsset indicates an expected call of ScriptHistoryByAsset
+func (mr *MockStateInfoMockRecorder) ScriptHistoryByAsset(assetID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptHistoryByAsset", reflect.TypeOf((*MockStateInfo)(nil).ScriptHistoryByAsset), assetID, after, limit)
+}
+
+// ScriptStatsByAccount mocks base method
+func (m *MockStateInfo) ScriptStatsByAccount(account proto.Recipient, windows []uint64) ([]proto.ScriptStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptStatsByAccount", account, windows)
+	ret0, _ := ret[0].([]proto.ScriptStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptStatsByAccount indicates an expected call of ScriptStatsByAccount
+func (mr *MockStateInfoMockRecorder) ScriptStatsByAccount(account, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptStatsByAccount", reflect.TypeOf((*MockStateInfo)(nil).ScriptStatsByAccount), account, windows)
+}
+
+// ScriptStatsByAsset mocks base method
+func (m *MockStateInfo) ScriptStatsByAsset(assetID crypto.Digest, windows []uint64) ([]proto.ScriptStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptStatsByAsset", assetID, windows)
+	ret0, _ := ret[0].([]proto.ScriptStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptStatsByAsset indicates an expected call of ScriptStatsByAsset
+func (mr *MockStateInfoMockRecorder) ScriptStatsByAsset(assetID, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptStatsByAsset", reflect.TypeOf((*MockStateInfo)(nil).ScriptStatsByAsset), assetID, windows)
+}
+
 // IsActiveLeasing mocks base method
 func (m *MockStateInfo) IsActiveLeasing(leaseID crypto.Digest) (bool, error) {
 	m.ctrl.T.Helper()
@@ -730,6 +820,36 @@ func (mr *MockStateInfoMockRecorder) IsActiveLeasing(leaseID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsActiveLeasing", reflect.TypeOf((*MockStateInfo)(nil).IsActiveLeasing), leaseID)
 }
 
+// LeaseInfo mocks base method
+func (m *MockStateInfo) LeaseInfo(leaseID crypto.Digest) (*proto.LeaseInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaseInfo", leaseID)
+	ret0, _ := ret[0].(*proto.LeaseInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaseInfo indicates an expected call of LeaseInfo
+func (mr *MockStateInfoMockRecorder) LeaseInfo(leaseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaseInfo", reflect.TypeOf((*MockStateInfo)(nil).LeaseInfo), leaseID)
+}
+
+// LeasesByAddr mocks base method
+func (m *MockStateInfo) LeasesByAddr(addr proto.Address, incoming bool, status proto.LeaseStatus, after *crypto.Digest, limit int) ([]proto.LeaseInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeasesByAddr", addr, incoming, status, after, limit)
+	ret0, _ := ret[0].([]proto.LeaseInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeasesByAddr indicates an expected call of LeasesByAddr
+func (mr *MockStateInfoMockRecorder) LeasesByAddr(addr, incoming, status, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeasesByAddr", reflect.TypeOf((*MockStateInfo)(nil).LeasesByAddr), addr, incoming, status, after, limit)
+}
+
 // InvokeResultByID mocks base method
 func (m *MockStateInfo) InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error) {
 	m.ctrl.T.Helper()
@@ -760,6 +880,36 @@ func (mr *MockStateInfoMockRecorder) ProvidesExtendedApi() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvidesExtendedApi", reflect.TypeOf((*MockStateInfo)(nil).ProvidesExtendedApi))
 }
 
+// ProvidesStateHashes mocks base method
+func (m *MockStateInfo) ProvidesStateHashes() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvidesStateHashes")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProvidesStateHashes indicates an expected call of ProvidesStateHashes
+func (mr *MockStateInfoMockRecorder) ProvidesStateHashes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvidesStateHashes", reflect.TypeOf((*MockStateInfo)(nil).ProvidesStateHashes))
+}
+
+// StateHashAtHeight mocks base method
+func (m *MockStateInfo) StateHashAtHeight(height proto.Height) (*proto.StateHash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateHashAtHeight", height)
+	ret0, _ := ret[0].(*proto.StateHash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateHashAtHeight indicates an expected call of StateHashAtHeight
+func (mr *MockStateInfoMockRecorder) StateHashAtHeight(height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateHashAtHeight", reflect.TypeOf((*MockStateInfo)(nil).StateHashAtHeight), height)
+}
+
 // MockStateModifier is a mock of StateModifier interface
 type MockStateModifier struct {
 	ctrl     *gomock.Controller
@@ -1392,6 +1542,21 @@ func (mr *MockStateMockRecorder) AddrByAlias(alias interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddrByAlias", reflect.TypeOf((*MockState)(nil).AddrByAlias), alias)
 }
 
+// AliasesByAddr mocks base method
+func (m *MockState) AliasesByAddr(addr proto.Address) ([]proto.Alias, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AliasesByAddr", addr)
+	ret0, _ := ret[0].([]proto.Alias)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AliasesByAddr indicates an expected call of AliasesByAddr
+func (mr *MockStateMockRecorder) AliasesByAddr(addr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AliasesByAddr", reflect.TypeOf((*MockState)(nil).AliasesByAddr), addr)
+}
+
 // RetrieveEntries mocks base method
 func (m *MockState) RetrieveEntries(account proto.Recipient) ([]proto.DataEntry, error) {
 	m.ctrl.T.Helper()
@@ -1542,6 +1707,21 @@ func (mr *MockStateMockRecorder) AssetIsSponsored(assetID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetIsSponsored", reflect.TypeOf((*MockState)(nil).AssetIsSponsored), assetID)
 }
 
+// SponsorshipInfo mocks base method
+func (m *MockState) SponsorshipInfo(assetID crypto.Digest) (*proto.SponsorshipInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SponsorshipInfo", assetID)
+	ret0, _ := ret[0].(*proto.SponsorshipInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SponsorshipInfo indicates an expected call of SponsorshipInfo
+func (mr *MockStateMockRecorder) SponsorshipInfo(assetID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SponsorshipInfo", reflect.TypeOf((*MockState)(nil).SponsorshipInfo), assetID)
+}
+
 // AssetInfo mocks base method
 func (m *MockState) AssetInfo(assetID crypto.Digest) (*proto.AssetInfo, error) {
 	m.ctrl.T.Helper()
@@ -1602,6 +1782,66 @@ func (mr *MockStateMockRecorder) ScriptInfoByAsset(assetID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptInfoByAsset", reflect.TypeOf((*MockState)(nil).ScriptInfoByAsset), assetID)
 }
 
+// ScriptHistoryByAccount mocks base method
+func (m *MockState) ScriptHistoryByAccount(account proto.Recipient, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptHistoryByAccount", account, after, limit)
+	ret0, _ := ret[0].([]proto.ScriptHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptHistoryByAccount indicates an expected call of ScriptHistoryByAccount
+func (mr *MockStateMockRecorder) ScriptHistoryByAccount(account, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptHistoryByAccount", reflect.TypeOf((*MockState)(nil).ScriptHistoryByAccount), account, after, limit)
+}
+
+// ScriptHistoryByAsset mocks base method
+func (m *MockState) ScriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptHistoryByAsset", assetID, after, limit)
+	ret0, _ := ret[0].([]proto.ScriptHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptHistoryByAsset indicates an expected call of ScriptHistoryByAsset
+func (mr *MockStateMockRecorder) ScriptHistoryByAsset(assetID, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptHistoryByAsset", reflect.TypeOf((*MockState)(nil).ScriptHistoryByAsset), assetID, after, limit)
+}
+
+// ScriptStatsByAccount mocks base method
+func (m *MockState) ScriptStatsByAccount(account proto.Recipient, windows []uint64) ([]proto.ScriptStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptStatsByAccount", account, windows)
+	ret0, _ := ret[0].([]proto.ScriptStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptStatsByAccount indicates an expected call of ScriptStatsByAccount
+func (mr *MockStateMockRecorder) ScriptStatsByAccount(account, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptStatsByAccount", reflect.TypeOf((*MockState)(nil).ScriptStatsByAccount), account, windows)
+}
+
+// ScriptStatsByAsset mocks base method
+func (m *MockState) ScriptStatsByAsset(assetID crypto.Digest, windows []uint64) ([]proto.ScriptStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScriptStatsByAsset", assetID, windows)
+	ret0, _ := ret[0].([]proto.ScriptStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScriptStatsByAsset indicates an expected call of ScriptStatsByAsset
+func (mr *MockStateMockRecorder) ScriptStatsByAsset(assetID, windows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScriptStatsByAsset", reflect.TypeOf((*MockState)(nil).ScriptStatsByAsset), assetID, windows)
+}
+
 // IsActiveLeasing mocks base method
 func (m *MockState) IsActiveLeasing(leaseID crypto.Digest) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1617,6 +1857,36 @@ func (mr *MockStateMockRecorder) IsActiveLeasing(leaseID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsActiveLeasing", reflect.TypeOf((*MockState)(nil).IsActiveLeasing), leaseID)
 }
 
+// LeaseInfo mocks base method
+func (m *MockState) LeaseInfo(leaseID crypto.Digest) (*proto.LeaseInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaseInfo", leaseID)
+	ret0, _ := ret[0].(*proto.LeaseInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeaseInfo indicates an expected call of LeaseInfo
+func (mr *MockStateMockRecorder) LeaseInfo(leaseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaseInfo", reflect.TypeOf((*MockState)(nil).LeaseInfo), leaseID)
+}
+
+// LeasesByAddr mocks base method
+func (m *MockState) LeasesByAddr(addr proto.Address, incoming bool, status proto.LeaseStatus, after *crypto.Digest, limit int) ([]proto.LeaseInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeasesByAddr", addr, incoming, status, after, limit)
+	ret0, _ := ret[0].([]proto.LeaseInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LeasesByAddr indicates an expected call of LeasesByAddr
+func (mr *MockStateMockRecorder) LeasesByAddr(addr, incoming, status, after, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeasesByAddr", reflect.TypeOf((*MockState)(nil).LeasesByAddr), addr, incoming, status, after, limit)
+}
+
 // InvokeResultByID mocks base method
 func (m *MockState) InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error) {
 	m.ctrl.T.Helper()
@@ -1647,6 +1917,36 @@ func (mr *MockStateMockRecorder) ProvidesExtendedApi() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvidesExtendedApi", reflect.TypeOf((*MockState)(nil).ProvidesExtendedApi))
 }
 
+// ProvidesStateHashes mocks base method
+func (m *MockState) ProvidesStateHashes() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvidesStateHashes")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProvidesStateHashes indicates an expected call of ProvidesStateHashes
+func (mr *MockStateMockRecorder) ProvidesStateHashes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvidesStateHashes", reflect.TypeOf((*MockState)(nil).ProvidesStateHashes))
+}
+
+// StateHashAtHeight mocks base method
+func (m *MockState) StateHashAtHeight(height proto.Height) (*proto.StateHash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateHashAtHeight", height)
+	ret0, _ := ret[0].(*proto.StateHash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateHashAtHeight indicates an expected call of StateHashAtHeight
+func (mr *MockStateMockRecorder) StateHashAtHeight(height interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateHashAtHeight", reflect.TypeOf((*MockState)(nil).StateHashAtHeight), height)
+}
+
 // Mutex mocks base method
 func (m *MockState) Mutex() *lock.RwMutex {
 	m.ctrl.T.Helper()
@@ -1802,6 +2102,21 @@ func (mr *MockStateMockRecorder) ResetValidationList() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetValidationList", reflect.TypeOf((*MockState)(nil).ResetValidationList))
 }
 
+// NewestAccountBalance mocks base method
+func (m *MockState) NewestAccountBalance(arg0 proto.Recipient, arg1 []byte) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewestAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewestAccountBalance indicates an expected call of NewestAccountBalance
+func (mr *MockStateMockRecorder) NewestAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewestAccountBalance", reflect.TypeOf((*MockState)(nil).NewestAccountBalance), arg0, arg1)
+}
+
 // SavePeers mocks base method
 func (m *MockState) SavePeers(arg0 []proto.TCPAddr) error {
 	m.ctrl.T.Helper()
@@ -10,6 +10,7 @@ import (
 )
 
 type Runtime interface {
+	MinedBlock(block *proto.Block)
 	MinedMicroblock(block *proto.MicroBlock, inv *proto.MicroBlockInv)
 }
 
@@ -34,10 +35,17 @@ func NewRuntime(services services.Services, ngState *State) *RuntimeImpl {
 	}
 }
 
+// MinedBlock makes NG aware of the key block that was mined and applied by the node.
+func (a *RuntimeImpl) MinedBlock(block *proto.Block) {
+	a.ngState.blockApplied(block)
+}
+
 func (a *RuntimeImpl) MinedMicroblock(block *proto.MicroBlock, inv *proto.MicroBlockInv) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	a.ngState.minedMicroblock(block)
+
 	_, ok := a.blocks.MicroBlock(block.TotalBlockID)
 	if !ok {
 		a.blocks.AddMicroBlock(block)
@@ -174,6 +174,53 @@ func (a *State) AddMicroblock(micro *proto.MicroBlock) {
 	a.prevAddedBlock = block
 }
 
+// minedMicroblock adds the microblock that was mined and applied by the node.
+func (a *State) minedMicroblock(micro *proto.MicroBlock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.storage.PushMicro(micro); err != nil {
+		zap.S().Debugf("NG State: failed to push mined micro: %v", err)
+		return
+	}
+	block, err := a.storage.Block()
+	if err != nil {
+		zap.S().Debug(err)
+		return
+	}
+	a.prevAddedBlock = block
+}
+
+// LiquidBlock returns the last key block and the microblocks applied to the state on top of it.
+// False is returned if the state's top block is not known to NG, for example while the state is being rolled back.
+func (a *State) LiquidBlock() (*proto.Block, []*proto.MicroBlock, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	row, err := a.storage.curState.Row()
+	if err != nil {
+		return nil, nil, false
+	}
+	id := row.KeyBlock.BlockID()
+	if l := len(row.MicroBlocks); l > 0 {
+		id = row.MicroBlocks[l-1].TotalBlockID
+	}
+	if top := a.state.TopBlock(); top == nil || top.BlockID() != id {
+		return nil, nil, false
+	}
+	return row.KeyBlock, row.MicroBlocks, true
+}
+
+// LiquidRow returns the last key block and the microblocks known to NG on top of it.
+// Unlike LiquidBlock, the microblocks are returned even if they are not applied to the state yet.
+func (a *State) LiquidRow() (*proto.Block, []*proto.MicroBlock, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	row, err := a.storage.curState.Row()
+	if err != nil {
+		return nil, nil, false
+	}
+	return row.KeyBlock, row.MicroBlocks, true
+}
+
 func (a *State) BlockApplied() {
 	h, err := a.state.Height()
 	if err != nil {
@@ -1 +1,83 @@
 package ng
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/mock"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/services"
+	"github.com/wavesplatform/gowaves/pkg/util/lock"
+)
+
+type appliedBlocks struct {
+	blocks []*proto.Block
+}
+
+func (a *appliedBlocks) Apply(blocks []*proto.Block) error {
+	a.blocks = append(a.blocks, blocks...)
+	return nil
+}
+
+// Blocks mined by the node are applied by miner directly, NG must know them to accept the next key block from
+// peers referencing the mined block or microblock.
+func TestStateAddBlockOnMinedMicroblock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := newBlock(sig1, emptySig)
+	micro := newMicro(sig2, sig1)
+	next := newBlock(sig3, sig2)
+
+	st := mock.NewMockState(ctrl)
+	st.EXPECT().Mutex().Return(lock.NewRwMutex(&sync.RWMutex{})).AnyTimes()
+	st.EXPECT().RollbackTo(micro.TotalBlockID).Return(nil)
+	applier := &appliedBlocks{}
+	ngState := NewState(services.Services{State: st, BlocksApplier: applier, Scheme: proto.MainNetScheme})
+	runtime := NewRuntime(services.Services{Scheme: proto.MainNetScheme}, ngState)
+
+	runtime.MinedBlock(key)
+	ngState.minedMicroblock(micro)
+	assert.Equal(t, proto.MainNetScheme, ngState.storage.scheme)
+	assert.Equal(t, micro.TotalBlockID, ngState.prevAddedBlock.BlockID())
+
+	ngState.AddBlock(next)
+	require.Len(t, applier.blocks, 1)
+	assert.Equal(t, next.BlockID(), applier.blocks[0].BlockID())
+	assert.Equal(t, next.BlockID(), ngState.prevAddedBlock.BlockID())
+}
+
+func TestStateLiquidBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	key := newBlock(sig1, emptySig)
+	micro := newMicro(sig2, sig1)
+
+	st := mock.NewMockState(ctrl)
+	ngState := NewState(services.Services{State: st, Scheme: proto.MainNetScheme})
+
+	_, _, ok := ngState.LiquidBlock()
+	assert.False(t, ok, "nothing is known before the first block")
+
+	ngState.blockApplied(key)
+	st.EXPECT().TopBlock().Return(key)
+	k, micros, ok := ngState.LiquidBlock()
+	require.True(t, ok)
+	assert.Equal(t, key, k)
+	assert.Empty(t, micros)
+
+	ngState.minedMicroblock(micro)
+	st.EXPECT().TopBlock().Return(&proto.Block{BlockHeader: proto.BlockHeader{BlockSignature: sig2}})
+	k, micros, ok = ngState.LiquidBlock()
+	require.True(t, ok)
+	assert.Equal(t, key, k)
+	assert.Equal(t, []*proto.MicroBlock{micro}, micros)
+
+	st.EXPECT().TopBlock().Return(key)
+	_, _, ok = ngState.LiquidBlock()
+	assert.False(t, ok, "state is being rolled back")
+}
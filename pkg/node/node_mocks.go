@@ -170,6 +170,10 @@ func (a *MockStateManager) ResetValidationList() {
 
 }
 
+func (a *MockStateManager) NewestAccountBalance(account proto.Recipient, asset []byte) (uint64, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) SavePeers([]proto.TCPAddr) error {
 	panic("implement me")
 }
@@ -217,6 +221,10 @@ func (a *MockStateManager) AssetIsSponsored(assetID crypto.Digest) (bool, error)
 	panic("implement me")
 }
 
+func (a *MockStateManager) SponsorshipInfo(assetID crypto.Digest) (*proto.SponsorshipInfo, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) AssetInfo(assetID crypto.Digest) (*proto.AssetInfo, error) {
 	panic("implement me")
 }
@@ -233,10 +241,34 @@ func (a *MockStateManager) ScriptInfoByAsset(assetID crypto.Digest) (*proto.Scri
 	panic("implement me")
 }
 
+func (a *MockStateManager) ScriptHistoryByAccount(account proto.Recipient, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	panic("implement me")
+}
+
+func (a *MockStateManager) ScriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	panic("implement me")
+}
+
+func (a *MockStateManager) ScriptStatsByAccount(account proto.Recipient, windows []uint64) ([]proto.ScriptStats, error) {
+	panic("implement me")
+}
+
+func (a *MockStateManager) ScriptStatsByAsset(assetID crypto.Digest, windows []uint64) ([]proto.ScriptStats, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) IsActiveLeasing(leaseID crypto.Digest) (bool, error) {
 	panic("implement me")
 }
 
+func (a *MockStateManager) LeaseInfo(leaseID crypto.Digest) (*proto.LeaseInfo, error) {
+	panic("implement me")
+}
+
+func (a *MockStateManager) LeasesByAddr(addr proto.Address, incoming bool, status proto.LeaseStatus, after *crypto.Digest, limit int) ([]proto.LeaseInfo, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error) {
 	panic("implement me")
 }
@@ -245,6 +277,14 @@ func (a *MockStateManager) ProvidesExtendedApi() (bool, error) {
 	panic("implement me")
 }
 
+func (a *MockStateManager) ProvidesStateHashes() (bool, error) {
+	panic("implement me")
+}
+
+func (a *MockStateManager) StateHashAtHeight(height proto.Height) (*proto.StateHash, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) IsNotFound(err error) bool {
 	panic("implement me")
 }
@@ -265,6 +305,10 @@ func (a *MockStateManager) AddrByAlias(alias proto.Alias) (proto.Address, error)
 	panic("implement me")
 }
 
+func (a *MockStateManager) AliasesByAddr(addr proto.Address) ([]proto.Alias, error) {
+	panic("implement me")
+}
+
 func (a *MockStateManager) FullWavesBalance(account proto.Recipient) (*proto.FullWavesBalance, error) {
 	panic("implement me")
 }
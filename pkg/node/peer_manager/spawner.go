@@ -9,6 +9,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/p2p/incoming"
 	"github.com/wavesplatform/gowaves/pkg/p2p/outgoing"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/p2p/secure"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
@@ -30,9 +31,12 @@ type PeerSpawnerImpl struct {
 	nodeName     string
 	nodeNonce    uint64
 	version      proto.Version
+	recorder     peer.Recorder
+	transport    *secure.Transport
 }
 
-func NewPeerSpawner(pool bytespool.Pool, parent peer.Parent, WavesNetwork string, declAddr proto.TCPAddr, nodeName string, nodeNonce uint64, version proto.Version) *PeerSpawnerImpl {
+// NewPeerSpawner creates the spawner of peers, the recorder of peers' messages and the secure transport are optional.
+func NewPeerSpawner(pool bytespool.Pool, parent peer.Parent, WavesNetwork string, declAddr proto.TCPAddr, nodeName string, nodeNonce uint64, version proto.Version, recorder peer.Recorder, transport *secure.Transport) *PeerSpawnerImpl {
 	return &PeerSpawnerImpl{
 		pool:         pool,
 		skipFunc:     noSkip,
@@ -42,6 +46,8 @@ func NewPeerSpawner(pool bytespool.Pool, parent peer.Parent, WavesNetwork string
 		nodeName:     nodeName,
 		nodeNonce:    nodeNonce,
 		version:      version,
+		recorder:     recorder,
+		transport:    transport,
 	}
 }
 
@@ -55,6 +61,8 @@ func (a *PeerSpawnerImpl) SpawnOutgoing(ctx context.Context, address proto.TCPAd
 		Skip:         a.skipFunc,
 		NodeName:     a.nodeName,
 		NodeNonce:    a.nodeNonce,
+		Recorder:     a.recorder,
+		Secure:       a.transport,
 	}
 
 	return outgoing.EstablishConnection(ctx, params, a.version)
@@ -72,6 +80,8 @@ func (a *PeerSpawnerImpl) SpawnIncoming(ctx context.Context, c net.Conn) error {
 		NodeName:  a.nodeName,
 		NodeNonce: a.nodeNonce,
 		Version:   a.version,
+		Recorder:  a.recorder,
+		Secure:    a.transport,
 	}
 
 	return incoming.RunIncomingPeer(ctx, params)
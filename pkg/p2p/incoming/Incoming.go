@@ -9,6 +9,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/libs/bytespool"
 	"github.com/wavesplatform/gowaves/pkg/p2p/conn"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/p2p/secure"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"go.uber.org/zap"
 )
@@ -23,6 +24,8 @@ type IncomingPeerParams struct {
 	NodeName     string
 	NodeNonce    uint64
 	Version      proto.Version
+	Recorder     peer.Recorder
+	Secure       *secure.Transport // Optional
 }
 
 func RunIncomingPeer(ctx context.Context, params IncomingPeerParams) error {
@@ -43,10 +46,18 @@ func RunIncomingPeer(ctx context.Context, params IncomingPeerParams) error {
 	default:
 	}
 
+	nodeName := params.NodeName
+	if params.Secure != nil {
+		nodeName, err = secure.Mark(nodeName)
+		if err != nil {
+			c.Close()
+			return errors.Wrap(err, "RunIncomingPeer")
+		}
+	}
 	writeHandshake := proto.Handshake{
 		AppName:      params.WavesNetwork,
 		Version:      params.Version,
-		NodeName:     params.NodeName,
+		NodeName:     nodeName,
 		NodeNonce:    params.NodeNonce,
 		DeclaredAddr: proto.HandshakeTCPAddr(params.DeclAddr),
 		Timestamp:    proto.NewTimestampFromTime(time.Now()),
@@ -66,9 +77,22 @@ func RunIncomingPeer(ctx context.Context, params IncomingPeerParams) error {
 	default:
 	}
 
+	if params.Secure != nil {
+		c, err = params.Secure.Upgrade(c, readHandshake, false)
+		if err != nil {
+			zap.S().Debugf("failed to establish secure connection with %s: %v", params.Conn.RemoteAddr(), err)
+			_ = params.Conn.Close()
+			return err
+		}
+	}
+
 	remote := peer.NewRemote()
 	connection := conn.WrapConnection(c, params.Pool, remote.ToCh, remote.FromCh, remote.ErrCh, params.Skip)
-	peerImpl := peer.NewPeerImpl(readHandshake, connection, peer.Incoming, remote)
+	peerImpl := peer.NewPeerImpl(readHandshake, connection, peer.Incoming, remote, params.Recorder)
+
+	if params.Recorder != nil {
+		params.Recorder.RecordConnected(peerImpl)
+	}
 
 	out := peer.InfoMessage{
 		Peer: peerImpl,
@@ -86,5 +110,6 @@ func RunIncomingPeer(ctx context.Context, params IncomingPeerParams) error {
 		Parent:     params.Parent,
 		Pool:       params.Pool,
 		Peer:       peerImpl,
+		Recorder:   params.Recorder,
 	})
 }
@@ -14,6 +14,8 @@ type Peer struct {
 	IncomeCh              chan peer.ProtoMessage
 	HandshakeField        proto.Handshake
 	RemoteAddress         proto.TCPAddr
+	DirectionField        peer.Direction
+	Closed                bool
 	mu                    sync.Mutex
 }
 
@@ -25,16 +27,19 @@ func (a *Peer) RemoteAddr() proto.TCPAddr {
 	return a.RemoteAddress
 }
 
-func (*Peer) Direction() peer.Direction {
-	panic("implement me")
+func (a *Peer) Direction() peer.Direction {
+	return a.DirectionField
 }
 
 func (*Peer) Reconnect() error {
 	panic("implement me")
 }
 
-func (*Peer) Close() error {
-	panic("implement me")
+func (a *Peer) Close() error {
+	a.mu.Lock()
+	a.Closed = true
+	a.mu.Unlock()
+	return nil
 }
 
 func (*Peer) Connection() conn.Connection {
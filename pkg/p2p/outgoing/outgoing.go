@@ -9,6 +9,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/libs/bytespool"
 	"github.com/wavesplatform/gowaves/pkg/p2p/conn"
 	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/p2p/secure"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"go.uber.org/zap"
 )
@@ -22,6 +23,8 @@ type EstablishParams struct {
 	Skip         conn.SkipFilter
 	NodeName     string
 	NodeNonce    uint64
+	Recorder     peer.Recorder
+	Secure       *secure.Transport // Optional
 }
 
 func EstablishConnection(ctx context.Context, params EstablishParams, v proto.Version) error {
@@ -45,7 +48,11 @@ func EstablishConnection(ctx context.Context, params EstablishParams, v proto.Ve
 	}
 	p.connection = connection
 
-	peerImpl := peer.NewPeerImpl(*handshake, connection, peer.Outgoing, remote)
+	peerImpl := peer.NewPeerImpl(*handshake, connection, peer.Outgoing, remote, params.Recorder)
+
+	if params.Recorder != nil {
+		params.Recorder.RecordConnected(peerImpl)
+	}
 
 	connected := peer.InfoMessage{
 		Peer: peerImpl,
@@ -64,6 +71,7 @@ func EstablishConnection(ctx context.Context, params EstablishParams, v proto.Ve
 		Parent:     params.Parent,
 		Pool:       params.Pool,
 		Peer:       peerImpl,
+		Recorder:   params.Recorder,
 	})
 }
 
@@ -75,10 +83,18 @@ type connector struct {
 }
 
 func (a *connector) connect(ctx context.Context, c net.Conn, v proto.Version) (conn.Connection, *proto.Handshake, error) {
+	nodeName := a.params.NodeName
+	if a.params.Secure != nil {
+		var err error
+		nodeName, err = secure.Mark(nodeName)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "connector.connect")
+		}
+	}
 	handshake := proto.Handshake{
 		AppName:      a.params.WavesNetwork,
 		Version:      v,
-		NodeName:     a.params.NodeName,
+		NodeName:     nodeName,
 		NodeNonce:    a.params.NodeNonce,
 		DeclaredAddr: proto.HandshakeTCPAddr(a.params.DeclAddr),
 		Timestamp:    proto.NewTimestampFromTime(time.Now()),
@@ -107,5 +123,13 @@ func (a *connector) connect(ctx context.Context, c net.Conn, v proto.Version) (c
 			return nil, nil, err
 		}
 	}
+	if a.params.Secure != nil {
+		sc, err := a.params.Secure.Upgrade(c, handshake, true)
+		if err != nil {
+			_ = c.Close()
+			return nil, nil, err
+		}
+		c = sc
+	}
 	return conn.WrapConnection(c, a.params.Pool, a.remote.ToCh, a.remote.FromCh, a.remote.ErrCh, a.params.Skip), &handshake, nil
 }
@@ -33,6 +33,18 @@ func bytesToMessage(b []byte, id string, resendTo chan ProtoMessage, pool bytesp
 	return nil
 }
 
+// messageBytes cuts the message from the pooled buffer, which is usually longer than the message.
+func messageBytes(b []byte) []byte {
+	var h proto.Header
+	if err := h.UnmarshalBinary(b); err != nil {
+		return b
+	}
+	if l := int(h.HeaderLength() + h.PayloadLength); l <= len(b) {
+		return b[:l]
+	}
+	return b
+}
+
 type HandlerParams struct {
 	Ctx        context.Context
 	ID         string
@@ -41,6 +53,7 @@ type HandlerParams struct {
 	Parent     Parent
 	Pool       bytespool.Pool
 	Peer       Peer
+	Recorder   Recorder // Optional
 }
 
 // for Handle doesn't matter outgoing or incoming Connection, it just send and receive messages
@@ -52,6 +65,9 @@ func Handle(params HandlerParams) error {
 			return errors.Wrap(params.Ctx.Err(), "Handle")
 
 		case bts := <-params.Remote.FromCh:
+			if params.Recorder != nil {
+				params.Recorder.RecordInbound(params.ID, messageBytes(bts))
+			}
 			err := bytesToMessage(bts, params.ID, params.Parent.MessageCh, params.Pool, params.Peer)
 			if err != nil {
 				out := InfoMessage{
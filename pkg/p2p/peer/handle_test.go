@@ -97,3 +97,47 @@ func TestHandleError(t *testing.T) {
 	cancel()
 	wg.Wait()
 }
+
+type recorderMock struct {
+	mu      sync.Mutex
+	inbound map[string][][]byte
+}
+
+func (a *recorderMock) RecordConnected(Peer) {}
+
+func (a *recorderMock) RecordInbound(peerID string, message []byte) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inbound[peerID] = append(a.inbound[peerID], append([]byte(nil), message...))
+}
+
+func (a *recorderMock) RecordOutbound(string, []byte) {}
+
+func TestHandleRecordInbound(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	remote := NewRemote()
+	parent := NewParent()
+	rec := &recorderMock{inbound: make(map[string][][]byte)}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		_ = Handle(HandlerParams{
+			Ctx:        ctx,
+			ID:         "peer",
+			Connection: &mockConnection{},
+			Parent:     parent,
+			Remote:     remote,
+			Pool:       bytespool.NewBytesPool(1, 15*1024),
+			Recorder:   rec,
+		})
+		wg.Done()
+	}()
+	// Pooled buffer is longer than the message, only the message is recorded.
+	b := make([]byte, 15*1024)
+	copy(b, byte_helpers.TransferWithSig.MessageBytes)
+	remote.FromCh <- b
+	<-parent.MessageCh
+	cancel()
+	wg.Wait()
+	assert.Equal(t, [][]byte{byte_helpers.TransferWithSig.MessageBytes}, rec.inbound["peer"])
+}
@@ -40,3 +40,11 @@ type Peer interface {
 	Handshake() proto.Handshake
 	RemoteAddr() proto.TCPAddr
 }
+
+// Recorder receives the connected peers and the raw bytes of all messages sent and received by them.
+// Implementations must not retain the message slices, they are reused after the call.
+type Recorder interface {
+	RecordConnected(p Peer)
+	RecordInbound(peerID string, message []byte)
+	RecordOutbound(peerID string, message []byte)
+}
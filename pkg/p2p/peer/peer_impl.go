@@ -17,15 +17,18 @@ type PeerImpl struct {
 	direction Direction
 	remote    Remote
 	id        string
+	recorder  Recorder
 }
 
-func NewPeerImpl(handshake proto.Handshake, conn conn.Connection, direction Direction, remote Remote) *PeerImpl {
+// NewPeerImpl creates the peer, the recorder is optional.
+func NewPeerImpl(handshake proto.Handshake, conn conn.Connection, direction Direction, remote Remote, recorder Recorder) *PeerImpl {
 	return &PeerImpl{
 		handshake: handshake,
 		conn:      conn,
 		direction: direction,
 		remote:    remote,
 		id:        id(conn.Conn().RemoteAddr().String(), handshake.NodeNonce),
+		recorder:  recorder,
 	}
 }
 
@@ -43,6 +46,9 @@ func (a *PeerImpl) SendMessage(m proto.Message) {
 		zap.S().Error(err)
 		return
 	}
+	if a.recorder != nil {
+		a.recorder.RecordOutbound(a.id, b)
+	}
 	select {
 	case a.remote.ToCh <- b:
 	default:
@@ -0,0 +1,322 @@
+// Package recorder writes the messages of the node's peers to a file and replays the recordings against the node.
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"go.uber.org/zap"
+)
+
+const (
+	magic         = "GWP2P"
+	formatVersion = 1
+
+	flushInterval = time.Second
+	maxFieldSize  = 100 * 1024 * 1024
+)
+
+// Kind is the kind of recorded event.
+type Kind byte
+
+const (
+	Connected Kind = iota + 1 // Peer connected
+	Inbound                   // Message received from peer
+	Outbound                  // Message sent to peer
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Connected:
+		return "connected"
+	case Inbound:
+		return "inbound"
+	case Outbound:
+		return "outbound"
+	default:
+		return "unknown"
+	}
+}
+
+// Record is a single recorded event.
+// Recording file consists of the header followed by records, each record is encoded as
+// kind (1 byte), time in Unix nanoseconds (varint), peer ID and payload (both prefixed with uvarint length).
+// The payload of Connected record is direction (1 byte), remote address (prefixed with uvarint length) and handshake,
+// for other records it's the message bytes.
+type Record struct {
+	Kind   Kind
+	Time   time.Time
+	PeerID string
+
+	// Connected records only.
+	Direction  peer.Direction
+	RemoteAddr proto.TCPAddr
+	Handshake  proto.Handshake
+
+	// Inbound and Outbound records only.
+	Message []byte
+}
+
+func (r *Record) payload() ([]byte, error) {
+	if r.Kind != Connected {
+		return r.Message, nil
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(r.Direction))
+	writeBytes(buf, []byte(r.RemoteAddr.String()))
+	if _, err := r.Handshake.WriteTo(buf); err != nil {
+		return nil, errors.Wrap(err, "failed to write handshake")
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *Record) setPayload(data []byte) error {
+	if r.Kind != Connected {
+		r.Message = data
+		return nil
+	}
+	buf := bytes.NewReader(data)
+	d, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	r.Direction = peer.Direction(d)
+	addr, err := readBytes(buf)
+	if err != nil {
+		return err
+	}
+	r.RemoteAddr = proto.NewTCPAddrFromString(string(addr))
+	if _, err := r.Handshake.ReadFrom(buf); err != nil {
+		return errors.Wrap(err, "failed to read handshake")
+	}
+	return nil
+}
+
+func writeBytes(w *bytes.Buffer, b []byte) {
+	var l [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(l[:], uint64(len(b)))
+	w.Write(l[:n])
+	w.Write(b)
+}
+
+func readBytes(r interface {
+	io.Reader
+	io.ByteReader
+}) ([]byte, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > maxFieldSize {
+		return nil, errors.Errorf("field size %d is too big", l)
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Writer writes records to the recording file, it implements peer.Recorder.
+// Buffered records are flushed at least once a second while the records keep coming and on close.
+type Writer struct {
+	mu        sync.Mutex
+	w         *bufio.Writer
+	closer    io.Closer
+	buf       bytes.Buffer
+	lastFlush time.Time
+	failed    bool
+	now       func() time.Time
+}
+
+// NewWriter writes the header of recording to w and returns the Writer.
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	if err := bw.WriteByte(formatVersion); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	closer, _ := w.(io.Closer)
+	return &Writer{w: bw, closer: closer, lastFlush: time.Now(), now: time.Now}, nil
+}
+
+// Create creates the recording file, the existing file is truncated.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create recording file")
+	}
+	w, err := NewWriter(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Wrap(err, "failed to write recording header")
+	}
+	return w, nil
+}
+
+func (a *Writer) RecordConnected(p peer.Peer) {
+	a.record(&Record{Kind: Connected, PeerID: p.ID(), Direction: p.Direction(), RemoteAddr: p.RemoteAddr(), Handshake: p.Handshake()})
+}
+
+func (a *Writer) RecordInbound(peerID string, message []byte) {
+	a.record(&Record{Kind: Inbound, PeerID: peerID, Message: message})
+}
+
+func (a *Writer) RecordOutbound(peerID string, message []byte) {
+	a.record(&Record{Kind: Outbound, PeerID: peerID, Message: message})
+}
+
+// record writes the record, recording stops on the first error.
+func (a *Writer) record(r *Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.failed {
+		return
+	}
+	r.Time = a.now()
+	if err := a.write(r); err != nil {
+		zap.S().Errorf("P2P recording stopped: %v", err)
+		a.failed = true
+	}
+}
+
+// Write writes the record with its own time.
+func (a *Writer) Write(r *Record) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.write(r)
+}
+
+func (a *Writer) write(r *Record) error {
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	a.buf.Reset()
+	a.buf.WriteByte(byte(r.Kind))
+	var ts [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(ts[:], r.Time.UnixNano())
+	a.buf.Write(ts[:n])
+	writeBytes(&a.buf, []byte(r.PeerID))
+	writeBytes(&a.buf, payload)
+	if _, err := a.w.Write(a.buf.Bytes()); err != nil {
+		return err
+	}
+	if now := time.Now(); now.Sub(a.lastFlush) >= flushInterval {
+		a.lastFlush = now
+		return a.w.Flush()
+	}
+	return nil
+}
+
+// Close flushes the buffered records and closes the underlying file.
+func (a *Writer) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failed = true
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+	if a.closer != nil {
+		return a.closer.Close()
+	}
+	return nil
+}
+
+// Reader reads the records of recording.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader checks the header of recording and returns the Reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read recording header")
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("not a P2P recording")
+	}
+	if v := header[len(magic)]; v != formatVersion {
+		return nil, errors.Errorf("unsupported recording version %d", v)
+	}
+	return &Reader{r: br}, nil
+}
+
+// Read returns the next record, io.EOF is returned at the end of recording.
+// The last record of unexpectedly terminated recording may be incomplete, io.ErrUnexpectedEOF is returned in this case.
+func (a *Reader) Read() (*Record, error) {
+	k, err := a.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	r := &Record{Kind: Kind(k)}
+	if r.Kind < Connected || r.Kind > Outbound {
+		return nil, errors.Errorf("invalid record kind %d", k)
+	}
+	ts, err := binary.ReadVarint(a.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	r.Time = time.Unix(0, ts)
+	id, err := readBytes(a.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	r.PeerID = string(id)
+	payload, err := readBytes(a.r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if err := r.setPayload(payload); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s record", r.Kind)
+	}
+	return r, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// ReadFile reads all records of the recording file.
+func ReadFile(path string) ([]*Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var records []*Record
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
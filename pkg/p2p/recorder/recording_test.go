@@ -0,0 +1,174 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/p2p/mock"
+	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/util/byte_helpers"
+)
+
+func scoreMessage(t *testing.T, score byte) []byte {
+	b, err := (&proto.ScoreMessage{Score: []byte{score}}).MarshalBinary()
+	require.NoError(t, err)
+	return b
+}
+
+func testPeer() *mock.Peer {
+	return &mock.Peer{
+		Addr:           "10.0.0.1-12345",
+		RemoteAddress:  proto.NewTCPAddr(net.ParseIP("10.0.0.1"), 6868),
+		DirectionField: peer.Outgoing,
+		HandshakeField: proto.Handshake{
+			AppName:      "wavesW",
+			Version:      proto.Version{Major: 1, Minor: 2},
+			NodeName:     "node",
+			NodeNonce:    12345,
+			DeclaredAddr: proto.HandshakeTCPAddr(proto.NewTCPAddr(net.ParseIP("10.0.0.1"), 6868)),
+			Timestamp:    1600000000000,
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf)
+	require.NoError(t, err)
+	start := time.Unix(1600000000, 0)
+	ts := start
+	w.now = func() time.Time {
+		ts = ts.Add(time.Second)
+		return ts
+	}
+	p := testPeer()
+	w.RecordConnected(p)
+	w.RecordOutbound(p.ID(), scoreMessage(t, 1))
+	w.RecordInbound(p.ID(), byte_helpers.TransferWithSig.MessageBytes)
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	rec, err := r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, Connected, rec.Kind)
+	assert.Equal(t, start.Add(time.Second).UnixNano(), rec.Time.UnixNano())
+	assert.Equal(t, p.ID(), rec.PeerID)
+	assert.Equal(t, peer.Outgoing, rec.Direction)
+	assert.Equal(t, p.RemoteAddress.String(), rec.RemoteAddr.String())
+	assert.Equal(t, p.HandshakeField, rec.Handshake)
+	rec, err = r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, Outbound, rec.Kind)
+	assert.Equal(t, scoreMessage(t, 1), rec.Message)
+	rec, err = r.Read()
+	require.NoError(t, err)
+	assert.Equal(t, Inbound, rec.Kind)
+	assert.Equal(t, start.Add(3*time.Second).UnixNano(), rec.Time.UnixNano())
+	assert.Equal(t, byte_helpers.TransferWithSig.MessageBytes, rec.Message)
+	_, err = r.Read()
+	assert.Equal(t, io.EOF, err)
+
+	// Incomplete last record.
+	r, err = NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = r.Read()
+		require.NoError(t, err)
+	}
+	_, err = r.Read()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = NewReader(bytes.NewReader([]byte("not a recording")))
+	assert.Error(t, err)
+}
+
+func TestCreateReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "recorder")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "p2p.rec")
+	w, err := Create(path)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		w.RecordInbound("peer", scoreMessage(t, byte(i)))
+	}
+	require.NoError(t, w.Close())
+	records, err := ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, records, 100)
+	assert.Equal(t, scoreMessage(t, 99), records[99].Message)
+}
+
+type handlerMock struct {
+	connected []peer.Peer
+	messages  []peer.ProtoMessage
+}
+
+func (a *handlerMock) HandleProtoMessage(mess peer.ProtoMessage) {
+	a.messages = append(a.messages, mess)
+}
+
+func (a *handlerMock) HandleInfoMessage(m peer.InfoMessage) {
+	if c, ok := m.Value.(*peer.Connected); ok {
+		a.connected = append(a.connected, c.Peer)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf)
+	require.NoError(t, err)
+	p := testPeer()
+	start := time.Unix(1600000000, 0)
+	records := []*Record{
+		{Kind: Connected, Time: start, PeerID: p.ID(), Direction: p.DirectionField, RemoteAddr: p.RemoteAddress, Handshake: p.HandshakeField},
+		{Kind: Outbound, Time: start.Add(10 * time.Millisecond), PeerID: p.ID(), Message: scoreMessage(t, 1)},
+		{Kind: Inbound, Time: start.Add(20 * time.Millisecond), PeerID: p.ID(), Message: scoreMessage(t, 2)},
+		{Kind: Inbound, Time: start.Add(30 * time.Millisecond), PeerID: p.ID(), Message: []byte{1, 2, 3}},
+		{Kind: Inbound, Time: start.Add(40 * time.Millisecond), PeerID: "10.0.0.2-1", Message: byte_helpers.TransferWithSig.MessageBytes},
+	}
+	for _, r := range records {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Close())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	h := &handlerMock{}
+	replayer := NewReplayer(1)
+	begin := time.Now()
+	stats, err := replayer.Replay(context.Background(), r, h)
+	require.NoError(t, err)
+	assert.True(t, time.Since(begin) >= 40*time.Millisecond)
+	assert.Equal(t, ReplayStats{Peers: 2, Inbound: 2, Outbound: 1, Malformed: 1}, stats)
+	now := replayer.Now()
+	assert.True(t, !now.Before(start.Add(40*time.Millisecond)) && now.Before(start.Add(time.Second)))
+
+	require.Len(t, h.connected, 2)
+	rp, ok := replayer.Peer(p.ID())
+	require.True(t, ok)
+	assert.Equal(t, rp, h.connected[0])
+	assert.Equal(t, p.HandshakeField, rp.Handshake())
+	assert.Equal(t, peer.Outgoing, rp.Direction())
+	other, ok := replayer.Peer("10.0.0.2-1")
+	require.True(t, ok)
+	assert.True(t, net.ParseIP("10.0.0.2").Equal(other.RemoteAddr().IP))
+	require.Len(t, h.messages, 2)
+	assert.Equal(t, rp, h.messages[0].ID)
+	assert.Equal(t, &proto.ScoreMessage{Score: []byte{2}}, h.messages[0].Message)
+	assert.Equal(t, other, h.messages[1].ID)
+	assert.IsType(t, &proto.TransactionMessage{}, h.messages[1].Message)
+}
@@ -0,0 +1,172 @@
+package recorder
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/p2p/mock"
+	"github.com/wavesplatform/gowaves/pkg/p2p/peer"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"go.uber.org/zap"
+)
+
+// Handler handles the messages of peers, it's implemented by node.Node.
+type Handler interface {
+	HandleProtoMessage(mess peer.ProtoMessage)
+	HandleInfoMessage(m peer.InfoMessage)
+}
+
+type ReplayStats struct {
+	Peers     int // Fake peers connected to the handler
+	Inbound   int // Messages passed to the handler
+	Outbound  int // Recorded messages sent by the node, they are not replayed
+	Malformed int // Inbound messages that failed to unmarshal
+}
+
+// Replayer feeds the recorded connections and inbound messages to the handler on behalf of fake peers.
+// The messages sent by the handler are collected by the fake peers and can be compared to the recorded outbound messages.
+// Replayer also implements types.Time, providing the node with the time of recording.
+type Replayer struct {
+	speed float64
+	peers map[string]*mock.Peer
+
+	mu       sync.Mutex
+	recorded time.Time // Time of the last replayed record
+	replayed time.Time // Local time of replay of the last record
+}
+
+// NewReplayer creates the replayer. If the speed is positive the delays between records are reproduced,
+// divided by the speed, otherwise the records are replayed without delays.
+func NewReplayer(speed float64) *Replayer {
+	return &Replayer{speed: speed, peers: make(map[string]*mock.Peer)}
+}
+
+// Replay replays all records from the reader to the handler. Incomplete last record is ignored.
+func (a *Replayer) Replay(ctx context.Context, r *Reader, h Handler) (ReplayStats, error) {
+	var (
+		stats ReplayStats
+		prev  time.Time
+	)
+	for {
+		rec, err := r.Read()
+		switch err {
+		case nil:
+		case io.EOF:
+			return stats, nil
+		case io.ErrUnexpectedEOF:
+			zap.S().Warn("Recording ends with incomplete record")
+			return stats, nil
+		default:
+			return stats, err
+		}
+		if a.speed > 0 && !prev.IsZero() && rec.Time.After(prev) {
+			select {
+			case <-ctx.Done():
+				return stats, ctx.Err()
+			case <-time.After(time.Duration(float64(rec.Time.Sub(prev)) / a.speed)):
+			}
+		} else if ctx.Err() != nil {
+			return stats, ctx.Err()
+		}
+		prev = rec.Time
+		a.setTime(rec.Time)
+		switch rec.Kind {
+		case Connected:
+			a.connect(h, rec.PeerID, rec.Direction, rec.RemoteAddr, rec.Handshake)
+			stats.Peers++
+		case Inbound:
+			p, ok := a.Peer(rec.PeerID)
+			if !ok {
+				p = a.connect(h, rec.PeerID, peer.Incoming, addressFromID(rec.PeerID), proto.Handshake{})
+				stats.Peers++
+			}
+			m, err := proto.UnmarshalMessage(rec.Message)
+			if err != nil {
+				zap.S().Debugf("Failed to unmarshal message from '%s': %v", rec.PeerID, err)
+				stats.Malformed++
+				continue
+			}
+			h.HandleProtoMessage(peer.ProtoMessage{ID: p, Message: m})
+			stats.Inbound++
+		case Outbound:
+			stats.Outbound++
+		default:
+			return stats, errors.Errorf("unexpected record kind %d", rec.Kind)
+		}
+	}
+}
+
+// ReplayFile replays the recording file.
+func (a *Replayer) ReplayFile(ctx context.Context, path string, h Handler) (ReplayStats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	r, err := NewReader(f)
+	if err != nil {
+		return ReplayStats{}, err
+	}
+	return a.Replay(ctx, r, h)
+}
+
+// Now returns the time of the last replayed record plus the time passed since its replay, scaled by the speed.
+// The local time is returned before the replay.
+func (a *Replayer) Now() time.Time {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.recorded.IsZero() {
+		return time.Now()
+	}
+	passed := time.Since(a.replayed)
+	if a.speed > 0 {
+		passed = time.Duration(float64(passed) * a.speed)
+	}
+	return a.recorded.Add(passed)
+}
+
+func (a *Replayer) setTime(t time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.recorded = t
+	a.replayed = time.Now()
+}
+
+// Peer returns the fake peer with the recorded ID.
+func (a *Replayer) Peer(id string) (*mock.Peer, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, ok := a.peers[id]
+	return p, ok
+}
+
+func (a *Replayer) connect(h Handler, id string, direction peer.Direction, addr proto.TCPAddr, handshake proto.Handshake) *mock.Peer {
+	p := &mock.Peer{
+		Addr:           id,
+		HandshakeField: handshake,
+		RemoteAddress:  addr,
+		DirectionField: direction,
+	}
+	a.mu.Lock()
+	a.peers[id] = p
+	a.mu.Unlock()
+	h.HandleInfoMessage(peer.InfoMessage{Peer: p, Value: &peer.Connected{Peer: p}})
+	return p
+}
+
+// addressFromID restores the IP address of peer from its ID, which is made of the IP and the node's nonce.
+func addressFromID(id string) proto.TCPAddr {
+	i := strings.LastIndex(id, "-")
+	if i < 0 {
+		return proto.TCPAddr{}
+	}
+	return proto.NewTCPAddr(net.ParseIP(id[:i]), 0)
+}
@@ -0,0 +1,229 @@
+// Package secure provides the encrypted and mutually authenticated transport between the Go nodes.
+//
+// The support of secure transport is advertised with the capability mark appended to the node name of handshake,
+// so the handshake stays compatible with the nodes that don't know about it. If both peers advertise the capability,
+// the connection is upgraded to TLS 1.3 right after the handshake. Each node is identified by its ed25519 node key,
+// the self-signed certificates are used only to transfer the keys and the peers are authenticated by their keys
+// against the allowlist. The capability mark is sent in plaintext and can be stripped by an attacker, so the transport
+// with allowlist never falls back to the plain connection.
+package secure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	// CapabilityMark is appended to the node name of handshake by the nodes supporting secure transport.
+	CapabilityMark = "+tls"
+	// maxNodeNameLength is the limit of the node name of handshake.
+	maxNodeNameLength = 255
+
+	handshakeTimeout = 30 * time.Second
+	pemType          = "PRIVATE KEY"
+)
+
+// Marked returns true if the handshake advertises the secure transport capability.
+func Marked(h proto.Handshake) bool {
+	return strings.HasSuffix(h.NodeName, CapabilityMark)
+}
+
+// Mark appends the capability mark to the node name, the marked name must fit into the handshake.
+func Mark(nodeName string) (string, error) {
+	r := nodeName + CapabilityMark
+	if len(r) > maxNodeNameLength {
+		return "", errors.Errorf("node name is too long to add secure transport mark: %d bytes, max %d bytes", len(nodeName), maxNodeNameLength-len(CapabilityMark))
+	}
+	return r, nil
+}
+
+// Transport upgrades the connections after handshake.
+type Transport struct {
+	key      ed25519.PrivateKey
+	cert     tls.Certificate
+	allowed  map[string]struct{}
+	required bool
+}
+
+// NewTransport creates the transport with the node key. Only peers with the keys from the allowlist are accepted,
+// the empty allowlist accepts any key. If secure transport is required, the peers that don't support it are rejected,
+// otherwise the plain connection is used with them. Secure transport is always required with non-empty allowlist,
+// otherwise the peer could bypass it by not advertising the capability.
+func NewTransport(key ed25519.PrivateKey, allowlist []ed25519.PublicKey, required bool) (*Transport, error) {
+	cert, err := selfSignedCertificate(key)
+	if err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]struct{}, len(allowlist))
+	for _, pk := range allowlist {
+		allowed[string(pk)] = struct{}{}
+	}
+	return &Transport{key: key, cert: cert, allowed: allowed, required: required || len(allowed) > 0}, nil
+}
+
+// PublicKey returns the public node key.
+func (a *Transport) PublicKey() ed25519.PublicKey {
+	return a.key.Public().(ed25519.PublicKey)
+}
+
+// Upgrade returns the connection to use after the handshake, the handshake sent by the node must be marked.
+// Client is the side that initiated the connection. If the peer's handshake is marked too,
+// TLS handshake is performed and the peer's key is checked.
+func (a *Transport) Upgrade(c net.Conn, remote proto.Handshake, client bool) (net.Conn, error) {
+	if !Marked(remote) {
+		if a.required {
+			return nil, errors.Errorf("peer '%s' doesn't support secure transport", remote.NodeName)
+		}
+		return c, nil
+	}
+	cfg := &tls.Config{
+		Certificates:          []tls.Certificate{a.cert},
+		MinVersion:            tls.VersionTLS13,
+		InsecureSkipVerify:    true, // Self-signed certificates are verified by VerifyPeerCertificate
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: a.verify,
+	}
+	var tc *tls.Conn
+	if client {
+		tc = tls.Client(c, cfg)
+	} else {
+		tc = tls.Server(c, cfg)
+	}
+	if err := c.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	if err := tc.Handshake(); err != nil {
+		return nil, errors.Wrap(err, "TLS handshake failed")
+	}
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+func (a *Transport) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no peer certificate")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return errors.Wrap(err, "invalid peer certificate")
+	}
+	pk, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return errors.New("peer key is not ed25519")
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		return errors.Wrap(err, "invalid peer certificate signature")
+	}
+	if len(a.allowed) == 0 {
+		return nil
+	}
+	if _, ok := a.allowed[string(pk)]; !ok {
+		return errors.Errorf("peer key %s is not allowed", base58.Encode(pk))
+	}
+	return nil
+}
+
+func selfSignedCertificate(key ed25519.PrivateKey) (tls.Certificate, error) {
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gowaves"},
+		NotBefore:             time.Unix(0, 0),
+		NotAfter:              time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "failed to create certificate")
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// LoadOrGenerateKey reads the node key from PEM file, a new key is generated and saved if the file doesn't exist.
+func LoadOrGenerateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der}), 0600); err != nil {
+			return nil, errors.Wrap(err, "failed to save node key")
+		}
+		return key, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read node key")
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, errors.Errorf("no private key in '%s'", path)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid node key")
+	}
+	key, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("node key is not ed25519")
+	}
+	return key, nil
+}
+
+// ParseAllowlist parses the comma separated list of base58 encoded public node keys.
+func ParseAllowlist(s string) ([]ed25519.PublicKey, error) {
+	var r []ed25519.PublicKey
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		b, err := base58.Decode(f)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, errors.Errorf("invalid node key '%s'", f)
+		}
+		r = append(r, ed25519.PublicKey(b))
+	}
+	return r, nil
+}
+
+// FromOptions creates the transport from the node's command line options, nil is returned if the key path is empty.
+func FromOptions(keyPath, allowlist string, required bool) (*Transport, error) {
+	keys, err := ParseAllowlist(allowlist)
+	if err != nil {
+		return nil, err
+	}
+	if keyPath == "" {
+		if required || len(keys) > 0 {
+			return nil, errors.New("node key is required for secure transport")
+		}
+		return nil, nil
+	}
+	key, err := LoadOrGenerateKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransport(key, keys, required)
+}
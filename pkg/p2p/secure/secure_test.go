@@ -0,0 +1,163 @@
+package secure
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func newTransport(t *testing.T, required bool, allowlist ...ed25519.PublicKey) *Transport {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	tr, err := NewTransport(key, allowlist, required)
+	require.NoError(t, err)
+	return tr
+}
+
+type result struct {
+	conn net.Conn
+	err  error
+}
+
+// tcpPair returns both ends of loopback TCP connection.
+func tcpPair(t *testing.T) (net.Conn, net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		_ = l.Close()
+	}()
+	c, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	s, err := l.Accept()
+	require.NoError(t, err)
+	return c, s
+}
+
+// upgrade upgrades both ends of the connection concurrently, like two nodes after handshake do.
+func upgrade(t *testing.T, client, server *Transport, clientName, serverName string) (result, result) {
+	c, s := tcpPair(t)
+	ch := make(chan result, 1)
+	go func() {
+		conn, err := server.Upgrade(s, proto.Handshake{NodeName: clientName}, false)
+		if err != nil {
+			_ = s.Close()
+		}
+		ch <- result{conn, err}
+	}()
+	conn, err := client.Upgrade(c, proto.Handshake{NodeName: serverName}, true)
+	if err != nil {
+		_ = c.Close()
+	}
+	return result{conn, err}, <-ch
+}
+
+func TestUpgrade(t *testing.T) {
+	client := newTransport(t, false)
+	server := newTransport(t, false, client.PublicKey())
+	cr, sr := upgrade(t, client, server, "client"+CapabilityMark, "server"+CapabilityMark)
+	require.NoError(t, cr.err)
+	require.NoError(t, sr.err)
+	go func() {
+		_, _ = cr.conn.Write([]byte("ping"))
+	}()
+	buf := make([]byte, 4)
+	_, err := io.ReadFull(sr.conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+	assert.IsType(t, &tls.Conn{}, cr.conn)
+}
+
+func TestUpgradeNotAllowed(t *testing.T) {
+	client := newTransport(t, false)
+	server := newTransport(t, false, newTransport(t, false).PublicKey())
+	_, sr := upgrade(t, client, server, "client"+CapabilityMark, "server"+CapabilityMark)
+	require.Error(t, sr.err)
+	assert.Contains(t, sr.err.Error(), "is not allowed")
+}
+
+func TestUpgradeUnmarked(t *testing.T) {
+	tr := newTransport(t, false)
+	c, s := net.Pipe()
+	defer func() {
+		_ = c.Close()
+		_ = s.Close()
+	}()
+	conn, err := tr.Upgrade(c, proto.Handshake{NodeName: "legacy"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, c, conn)
+
+	_, err = newTransport(t, true).Upgrade(c, proto.Handshake{NodeName: "legacy"}, true)
+	assert.Error(t, err)
+}
+
+// The mark is sent in plaintext, the transport with allowlist must not accept the peer with stripped mark.
+func TestUpgradeStrippedMarkWithAllowlist(t *testing.T) {
+	client := newTransport(t, false)
+	server := newTransport(t, false, client.PublicKey())
+	c, s := net.Pipe()
+	defer func() {
+		_ = c.Close()
+		_ = s.Close()
+	}()
+	_, err := server.Upgrade(s, proto.Handshake{NodeName: "client"}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't support secure transport")
+}
+
+func TestMark(t *testing.T) {
+	assert.True(t, Marked(proto.Handshake{NodeName: "gowaves" + CapabilityMark}))
+	assert.False(t, Marked(proto.Handshake{NodeName: "gowaves"}))
+
+	name := strings.Repeat("n", 255-len(CapabilityMark))
+	marked, err := Mark(name)
+	require.NoError(t, err)
+	assert.Equal(t, name+CapabilityMark, marked)
+	_, err = proto.NewU8String(marked).MarshalBinary()
+	require.NoError(t, err)
+	_, err = Mark(name + "n")
+	assert.Error(t, err)
+}
+
+func TestKeyAndAllowlist(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "secure")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "node.key")
+	key, err := LoadOrGenerateKey(path)
+	require.NoError(t, err)
+	loaded, err := LoadOrGenerateKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, key, loaded)
+
+	pk := key.Public().(ed25519.PublicKey)
+	keys, err := ParseAllowlist(" " + base58.Encode(pk) + ",,")
+	require.NoError(t, err)
+	assert.Equal(t, []ed25519.PublicKey{pk}, keys)
+	_, err = ParseAllowlist("abc")
+	assert.Error(t, err)
+
+	tr, err := FromOptions("", "", false)
+	require.NoError(t, err)
+	assert.Nil(t, tr)
+	_, err = FromOptions("", "", true)
+	assert.Error(t, err)
+	_, err = FromOptions("", base58.Encode(pk), false)
+	assert.Error(t, err)
+	tr, err = FromOptions(path, base58.Encode(pk), true)
+	require.NoError(t, err)
+	assert.Equal(t, pk, tr.PublicKey())
+}
@@ -0,0 +1,161 @@
+package proto
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+const fieldsHashesNumber = 9
+
+// FieldsHashes are the hashes of the state changes of a block, one hash per kind of state entries.
+// The order of fields follows the order of sections of the legacy state hash of Scala node.
+type FieldsHashes struct {
+	WavesBalanceHash  crypto.Digest
+	AssetBalanceHash  crypto.Digest
+	DataEntryHash     crypto.Digest
+	AccountScriptHash crypto.Digest
+	AssetScriptHash   crypto.Digest
+	LeaseBalanceHash  crypto.Digest
+	LeaseStatusHash   crypto.Digest
+	SponsorshipHash   crypto.Digest
+	AliasesHash       crypto.Digest
+}
+
+func (s *FieldsHashes) hashes() [fieldsHashesNumber]*crypto.Digest {
+	return [fieldsHashesNumber]*crypto.Digest{
+		&s.WavesBalanceHash,
+		&s.AssetBalanceHash,
+		&s.DataEntryHash,
+		&s.AccountScriptHash,
+		&s.AssetScriptHash,
+		&s.LeaseBalanceHash,
+		&s.LeaseStatusHash,
+		&s.SponsorshipHash,
+		&s.AliasesHash,
+	}
+}
+
+// StateHash is a fingerprint of the state after applying the block.
+// SumHash accumulates the hashes of all previous blocks, so equal SumHashes mean equal states.
+type StateHash struct {
+	BlockID BlockID
+	SumHash crypto.Digest
+	FieldsHashes
+}
+
+func (s *StateHash) allHashes() [fieldsHashesNumber + 1]*crypto.Digest {
+	fh := s.hashes()
+	res := [fieldsHashesNumber + 1]*crypto.Digest{&s.SumHash}
+	copy(res[1:], fh[:])
+	return res
+}
+
+// GenerateSumHash calculates SumHash from the SumHash of the previous block and the fields hashes.
+// Empty prevSumHash should be used for the first block.
+func (s *StateHash) GenerateSumHash(prevSumHash []byte) error {
+	data := make([]byte, 0, len(prevSumHash)+fieldsHashesNumber*crypto.DigestSize)
+	data = append(data, prevSumHash...)
+	for _, d := range s.hashes() {
+		data = append(data, d[:]...)
+	}
+	h, err := crypto.FastHash(data)
+	if err != nil {
+		return err
+	}
+	s.SumHash = h
+	return nil
+}
+
+func (s *StateHash) MarshalBinary() ([]byte, error) {
+	idBytes := s.BlockID.Bytes()
+	res := make([]byte, 1+len(idBytes)+crypto.DigestSize*(fieldsHashesNumber+1))
+	res[0] = byte(len(idBytes))
+	pos := 1
+	pos += copy(res[pos:], idBytes)
+	for _, d := range s.allHashes() {
+		pos += copy(res[pos:], d[:])
+	}
+	return res, nil
+}
+
+func (s *StateHash) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 {
+		return errors.New("invalid data size")
+	}
+	idLen := int(data[0])
+	if len(data) != 1+idLen+crypto.DigestSize*(fieldsHashesNumber+1) {
+		return errors.New("invalid data size")
+	}
+	id, err := NewBlockIDFromBytes(data[1 : 1+idLen])
+	if err != nil {
+		return err
+	}
+	s.BlockID = id
+	pos := 1 + idLen
+	for _, d := range s.allHashes() {
+		pos += copy(d[:], data[pos:])
+	}
+	return nil
+}
+
+type stateHashJS struct {
+	BlockID           BlockID `json:"blockId"`
+	SumHash           string  `json:"stateHash"`
+	WavesBalanceHash  string  `json:"wavesBalanceHash"`
+	AssetBalanceHash  string  `json:"assetBalanceHash"`
+	DataEntryHash     string  `json:"dataEntryHash"`
+	AccountScriptHash string  `json:"accountScriptHash"`
+	AssetScriptHash   string  `json:"assetScriptHash"`
+	LeaseBalanceHash  string  `json:"leaseBalanceHash"`
+	LeaseStatusHash   string  `json:"leaseStatusHash"`
+	SponsorshipHash   string  `json:"sponsorshipHash"`
+	AliasesHash       string  `json:"aliasHash"`
+}
+
+func (s *stateHashJS) hashes() [fieldsHashesNumber + 1]*string {
+	return [fieldsHashesNumber + 1]*string{
+		&s.SumHash,
+		&s.WavesBalanceHash,
+		&s.AssetBalanceHash,
+		&s.DataEntryHash,
+		&s.AccountScriptHash,
+		&s.AssetScriptHash,
+		&s.LeaseBalanceHash,
+		&s.LeaseStatusHash,
+		&s.SponsorshipHash,
+		&s.AliasesHash,
+	}
+}
+
+// MarshalJSON produces the same JSON as the Scala node, hashes are encoded in hex.
+func (s StateHash) MarshalJSON() ([]byte, error) {
+	js := stateHashJS{BlockID: s.BlockID}
+	src := s.allHashes()
+	for i, dst := range js.hashes() {
+		*dst = hex.EncodeToString(src[i][:])
+	}
+	return json.Marshal(js)
+}
+
+func (s *StateHash) UnmarshalJSON(value []byte) error {
+	var js stateHashJS
+	if err := json.Unmarshal(value, &js); err != nil {
+		return err
+	}
+	s.BlockID = js.BlockID
+	dst := s.allHashes()
+	for i, src := range js.hashes() {
+		b, err := hex.DecodeString(*src)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode state hash")
+		}
+		if len(b) != crypto.DigestSize {
+			return errors.Errorf("invalid state hash length %d", len(b))
+		}
+		copy(dst[i][:], b)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package proto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+func createStateHash(t *testing.T) StateHash {
+	id, err := NewBlockIDFromBase58("2GNCYVy7k3kEPXzz12saMtRDeXFKr8cymVsG8Yxx3sZZ75eHj9csfXnGHuuJe7XawbcwjKdifUrV1uMq4ZNCWPf1")
+	require.NoError(t, err)
+	sh := StateHash{BlockID: id}
+	for i, d := range sh.hashes() {
+		h, err := crypto.FastHash([]byte{byte(i)})
+		require.NoError(t, err)
+		*d = h
+	}
+	require.NoError(t, sh.GenerateSumHash(nil))
+	return sh
+}
+
+func TestStateHash_GenerateSumHash(t *testing.T) {
+	empty, err := crypto.FastHash(nil)
+	require.NoError(t, err)
+	sh := StateHash{}
+	for _, d := range sh.hashes() {
+		*d = empty
+	}
+	prev := []byte{1, 2, 3}
+	require.NoError(t, sh.GenerateSumHash(prev))
+	data := prev
+	for i := 0; i < fieldsHashesNumber; i++ {
+		data = append(data, empty[:]...)
+	}
+	expected, err := crypto.FastHash(data)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sh.SumHash)
+
+	require.NoError(t, sh.GenerateSumHash(nil))
+	assert.NotEqual(t, expected, sh.SumHash)
+}
+
+func TestStateHash_BinaryRoundTrip(t *testing.T) {
+	sh := createStateHash(t)
+	data, err := sh.MarshalBinary()
+	require.NoError(t, err)
+	var sh2 StateHash
+	require.NoError(t, sh2.UnmarshalBinary(data))
+	assert.Equal(t, sh, sh2)
+	assert.Error(t, sh2.UnmarshalBinary(data[1:]))
+}
+
+func TestStateHash_JSONRoundTrip(t *testing.T) {
+	sh := createStateHash(t)
+	data, err := json.Marshal(sh)
+	require.NoError(t, err)
+	var fields map[string]string
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, sh.BlockID.String(), fields["blockId"])
+	assert.Equal(t, "03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314", fields["wavesBalanceHash"])
+	assert.Len(t, fields, fieldsHashesNumber+2)
+	var sh2 StateHash
+	require.NoError(t, json.Unmarshal(data, &sh2))
+	assert.Equal(t, sh, sh2)
+}
@@ -2551,6 +2551,104 @@ func (s ScriptInfo) ToProtobuf() *g.ScriptData {
 	}
 }
 
+// ScriptHistoryEntry describes the script set to account or asset at some height.
+// Empty script means that the script was removed.
+type ScriptHistoryEntry struct {
+	Height        uint64         `json:"height"`
+	TransactionID *crypto.Digest `json:"transactionId,omitempty"`
+	Version       int32          `json:"version"`
+	Bytes         []byte         `json:"-"`
+	Base64        string         `json:"script"`
+	Complexity    uint64         `json:"complexity"`
+	Text          string         `json:"text"`
+}
+
+// CallableFuncArgument is the argument of dApp's callable function.
+// Type is the names of accepted types joined with "|", or "Any" if the dApp has no meta.
+type CallableFuncArgument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// CallableFuncSignature is the name and the arguments of dApp's callable function.
+type CallableFuncSignature struct {
+	Name      string                 `json:"name"`
+	Arguments []CallableFuncArgument `json:"args"`
+}
+
+// ScriptMeta describes the callable functions of the account's script and the presence of its verifier.
+// Script of expression type has no callable functions and is a verifier itself.
+type ScriptMeta struct {
+	Address   Address                 `json:"address"`
+	Version   int32                   `json:"version"`
+	Callables []CallableFuncSignature `json:"callableFuncTypes"`
+	Verifier  bool                    `json:"verifier"`
+}
+
+// ScriptFailureReason is the error of failed script executions and the number of such failures.
+type ScriptFailureReason struct {
+	Reason string `json:"reason"`
+	Count  uint64 `json:"count"`
+}
+
+// ScriptStats is the statistics of account or asset script executions in the window of last blocks.
+// Invocations include failures, complexities are of successful executions only.
+// The node collects the statistics since its start, the window is complete if FromHeight is not below CollectedFrom.
+type ScriptStats struct {
+	Window            uint64                `json:"window"`
+	FromHeight        uint64                `json:"fromHeight"`
+	ToHeight          uint64                `json:"toHeight"`
+	CollectedFrom     uint64                `json:"collectedFrom"`
+	Invocations       uint64                `json:"invocations"`
+	Failures          uint64                `json:"failures"`
+	FailureReasons    []ScriptFailureReason `json:"failureReasons"`
+	AverageComplexity uint64                `json:"averageComplexity"`
+	MaxComplexity     uint64                `json:"maxComplexity"`
+	DistinctCallers   uint64                `json:"distinctCallers"`
+}
+
+// LeaseStatus is the state of the lease, the lease is cancelled by lease cancel transaction or by the node itself.
+type LeaseStatus string
+
+const (
+	LeaseActive   LeaseStatus = "active"
+	LeaseCanceled LeaseStatus = "canceled"
+)
+
+// LeaseInfo describes the lease created by the transaction with the same ID.
+// Leases cancelled by the node have the cancel height but no cancel transaction.
+type LeaseInfo struct {
+	ID                  crypto.Digest  `json:"id"`
+	Sender              Address        `json:"sender"`
+	Recipient           Address        `json:"recipient"`
+	Amount              uint64         `json:"amount"`
+	Status              LeaseStatus    `json:"status"`
+	Height              uint64         `json:"height"`
+	CancelHeight        uint64         `json:"cancelHeight,omitempty"`
+	CancelTransactionID *crypto.Digest `json:"cancelTransactionId,omitempty"`
+}
+
+// SponsorshipHistoryEntry describes the minimal fee in the sponsored asset set at some height.
+// Zero fee means that the sponsorship was cancelled.
+type SponsorshipHistoryEntry struct {
+	Height        uint64         `json:"height"`
+	TransactionID *crypto.Digest `json:"transactionId,omitempty"`
+	MinAssetFee   uint64         `json:"minSponsoredAssetFee"`
+}
+
+// SponsorshipInfo describes the fee sponsorship of the asset.
+// The sponsor is the issuer of the asset, it pays the fees in Waves from its available balance.
+type SponsorshipInfo struct {
+	AssetID               crypto.Digest             `json:"assetId"`
+	Sponsor               Address                   `json:"sponsor"`
+	Sponsored             bool                      `json:"sponsored"`
+	MinAssetFee           uint64                    `json:"minSponsoredAssetFee"`
+	SponsorBalance        uint64                    `json:"sponsorBalance"`
+	CanCoverFees          bool                      `json:"canCoverFees"`
+	SponsoredTransactions uint64                    `json:"sponsoredTransactions"`
+	History               []SponsorshipHistoryEntry `json:"history"`
+}
+
 func VersionFromScriptBytes(scriptBytes []byte) (int32, error) {
 	if len(scriptBytes) == 0 {
 		// No script has 0 version.
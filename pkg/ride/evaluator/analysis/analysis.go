@@ -0,0 +1,544 @@
+// Package analysis inspects RIDE scripts for mistakes that don't prevent the script from being compiled and evaluated.
+package analysis
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/estimation"
+)
+
+// Severity of the issue, scripts with errors should not be used.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Names of checks.
+const (
+	ConstantVerifier   = "constant-verifier"
+	UnusedDeclaration  = "unused-declaration"
+	UnguardedExtract   = "unguarded-extract"
+	UnreachableCase    = "unreachable-case"
+	DeprecatedFunction = "deprecated-function"
+	Complexity         = "complexity"
+)
+
+// Issue is a single finding, Function is the verifier, callable or user function the issue is found in.
+type Issue struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Function string   `json:"function,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// Costs are the estimated complexities of the script and the limit for its version.
+type Costs struct {
+	Verifier  uint64            `json:"verifier"`
+	DApp      uint64            `json:"dApp,omitempty"`
+	Callables map[string]uint64 `json:"callables,omitempty"`
+	Limit     uint64            `json:"limit"`
+}
+
+// Report is the result of analysis, Complexity is nil if the script can't be estimated.
+type Report struct {
+	Version    int     `json:"version"`
+	DApp       bool    `json:"dApp"`
+	Issues     []Issue `json:"issues"`
+	Complexity *Costs  `json:"complexity,omitempty"`
+}
+
+// Count returns the number of issues of the severity.
+func (r Report) Count(s Severity) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+const verifierName = "verifier"
+
+// Functions returning Unit if the value is absent, by the name in script.
+var optionalFunctions = map[string]string{
+	"1000":              "transactionById",
+	"1001":              "transactionHeightById",
+	"1004":              "assetInfo",
+	"1005":              "blockInfoByHeight",
+	"1006":              "transferTransactionById",
+	"1040":              "getInteger",
+	"1041":              "getBoolean",
+	"1042":              "getBinary",
+	"1043":              "getString",
+	"1050":              "getInteger",
+	"1051":              "getBoolean",
+	"1052":              "getBinary",
+	"1053":              "getString",
+	"1203":              "indexOf",
+	"1204":              "indexOf",
+	"1206":              "parseInt",
+	"1207":              "lastIndexOf",
+	"1208":              "lastIndexOf",
+	"getInteger":        "getInteger",
+	"getBoolean":        "getBoolean",
+	"getBinary":         "getBinary",
+	"getString":         "getString",
+	"addressFromString": "addressFromString",
+}
+
+// Fields of transactions and invocation which may be Unit.
+var optionalFields = map[string]bool{
+	"payment":           true,
+	"assetId":           true,
+	"feeAssetId":        true,
+	"matcherFeeAssetId": true,
+	"script":            true,
+}
+
+type deprecation struct {
+	name        string
+	since       int
+	replacement string
+}
+
+var deprecatedFunctions = map[string]deprecation{
+	"1000": {name: "transactionById", since: 3, replacement: "transferTransactionById"},
+}
+
+// Maximal complexity of verifier or callable function by the script version.
+func complexityLimit(version int) uint64 {
+	if version < 3 {
+		return 2000
+	}
+	return 4000
+}
+
+// Analyze runs all checks on the script.
+func Analyze(script *ast.Script) Report {
+	a := &analyzer{version: script.Version}
+	if script.IsDapp() {
+		a.version = 3
+		a.dApp(script.DApp)
+	} else {
+		a.function = verifierName
+		a.constant(script.Verifier)
+		a.walk(script.Verifier, &scope{}, nil)
+	}
+	costs := a.complexity(script)
+	r := Report{Version: script.Version, DApp: script.IsDapp(), Issues: a.issues, Complexity: costs}
+	if r.Issues == nil {
+		r.Issues = []Issue{}
+	}
+	return r
+}
+
+type declaration struct {
+	name  string
+	fn    bool
+	value ast.Expr
+	used  bool
+}
+
+type scope struct {
+	parent       *scope
+	declarations []*declaration
+}
+
+func (s *scope) child(ds ...*declaration) *scope {
+	return &scope{parent: s, declarations: ds}
+}
+
+func (s *scope) lookup(name string, fn bool) *declaration {
+	for ; s != nil; s = s.parent {
+		for i := len(s.declarations) - 1; i >= 0; i-- {
+			if d := s.declarations[i]; d.name == name && d.fn == fn {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+type analyzer struct {
+	version  int
+	function string
+	issues   []Issue
+}
+
+func (a *analyzer) report(check string, severity Severity, format string, args ...interface{}) {
+	a.issues = append(a.issues, Issue{Check: check, Severity: severity, Function: a.function, Message: fmt.Sprintf(format, args...)})
+}
+
+func (a *analyzer) dApp(dApp ast.DApp) {
+	globals := &scope{}
+	for _, e := range dApp.Declarations {
+		a.function = ""
+		switch d := e.(type) {
+		case *ast.LetExpr:
+			a.walk(d.Value, globals, nil)
+			globals.declarations = append(globals.declarations, &declaration{name: d.Name, value: d.Value})
+		case *ast.FuncDeclaration:
+			a.function = d.Name
+			a.walk(d.Body, globals.child(arguments(d.Args)...), nil)
+			globals.declarations = append(globals.declarations, &declaration{name: d.Name, fn: true})
+		}
+	}
+	names := make([]string, 0, len(dApp.CallableFuncs))
+	for name := range dApp.CallableFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fn := dApp.CallableFuncs[name]
+		a.function = name
+		args := append(arguments(fn.FuncDecl.Args), &declaration{name: fn.AnnotationInvokeName})
+		a.walk(fn.FuncDecl.Body, globals.child(args...), nil)
+	}
+	if fn := dApp.Verifier; fn != nil {
+		a.function = verifierName
+		a.constant(fn.FuncDecl.Body)
+		a.walk(fn.FuncDecl.Body, globals.child(arguments([]string{fn.AnnotationInvokeName})...), nil)
+	}
+	a.function = ""
+	a.unused(globals.declarations)
+}
+
+func arguments(names []string) []*declaration {
+	r := make([]*declaration, len(names))
+	for i, n := range names {
+		r[i] = &declaration{name: n}
+	}
+	return r
+}
+
+func (a *analyzer) unused(ds []*declaration) {
+	for _, d := range ds {
+		if d.used || strings.HasPrefix(d.name, "$") {
+			continue
+		}
+		if d.fn {
+			a.report(UnusedDeclaration, Warning, "function '%s' is never called", d.name)
+		} else {
+			a.report(UnusedDeclaration, Warning, "variable '%s' is never used", d.name)
+		}
+	}
+}
+
+// walk checks the expression, guards are the keys of expressions checked to be defined on the path to the expression.
+func (a *analyzer) walk(e ast.Expr, s *scope, guards []string) {
+	switch t := e.(type) {
+	case *ast.RefExpr:
+		if d := s.lookup(t.Name, false); d != nil {
+			d.used = true
+		}
+	case *ast.Block:
+		a.let(t.Let, t.Body, s, guards)
+	case *ast.BlockV2:
+		switch d := t.Decl.(type) {
+		case *ast.LetExpr:
+			a.let(d, t.Body, s, guards)
+		case *ast.FuncDeclaration:
+			outer := a.function
+			a.function = d.Name
+			a.walk(d.Body, s.child(arguments(d.Args)...), nil)
+			a.function = outer
+			fn := &declaration{name: d.Name, fn: true}
+			a.walk(t.Body, s.child(fn), guards)
+			a.unused([]*declaration{fn})
+		}
+	case *ast.FuncCallExpr:
+		a.walk(t.Func, s, guards)
+	case *ast.FunctionCall:
+		a.call(t, s, guards)
+	case *ast.IfExpr:
+		a.walk(t.Condition, s, guards)
+		a.walk(t.True, s, append(guards[:len(guards):len(guards)], defined(t.Condition, true)...))
+		a.walk(t.False, s, append(guards[:len(guards):len(guards)], defined(t.Condition, false)...))
+	case *ast.GetterExpr:
+		a.walk(t.Object, s, guards)
+	}
+}
+
+func (a *analyzer) let(let *ast.LetExpr, body ast.Expr, s *scope, guards []string) {
+	a.walk(let.Value, s, guards)
+	if strings.HasPrefix(let.Name, "$match") {
+		a.match(let.Name, body)
+	}
+	d := &declaration{name: let.Name, value: let.Value}
+	a.walk(body, s.child(d), guards)
+	a.unused([]*declaration{d})
+}
+
+func (a *analyzer) call(call *ast.FunctionCall, s *scope, guards []string) {
+	if d := s.lookup(call.Name, true); d != nil {
+		d.used = true
+	} else if dep, ok := deprecatedFunctions[call.Name]; ok && a.version >= dep.since {
+		a.report(DeprecatedFunction, Error, "function %s is deprecated since version %d, use %s", dep.name, dep.since, dep.replacement)
+	}
+	if (call.Name == "extract" || call.Name == "value") && len(call.Argv) == 1 {
+		arg := call.Argv[0]
+		if what, ok := a.optional(arg, s, guards); ok {
+			a.report(UnguardedExtract, Warning, "%s() is called on %s without isDefined() check", call.Name, what)
+		}
+	}
+	for _, arg := range call.Argv {
+		a.walk(arg, s, guards)
+	}
+}
+
+// optional tells if the expression may be Unit and isn't guarded, the description of expression is returned.
+func (a *analyzer) optional(e ast.Expr, s *scope, guards []string) (string, bool) {
+	if guarded(e, guards) {
+		return "", false
+	}
+	switch t := e.(type) {
+	case *ast.FuncCallExpr:
+		return a.optional(t.Func, s, guards)
+	case *ast.FunctionCall:
+		if s.lookup(t.Name, true) != nil {
+			return "", false
+		}
+		if name, ok := optionalFunctions[t.Name]; ok {
+			return fmt.Sprintf("the result of %s()", name), true
+		}
+	case *ast.GetterExpr:
+		if optionalFields[t.Key] {
+			return fmt.Sprintf("optional field '%s'", t.Key), true
+		}
+	case *ast.RefExpr:
+		if d := s.lookup(t.Name, false); d != nil && d.value != nil {
+			if what, ok := a.optional(d.value, s, guards); ok {
+				return fmt.Sprintf("variable '%s' holding %s", t.Name, what), true
+			}
+		}
+	}
+	return "", false
+}
+
+// match checks the chain of cases of the match expression, the case is unreachable if all its types are matched before.
+func (a *analyzer) match(name string, body ast.Expr) {
+	matched := make(map[string]bool)
+	for {
+		cond, ok := body.(*ast.IfExpr)
+		if !ok {
+			return
+		}
+		types := instanceOf(cond.Condition, name)
+		if len(types) == 0 {
+			return
+		}
+		unreachable := true
+		for _, t := range types {
+			if !matched[t] {
+				unreachable = false
+			}
+			matched[t] = true
+		}
+		if unreachable {
+			a.report(UnreachableCase, Warning, "case %s is unreachable, the types are matched by previous cases", strings.Join(types, "|"))
+		}
+		body = cond.False
+	}
+}
+
+// instanceOf returns the types the match variable is checked against in the condition of case.
+func instanceOf(e ast.Expr, name string) []string {
+	switch t := e.(type) {
+	case *ast.FuncCallExpr:
+		return instanceOf(t.Func, name)
+	case *ast.FunctionCall:
+		if t.Name != "1" || len(t.Argv) != 2 {
+			return nil
+		}
+		ref, ok := t.Argv[0].(*ast.RefExpr)
+		if !ok || ref.Name != name {
+			return nil
+		}
+		typ, ok := t.Argv[1].(*ast.StringExpr)
+		if !ok {
+			return nil
+		}
+		return []string{typ.Value}
+	case *ast.IfExpr:
+		// Case of several types is compiled to "if (isInstanceOf(A)) then true else isInstanceOf(B)".
+		if b, ok := t.True.(*ast.BooleanExpr); ok && b.Value {
+			first := instanceOf(t.Condition, name)
+			rest := instanceOf(t.False, name)
+			if len(first) == 0 || len(rest) == 0 {
+				return nil
+			}
+			return append(first, rest...)
+		}
+	}
+	return nil
+}
+
+// defined returns the keys of expressions which are defined if the condition evaluates to value.
+func defined(cond ast.Expr, value bool) []string {
+	switch t := cond.(type) {
+	case *ast.FuncCallExpr:
+		return defined(t.Func, value)
+	case *ast.FunctionCall:
+		switch {
+		case t.Name == "isDefined" && len(t.Argv) == 1 && value:
+			return []string{key(t.Argv[0])}
+		case t.Name == "!" && len(t.Argv) == 1:
+			return defined(t.Argv[0], !value)
+		case t.Name == "!=" && len(t.Argv) == 2 && value, t.Name == "0" && len(t.Argv) == 2 && !value:
+			if isUnit(t.Argv[1]) {
+				return []string{key(t.Argv[0])}
+			}
+			if isUnit(t.Argv[0]) {
+				return []string{key(t.Argv[1])}
+			}
+		}
+	case *ast.IfExpr:
+		// "A && B" is compiled to "if (A) then B else false", both are true if it's true.
+		if b, ok := t.False.(*ast.BooleanExpr); ok && !b.Value && value {
+			return append(defined(t.Condition, true), defined(t.True, true)...)
+		}
+	}
+	return nil
+}
+
+func isUnit(e ast.Expr) bool {
+	ref, ok := e.(*ast.RefExpr)
+	return ok && ref.Name == "unit"
+}
+
+func guarded(e ast.Expr, guards []string) bool {
+	k := key(e)
+	for _, g := range guards {
+		if g == k {
+			return true
+		}
+	}
+	return false
+}
+
+func key(e ast.Expr) string {
+	b := new(bytes.Buffer)
+	e.Write(b)
+	return b.String()
+}
+
+// constant reports the verifier which result doesn't depend on the transaction.
+func (a *analyzer) constant(verifier ast.Expr) {
+	v, ok := fold(verifier)
+	if !ok {
+		return
+	}
+	if v {
+		a.report(ConstantVerifier, Error, "verifier always returns true, any transaction is allowed")
+	} else {
+		a.report(ConstantVerifier, Warning, "verifier always returns false, all transactions are rejected")
+	}
+}
+
+// fold returns the result of boolean expression if it's known without evaluation. Thrown exception is false.
+func fold(e ast.Expr) (bool, bool) {
+	switch t := e.(type) {
+	case *ast.BooleanExpr:
+		return t.Value, true
+	case *ast.Block:
+		return fold(t.Body)
+	case *ast.BlockV2:
+		return fold(t.Body)
+	case *ast.FuncCallExpr:
+		return fold(t.Func)
+	case *ast.FunctionCall:
+		switch t.Name {
+		case "2", "throw":
+			return false, true
+		case "!":
+			if len(t.Argv) == 1 {
+				if v, ok := fold(t.Argv[0]); ok {
+					return !v, true
+				}
+			}
+		case "0", "!=":
+			if len(t.Argv) == 2 && literal(t.Argv[0]) && literal(t.Argv[1]) {
+				eq := t.Argv[0].Eq(t.Argv[1])
+				return eq == (t.Name == "0"), true
+			}
+		}
+	case *ast.IfExpr:
+		if c, ok := fold(t.Condition); ok {
+			if c {
+				return fold(t.True)
+			}
+			return fold(t.False)
+		}
+		yes, ok1 := fold(t.True)
+		no, ok2 := fold(t.False)
+		if ok1 && ok2 && yes == no {
+			return yes, true
+		}
+	}
+	return false, false
+}
+
+func literal(e ast.Expr) bool {
+	switch e.(type) {
+	case *ast.LongExpr, *ast.StringExpr, *ast.BooleanExpr, *ast.BytesExpr:
+		return true
+	}
+	return false
+}
+
+// complexity estimates the script and reports the functions exceeding the limit and the most complex callables of dApp.
+func (a *analyzer) complexity(script *ast.Script) *Costs {
+	variables, catalogue := ast.VariablesV3(), estimation.NewCatalogueV3()
+	if a.version < 3 {
+		variables, catalogue = ast.VariablesV2(), estimation.NewCatalogueV2()
+	}
+	costs, err := estimation.NewEstimator(2, catalogue, variables).Estimate(script)
+	if err != nil {
+		a.function = ""
+		a.report(Complexity, Warning, "failed to estimate complexity: %v", err)
+		return nil
+	}
+	r := &Costs{Verifier: costs.Verifier, Limit: complexityLimit(a.version)}
+	if script.IsDapp() {
+		r.DApp = costs.DApp
+		r.Callables = costs.Functions
+	}
+	check := func(name string, cost uint64) {
+		a.function = name
+		if cost > r.Limit {
+			a.report(Complexity, Error, "complexity %d exceeds the limit %d", cost, r.Limit)
+		}
+	}
+	if !script.IsDapp() || script.DApp.Verifier != nil {
+		check(verifierName, costs.Verifier)
+	}
+	names := make([]string, 0, len(costs.Functions))
+	for name := range costs.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var max uint64
+	for _, name := range names {
+		check(name, costs.Functions[name])
+		if costs.Functions[name] > max {
+			max = costs.Functions[name]
+		}
+	}
+	if len(names) > 1 {
+		for _, name := range names {
+			if costs.Functions[name] == max {
+				a.function = name
+				a.report(Complexity, Info, "callable has the highest complexity %d of %d", max, r.Limit)
+			}
+		}
+	}
+	return r
+}
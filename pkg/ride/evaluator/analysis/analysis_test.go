@@ -0,0 +1,135 @@
+package analysis
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+)
+
+func ref(name string) *ast.RefExpr {
+	return &ast.RefExpr{Name: name}
+}
+
+func call(name string, args ...ast.Expr) *ast.FuncCallExpr {
+	return ast.NewFuncCall(ast.NewFunctionCall(name, args))
+}
+
+func let(name string, value, body ast.Expr) *ast.BlockV2 {
+	return &ast.BlockV2{Decl: ast.NewLet(name, value), Body: body}
+}
+
+func analyze(version int, verifier ast.Expr) []Issue {
+	r := Analyze(&ast.Script{Version: version, HasBlockV2: true, Verifier: verifier})
+	issues := make([]Issue, 0, len(r.Issues))
+	for _, i := range r.Issues {
+		if i.Check != Complexity {
+			issues = append(issues, i)
+		}
+	}
+	return issues
+}
+
+func TestConstantVerifier(t *testing.T) {
+	issues := analyze(3, let("x", ast.NewLong(1), call("0", ast.NewLong(1), ref("x"))))
+	assert.Empty(t, issues)
+
+	issues = analyze(3, ast.NewIf(call("102", ref("height"), ast.NewLong(5)), ast.NewBoolean(true), call("!", ast.NewBoolean(false))))
+	require.Len(t, issues, 1)
+	assert.Equal(t, Issue{Check: ConstantVerifier, Severity: Error, Function: "verifier", Message: "verifier always returns true, any transaction is allowed"}, issues[0])
+
+	issues = analyze(3, ast.NewIf(call("102", ref("height"), ast.NewLong(5)), call("2", ast.NewString("no")), call("!=", ast.NewString("a"), ast.NewString("a"))))
+	require.Len(t, issues, 1)
+	assert.Equal(t, ConstantVerifier, issues[0].Check)
+	assert.Equal(t, Warning, issues[0].Severity)
+}
+
+func TestUnusedDeclarations(t *testing.T) {
+	// func f(a) = a > 1; func g(b) = f(b); let unused = 1; let $match0 = height; g(height)
+	f := &ast.FuncDeclaration{Name: "f", Args: []string{"a"}, Body: call("102", ref("a"), ast.NewLong(1))}
+	g := &ast.FuncDeclaration{Name: "g", Args: []string{"b"}, Body: call("f", ref("b"))}
+	unusedFn := &ast.FuncDeclaration{Name: "h", Args: []string{}, Body: ast.NewBoolean(true)}
+	script := &ast.BlockV2{Decl: f, Body: &ast.BlockV2{Decl: g, Body: &ast.BlockV2{Decl: unusedFn,
+		Body: let("unused", ast.NewLong(1), let("$match0", ref("height"), call("g", ref("height"))))}}}
+	issues := analyze(3, script)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "variable 'unused' is never used", issues[0].Message)
+	assert.Equal(t, "function 'h' is never called", issues[1].Message)
+}
+
+func TestUnguardedExtract(t *testing.T) {
+	value := call("1050", ref("this"), ast.NewString("k"))
+	issues := analyze(3, let("v", value, call("0", call("extract", ref("v")), ast.NewLong(1))))
+	require.Len(t, issues, 1)
+	assert.Equal(t, Issue{Check: UnguardedExtract, Severity: Warning, Function: "verifier",
+		Message: "extract() is called on variable 'v' holding the result of getInteger() without isDefined() check"}, issues[0])
+
+	issues = analyze(3, call("0", call("value", call("getString", ref("this"), ast.NewString("k"))), ast.NewString("a")))
+	require.Len(t, issues, 1)
+	assert.Equal(t, "value() is called on the result of getString() without isDefined() check", issues[0].Message)
+
+	// if (isDefined(v) && height > 1) then extract(v) == 1 else false
+	guard := ast.NewIf(call("isDefined", ref("v")), call("102", ref("height"), ast.NewLong(1)), ast.NewBoolean(false))
+	issues = analyze(3, let("v", value, ast.NewIf(guard, call("0", call("extract", ref("v")), ast.NewLong(1)), ast.NewBoolean(false))))
+	assert.Empty(t, issues)
+
+	// if (v == unit) then false else extract(v) == 1
+	issues = analyze(3, let("v", value, ast.NewIf(call("0", ref("v"), ref("unit")), ast.NewBoolean(false), call("0", call("extract", ref("v")), ast.NewLong(1)))))
+	assert.Empty(t, issues)
+
+	// Guard of the other value doesn't help.
+	issues = analyze(3, let("v", value, ast.NewIf(call("isDefined", ref("height")), call("0", call("extract", ref("v")), ast.NewLong(1)), ast.NewBoolean(false))))
+	assert.Len(t, issues, 1)
+}
+
+func TestUnreachableCase(t *testing.T) {
+	isInstanceOf := func(typ string) ast.Expr {
+		return call("1", ref("$match0"), ast.NewString(typ))
+	}
+	// match tx { case _: TransferTransaction | DataTransaction => true; case _: DataTransaction => false; case _ => false }
+	cases := ast.NewIf(ast.NewIf(isInstanceOf("TransferTransaction"), ast.NewBoolean(true), isInstanceOf("DataTransaction")),
+		ast.NewBoolean(true),
+		ast.NewIf(isInstanceOf("DataTransaction"), ast.NewBoolean(false), call("!", ast.NewBoolean(false))))
+	issues := analyze(3, let("$match0", ref("tx"), cases))
+	require.Len(t, issues, 1)
+	assert.Equal(t, Issue{Check: UnreachableCase, Severity: Warning, Function: "verifier",
+		Message: "case DataTransaction is unreachable, the types are matched by previous cases"}, issues[0])
+}
+
+func TestDeprecatedFunction(t *testing.T) {
+	verifier := call("isDefined", call("1000", ast.NewBytes([]byte{1, 2, 3})))
+	assert.Empty(t, analyze(2, verifier))
+	issues := analyze(3, verifier)
+	require.Len(t, issues, 1)
+	assert.Equal(t, Issue{Check: DeprecatedFunction, Severity: Error, Function: "verifier",
+		Message: "function transactionById is deprecated since version 3, use transferTransactionById"}, issues[0])
+}
+
+func TestComplexity(t *testing.T) {
+	// Two calls of user function are more complex than one.
+	// {-# CONTENT_TYPE DAPP #-}
+	// func f(a: String) = a + a
+	// let unused = 1
+	// @Callable(i) func one(a: String) = WriteSet([DataEntry("k", f(a))])
+	// @Callable(i) func two(a: String) = WriteSet([DataEntry("k", f(f(a)))])
+	const dApp = "AAIDAAAAAAAAAAAAAAACAQAAAAFmAAAAAQAAAAFhCQABLAAAAAIFAAAAAWEFAAAAAWEAAAAABnVudXNlZAAAAAAAAAAAAQAAAAIAAAABaQEAAAADb25lAAAAAQAAAAFhCQEAAAAIV3JpdGVTZXQAAAABCQAETAAAAAIJAQAAAAlEYXRhRW50cnkAAAACAgAAAAFrCQEAAAABZgAAAAEFAAAAAWEFAAAAA25pbAAAAAFpAQAAAAN0d28AAAABAAAAAWEJAQAAAAhXcml0ZVNldAAAAAEJAARMAAAAAgkBAAAACURhdGFFbnRyeQAAAAICAAAAAWsJAQAAAAFmAAAAAQkBAAAAAWYAAAABBQAAAAFhBQAAAANuaWwAAAAA"
+	b, err := base64.StdEncoding.DecodeString(dApp)
+	require.NoError(t, err)
+	script, err := ast.BuildScript(reader.NewBytesReader(b))
+	require.NoError(t, err)
+	r := Analyze(script)
+	assert.True(t, r.DApp)
+	require.NotNil(t, r.Complexity)
+	assert.Equal(t, uint64(4000), r.Complexity.Limit)
+	require.Len(t, r.Complexity.Callables, 2)
+	assert.True(t, r.Complexity.Callables["two"] > r.Complexity.Callables["one"])
+	assert.Equal(t, []Issue{
+		{Check: UnusedDeclaration, Severity: Warning, Message: "variable 'unused' is never used"},
+		{Check: Complexity, Severity: Info, Function: "two", Message: "callable has the highest complexity 77 of 4000"},
+	}, r.Issues)
+	assert.Equal(t, 0, r.Count(Error))
+	assert.Equal(t, 1, r.Count(Warning))
+}
@@ -22,6 +22,19 @@ type Script struct {
 	Verifier   Expr
 	DApp       DApp
 	dApp       bool
+	compiled   *program
+}
+
+// Compile lowers the script into closures with predefined functions resolved and variables stored in slots,
+// so the repeated evaluations don't walk the tree and don't copy the scopes. Verify and CallFunction use
+// the compiled form if the script is compiled.
+func (a *Script) Compile() error {
+	p, err := compile(a)
+	if err != nil {
+		return errors.Wrap(err, "failed to compile script")
+	}
+	a.compiled = p
+	return nil
 }
 
 func (a *Script) HasVerifier() bool {
@@ -70,35 +83,24 @@ func (a *Script) CallFunction(scheme proto.Scheme, state types.SmartState, tx *p
 	if err != nil {
 		return nil, err
 	}
-	scope := NewScope(3, scheme, state)
-	scope.SetThis(this)
-	scope.SetLastBlockInfo(lastBlock)
-	scope.SetHeight(height)
-
-	// assign of global vars and function
-	for _, expr := range a.DApp.Declarations {
-		_, err = expr.Evaluate(scope)
-		if err != nil {
-			return nil, errors.Wrap(err, "Script.CallFunction")
-		}
-	}
-
 	if len(fn.FuncDecl.Args) != len(tx.FunctionCall.Arguments) {
 		return nil, errors.Errorf("invalid func '%s' args count, expected %d, got %d", fn.FuncDecl.Name, len(fn.FuncDecl.Args), len(tx.FunctionCall.Arguments))
 	}
-	// pass function arguments
-	curScope := scope.Clone()
+	args := make([]Expr, len(tx.FunctionCall.Arguments))
 	for i, arg := range tx.FunctionCall.Arguments {
-		argExpr, err := protoArgToArgExpr(arg)
+		args[i], err = protoArgToArgExpr(arg)
 		if err != nil {
 			return nil, errors.Wrap(err, "Script.CallFunction")
 		}
-		curScope.AddValue(fn.FuncDecl.Args[i], argExpr)
 	}
-	// invocation type
-	curScope.AddValue(fn.AnnotationInvokeName, invoke)
 
-	rs, err := fn.FuncDecl.Body.Evaluate(curScope)
+	var rs Expr
+	if a.compiled != nil {
+		ctx := a.compiled.context(scheme, state, this, lastBlock, height)
+		rs, err = a.compiled.callables[name].run(ctx, append(args, invoke)...)
+	} else {
+		rs, err = a.evaluateCallable(fn, NewScope(3, scheme, state), this, lastBlock, height, args, invoke)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "Script.CallFunction")
 	}
@@ -117,15 +119,47 @@ func (a *Script) CallFunction(scheme proto.Scheme, state types.SmartState, tx *p
 	return resExpr.ConvertToProto()
 }
 
+func (a *Script) evaluateCallable(fn *DappCallableFunc, scope *ScopeImpl, this, lastBlock Expr, height uint64, args []Expr, invoke Expr) (Expr, error) {
+	scope.SetThis(this)
+	scope.SetLastBlockInfo(lastBlock)
+	scope.SetHeight(height)
+
+	// assign of global vars and function
+	for _, expr := range a.DApp.Declarations {
+		_, err := expr.Evaluate(scope)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// pass function arguments
+	curScope := scope.Clone()
+	for i, arg := range args {
+		curScope.AddValue(fn.FuncDecl.Args[i], arg)
+	}
+	// invocation type
+	curScope.AddValue(fn.AnnotationInvokeName, invoke)
+
+	return fn.FuncDecl.Body.Evaluate(curScope)
+}
+
 func (a *Script) Verify(scheme byte, state types.SmartState, object map[string]Expr, this, lastBlock Expr) (bool, error) {
 	height, err := state.AddingBlockHeight()
 	if err != nil {
 		return false, err
 	}
-	if a.IsDapp() {
-		if a.DApp.Verifier == nil {
-			return false, errors.New("verify function not defined")
+	if a.IsDapp() && a.DApp.Verifier == nil {
+		return false, errors.New("verify function not defined")
+	}
+	if a.compiled != nil {
+		ctx := a.compiled.context(scheme, state, this, lastBlock, height)
+		if a.IsDapp() {
+			return asBool(a.compiled.verifier.run(ctx, NewObject(object)))
 		}
+		ctx.tx = NewObject(object)
+		return asBool(a.compiled.verifier.run(ctx))
+	}
+	if a.IsDapp() {
 		scope := NewScope(3, scheme, state)
 		scope.SetThis(this)
 		scope.SetLastBlockInfo(lastBlock)
@@ -155,7 +189,10 @@ func (a *Script) Verify(scheme byte, state types.SmartState, object map[string]E
 }
 
 func evalAsBool(e Expr, s Scope) (bool, error) {
-	rs, err := e.Evaluate(s)
+	return asBool(e.Evaluate(s))
+}
+
+func asBool(rs Expr, err error) (bool, error) {
 	if err != nil {
 		if _, ok := err.(Throw); ok {
 			// maybe log error
@@ -0,0 +1,428 @@
+package ast
+
+import (
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/types"
+)
+
+// Predefined functions and constant variables don't depend on evaluation, so they are resolved once
+// at compilation and shared by all compiled scripts.
+var (
+	predefinedFunctions = map[int]map[string]Expr{1: functionsV2(), 2: functionsV2(), 3: functionsV3()}
+	predefinedVariables = map[int]map[string]Expr{1: VariablesV1(), 2: VariablesV2(), 3: VariablesV3()}
+)
+
+// evaluator is the compiled expression, it's evaluated in the frame of the function the expression belongs to.
+type evaluator func(*frame) (Expr, error)
+
+// unit is the compiled body of user function or of the script entry point (verifier or callable function).
+// Arguments and variables of the body are stored in the slots of frame, the code of variable
+// is kept at the index of its slot and is evaluated lazily on the first reference.
+type unit struct {
+	level int
+	lets  []evaluator
+	body  evaluator
+}
+
+func (u *unit) allocate(code evaluator) int {
+	u.lets = append(u.lets, code)
+	return len(u.lets) - 1
+}
+
+type function struct {
+	argc int
+	unit *unit
+}
+
+// binding is the name visible at some point of the script. Values are referenced by the level of the unit
+// and the slot, functions are resolved to their compiled units.
+type binding struct {
+	name  string
+	level int
+	slot  int
+	fn    *function
+	next  *binding
+}
+
+func (b *binding) lookup(name string) *binding {
+	for ; b != nil; b = b.next {
+		if b.name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// context holds the values which are the same for the whole evaluation.
+type context struct {
+	scope     *ScopeImpl // Scope passed to predefined functions, it provides only state, scheme and message length validation
+	tx        Expr
+	height    Expr
+	this      Expr
+	lastBlock Expr
+}
+
+type slot struct {
+	value Expr
+	err   error
+	done  bool
+}
+
+// frame is the evaluation of a unit, parent is the frame of the unit the function is declared in.
+type frame struct {
+	parent *frame
+	unit   *unit
+	slots  []slot
+	ctx    *context
+}
+
+func newFrame(parent *frame, u *unit, ctx *context) *frame {
+	return &frame{parent: parent, unit: u, slots: make([]slot, len(u.lets)), ctx: ctx}
+}
+
+func (f *frame) up(hops int) *frame {
+	for i := 0; i < hops; i++ {
+		f = f.parent
+	}
+	return f
+}
+
+func (f *frame) load(i int) (Expr, error) {
+	s := &f.slots[i]
+	if !s.done {
+		s.value, s.err = f.unit.lets[i](f)
+		s.done = true
+	}
+	return s.value, s.err
+}
+
+// entry is the compiled verifier or callable function, args are the slots of its arguments.
+type entry struct {
+	unit *unit
+	args []int
+}
+
+func (e *entry) run(ctx *context, args ...Expr) (Expr, error) {
+	f := newFrame(nil, e.unit, ctx)
+	for i, arg := range args {
+		f.slots[e.args[i]] = slot{value: arg, done: true}
+	}
+	return e.unit.body(f)
+}
+
+// program is the compiled script.
+type program struct {
+	version   int
+	verifier  *entry
+	callables map[string]*entry
+}
+
+func (p *program) context(scheme byte, state types.SmartState, this, lastBlock Expr, height uint64) *context {
+	return &context{
+		scope:     newScopeImpl(scheme, state, messageLengthValidation(p.version)),
+		tx:        NewUnit(),
+		height:    NewLong(int64(height)),
+		this:      this,
+		lastBlock: lastBlock,
+	}
+}
+
+type compiler struct {
+	functions map[string]Expr
+	variables map[string]Expr
+}
+
+func compile(script *Script) (*program, error) {
+	version := script.Version
+	if script.IsDapp() {
+		version = 3
+	}
+	functions, ok := predefinedFunctions[version]
+	if !ok {
+		functions = predefinedFunctions[3]
+		version = 3
+	}
+	c := &compiler{functions: functions, variables: predefinedVariables[version]}
+	p := &program{version: version}
+	if !script.IsDapp() {
+		if script.Verifier == nil {
+			return nil, errors.New("no verifier")
+		}
+		u := &unit{}
+		body, err := c.compile(u, nil, script.Verifier)
+		if err != nil {
+			return nil, err
+		}
+		u.body = body
+		p.verifier = &entry{unit: u}
+		return p, nil
+	}
+	if fn := script.DApp.Verifier; fn != nil {
+		e, err := c.entry(script.DApp.Declarations, fn, true)
+		if err != nil {
+			return nil, errors.Wrap(err, "verifier")
+		}
+		p.verifier = e
+	}
+	p.callables = make(map[string]*entry, len(script.DApp.CallableFuncs))
+	for name, fn := range script.DApp.CallableFuncs {
+		e, err := c.entry(script.DApp.Declarations, fn, false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "callable function '%s'", name)
+		}
+		p.callables[name] = e
+	}
+	return p, nil
+}
+
+// entry compiles the function of dApp with the global declarations in the order they are added to scope by
+// Script.Verify (annotation first) and Script.CallFunction (arguments and annotation last).
+func (c *compiler) entry(declarations Exprs, fn *DappCallableFunc, verifier bool) (*entry, error) {
+	u := &unit{}
+	e := &entry{unit: u}
+	var b *binding
+	if verifier {
+		b = c.argument(u, b, fn.AnnotationInvokeName, e)
+	}
+	for _, d := range declarations {
+		var err error
+		b, err = c.declaration(u, b, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !verifier {
+		for _, arg := range fn.FuncDecl.Args {
+			b = c.argument(u, b, arg, e)
+		}
+		b = c.argument(u, b, fn.AnnotationInvokeName, e)
+	}
+	body, err := c.compile(u, b, fn.FuncDecl.Body)
+	if err != nil {
+		return nil, err
+	}
+	u.body = body
+	return e, nil
+}
+
+func (c *compiler) argument(u *unit, b *binding, name string, e *entry) *binding {
+	s := u.allocate(nil)
+	e.args = append(e.args, s)
+	return &binding{name: name, level: u.level, slot: s, next: b}
+}
+
+func (c *compiler) declaration(u *unit, b *binding, d Expr) (*binding, error) {
+	switch t := d.(type) {
+	case *LetExpr:
+		code, err := c.compile(u, b, t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &binding{name: t.Name, level: u.level, slot: u.allocate(code), next: b}, nil
+	case *FuncDeclaration:
+		fu := &unit{level: u.level + 1}
+		fb := b
+		for _, arg := range t.Args {
+			fb = &binding{name: arg, level: fu.level, slot: fu.allocate(nil), next: fb}
+		}
+		body, err := c.compile(fu, fb, t.Body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "function '%s'", t.Name)
+		}
+		fu.body = body
+		return &binding{name: t.Name, level: u.level, fn: &function{argc: len(t.Args), unit: fu}, next: b}, nil
+	default:
+		return nil, errors.Errorf("unsupported declaration %T", d)
+	}
+}
+
+func (c *compiler) compile(u *unit, b *binding, e Expr) (evaluator, error) {
+	switch t := e.(type) {
+	case *LongExpr, *BooleanExpr, *StringExpr, *BytesExpr:
+		return constant(e), nil
+	case *RefExpr:
+		return c.ref(u, b, t.Name), nil
+	case *Block:
+		b, err := c.declaration(u, b, t.Let)
+		if err != nil {
+			return nil, err
+		}
+		return c.compile(u, b, t.Body)
+	case *BlockV2:
+		b, err := c.declaration(u, b, t.Decl)
+		if err != nil {
+			return nil, err
+		}
+		return c.compile(u, b, t.Body)
+	case *FuncCallExpr:
+		return c.compile(u, b, t.Func)
+	case *FunctionCall:
+		return c.call(u, b, t)
+	case *IfExpr:
+		return c.condition(u, b, t)
+	case *GetterExpr:
+		return c.getter(u, b, t)
+	default:
+		return func(f *frame) (Expr, error) {
+			return e.Evaluate(f.ctx.scope)
+		}, nil
+	}
+}
+
+func (c *compiler) ref(u *unit, b *binding, name string) evaluator {
+	if v := b.lookup(name); v != nil {
+		if v.fn != nil {
+			return fail(errors.Errorf("RefExpr evaluate: '%s' is a function", name))
+		}
+		hops, s := u.level-v.level, v.slot
+		if hops == 0 {
+			return func(f *frame) (Expr, error) {
+				return f.load(s)
+			}
+		}
+		return func(f *frame) (Expr, error) {
+			return f.up(hops).load(s)
+		}
+	}
+	switch name {
+	case "tx":
+		return func(f *frame) (Expr, error) { return f.ctx.tx, nil }
+	case "height":
+		return func(f *frame) (Expr, error) { return f.ctx.height, nil }
+	case "this":
+		return func(f *frame) (Expr, error) { return f.ctx.this, nil }
+	case "lastBlock":
+		return func(f *frame) (Expr, error) { return f.ctx.lastBlock, nil }
+	}
+	if v, ok := c.variables[name]; ok {
+		return constant(v)
+	}
+	if _, ok := c.functions[name]; ok {
+		return fail(errors.Errorf("RefExpr evaluate: '%s' is a function", name))
+	}
+	return fail(errors.Errorf("RefExpr evaluate: not found expr by name '%s'", name))
+}
+
+func (c *compiler) call(u *unit, b *binding, call *FunctionCall) (evaluator, error) {
+	args := make([]evaluator, call.Argc)
+	for i := range args {
+		arg, err := c.compile(u, b, call.Argv[i])
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	name := call.Name
+	if v := b.lookup(name); v != nil {
+		if v.fn == nil {
+			return fail(errors.Errorf("evaluate user function: '%s' is not a function", name)), nil
+		}
+		if v.fn.argc != call.Argc {
+			return fail(errors.Errorf("evaluate user function: function %s expects %d arguments, passed %d", name, v.fn.argc, call.Argc)), nil
+		}
+		hops, fu := u.level-v.level, v.fn.unit
+		return func(f *frame) (Expr, error) {
+			callee := newFrame(f.up(hops), fu, f.ctx)
+			for i, arg := range args {
+				rs, err := arg(f)
+				if err != nil {
+					return nil, errors.Wrapf(err, "evaluate user function: %s", name)
+				}
+				callee.slots[i] = slot{value: rs, done: true}
+			}
+			return fu.body(callee)
+		}, nil
+	}
+	e, ok := c.functions[name]
+	if !ok {
+		if _, ok := c.variables[name]; ok {
+			return fail(errors.Errorf("evaluate user function: '%s' is not a function", name)), nil
+		}
+		return fail(errors.Errorf("evaluate user function: function named '%s' not found in scope", name)), nil
+	}
+	fn := e.(*Function)
+	if fn.Argc != call.Argc {
+		return fail(errors.Errorf("evaluate user function: function %s expects %d arguments, passed %d", name, fn.Argc, call.Argc)), nil
+	}
+	predef, ok := fn.Body.(*PredefFunction)
+	if !ok {
+		return nil, errors.Errorf("unexpected body %T of predefined function '%s'", fn.Body, name)
+	}
+	callable := predef.fn
+	return func(f *frame) (Expr, error) {
+		params := make(Exprs, len(args))
+		for i, arg := range args {
+			rs, err := arg(f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "evaluate user function: %s", name)
+			}
+			params[i] = rs
+		}
+		return callable(f.ctx.scope, params)
+	}, nil
+}
+
+func (c *compiler) condition(u *unit, b *binding, e *IfExpr) (evaluator, error) {
+	cond, err := c.compile(u, b, e.Condition)
+	if err != nil {
+		return nil, err
+	}
+	yes, err := c.compile(u, b, e.True)
+	if err != nil {
+		return nil, err
+	}
+	no, err := c.compile(u, b, e.False)
+	if err != nil {
+		return nil, err
+	}
+	return func(f *frame) (Expr, error) {
+		rs, err := cond(f)
+		if err != nil {
+			return nil, err
+		}
+		v, ok := rs.(*BooleanExpr)
+		if !ok {
+			return nil, errors.Errorf("IfExpr evaluate: expected bool in condition found %T", rs)
+		}
+		if v.Value {
+			return yes(f)
+		}
+		return no(f)
+	}, nil
+}
+
+func (c *compiler) getter(u *unit, b *binding, e *GetterExpr) (evaluator, error) {
+	object, err := c.compile(u, b, e.Object)
+	if err != nil {
+		return nil, err
+	}
+	key := e.Key
+	return func(f *frame) (Expr, error) {
+		val, err := object(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "GetterExpr Evaluate by key %s", key)
+		}
+		switch obj := val.(type) {
+		case Getable:
+			return obj.Get(key)
+		case *Unit:
+			return NewUnit(), nil
+		default:
+			return nil, errors.Errorf("GetterExpr Evaluate: expected value be Getable, got %T", val)
+		}
+	}, nil
+}
+
+func constant(e Expr) evaluator {
+	return func(*frame) (Expr, error) {
+		return e, nil
+	}
+}
+
+// fail returns the evaluator of the expression which can't be evaluated, the error is reported only
+// if the expression is reached like it's done by the tree walking evaluation.
+func fail(err error) evaluator {
+	return func(*frame) (Expr, error) {
+		return nil, err
+	}
+}
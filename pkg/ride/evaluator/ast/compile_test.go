@@ -0,0 +1,116 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/mockstate"
+)
+
+func ref(name string) *RefExpr {
+	return &RefExpr{Name: name}
+}
+
+func call(name string, args ...Expr) *FuncCallExpr {
+	return NewFuncCall(NewFunctionCall(name, args))
+}
+
+// nestedFunctionsScript is the script where inner function refers to the argument of outer function:
+// func outer(a) = { func inner(b) = a + b; let c = 1; inner(c) + a + height }; let x = 40; outer(x) == expected
+func nestedFunctionsScript(expected int64) Expr {
+	inner := &FuncDeclaration{Name: "inner", Args: []string{"b"}, Body: call("100", ref("a"), ref("b"))}
+	outerBody := &BlockV2{
+		Decl: inner,
+		Body: &BlockV2{
+			Decl: NewLet("c", NewLong(1)),
+			Body: call("100", call("100", call("inner", ref("c")), ref("a")), ref("height")),
+		},
+	}
+	outer := &FuncDeclaration{Name: "outer", Args: []string{"a"}, Body: outerBody}
+	return &BlockV2{
+		Decl: outer,
+		Body: &BlockV2{
+			Decl: NewLet("x", NewLong(40)),
+			Body: call("0", call("outer", ref("x")), NewLong(expected)),
+		},
+	}
+}
+
+func verifyTreeAndCompiled(t *testing.T, verifier Expr) (bool, error) {
+	tree := &Script{Version: 3, HasBlockV2: true, Verifier: verifier}
+	compiled := &Script{Version: 3, HasBlockV2: true, Verifier: verifier}
+	require.NoError(t, compiled.Compile())
+	require.NotNil(t, compiled.compiled)
+
+	st := mockstate.State{NewestHeightVal: 10}
+	expected, expectedErr := tree.Verify(proto.MainNetScheme, st, map[string]Expr{}, NewUnit(), NewUnit())
+	actual, actualErr := compiled.Verify(proto.MainNetScheme, st, map[string]Expr{}, NewUnit(), NewUnit())
+	assert.Equal(t, expected, actual)
+	if expectedErr == nil {
+		assert.NoError(t, actualErr)
+	} else {
+		require.Error(t, actualErr)
+		assert.Equal(t, expectedErr.Error(), actualErr.Error())
+	}
+	return actual, actualErr
+}
+
+func TestCompile_NestedFunctions(t *testing.T) {
+	rs, err := verifyTreeAndCompiled(t, nestedFunctionsScript(91))
+	require.NoError(t, err)
+	assert.True(t, rs)
+
+	rs, err = verifyTreeAndCompiled(t, nestedFunctionsScript(90))
+	require.NoError(t, err)
+	assert.False(t, rs)
+}
+
+func TestCompile_LazyVariables(t *testing.T) {
+	// let unused = throw("never")
+	// true
+	rs, err := verifyTreeAndCompiled(t, &BlockV2{Decl: NewLet("unused", call("2", NewString("never"))), Body: NewBoolean(true)})
+	require.NoError(t, err)
+	assert.True(t, rs)
+}
+
+func TestCompile_Throw(t *testing.T) {
+	// if (height > 5) then throw("big") else true
+	rs, err := verifyTreeAndCompiled(t, NewIf(call("102", ref("height"), NewLong(5)), call("2", NewString("big")), NewBoolean(true)))
+	require.NoError(t, err)
+	assert.False(t, rs)
+
+	// throw("arg") == true
+	_, err = verifyTreeAndCompiled(t, call("0", call("2", NewString("arg")), NewBoolean(true)))
+	assert.EqualError(t, err, "evaluate user function: 0: arg")
+}
+
+func TestCompile_UnknownNames(t *testing.T) {
+	// Unknown names are reported only if evaluated.
+	rs, err := verifyTreeAndCompiled(t, NewIf(NewBoolean(true), NewBoolean(true), call("unknown", ref("missing"))))
+	require.NoError(t, err)
+	assert.True(t, rs)
+
+	_, err = verifyTreeAndCompiled(t, call("unknown", ref("missing")))
+	assert.EqualError(t, err, "evaluate user function: function named 'unknown' not found in scope")
+
+	_, err = verifyTreeAndCompiled(t, call("0", ref("missing"), NewBoolean(true)))
+	assert.EqualError(t, err, "evaluate user function: 0: RefExpr evaluate: not found expr by name 'missing'")
+}
+
+func BenchmarkScript_Verify(b *testing.B) {
+	st := mockstate.State{NewestHeightVal: 10}
+	tree := &Script{Version: 3, HasBlockV2: true, Verifier: nestedFunctionsScript(91)}
+	compiled := &Script{Version: 3, HasBlockV2: true, Verifier: nestedFunctionsScript(91)}
+	require.NoError(b, compiled.Compile())
+	for name, s := range map[string]*Script{"tree": tree, "compiled": compiled} {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Verify(proto.MainNetScheme, st, map[string]Expr{}, NewUnit(), NewUnit()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+
+	protobuf "github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// ArgumentType is the union of types accepted by the argument of callable function, as it is encoded in the meta of dApp.
+// Zero value means that the type is unknown because the dApp has no meta.
+type ArgumentType byte
+
+const (
+	ArgumentInt ArgumentType = 1 << iota
+	ArgumentByteVector
+	ArgumentBoolean
+	ArgumentString
+)
+
+var argumentTypeNames = []struct {
+	t    ArgumentType
+	name string
+}{
+	{ArgumentInt, "Int"},
+	{ArgumentByteVector, "ByteVector"},
+	{ArgumentBoolean, "Boolean"},
+	{ArgumentString, "String"},
+}
+
+func (t ArgumentType) String() string {
+	if t == 0 {
+		return "Any"
+	}
+	names := make([]string, 0, len(argumentTypeNames))
+	for _, n := range argumentTypeNames {
+		if t&n.t != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ParseArgumentType is the reverse of String.
+func ParseArgumentType(s string) (ArgumentType, error) {
+	if s == "Any" {
+		return 0, nil
+	}
+	var r ArgumentType
+	for _, name := range strings.Split(s, "|") {
+		found := false
+		for _, n := range argumentTypeNames {
+			if n.name == name {
+				r |= n.t
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.Errorf("unknown argument type '%s'", name)
+		}
+	}
+	return r, nil
+}
+
+type CallableArgument struct {
+	Name string
+	Type ArgumentType
+}
+
+type CallableSignature struct {
+	Name      string
+	Arguments []CallableArgument
+}
+
+// Callables returns the signatures of callable functions in the order of declaration.
+// Types of arguments are taken from the meta, only the meta of version 1 is supported.
+func (a *DApp) Callables() ([]CallableSignature, error) {
+	names := a.callables
+	if names == nil {
+		for name := range a.CallableFuncs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	types, err := a.Meta.argumentTypes()
+	if err != nil {
+		return nil, err
+	}
+	if types != nil && len(types) != len(names) {
+		return nil, errors.Errorf("meta describes %d callable functions, dApp has %d", len(types), len(names))
+	}
+	r := make([]CallableSignature, len(names))
+	for i, name := range names {
+		f, ok := a.CallableFuncs[name]
+		if !ok {
+			return nil, errors.Errorf("no callable function '%s'", name)
+		}
+		args := make([]CallableArgument, len(f.FuncDecl.Args))
+		for j, arg := range f.FuncDecl.Args {
+			args[j].Name = arg
+		}
+		if types != nil {
+			if len(types[i]) != len(args) {
+				return nil, errors.Errorf("meta describes %d arguments of function '%s', it has %d", len(types[i]), name, len(args))
+			}
+			for j, t := range types[i] {
+				args[j].Type = ArgumentType(t)
+			}
+		}
+		r[i] = CallableSignature{Name: name, Arguments: args}
+	}
+	return r, nil
+}
+
+// Messages of meta as they are defined in protobuf schemas of Waves.
+type dAppMeta struct {
+	Version int32                    `protobuf:"varint,1,opt,name=version,proto3"`
+	Funcs   []*callableFuncSignature `protobuf:"bytes,2,rep,name=funcs,proto3"`
+}
+
+func (m *dAppMeta) Reset()         { *m = dAppMeta{} }
+func (m *dAppMeta) String() string { return protobuf.CompactTextString(m) }
+func (*dAppMeta) ProtoMessage()    {}
+
+type callableFuncSignature struct {
+	Types []byte `protobuf:"bytes,1,opt,name=types,proto3"`
+}
+
+func (m *callableFuncSignature) Reset()         { *m = callableFuncSignature{} }
+func (m *callableFuncSignature) String() string { return protobuf.CompactTextString(m) }
+func (*callableFuncSignature) ProtoMessage()    {}
+
+// argumentTypes decodes the protobuf message of meta, it returns the types of arguments of each callable function
+// or nil if the dApp was compiled without meta.
+func (m DappMeta) argumentTypes() ([][]byte, error) {
+	if len(m.Bytes) == 0 {
+		return nil, nil
+	}
+	var meta dAppMeta
+	if err := protobuf.Unmarshal(m.Bytes, &meta); err != nil {
+		return nil, errors.Wrap(err, "invalid meta")
+	}
+	if meta.Version != 1 {
+		return nil, errors.Errorf("unsupported meta version %d", meta.Version)
+	}
+	r := make([][]byte, len(meta.Funcs))
+	for i, f := range meta.Funcs {
+		r[i] = f.Types
+	}
+	return r, nil
+}
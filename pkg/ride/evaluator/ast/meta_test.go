@@ -0,0 +1,96 @@
+package ast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+)
+
+type testCallable struct {
+	name string
+	args []string
+}
+
+func putString(buf *bytes.Buffer, s string) {
+	_ = binary.Write(buf, binary.BigEndian, int32(len(s)))
+	buf.WriteString(s)
+}
+
+// testDApp builds dApp of callable functions returning true.
+func testDApp(t *testing.T, meta []byte, callables ...testCallable) *Script {
+	buf := new(bytes.Buffer)
+	buf.Write([]byte{0, 1, 3})
+	_ = binary.Write(buf, binary.BigEndian, int32(0))
+	_ = binary.Write(buf, binary.BigEndian, int32(len(meta)))
+	buf.Write(meta)
+	_ = binary.Write(buf, binary.BigEndian, int32(0))
+	_ = binary.Write(buf, binary.BigEndian, int32(len(callables)))
+	for _, c := range callables {
+		putString(buf, "i")
+		buf.WriteByte(reader.DEC_FUNC)
+		putString(buf, c.name)
+		_ = binary.Write(buf, binary.BigEndian, int32(len(c.args)))
+		for _, a := range c.args {
+			putString(buf, a)
+		}
+		buf.WriteByte(reader.E_TRUE)
+	}
+	_ = binary.Write(buf, binary.BigEndian, int32(0))
+	script, err := BuildScript(reader.NewBytesReader(buf.Bytes()))
+	require.NoError(t, err)
+	return script
+}
+
+func TestDAppCallables(t *testing.T) {
+	// Version 1, function "b" with arguments Int|String and ByteVector, function "a" without arguments.
+	meta := []byte{0x08, 0x01, 0x12, 0x04, 0x0a, 0x02, 0x09, 0x02, 0x12, 0x00}
+	script := testDApp(t, meta, testCallable{"b", []string{"x", "y"}}, testCallable{"a", nil})
+	callables, err := script.DApp.Callables()
+	require.NoError(t, err)
+	expected := []CallableSignature{
+		{Name: "b", Arguments: []CallableArgument{{"x", ArgumentInt | ArgumentString}, {"y", ArgumentByteVector}}},
+		{Name: "a", Arguments: []CallableArgument{}},
+	}
+	assert.Equal(t, expected, callables)
+}
+
+func TestDAppCallablesWithoutMeta(t *testing.T) {
+	script := testDApp(t, nil, testCallable{"f", []string{"x"}})
+	callables, err := script.DApp.Callables()
+	require.NoError(t, err)
+	assert.Equal(t, []CallableSignature{{Name: "f", Arguments: []CallableArgument{{"x", 0}}}}, callables)
+}
+
+func TestDAppCallablesInvalidMeta(t *testing.T) {
+	// Meta describes one argument while the function has none.
+	script := testDApp(t, []byte{0x08, 0x01, 0x12, 0x03, 0x0a, 0x01, 0x01}, testCallable{"f", nil})
+	_, err := script.DApp.Callables()
+	assert.Error(t, err)
+	// Unsupported version of meta.
+	script = testDApp(t, []byte{0x08, 0x02}, testCallable{"f", nil})
+	_, err = script.DApp.Callables()
+	assert.Error(t, err)
+}
+
+func TestArgumentType(t *testing.T) {
+	for _, tc := range []struct {
+		t ArgumentType
+		s string
+	}{
+		{0, "Any"},
+		{ArgumentInt, "Int"},
+		{ArgumentBoolean | ArgumentString, "Boolean|String"},
+		{ArgumentInt | ArgumentByteVector | ArgumentBoolean | ArgumentString, "Int|ByteVector|Boolean|String"},
+	} {
+		assert.Equal(t, tc.s, tc.t.String())
+		at, err := ParseArgumentType(tc.s)
+		require.NoError(t, err)
+		assert.Equal(t, tc.t, at)
+	}
+	_, err := ParseArgumentType("Int|List")
+	assert.Error(t, err)
+}
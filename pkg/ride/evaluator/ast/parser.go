@@ -49,6 +49,7 @@ type DApp struct {
 	Declarations  Exprs
 	CallableFuncs map[string]*DappCallableFunc
 	Verifier      *DappCallableFunc
+	callables     []string // Names of callable functions in the order of declaration
 }
 
 type DappMeta struct {
@@ -97,6 +98,7 @@ func parseDApp(r *BytesReader) (DApp, error) {
 			AnnotationInvokeName: annotationInvokeName,
 			FuncDecl:             f,
 		}
+		dApp.callables = append(dApp.callables, f.Name)
 	}
 	dApp.CallableFuncs = callableFuncs
 
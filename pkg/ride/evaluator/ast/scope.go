@@ -53,20 +53,21 @@ func (a *ScopeImpl) withExprs(e map[string]Expr) *ScopeImpl {
 	return a
 }
 
-func NewScope(version int, scheme byte, state types.SmartState) *ScopeImpl {
-	var v func(int) bool
+func messageLengthValidation(version int) func(int) bool {
 	switch version {
 	case 1, 2:
-		v = func(int) bool {
+		return func(int) bool {
 			return true
 		}
 	default:
-		v = func(l int) bool {
+		return func(l int) bool {
 			return l <= maxMessageLengthV3
 		}
 	}
+}
 
-	out := newScopeImpl(scheme, state, v)
+func NewScope(version int, scheme byte, state types.SmartState) *ScopeImpl {
+	out := newScopeImpl(scheme, state, messageLengthValidation(version))
 
 	var e map[string]Expr
 	switch version {
@@ -0,0 +1,282 @@
+package decompiler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+)
+
+const indentation = "    "
+
+// Names of native functions by their numbers.
+var nativeFunctions = map[string]string{
+	"1":    "_isInstanceOf",
+	"2":    "throw",
+	"107":  "fraction",
+	"108":  "pow",
+	"109":  "log",
+	"200":  "size",
+	"201":  "take",
+	"202":  "drop",
+	"303":  "take",
+	"304":  "drop",
+	"305":  "size",
+	"400":  "size",
+	"410":  "toBytes",
+	"411":  "toBytes",
+	"412":  "toBytes",
+	"420":  "toString",
+	"421":  "toString",
+	"500":  "sigVerify",
+	"501":  "keccak256",
+	"502":  "blake2b256",
+	"503":  "sha256",
+	"504":  "rsaVerify",
+	"600":  "toBase58String",
+	"601":  "fromBase58String",
+	"602":  "toBase64String",
+	"603":  "fromBase64String",
+	"604":  "toBase16String",
+	"605":  "fromBase16String",
+	"700":  "checkMerkleProof",
+	"1000": "transactionById",
+	"1001": "transactionHeightById",
+	"1003": "assetBalance",
+	"1004": "assetInfo",
+	"1005": "blockInfoByHeight",
+	"1006": "transferTransactionById",
+	"1040": "getInteger",
+	"1041": "getBoolean",
+	"1042": "getBinary",
+	"1043": "getString",
+	"1050": "getInteger",
+	"1051": "getBoolean",
+	"1052": "getBinary",
+	"1053": "getString",
+	"1060": "addressFromRecipient",
+	"1061": "toString",
+	"1070": "parseBlockHeader",
+	"1200": "toUtf8String",
+	"1201": "toInt",
+	"1202": "toInt",
+	"1203": "indexOf",
+	"1204": "indexOf",
+	"1205": "split",
+	"1206": "parseInt",
+	"1207": "lastIndexOf",
+	"1208": "lastIndexOf",
+}
+
+// Binary operators by the number of native function or the name of user function.
+var operators = map[string]string{
+	"0":    "==",
+	"!=":   "!=",
+	"100":  "+",
+	"101":  "-",
+	"102":  ">",
+	"103":  ">=",
+	"104":  "*",
+	"105":  "/",
+	"106":  "%",
+	"203":  "+",
+	"300":  "+",
+	"1100": "::",
+}
+
+// Decompile returns the source code of the script restored from its tree.
+// Names of local variables and the match expressions are shown as the compiler generated them.
+func Decompile(script *ast.Script) string {
+	d := &decompiler{}
+	d.printf("{-# STDLIB_VERSION %d #-}\n", script.Version)
+	if !script.IsDapp() {
+		d.line("{-# CONTENT_TYPE EXPRESSION #-}")
+		d.line("")
+		d.statements(script.Verifier, 0)
+		d.line("")
+		return d.String()
+	}
+	d.line("{-# CONTENT_TYPE DAPP #-}")
+	for _, decl := range script.DApp.Declarations {
+		d.line("")
+		d.declaration(decl, 0)
+		d.line("")
+	}
+	names := make([]string, 0, len(script.DApp.CallableFuncs))
+	for name := range script.DApp.CallableFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cf := script.DApp.CallableFuncs[name]
+		d.line("")
+		d.printf("@Callable(%s)\n", cf.AnnotationInvokeName)
+		d.function(cf.FuncDecl, 0)
+		d.line("")
+	}
+	if v := script.DApp.Verifier; v != nil {
+		d.line("")
+		d.printf("@Verifier(%s)\n", v.AnnotationInvokeName)
+		d.function(v.FuncDecl, 0)
+		d.line("")
+	}
+	return d.String()
+}
+
+type decompiler struct {
+	strings.Builder
+}
+
+func (d *decompiler) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(d, format, args...)
+}
+
+func (d *decompiler) line(s string) {
+	d.printf("%s\n", s)
+}
+
+func (d *decompiler) indent(level int) {
+	d.WriteString(strings.Repeat(indentation, level))
+}
+
+// statements writes the chain of blocks as declarations followed by the resulting expression, each on its own line.
+func (d *decompiler) statements(expr ast.Expr, level int) {
+	for {
+		switch e := expr.(type) {
+		case *ast.Block:
+			d.indent(level)
+			d.declaration(e.Let, level)
+			d.line("")
+			expr = e.Body
+			continue
+		case *ast.BlockV2:
+			d.indent(level)
+			d.declaration(e.Decl, level)
+			d.line("")
+			expr = e.Body
+			continue
+		}
+		d.indent(level)
+		d.expression(expr, level)
+		return
+	}
+}
+
+func (d *decompiler) declaration(decl ast.Expr, level int) {
+	switch e := decl.(type) {
+	case *ast.LetExpr:
+		d.printf("let %s = ", e.Name)
+		d.expression(e.Value, level)
+	case *ast.FuncDeclaration:
+		d.function(e, level)
+	default:
+		d.printf("/* unknown declaration %T */", decl)
+	}
+}
+
+func (d *decompiler) function(f *ast.FuncDeclaration, level int) {
+	d.printf("func %s(%s) = ", f.Name, strings.Join(f.Args, ", "))
+	d.expression(f.Body, level)
+}
+
+func (d *decompiler) expression(expr ast.Expr, level int) {
+	switch e := expr.(type) {
+	case *ast.LongExpr:
+		d.printf("%d", e.Value)
+	case *ast.BooleanExpr:
+		d.printf("%t", e.Value)
+	case *ast.StringExpr:
+		d.WriteString(strconv.Quote(e.Value))
+	case *ast.BytesExpr:
+		d.printf("base58'%s'", base58.Encode(e.Value))
+	case *ast.RefExpr:
+		d.WriteString(e.Name)
+	case *ast.GetterExpr:
+		d.operand(e.Object, level)
+		d.printf(".%s", e.Key)
+	case *ast.IfExpr:
+		d.WriteString("if (")
+		d.expression(e.Condition, level)
+		d.line(")")
+		d.indent(level + 1)
+		d.WriteString("then ")
+		d.expression(e.True, level+1)
+		d.line("")
+		d.indent(level + 1)
+		d.WriteString("else ")
+		d.expression(e.False, level+1)
+	case *ast.Block, *ast.BlockV2:
+		d.line("{")
+		d.statements(e, level+1)
+		d.line("")
+		d.indent(level)
+		d.WriteString("}")
+	case *ast.FuncCallExpr:
+		d.expression(e.Func, level)
+	case *ast.FunctionCall:
+		d.call(e, level)
+	default:
+		d.printf("/* unknown expression %T */", expr)
+	}
+}
+
+func (d *decompiler) call(f *ast.FunctionCall, level int) {
+	if op, ok := operators[f.Name]; ok && len(f.Argv) == 2 {
+		d.operand(f.Argv[0], level)
+		d.printf(" %s ", op)
+		d.operand(f.Argv[1], level)
+		return
+	}
+	if (f.Name == "!" || f.Name == "-") && len(f.Argv) == 1 {
+		d.WriteString(f.Name)
+		d.operand(f.Argv[0], level)
+		return
+	}
+	if f.Name == "401" && len(f.Argv) == 2 {
+		d.operand(f.Argv[0], level)
+		d.WriteString("[")
+		d.expression(f.Argv[1], level)
+		d.WriteString("]")
+		return
+	}
+	name := f.Name
+	if n, ok := nativeFunctions[name]; ok {
+		name = n
+	}
+	d.printf("%s(", name)
+	for i, a := range f.Argv {
+		if i > 0 {
+			d.WriteString(", ")
+		}
+		d.expression(a, level)
+	}
+	d.WriteString(")")
+}
+
+// operand writes the expression in parentheses unless it binds tighter than any operator.
+func (d *decompiler) operand(expr ast.Expr, level int) {
+	if needsParentheses(expr) {
+		d.WriteString("(")
+		d.expression(expr, level)
+		d.WriteString(")")
+		return
+	}
+	d.expression(expr, level)
+}
+
+func needsParentheses(expr ast.Expr) bool {
+	switch e := expr.(type) {
+	case *ast.IfExpr:
+		return true
+	case *ast.FuncCallExpr:
+		return needsParentheses(e.Func)
+	case *ast.FunctionCall:
+		_, ok := operators[e.Name]
+		return ok && len(e.Argv) == 2
+	default:
+		return false
+	}
+}
@@ -0,0 +1,68 @@
+package decompiler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+)
+
+func parse(t *testing.T, base64 string) *ast.Script {
+	r, err := reader.NewReaderFromBase64(base64)
+	require.NoError(t, err)
+	script, err := ast.BuildScript(r)
+	require.NoError(t, err)
+	return script
+}
+
+func TestDecompileExpression(t *testing.T) {
+	script := parse(t, "AQQAAAABeAkAAGgAAAACAAAAAAAAAAACAAAAAAAAAAACCQAAAAAAAAIFAAAAAXgAAAAAAAAAAARc6yrX")
+	expected := `{-# STDLIB_VERSION 1 #-}
+{-# CONTENT_TYPE EXPRESSION #-}
+
+let x = 2 * 2
+x == 4
+`
+	assert.Equal(t, expected, Decompile(script))
+}
+
+func TestDecompileNestedExpressions(t *testing.T) {
+	sum := ast.NewFunctionCall("100", ast.Exprs{ast.NewLong(1), ast.NewLong(2)})
+	product := ast.NewFunctionCall("104", ast.Exprs{sum, ast.NewLong(3)})
+	condition := ast.NewFunctionCall("!", ast.Exprs{ast.NewFunctionCall("0", ast.Exprs{product, ast.NewLong(9)})})
+	getter := ast.NewGetterExpr(&ast.RefExpr{Name: "tx"}, "sender")
+	balance := ast.NewFunctionCall("1003", ast.Exprs{getter, &ast.RefExpr{Name: "unit"}})
+	block := &ast.Block{Let: ast.NewLet("b", balance), Body: ast.NewFunctionCall("102", ast.Exprs{&ast.RefExpr{Name: "b"}, ast.NewLong(0)})}
+	script := &ast.Script{Version: 3, Verifier: ast.NewIf(condition, ast.NewString("x\"y"), block)}
+	expected := `{-# STDLIB_VERSION 3 #-}
+{-# CONTENT_TYPE EXPRESSION #-}
+
+if (!(((1 + 2) * 3) == 9))
+    then "x\"y"
+    else {
+        let b = assetBalance(tx.sender, unit)
+        b > 0
+    }
+`
+	assert.Equal(t, expected, Decompile(script))
+}
+
+func TestDecompileDApp(t *testing.T) {
+	script := parse(t, "AAIDAAAAAAAAAAAAAAACAQAAAAFmAAAAAQAAAAFhCQABLAAAAAIFAAAAAWEFAAAAAWEAAAAABnVudXNlZAAAAAAAAAAAAQAAAAIAAAABaQEAAAADb25lAAAAAQAAAAFhCQEAAAAIV3JpdGVTZXQAAAABCQAETAAAAAIJAQAAAAlEYXRhRW50cnkAAAACAgAAAAFrCQEAAAABZgAAAAEFAAAAAWEFAAAAA25pbAAAAAFpAQAAAAN0d28AAAABAAAAAWEJAQAAAAhXcml0ZVNldAAAAAEJAARMAAAAAgkBAAAACURhdGFFbnRyeQAAAAICAAAAAWsJAQAAAAFmAAAAAQkBAAAAAWYAAAABBQAAAAFhBQAAAANuaWwAAAAA")
+	expected := `{-# STDLIB_VERSION 3 #-}
+{-# CONTENT_TYPE DAPP #-}
+
+func f(a) = a + a
+
+let unused = 1
+
+@Callable(i)
+func one(a) = WriteSet(DataEntry("k", f(a)) :: nil)
+
+@Callable(i)
+func two(a) = WriteSet(DataEntry("k", f(f(a))) :: nil)
+`
+	assert.Equal(t, expected, Decompile(script))
+}
@@ -169,10 +169,14 @@ type Costs struct {
 	Verifier  uint64
 }
 
+// LatestVersion is the version of the most recent estimator.
+const LatestVersion = 3
+
 type Estimator struct {
 	Version   int
 	catalogue *Catalogue
 	contexts  *contexts
+	variables map[string]ast.Expr
 }
 
 func NewEstimator(version int, catalogue *Catalogue, variables map[string]ast.Expr) *Estimator {
@@ -180,7 +184,16 @@ func NewEstimator(version int, catalogue *Catalogue, variables map[string]ast.Ex
 		Version:   version,
 		catalogue: catalogue,
 		contexts:  newContexts(variables),
+		variables: variables,
+	}
+}
+
+// NewEstimatorForScript creates the estimator of given version with the catalogue and variables of the script's library version.
+func NewEstimatorForScript(version int, script *ast.Script) *Estimator {
+	if script.IsDapp() || script.Version >= 3 {
+		return NewEstimator(version, NewCatalogueV3(), ast.VariablesV3())
 	}
+	return NewEstimator(version, NewCatalogueV2(), ast.VariablesV2())
 }
 
 func (e *Estimator) Estimate(script *ast.Script) (Costs, error) {
@@ -194,6 +207,9 @@ func (e *Estimator) EstimateDApp(script *ast.Script) (Costs, error) {
 	if !script.IsDapp() {
 		return Costs{}, errors.New("estimation: not a DApp")
 	}
+	if e.Version == 3 {
+		return e.estimateDAppV3(script)
+	}
 	e.contexts.deleteRootExpression("tx")
 	e.contexts.setRootExpression("height", expression{expr: ast.NewLong(0), evaluated: true})
 	e.contexts.setRootExpression("this", expression{expr: ast.NewUnit(), evaluated: false})
@@ -259,6 +275,9 @@ func (e *Estimator) EstimateVerifier(script *ast.Script) (Costs, error) {
 	if script.IsDapp() {
 		return Costs{}, errors.New("estimation: not a simple script")
 	}
+	if e.Version == 3 {
+		return e.estimateVerifierV3(script)
+	}
 	verifierCost, err := e.estimate(script.Verifier)
 	if err != nil {
 		return Costs{}, errors.Wrap(err, "estimation")
@@ -207,3 +207,120 @@ func TestDAppEstimation(t *testing.T) {
 		assert.Equal(t, test.count, len(estimation.Functions), fmt.Sprintf("Failure: V%d: %s: unexpected number of functions %d", test.version, test.code, len(estimation.Functions)))
 	}
 }
+
+func TestEstimatorV3(t *testing.T) {
+	for _, test := range []struct {
+		code   string
+		script string
+		cost   uint64
+	}{
+		{`false`, "AweHXCN1", 1},
+		{`let x = 2 * 2; x == 4`, "AwQAAAABeAkAAGgAAAACAAAAAAAAAAACAAAAAAAAAAACCQAAAAAAAAIFAAAAAXgAAAAAAAAAAARdrwMC", 5},
+		{`let x = parseIntValue("12345"); x + x == 0`, "AwQAAAABeAkBAAAADXBhcnNlSW50VmFsdWUAAAABAgAAAAUxMjM0NQkAAAAAAAACCQAAZAAAAAIFAAAAAXgFAAAAAXgAAAAAAAAAAADVoBKt", 24},
+		{`let x = parseIntValue("12345"); 0 == 0`, "AwQAAAABeAkBAAAADXBhcnNlSW50VmFsdWUAAAABAgAAAAUxMjM0NQkAAAAAAAACAAAAAAAAAAAAAAAAAAAAAAAAk6EsIQ==", 3},
+		{`func f(a: Int) = a; f(1) == 1`, "AwoBAAAAAWYAAAABAAAAAWEFAAAAAWEJAAAAAAAAAgkBAAAAAWYAAAABAAAAAAAAAAABAAAAAAAAAAABAYVjTw==", 9},
+		{`let a = 1; let b = 2; let c = if true then a else a + b; c == 3`, "AwQAAAABYQAAAAAAAAAAAQQAAAABYgAAAAAAAAAAAgQAAAABYwMGBQAAAAFhCQAAZAAAAAIFAAAAAWEFAAAAAWIJAAAAAAAAAgUAAAABYwAAAAAAAAAAA4HJg3U=", 6},
+	} {
+		r, err := reader.NewReaderFromBase64(test.script)
+		require.NoError(t, err, test.code)
+		script, err := ast.BuildScript(r)
+		require.NoError(t, err, test.code)
+		cost, err := NewEstimatorForScript(3, script).Estimate(script)
+		require.NoError(t, err, test.code)
+		assert.Equal(t, int(test.cost), int(cost.Verifier), test.code)
+	}
+}
+
+func TestDAppEstimationV3(t *testing.T) {
+	for _, test := range []struct {
+		code     string
+		script   string
+		verifier uint64
+		dApp     uint64
+		count    int
+	}{
+		{"@Verifier(tx) func verify() = false", "AAIDAAAAAAAAAAIIAQAAAAAAAAAAAAAAAQAAAAJ0eAEAAAAGdmVyaWZ5AAAAAAcysh6J", 1, 1, 0},
+		{`@Callable(i)func f() = {WriteSet([DataEntry("YYY", "XXX")])}`, "AAIDAAAAAAAAAAQIARIAAAAAAAAAAAEAAAABaQEAAAABZgAAAAAJAQAAAAhXcml0ZVNldAAAAAEJAARMAAAAAgkBAAAACURhdGFFbnRyeQAAAAICAAAAA1lZWQIAAAADWFhYBQAAAANuaWwAAAAAeFguLA==", 0, 7, 1},
+	} {
+		r, err := reader.NewReaderFromBase64(test.script)
+		require.NoError(t, err, test.code)
+		script, err := ast.BuildScript(r)
+		require.NoError(t, err, test.code)
+		cost, err := NewEstimatorForScript(3, script).Estimate(script)
+		require.NoError(t, err, test.code)
+		assert.Equal(t, int(test.verifier), int(cost.Verifier), test.code)
+		assert.Equal(t, int(test.dApp), int(cost.DApp), test.code)
+		assert.Equal(t, test.count, len(cost.Functions), test.code)
+	}
+}
+
+func TestEstimatorV3Rules(t *testing.T) {
+	let := func(name string, value ast.Expr, body ast.Expr) ast.Expr {
+		return &ast.BlockV2{Decl: ast.NewLet(name, value), Body: body}
+	}
+	fn := func(name string, args []string, fb ast.Expr, body ast.Expr) ast.Expr {
+		return &ast.BlockV2{Decl: &ast.FuncDeclaration{Name: name, Args: args, Body: fb}, Body: body}
+	}
+	call := func(name string, args ...ast.Expr) ast.Expr {
+		return ast.NewFunctionCall(name, ast.NewExprs(args...))
+	}
+	ref := func(name string) ast.Expr { return &ast.RefExpr{Name: name} }
+	parse := call("parseIntValue", ast.NewString("1"))
+	long := ast.NewLong
+	// Expected costs are calculated by hand following the rules of ScriptEstimatorV3 of Scala node.
+	for _, test := range []struct {
+		code string
+		expr ast.Expr
+		cost uint64
+	}{
+		{`let a = parseIntValue("1"); func f() = a; f() + f() == 2`,
+			let("a", parse, fn("f", nil, ref("a"), call("0", call("100", call("f"), call("f")), long(2)))), 45},
+		{`let a = parseIntValue("1"); func f() = a; a + f() == 2`,
+			let("a", parse, fn("f", nil, ref("a"), call("0", call("100", ref("a"), call("f")), long(2)))), 24},
+		{`func f(x: Int, y: Int) = x + x + y; f(1, 2) == 3`,
+			fn("f", []string{"x", "y"}, call("100", call("100", ref("x"), ref("x")), ref("y")), call("0", call("f", long(1), long(2)), long(3))), 18},
+		{`if (true) then parseIntValue("1") else 0`,
+			ast.NewIf(ast.NewBoolean(true), parse, long(0)), 23},
+		{`let a = parseIntValue("1"); if (a == 1) then a else 0`,
+			let("a", parse, ast.NewIf(call("0", ref("a"), long(1)), ref("a"), long(0))), 25},
+		{`let a = parseIntValue("1"); func f() = 1; f() == 1`,
+			let("a", parse, fn("f", nil, long(1), call("0", call("f"), long(1)))), 3},
+		{`let a = {let b = parseIntValue("1"); b + b}; a + a == 4`,
+			let("a", let("b", parse, call("100", ref("b"), ref("b"))), call("0", call("100", ref("a"), ref("a")), long(4))), 25},
+		{`let a = 1; let b = {let a = parseIntValue("1"); a}; a + b == 2`,
+			let("a", long(1), let("b", let("a", parse, ref("a")), call("0", call("100", ref("a"), ref("b")), long(2)))), 25},
+		{`func f(x: Int) = {let y = x + 1; y}; f(1) + f(2) == 5`,
+			fn("f", []string{"x"}, let("y", call("100", ref("x"), long(1)), ref("y")), call("0", call("100", call("f", long(1)), call("f", long(2))), long(5))), 21},
+		{`func g(x: Int) = x; func f(y: Int) = g(y) + 1; f(1) == 2`,
+			fn("g", []string{"x"}, ref("x"), fn("f", []string{"y"}, call("100", call("g", ref("y")), long(1)), call("0", call("f", long(1)), long(2)))), 17},
+		{`func f() = {let a = parseIntValue("1"); 1}; f() == 1`,
+			fn("f", nil, let("a", parse, long(1)), call("0", call("f"), long(1))), 3},
+	} {
+		script := &ast.Script{Version: 3, HasBlockV2: true, Verifier: test.expr}
+		cost, err := NewEstimatorForScript(3, script).Estimate(script)
+		require.NoError(t, err, test.code)
+		assert.Equal(t, int(test.cost), int(cost.Verifier), test.code)
+	}
+}
+
+func TestEstimatorV3Errors(t *testing.T) {
+	for _, test := range []struct {
+		code string
+		expr ast.Expr
+		err  string
+	}{
+		{`func f() = z; true`,
+			&ast.BlockV2{Decl: &ast.FuncDeclaration{Name: "f", Body: &ast.RefExpr{Name: "z"}}, Body: ast.NewBoolean(true)},
+			"estimation: no variable 'z' in context"},
+		{`func f() = f(); f()`,
+			&ast.BlockV2{Decl: &ast.FuncDeclaration{Name: "f", Body: ast.NewFunctionCall("f", ast.NewExprs())}, Body: ast.NewFunctionCall("f", ast.NewExprs())},
+			"estimation: EstimatorV3: no user function 'f' in scope"},
+		{`unit == unit`,
+			ast.NewFunctionCall("0", ast.NewExprs(ast.NewUnit(), ast.NewUnit())),
+			"estimation: unsupported expression of type *ast.Unit"},
+	} {
+		script := &ast.Script{Version: 3, HasBlockV2: true, Verifier: test.expr}
+		_, err := NewEstimatorForScript(3, script).Estimate(script)
+		assert.EqualError(t, err, test.err, test.code)
+	}
+}
@@ -0,0 +1,264 @@
+package estimation
+
+import (
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+)
+
+// Estimator of version 3 follows the rules of evaluation more closely than the previous ones:
+//  - constants cost 1, declarations of variables and functions cost nothing;
+//  - the value of variable is estimated on first reference only, subsequent references cost nothing;
+//  - the body of user function is estimated on every call, its arguments cost 1 and 5 more per argument,
+//    variables referenced during the call are estimated anew by the next call;
+//  - condition costs 1 plus the cost of the most expensive branch.
+
+// letV3 is the declaration of variable, the predefined variables have no expression and cost nothing.
+type letV3 struct {
+	expr  ast.Expr
+	scope *scopeV3
+}
+
+type functionV3 struct {
+	decl  *ast.FuncDeclaration
+	scope *scopeV3
+}
+
+type scopeV3 struct {
+	parent    *scopeV3
+	lets      map[string]*letV3
+	functions map[string]*functionV3
+}
+
+func newScopeV3(parent *scopeV3) *scopeV3 {
+	return &scopeV3{parent: parent, lets: make(map[string]*letV3), functions: make(map[string]*functionV3)}
+}
+
+func (s *scopeV3) let(name string) (*letV3, bool) {
+	for ; s != nil; s = s.parent {
+		if l, ok := s.lets[name]; ok {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scopeV3) function(name string) (*functionV3, bool) {
+	for ; s != nil; s = s.parent {
+		if f, ok := s.functions[name]; ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// arguments creates the scope of function with arguments which cost 1 each.
+func (s *scopeV3) arguments(names ...string) *scopeV3 {
+	r := newScopeV3(s)
+	for _, n := range names {
+		r.lets[n] = &letV3{expr: ast.NewBoolean(true)}
+	}
+	return r
+}
+
+func rootScopeV3(variables map[string]ast.Expr) *scopeV3 {
+	root := newScopeV3(nil)
+	for k := range variables {
+		root.lets[k] = &letV3{}
+	}
+	for _, k := range []string{"height", "tx", "this"} {
+		root.lets[k] = &letV3{}
+	}
+	return root
+}
+
+// walkerV3 holds the state of single estimation, the set of variables already estimated on the current path.
+type walkerV3 struct {
+	catalogue *Catalogue
+	used      map[*letV3]struct{}
+}
+
+func (e *Estimator) walkerV3() *walkerV3 {
+	return &walkerV3{catalogue: e.catalogue, used: make(map[*letV3]struct{})}
+}
+
+func (w *walkerV3) saveUsed() map[*letV3]struct{} {
+	r := make(map[*letV3]struct{}, len(w.used))
+	for k := range w.used {
+		r[k] = struct{}{}
+	}
+	return r
+}
+
+func (e *Estimator) estimateVerifierV3(script *ast.Script) (Costs, error) {
+	c, err := e.walkerV3().estimate(script.Verifier, rootScopeV3(e.variables))
+	if err != nil {
+		return Costs{}, errors.Wrap(err, "estimation")
+	}
+	return Costs{Verifier: c}, nil
+}
+
+func (e *Estimator) estimateDAppV3(script *ast.Script) (Costs, error) {
+	root := rootScopeV3(e.variables)
+	delete(root.lets, "tx")
+	r := Costs{Functions: make(map[string]uint64, len(script.DApp.CallableFuncs))}
+	for _, cf := range script.DApp.CallableFuncs {
+		c, err := e.estimateCallableV3(script.DApp.Declarations, root, cf)
+		if err != nil {
+			return Costs{}, errors.Wrap(err, "estimation")
+		}
+		r.Functions[cf.FuncDecl.Name] = c
+		if c > r.DApp {
+			r.DApp = c
+		}
+	}
+	if script.DApp.Verifier != nil {
+		c, err := e.estimateCallableV3(script.DApp.Declarations, root, script.DApp.Verifier)
+		if err != nil {
+			return Costs{}, errors.Wrap(err, "estimation")
+		}
+		r.Verifier = c
+		if c > r.DApp {
+			r.DApp = c
+		}
+	}
+	return r, nil
+}
+
+// estimateCallableV3 estimates the callable function as a call of it with constant arguments made after the global declarations.
+// Global variables are declared for every callable anew, so each callable pays for the variables it uses.
+func (e *Estimator) estimateCallableV3(declarations ast.Exprs, root *scopeV3, callable *ast.DappCallableFunc) (uint64, error) {
+	globals := newScopeV3(root)
+	for _, d := range declarations {
+		switch decl := d.(type) {
+		case *ast.LetExpr:
+			globals.lets[decl.Name] = &letV3{expr: decl.Value, scope: globals}
+		case *ast.FuncDeclaration:
+			globals.functions[decl.Name] = &functionV3{decl: decl, scope: globals}
+		default:
+			return 0, errors.Errorf("unsupported declaration of type %T", d)
+		}
+	}
+	scope := globals.arguments(callable.AnnotationInvokeName).arguments(callable.FuncDecl.Args...)
+	bc, err := e.walkerV3().estimate(callable.FuncDecl.Body, scope)
+	if err != nil {
+		return 0, err
+	}
+	argc := uint64(len(callable.FuncDecl.Args))
+	return argc + argc*5 + bc, nil
+}
+
+func (w *walkerV3) estimate(expr ast.Expr, scope *scopeV3) (uint64, error) {
+	switch ce := expr.(type) {
+	case *ast.StringExpr, *ast.LongExpr, *ast.BooleanExpr, *ast.BytesExpr:
+		return 1, nil
+
+	case ast.Exprs:
+		var total uint64 = 0
+		for _, item := range ce {
+			c, err := w.estimate(item, scope)
+			if err != nil {
+				return 0, err
+			}
+			total += c
+		}
+		return total, nil
+
+	case *ast.Block:
+		inner := newScopeV3(scope)
+		inner.lets[ce.Let.Name] = &letV3{expr: ce.Let.Value, scope: scope}
+		return w.estimate(ce.Body, inner)
+
+	case *ast.BlockV2:
+		inner := newScopeV3(scope)
+		switch declaration := ce.Decl.(type) {
+		case *ast.LetExpr:
+			inner.lets[declaration.Name] = &letV3{expr: declaration.Value, scope: scope}
+		case *ast.FuncDeclaration:
+			f := &functionV3{decl: declaration, scope: scope}
+			if err := w.check(f); err != nil {
+				return 0, err
+			}
+			inner.functions[declaration.Name] = f
+		default:
+			return 0, errors.Errorf("unsupported content of type %T", ce.Decl)
+		}
+		return w.estimate(ce.Body, inner)
+
+	case *ast.FuncCallExpr:
+		return w.estimate(ce.Func, scope)
+
+	case *ast.FunctionCall:
+		ac, err := w.estimate(ce.Argv, scope)
+		if err != nil {
+			return 0, err
+		}
+		if f, ok := scope.function(ce.Name); ok {
+			if na := len(f.decl.Args); na != ce.Argc {
+				return 0, errors.Errorf("unexpected number of arguments %d, function '%s' accepts %d arguments", ce.Argc, ce.Name, na)
+			}
+			bc, err := w.call(f)
+			if err != nil {
+				return 0, err
+			}
+			return ac + bc + uint64(len(f.decl.Args)*5), nil
+		}
+		fc, ok := w.catalogue.FunctionCost(ce.Name)
+		if !ok {
+			return 0, errors.Errorf("EstimatorV3: no user function '%s' in scope", ce.Name)
+		}
+		return fc + ac, nil
+
+	case *ast.RefExpr:
+		l, ok := scope.let(ce.Name)
+		if !ok {
+			return 0, errors.Errorf("no variable '%s' in context", ce.Name)
+		}
+		if _, ok := w.used[l]; ok || l.expr == nil {
+			return 0, nil
+		}
+		w.used[l] = struct{}{}
+		return w.estimate(l.expr, l.scope)
+
+	case *ast.IfExpr:
+		cc, err := w.estimate(ce.Condition, scope)
+		if err != nil {
+			return 0, err
+		}
+		tc, err := w.estimate(ce.True, scope)
+		if err != nil {
+			return 0, err
+		}
+		fc, err := w.estimate(ce.False, scope)
+		if err != nil {
+			return 0, err
+		}
+		if tc > fc {
+			return cc + tc + 1, nil
+		}
+		return cc + fc + 1, nil
+
+	case *ast.GetterExpr:
+		c, err := w.estimate(ce.Object, scope)
+		if err != nil {
+			return 0, err
+		}
+		return c + 1, nil
+
+	default:
+		return 0, errors.Errorf("unsupported expression of type %T", expr)
+	}
+}
+
+// call estimates the body of user function. Variables estimated during the call are forgotten after it.
+func (w *walkerV3) call(f *functionV3) (uint64, error) {
+	saved := w.saveUsed()
+	defer func() { w.used = saved }()
+	return w.estimate(f.decl.Body, f.scope.arguments(f.decl.Args...))
+}
+
+// check estimates the body of user function at the declaration to reject the functions with undefined references,
+// even if they are never called.
+func (w *walkerV3) check(f *functionV3) error {
+	_, err := w.call(f)
+	return err
+}
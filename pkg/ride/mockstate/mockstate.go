@@ -1,3 +1,4 @@
+// Package mockstate provides in-memory implementation of types.SmartState to evaluate scripts without blockchain.
 package mockstate
 
 import (
@@ -5,8 +6,20 @@ import (
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/types"
 )
 
+var _ types.SmartState = State{}
+
+// Account holds the balances and the data entries of one account.
+type Account struct {
+	WavesBalance   uint64
+	AssetsBalances map[crypto.Digest]uint64
+	DataEntries    map[string]proto.DataEntry
+}
+
+// State is the in-memory SmartState. The values of Accounts take precedence, the balances and the data entries
+// of the accounts missing in Accounts are taken from the shared fields WavesBalance, AssetsBalances and DataEntries.
 type State struct {
 	TransactionsByID       map[string]proto.Transaction
 	TransactionsHeightByID map[string]uint64
@@ -17,31 +30,77 @@ type State struct {
 	BlockHeaderByHeight    *proto.BlockHeader
 	NewestHeightVal        proto.Height
 	Assets                 map[crypto.Digest]proto.AssetInfo
+
+	Accounts     map[proto.Address]*Account
+	Aliases      map[string]proto.Address
+	BlockHeaders map[proto.Height]*proto.BlockHeader
+}
+
+// New creates the empty state with all maps initialized.
+func New() State {
+	return State{
+		TransactionsByID:       make(map[string]proto.Transaction),
+		TransactionsHeightByID: make(map[string]uint64),
+		AssetsBalances:         make(map[crypto.Digest]uint64),
+		DataEntries:            make(map[string]proto.DataEntry),
+		Assets:                 make(map[crypto.Digest]proto.AssetInfo),
+		Accounts:               make(map[proto.Address]*Account),
+		Aliases:                make(map[string]proto.Address),
+		BlockHeaders:           make(map[proto.Height]*proto.BlockHeader),
+	}
 }
 
-func (a State) NewestAccountBalance(account proto.Recipient, asset []byte) (uint64, error) {
-	if asset == nil {
-		return a.WavesBalance, nil
-	} else {
-		d, err := crypto.NewDigestFromBytes(asset)
-		if err != nil {
-			return 0, err
+// Account returns the account of address, it's created if missing. Accounts map must be initialized.
+func (a State) Account(addr proto.Address) *Account {
+	acc, ok := a.Accounts[addr]
+	if !ok {
+		acc = &Account{AssetsBalances: make(map[crypto.Digest]uint64), DataEntries: make(map[string]proto.DataEntry)}
+		a.Accounts[addr] = acc
+	}
+	return acc
+}
+
+// account returns the account of recipient or the shared values if the account or the alias is unknown.
+func (a State) account(recipient proto.Recipient) *Account {
+	var addr *proto.Address
+	switch {
+	case recipient.Address != nil:
+		addr = recipient.Address
+	case recipient.Alias != nil:
+		if v, err := a.NewestAddrByAlias(*recipient.Alias); err == nil {
+			addr = &v
 		}
-		if a.AssetsBalances != nil {
-			if b, ok := a.AssetsBalances[d]; ok {
-				return b, nil
-			}
+	}
+	if addr != nil {
+		if acc, ok := a.Accounts[*addr]; ok {
+			return acc
 		}
-		return 0, nil
 	}
+	return &Account{WavesBalance: a.WavesBalance, AssetsBalances: a.AssetsBalances, DataEntries: a.DataEntries}
+}
+
+func (a State) NewestAccountBalance(account proto.Recipient, asset []byte) (uint64, error) {
+	acc := a.account(account)
+	if asset == nil {
+		return acc.WavesBalance, nil
+	}
+	d, err := crypto.NewDigestFromBytes(asset)
+	if err != nil {
+		return 0, err
+	}
+	return acc.AssetsBalances[d], nil
 }
 
 func (a State) NewestAddrByAlias(alias proto.Alias) (proto.Address, error) {
-	panic("implement NewestAddrByAlias")
+	addr, ok := a.Aliases[alias.Alias]
+	if !ok {
+		return proto.Address{}, proto.ErrNotFound
+	}
+	return addr, nil
 }
 
 func (a State) RetrieveNewestEntry(account proto.Recipient, key string) (proto.DataEntry, error) {
-	v, ok := a.DataEntries[key]
+	v, ok := a.account(account).DataEntries[key]
 	if !ok {
 		return nil, errors.Errorf("key not found '%s'", key)
 	}
@@ -49,9 +108,9 @@ func (a State) RetrieveNewestEntry(account proto.Recipient, key string) (proto.D
 }
 
 func (a State) RetrieveNewestIntegerEntry(account proto.Recipient, key string) (*proto.IntegerDataEntry, error) {
-	v, ok := a.DataEntries[key]
-	if !ok {
-		return nil, errors.Errorf("key not found '%s'", key)
+	v, err := a.RetrieveNewestEntry(account, key)
+	if err != nil {
+		return nil, err
 	}
 	iv, ok := v.(*proto.IntegerDataEntry)
 	if !ok {
@@ -61,9 +120,9 @@ func (a State) RetrieveNewestIntegerEntry(account proto.Recipient, key string) (
 }
 
 func (a State) RetrieveNewestBooleanEntry(account proto.Recipient, key string) (*proto.BooleanDataEntry, error) {
-	v, ok := a.DataEntries[key]
-	if !ok {
-		return nil, errors.Errorf("key not found '%s'", key)
+	v, err := a.RetrieveNewestEntry(account, key)
+	if err != nil {
+		return nil, err
 	}
 	bv, ok := v.(*proto.BooleanDataEntry)
 	if !ok {
@@ -73,9 +132,9 @@ func (a State) RetrieveNewestBooleanEntry(account proto.Recipient, key string) (
 }
 
 func (a State) RetrieveNewestStringEntry(account proto.Recipient, key string) (*proto.StringDataEntry, error) {
-	v, ok := a.DataEntries[key]
-	if !ok {
-		return nil, errors.Errorf("key not found '%s'", key)
+	v, err := a.RetrieveNewestEntry(account, key)
+	if err != nil {
+		return nil, err
 	}
 	sv, ok := v.(*proto.StringDataEntry)
 	if !ok {
@@ -85,9 +144,9 @@ func (a State) RetrieveNewestStringEntry(account proto.Recipient, key string) (*
 }
 
 func (a State) RetrieveNewestBinaryEntry(account proto.Recipient, key string) (*proto.BinaryDataEntry, error) {
-	v, ok := a.DataEntries[key]
-	if !ok {
-		return nil, errors.Errorf("key not found '%s'", key)
+	v, err := a.RetrieveNewestEntry(account, key)
+	if err != nil {
+		return nil, err
 	}
 	bv, ok := v.(*proto.BinaryDataEntry)
 	if !ok {
@@ -121,10 +180,19 @@ func (a State) AddingBlockHeight() (uint64, error) {
 }
 
 func (a State) NewestAssetIsSponsored(assetID crypto.Digest) (bool, error) {
+	if info, ok := a.Assets[assetID]; ok && info.Sponsored {
+		return true, nil
+	}
 	return a.AssetIsSponsored, nil
 }
 
 func (a State) NewestHeaderByHeight(height proto.Height) (*proto.BlockHeader, error) {
+	if h, ok := a.BlockHeaders[height]; ok {
+		return h, nil
+	}
+	if a.BlockHeaderByHeight == nil {
+		return nil, proto.ErrNotFound
+	}
 	return a.BlockHeaderByHeight, nil
 }
 
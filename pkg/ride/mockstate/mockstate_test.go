@@ -0,0 +1,51 @@
+package mockstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestState_Accounts(t *testing.T) {
+	st := New()
+	st.WavesBalance = 1
+	st.DataEntries["key"] = &proto.IntegerDataEntry{Key: "key", Value: 1}
+	addr, err := proto.NewAddressFromPublicKey(proto.MainNetScheme, crypto.PublicKey{1})
+	require.NoError(t, err)
+	other, err := proto.NewAddressFromPublicKey(proto.MainNetScheme, crypto.PublicKey{2})
+	require.NoError(t, err)
+	acc := st.Account(addr)
+	acc.WavesBalance = 10
+	acc.AssetsBalances[crypto.Digest{1}] = 20
+	acc.DataEntries["key"] = &proto.StringDataEntry{Key: "key", Value: "value"}
+	st.Aliases["alice"] = addr
+
+	alias := proto.NewRecipientFromAlias(*proto.NewAlias(proto.MainNetScheme, "alice"))
+	b, err := st.NewestAccountBalance(alias, nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), b)
+	b, err = st.NewestAccountBalance(proto.NewRecipientFromAddress(addr), crypto.Digest{1}.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), b)
+	s, err := st.RetrieveNewestStringEntry(alias, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", s.Value)
+	_, err = st.RetrieveNewestIntegerEntry(alias, "key")
+	assert.Error(t, err)
+
+	// Unknown accounts get the shared values.
+	b, err = st.NewestAccountBalance(proto.NewRecipientFromAddress(other), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), b)
+	i, err := st.RetrieveNewestIntegerEntry(proto.NewRecipientFromAddress(other), "key")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), i.Value)
+
+	_, err = st.NewestAddrByAlias(*proto.NewAlias(proto.MainNetScheme, "bob"))
+	assert.True(t, st.IsNotFound(err))
+	_, err = st.NewestHeaderByHeight(1)
+	assert.True(t, st.IsNotFound(err))
+}
@@ -108,6 +108,28 @@ var (
 			MinUpdateAssetInfoInterval: 100000,
 		},
 	}
+	// DefaultDevSettings is the profile of custom blockchain for development mode, when blocks are produced on demand
+	// and the node's time runs ahead of the local clock. Transactions with timestamps from the local clock are
+	// accepted for a long time, main features are preactivated.
+	DefaultDevSettings = &BlockchainSettings{
+		Type: Custom,
+		FunctionalitySettings: FunctionalitySettings{
+			FeaturesVotingPeriod:             100,
+			VotesForFeatureActivation:        40,
+			PreactivatedFeatures:             []int16{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13},
+			DoubleFeaturesPeriodsAfterHeight: math.MaxInt32,
+			MaxTxTimeBackOffset:              30 * 24 * 60 * 60000,
+			MaxTxTimeForwardOffset:           90 * 60000,
+			AddressSchemeCharacter:           proto.CustomNetScheme,
+			AverageBlockDelaySeconds:         10,
+			MaxBaseTarget:                    math.MaxUint64,
+			BlockRewardTerm:                  100000,
+			InitialBlockReward:               600000000,
+			BlockRewardIncrement:             50000000,
+			BlockRewardVotingPeriod:          10000,
+			MinUpdateAssetInfoInterval:       10,
+		},
+	}
 )
 
 func mustLoadEmbeddedSettings(blockchain BlockchainType) *BlockchainSettings {
@@ -31,14 +31,19 @@ type accountsDataStorage struct {
 
 	addrToNumMem map[proto.Address]uint64
 	addrNum      uint64
+
+	calculateHashes bool
+	hasher          *stateHasher
 }
 
-func newAccountsDataStorage(db keyvalue.IterableKeyVal, dbBatch keyvalue.Batch, hs *historyStorage) (*accountsDataStorage, error) {
+func newAccountsDataStorage(db keyvalue.IterableKeyVal, dbBatch keyvalue.Batch, hs *historyStorage, calcHashes bool) (*accountsDataStorage, error) {
 	return &accountsDataStorage{
-		db:           db,
-		dbBatch:      dbBatch,
-		hs:           hs,
-		addrToNumMem: make(map[proto.Address]uint64),
+		db:              db,
+		dbBatch:         dbBatch,
+		hs:              hs,
+		addrToNumMem:    make(map[proto.Address]uint64),
+		calculateHashes: calcHashes,
+		hasher:          newStateHasher(),
 	}, nil
 }
 
@@ -114,6 +119,10 @@ func (s *accountsDataStorage) appendEntry(addr proto.Address, entry proto.DataEn
 	if err := s.hs.addNewEntry(dataEntry, key.bytes(), recordBytes, blockID); err != nil {
 		return err
 	}
+	if s.calculateHashes {
+		hashKey := append(addr.Bytes(), entry.GetKey()...)
+		s.hasher.push(hashKey, valueBytes, blockID)
+	}
 	return nil
 }
 
@@ -339,3 +348,7 @@ func (s *accountsDataStorage) reset() {
 	s.addrToNumMem = make(map[proto.Address]uint64)
 	s.addrNum = 0
 }
+
+func (s *accountsDataStorage) resetHashes() {
+	s.hasher.reset()
+}
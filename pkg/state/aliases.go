@@ -10,7 +10,8 @@ import (
 var errAliasDisabled = errors.New("alias was stolen and is now disabled")
 
 const (
-	aliasRecordSize = 1 + proto.AddressSize
+	aliasRecordSize       = 1 + proto.AddressSize
+	aliasByAddrRecordSize = 1
 )
 
 type aliasInfo struct {
@@ -42,14 +43,44 @@ func (r *aliasRecord) unmarshalBinary(data []byte) error {
 	return nil
 }
 
+// aliasByAddrRecord tells if the address still owns the alias it has created.
+// The alias is lost when it is stolen by another address.
+type aliasByAddrRecord struct {
+	owned bool
+}
+
+func (r *aliasByAddrRecord) marshalBinary() ([]byte, error) {
+	res := make([]byte, aliasByAddrRecordSize)
+	proto.PutBool(res, r.owned)
+	return res, nil
+}
+
+func (r *aliasByAddrRecord) unmarshalBinary(data []byte) error {
+	if len(data) != aliasByAddrRecordSize {
+		return errInvalidDataSize
+	}
+	var err error
+	r.owned, err = proto.Bool(data)
+	return err
+}
+
 type aliases struct {
 	db      keyvalue.IterableKeyVal
 	dbBatch keyvalue.Batch
 	hs      *historyStorage
+
+	calculateHashes bool
+	hasher          *stateHasher
 }
 
-func newAliases(db keyvalue.IterableKeyVal, dbBatch keyvalue.Batch, hs *historyStorage) (*aliases, error) {
-	return &aliases{db, dbBatch, hs}, nil
+func newAliases(db keyvalue.IterableKeyVal, dbBatch keyvalue.Batch, hs *historyStorage, calcHashes bool) (*aliases, error) {
+	return &aliases{
+		db:              db,
+		dbBatch:         dbBatch,
+		hs:              hs,
+		calculateHashes: calcHashes,
+		hasher:          newStateHasher(),
+	}, nil
 }
 
 func (a *aliases) createAlias(aliasStr string, info *aliasInfo, blockID proto.BlockID) error {
@@ -59,9 +90,54 @@ func (a *aliases) createAlias(aliasStr string, info *aliasInfo, blockID proto.Bl
 	if err != nil {
 		return err
 	}
+	if a.calculateHashes {
+		hashKey := append(info.addr.Bytes(), aliasStr...)
+		a.hasher.push(hashKey, nil, blockID)
+	}
+	if info.stolen {
+		prevAddr, err := a.newestOwner(aliasStr)
+		if err != nil && err != keyvalue.ErrNotFound && err != errEmptyHist {
+			return err
+		}
+		if err == nil && prevAddr != info.addr {
+			if err := a.setOwned(prevAddr, aliasStr, false, blockID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := a.setOwned(info.addr, aliasStr, true, blockID); err != nil {
+		return err
+	}
 	return a.hs.addNewEntry(alias, key.bytes(), recordBytes, blockID)
 }
 
+func (a *aliases) newestOwner(aliasStr string) (proto.Address, error) {
+	key := aliasKey{alias: aliasStr}
+	recordBytes, err := a.hs.freshLatestEntryData(key.bytes(), true)
+	if err != nil {
+		return proto.Address{}, err
+	}
+	var record aliasRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return proto.Address{}, errors.Errorf("failed to unmarshal record: %v", err)
+	}
+	return record.info.addr, nil
+}
+
+func (a *aliases) setOwned(addr proto.Address, aliasStr string, owned bool, blockID proto.BlockID) error {
+	key := aliasByAddrKey{addr: addr, alias: aliasStr}
+	r := aliasByAddrRecord{owned: owned}
+	recordBytes, err := r.marshalBinary()
+	if err != nil {
+		return err
+	}
+	return a.hs.addNewEntry(aliasByAddr, key.bytes(), recordBytes, blockID)
+}
+
+func (a *aliases) resetHashes() {
+	a.hasher.reset()
+}
+
 func (a *aliases) exists(aliasStr string, filter bool) bool {
 	key := aliasKey{alias: aliasStr}
 	if _, err := a.hs.freshLatestEntryData(key.bytes(), filter); err != nil {
@@ -123,6 +199,53 @@ func (a *aliases) addrByAlias(aliasStr string, filter bool) (*proto.Address, err
 	return &record.info.addr, nil
 }
 
+// aliasesByAddr returns the aliases owned by the address, except the disabled ones.
+// Only the aliases from the blocks saved to DB are returned.
+func (a *aliases) aliasesByAddr(addr proto.Address, filter bool) ([]string, error) {
+	iter, err := a.db.NewKeyIterator(aliasesByAddrPrefix(addr))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	var res []string
+	for iter.Next() {
+		recordBytes, err := a.hs.latestEntryData(iter.Key(), filter)
+		if err == keyvalue.ErrNotFound || err == errEmptyHist {
+			// All the records were rolled back.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record aliasByAddrRecord
+		if err := record.unmarshalBinary(recordBytes); err != nil {
+			return nil, errors.Errorf("failed to unmarshal record: %v", err)
+		}
+		if !record.owned {
+			continue
+		}
+		var key aliasByAddrKey
+		if err := key.unmarshal(iter.Key()); err != nil {
+			return nil, err
+		}
+		disabled, err := a.isDisabled(key.alias)
+		if err != nil {
+			return nil, err
+		}
+		if disabled {
+			continue
+		}
+		res = append(res, key.alias)
+	}
+	return res, nil
+}
+
 func (a *aliases) disableStolenAliases() error {
 	// TODO: this action can not be rolled back now, do we need it?
 	iter, err := a.db.NewKeyIterator([]byte{aliasKeyPrefix})
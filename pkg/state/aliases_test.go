@@ -18,7 +18,7 @@ func createAliases() (*aliasesTestObjects, []string, error) {
 	if err != nil {
 		return nil, path, err
 	}
-	aliases, err := newAliases(stor.db, stor.dbBatch, stor.hs)
+	aliases, err := newAliases(stor.db, stor.dbBatch, stor.hs, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -84,3 +84,64 @@ func TestDisableStolenAliases(t *testing.T) {
 	_, err = to.aliases.newestAddrByAlias(aliasStr, true)
 	assert.Equal(t, errAliasDisabled, err)
 }
+
+func TestAliasesByAddr(t *testing.T) {
+	to, path, err := createAliases()
+	assert.NoError(t, err, "createAliases() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	addr0 := testGlobal.senderInfo.addr
+	addr1 := testGlobal.recipientInfo.addr
+	to.stor.addBlock(t, blockID0)
+	err = to.aliases.createAlias("first", &aliasInfo{false, addr0}, blockID0)
+	assert.NoError(t, err, "createAlias() failed")
+	err = to.aliases.createAlias("second", &aliasInfo{false, addr0}, blockID0)
+	assert.NoError(t, err, "createAlias() failed")
+	to.stor.flush(t)
+	aliases, err := to.aliases.aliasesByAddr(addr0, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Equal(t, []string{"first", "second"}, aliases)
+
+	// Stolen alias belongs to the new owner.
+	to.stor.addBlock(t, blockID1)
+	err = to.aliases.createAlias("second", &aliasInfo{true, addr1}, blockID1)
+	assert.NoError(t, err, "createAlias() failed")
+	to.stor.flush(t)
+	aliases, err = to.aliases.aliasesByAddr(addr0, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Equal(t, []string{"first"}, aliases)
+	aliases, err = to.aliases.aliasesByAddr(addr1, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Equal(t, []string{"second"}, aliases)
+
+	// Rollback returns stolen alias to the previous owner.
+	err = to.stor.stateDB.rollbackBlock(blockID1)
+	assert.NoError(t, err, "rollbackBlock() failed")
+	aliases, err = to.aliases.aliasesByAddr(addr0, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Equal(t, []string{"first", "second"}, aliases)
+	aliases, err = to.aliases.aliasesByAddr(addr1, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Empty(t, aliases)
+
+	// Disabled aliases are not returned.
+	to.stor.addBlock(t, blockID1)
+	err = to.aliases.createAlias("second", &aliasInfo{true, addr1}, blockID1)
+	assert.NoError(t, err, "createAlias() failed")
+	to.stor.flush(t)
+	err = to.aliases.disableStolenAliases()
+	assert.NoError(t, err, "disableStolenAliases() failed")
+	to.stor.flush(t)
+	aliases, err = to.aliases.aliasesByAddr(addr1, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Empty(t, aliases)
+	aliases, err = to.aliases.aliasesByAddr(addr0, true)
+	assert.NoError(t, err, "aliasesByAddr() failed")
+	assert.Equal(t, []string{"first"}, aliases)
+}
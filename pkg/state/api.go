@@ -75,6 +75,7 @@ type StateInfo interface {
 
 	// Aliases.
 	AddrByAlias(alias proto.Alias) (proto.Address, error)
+	AliasesByAddr(addr proto.Address) ([]proto.Alias, error)
 
 	// Accounts data storage.
 	RetrieveEntries(account proto.Recipient) ([]proto.DataEntry, error)
@@ -94,21 +95,39 @@ type StateInfo interface {
 
 	// Asset fee sponsorship.
 	AssetIsSponsored(assetID crypto.Digest) (bool, error)
+	// Current and historical minimal fees of the asset, number of sponsored transactions and balance of the sponsor.
+	SponsorshipInfo(assetID crypto.Digest) (*proto.SponsorshipInfo, error)
 	AssetInfo(assetID crypto.Digest) (*proto.AssetInfo, error)
 	FullAssetInfo(assetID crypto.Digest) (*proto.FullAssetInfo, error)
 
 	// Script information.
 	ScriptInfoByAccount(account proto.Recipient) (*proto.ScriptInfo, error)
 	ScriptInfoByAsset(assetID crypto.Digest) (*proto.ScriptInfo, error)
+	// Scripts that account or asset has had, from the oldest to the current one. At most limit scripts set
+	// after the given height are returned, zero limit means no limit.
+	ScriptHistoryByAccount(account proto.Recipient, after uint64, limit int) ([]proto.ScriptHistoryEntry, error)
+	ScriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int) ([]proto.ScriptHistoryEntry, error)
+	// Statistics of script executions for each window of last blocks.
+	ScriptStatsByAccount(account proto.Recipient, windows []uint64) ([]proto.ScriptStats, error)
+	ScriptStatsByAsset(assetID crypto.Digest, windows []uint64) ([]proto.ScriptStats, error)
 
 	// Leases.
 	IsActiveLeasing(leaseID crypto.Digest) (bool, error)
+	LeaseInfo(leaseID crypto.Digest) (*proto.LeaseInfo, error)
+	// Leases received or sent by the address, the newest first. Empty status selects leases of any status.
+	// If after is not nil, the leases next to the lease with this ID are returned. Zero limit means no limit.
+	LeasesByAddr(addr proto.Address, incoming bool, status proto.LeaseStatus, after *crypto.Digest, limit int) ([]proto.LeaseInfo, error)
 
 	// Invoke results.
 	InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error)
 
 	// True if state stores additional information in order to provide extended API.
 	ProvidesExtendedApi() (bool, error)
+
+	// True if state calculates and stores state hashes of blocks.
+	ProvidesStateHashes() (bool, error)
+	// StateHashAtHeight returns legacy state hash of the block at given height.
+	StateHashAtHeight(height proto.Height) (*proto.StateHash, error)
 }
 
 // StateModifier contains all the methods needed to modify node's state.
@@ -146,6 +165,9 @@ type StateModifier interface {
 	ValidateNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, blockVersion proto.BlockVersion) error
 	// ResetValidationList() resets the validation list, so you can ValidateNextTx() from scratch after calling it.
 	ResetValidationList()
+	// NewestAccountBalance() is the same as AccountBalance(), but takes into account the changes from transactions
+	// that were added using ValidateNextTx().
+	NewestAccountBalance(account proto.Recipient, asset []byte) (uint64, error)
 
 	// Create or replace Peers.
 	SavePeers([]proto.TCPAddr) error
@@ -215,6 +237,8 @@ type StateParams struct {
 	StoreExtendedApiData bool
 	// ProvideExtendedApi specifies whether state must provide data for extended API.
 	ProvideExtendedApi bool
+	// When BuildStateHashes is true, state calculates legacy state hashes of blocks.
+	BuildStateHashes bool
 }
 
 func DefaultStateParams() StateParams {
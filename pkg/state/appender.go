@@ -2,6 +2,7 @@ package state
 
 import (
 	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/types"
@@ -43,6 +44,11 @@ type txAppender struct {
 	// buildApiData flag indicates that additional data for API is built when
 	// appending transactions.
 	buildApiData bool
+
+	// stats is the index of scripts executions.
+	stats *scriptsStats
+	// Scripts executed in the blocks that are not flushed yet.
+	executions []scriptExecutionsAtHeight
 }
 
 func newTxAppender(
@@ -98,6 +104,7 @@ func newTxAppender(
 		diffStorInvoke: diffStorInvoke,
 		diffApplier:    diffApplier,
 		buildApiData:   buildApiData,
+		stats:          newScriptsStats(),
 	}, nil
 }
 
@@ -350,6 +357,8 @@ func (a *txAppender) appendBlock(params *appendBlockParams) error {
 	if err != nil {
 		return err
 	}
+	// Numbers of transactions of the block by the assets of their fees.
+	sponsoredTxs := make(map[crypto.Digest]uint64)
 	for _, tx := range params.transactions {
 		// Detect what signatures must be checked for this transaction.
 		senderAddr, err := proto.NewAddressFromPublicKey(a.settings.AddressSchemeCharacter, tx.GetSenderPK())
@@ -449,6 +458,9 @@ func (a *txAppender) appendBlock(params *appendBlockParams) error {
 		if err := a.txHandler.performTx(tx, performerInfo); err != nil {
 			return err
 		}
+		if feeAsset := feeAssetOfTx(tx); feeAsset.Present {
+			sponsoredTxs[feeAsset.ID]++
+		}
 		// Save transaction to storage.
 		if err := a.rw.writeTransaction(tx); err != nil {
 			return err
@@ -463,8 +475,12 @@ func (a *txAppender) appendBlock(params *appendBlockParams) error {
 	if err := a.checkScriptsLimits(scriptsRuns); err != nil {
 		return errors.Errorf("%s: %v", blockID.String(), err)
 	}
+	if err := a.countSponsoredFees(sponsoredTxs, blockID, params.initialisation); err != nil {
+		return err
+	}
 	// Reset block complexity counter.
 	a.sc.resetComplexity()
+	a.executions = append(a.executions, scriptExecutionsAtHeight{height: curHeight, executions: a.sc.takeExecutions()})
 	// Save fee distribution of this block.
 	// This will be needed for createMinerDiff() of next block due to NG.
 	if err := a.blockDiffer.saveCurFeeDistr(params.block); err != nil {
@@ -514,15 +530,89 @@ func (a *txAppender) handleInvoke(tx proto.Transaction, height uint64, block *pr
 	return nil
 }
 
+// commitScriptExecutions adds scripts executed in the appended blocks to scripts statistics.
+// It must be called after the blocks are flushed.
+func (a *txAppender) commitScriptExecutions() {
+	for _, e := range a.executions {
+		a.stats.add(e.height, e.executions)
+	}
+	a.executions = nil
+}
+
 func (a *txAppender) resetValidationList() {
 	a.sc.resetComplexity()
+	a.sc.takeExecutions()
 	a.totalScriptsRuns = 0
 	a.recentTxIds = make(map[string]struct{})
 	a.diffStor.reset()
 }
 
 // For UTX validation.
+// Failed scripts of rejected transactions are counted in scripts statistics.
 func (a *txAppender) validateNextTx(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, version proto.BlockVersion) error {
+	err := a.validateNextTxImpl(tx, currentTimestamp, parentTimestamp, version)
+	executions := a.sc.takeExecutions()
+	if err != nil {
+		a.countFailures(tx, executions)
+	}
+	return err
+}
+
+// countFailures adds failed scripts of the rejected transaction to scripts statistics.
+func (a *txAppender) countFailures(tx proto.Transaction, executions []scriptExecution) {
+	if len(executions) == 0 {
+		return
+	}
+	height, err := a.state.AddingBlockHeight()
+	if err != nil {
+		zap.S().Debugf("Failed to count script failures: %v", err)
+		return
+	}
+	txID, err := tx.GetID(a.settings.AddressSchemeCharacter)
+	if err != nil {
+		zap.S().Debugf("Failed to count script failures: %v", err)
+		return
+	}
+	a.stats.addFailures(height, txID, executions)
+}
+
+// feeAssetOfTx returns the asset of the fee for the transactions that could pay fee in sponsored assets.
+func feeAssetOfTx(tx proto.Transaction) proto.OptionalAsset {
+	switch t := tx.(type) {
+	case *proto.TransferWithSig:
+		return t.FeeAsset
+	case *proto.TransferWithProofs:
+		return t.FeeAsset
+	case *proto.InvokeScriptWithProofs:
+		return t.FeeAsset
+	case *proto.UpdateAssetInfoWithProofs:
+		return t.FeeAsset
+	default:
+		return proto.OptionalAsset{Present: false}
+	}
+}
+
+// countSponsoredFees adds the numbers of transactions of the block which fees were paid in sponsored assets.
+func (a *txAppender) countSponsoredFees(txs map[crypto.Digest]uint64, blockID proto.BlockID, initialisation bool) error {
+	if len(txs) == 0 {
+		return nil
+	}
+	sponsorshipActivated, err := a.stor.sponsoredAssets.isSponsorshipActivated()
+	if err != nil {
+		return err
+	}
+	if !sponsorshipActivated {
+		return nil
+	}
+	for assetID, count := range txs {
+		if err := a.stor.sponsoredAssets.countSponsoredTxs(assetID, count, blockID, !initialisation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *txAppender) validateNextTxImpl(tx proto.Transaction, currentTimestamp, parentTimestamp uint64, version proto.BlockVersion) error {
 	if err := a.checkDuplicateTxIds(tx, a.recentTxIds, currentTimestamp); err != nil {
 		return err
 	}
@@ -598,6 +688,8 @@ func (a *txAppender) validateNextTx(tx proto.Transaction, currentTimestamp, pare
 
 func (a *txAppender) reset() {
 	a.sc.resetComplexity()
+	a.sc.takeExecutions()
+	a.executions = nil
 	a.totalScriptsRuns = 0
 	a.recentTxIds = make(map[string]struct{})
 	a.diffStor.reset()
@@ -76,10 +76,22 @@ func (r *assetBalanceRecord) unmarshalBinary(data []byte) error {
 type balances struct {
 	db keyvalue.IterableKeyVal
 	hs *historyStorage
+
+	calculateHashes bool
+	wavesHasher     *stateHasher
+	assetsHasher    *stateHasher
+	leaseHasher     *stateHasher
 }
 
-func newBalances(db keyvalue.IterableKeyVal, hs *historyStorage) (*balances, error) {
-	return &balances{db, hs}, nil
+func newBalances(db keyvalue.IterableKeyVal, hs *historyStorage, calcHashes bool) (*balances, error) {
+	return &balances{
+		db:              db,
+		hs:              hs,
+		calculateHashes: calcHashes,
+		wavesHasher:     newStateHasher(),
+		assetsHasher:    newStateHasher(),
+		leaseHasher:     newStateHasher(),
+	}, nil
 }
 
 func (s *balances) cancelAllLeases(blockID proto.BlockID) error {
@@ -346,12 +358,64 @@ func (s *balances) wavesBalance(addr proto.Address, filter bool) (*balanceProfil
 
 func (s *balances) setAssetBalance(addr proto.Address, asset []byte, balance uint64, blockID proto.BlockID) error {
 	key := assetBalanceKey{address: addr, asset: asset}
+	keyBytes := key.bytes()
 	record := &assetBalanceRecord{balance}
 	recordBytes, err := record.marshalBinary()
 	if err != nil {
 		return err
 	}
-	return s.hs.addNewEntry(assetBalance, key.bytes(), recordBytes, blockID)
+	if s.calculateHashes {
+		prevBytes, err := s.hs.freshLatestEntryData(keyBytes, true)
+		if err == keyvalue.ErrNotFound || err == errEmptyHist {
+			prevBytes, err = (&assetBalanceRecord{}).marshalBinary()
+		}
+		if err != nil {
+			return err
+		}
+		// Key without prefix is address followed by asset ID.
+		s.assetsHasher.pushChange(keyBytes[1:], prevBytes, recordBytes, blockID)
+	}
+	return s.hs.addNewEntry(assetBalance, keyBytes, recordBytes, blockID)
+}
+
+// pushHashes adds the changes of Waves and lease balances to the state hashers.
+func (s *balances) pushHashes(key []byte, record *wavesBalanceRecord, blockID proto.BlockID) error {
+	prev, err := s.newestWavesRecord(key)
+	if err != nil {
+		return err
+	}
+	// Key without prefix is address.
+	addr := key[1:]
+	s.wavesHasher.pushChange(addr, wavesBalanceHashValue(prev), wavesBalanceHashValue(record), blockID)
+	s.leaseHasher.pushChange(addr, leaseBalanceHashValue(prev), leaseBalanceHashValue(record), blockID)
+	return nil
+}
+
+func wavesBalanceHashValue(record *wavesBalanceRecord) []byte {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, record.balance)
+	return value
+}
+
+func leaseBalanceHashValue(record *wavesBalanceRecord) []byte {
+	value := make([]byte, 16)
+	binary.BigEndian.PutUint64(value[:8], uint64(record.leaseIn))
+	binary.BigEndian.PutUint64(value[8:], uint64(record.leaseOut))
+	return value
+}
+
+func (s *balances) newestWavesRecord(key []byte) (*wavesBalanceRecord, error) {
+	recordBytes, err := s.hs.freshLatestEntryData(key, true)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist {
+		return &wavesBalanceRecord{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var record wavesBalanceRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return nil, err
+	}
+	return &record, nil
 }
 
 func (s *balances) setWavesBalanceImpl(key []byte, record *wavesBalanceRecord, blockID proto.BlockID) error {
@@ -359,6 +423,11 @@ func (s *balances) setWavesBalanceImpl(key []byte, record *wavesBalanceRecord, b
 	if err != nil {
 		return err
 	}
+	if s.calculateHashes {
+		if err := s.pushHashes(key, record, blockID); err != nil {
+			return err
+		}
+	}
 	return s.hs.addNewEntry(wavesBalance, key, recordBytes, blockID)
 }
 
@@ -367,3 +436,9 @@ func (s *balances) setWavesBalance(addr proto.Address, profile *balanceProfile,
 	record := &wavesBalanceRecord{*profile}
 	return s.setWavesBalanceImpl(key.bytes(), record, blockID)
 }
+
+func (s *balances) resetHashes() {
+	s.wavesHasher.reset()
+	s.assetsHasher.reset()
+	s.leaseHasher.reset()
+}
@@ -28,7 +28,7 @@ func createBalances() (*balancesTestObjects, []string, error) {
 	if err != nil {
 		return nil, path, err
 	}
-	balances, err := newBalances(stor.db, stor.hs)
+	balances, err := newBalances(stor.db, stor.hs, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -291,3 +291,63 @@ func TestBalances(t *testing.T) {
 		}
 	}
 }
+
+func TestBalancesStateHashes(t *testing.T) {
+	to, path, err := createBalances()
+	assert.NoError(t, err, "createBalances() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	to.balances.calculateHashes = true
+	to.stor.addBlock(t, blockID0)
+	to.stor.addBlock(t, blockID1)
+	addr, err := proto.NewAddressFromString(addr0)
+	assert.NoError(t, err, "NewAddressFromString() failed")
+
+	err = to.balances.setWavesBalance(addr, &balanceProfile{100, 0, 0}, blockID0)
+	assert.NoError(t, err)
+	// Only lease balance changes in the next block.
+	err = to.balances.setWavesBalance(addr, &balanceProfile{100, 5, 3}, blockID1)
+	assert.NoError(t, err)
+	err = to.balances.setAssetBalance(addr, genAsset(1), 10, blockID1)
+	assert.NoError(t, err)
+	// Intermediate values within the block are not hashed, only the changes of the whole block.
+	err = to.balances.setWavesBalance(addr, &balanceProfile{150, 5, 3}, blockID1)
+	assert.NoError(t, err)
+	err = to.balances.setWavesBalance(addr, &balanceProfile{100, 5, 3}, blockID1)
+	assert.NoError(t, err)
+	err = to.balances.setAssetBalance(addr, genAsset(2), 7, blockID1)
+	assert.NoError(t, err)
+	err = to.balances.setAssetBalance(addr, genAsset(2), 0, blockID1)
+	assert.NoError(t, err)
+
+	emptyHash, err := crypto.FastHash(nil)
+	assert.NoError(t, err)
+	wavesHash0, err := crypto.FastHash(append(addr.Bytes(), 0, 0, 0, 0, 0, 0, 0, 100))
+	assert.NoError(t, err)
+	hash, err := to.balances.wavesHasher.stateHashAt(blockID0)
+	assert.NoError(t, err)
+	assert.Equal(t, wavesHash0, hash)
+	hash, err = to.balances.leaseHasher.stateHashAt(blockID0)
+	assert.NoError(t, err)
+	assert.Equal(t, emptyHash, hash)
+
+	hash, err = to.balances.wavesHasher.stateHashAt(blockID1)
+	assert.NoError(t, err)
+	assert.Equal(t, emptyHash, hash)
+	leaseHash1, err := crypto.FastHash(append(addr.Bytes(), 0, 0, 0, 0, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 0, 3))
+	assert.NoError(t, err)
+	hash, err = to.balances.leaseHasher.stateHashAt(blockID1)
+	assert.NoError(t, err)
+	assert.Equal(t, leaseHash1, hash)
+	assetHash1, err := crypto.FastHash(append(append(addr.Bytes(), genAsset(1)...), 0, 0, 0, 0, 0, 0, 0, 10))
+	assert.NoError(t, err)
+	hash, err = to.balances.assetsHasher.stateHashAt(blockID1)
+	assert.NoError(t, err)
+	assert.Equal(t, assetHash1, hash)
+}
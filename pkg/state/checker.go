@@ -0,0 +1,879 @@
+package state
+
+// checker.go - offline integrity check of the state directory.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"go.uber.org/zap"
+)
+
+// maxReportedProblems limits the size of the report, the rest of problems are only counted.
+const maxReportedProblems = 1000
+
+// Key prefixes of the history records with the types of entities stored in them.
+var historyKeyPrefixes = map[byte]blockchainEntity{
+	wavesBalanceKeyPrefix:            wavesBalance,
+	assetBalanceKeyPrefix:            assetBalance,
+	assetHistKeyPrefix:               asset,
+	leaseKeyPrefix:                   lease,
+	aliasKeyPrefix:                   alias,
+	activatedFeaturesKeyPrefix:       activatedFeature,
+	approvedFeaturesKeyPrefix:        approvedFeature,
+	votesFeaturesKeyPrefix:           featureVote,
+	ordersVolumeKeyPrefix:            ordersVolume,
+	accountsDataStorKeyPrefix:        dataEntry,
+	sponsorshipKeyPrefix:             sponsorship,
+	accountScriptKeyPrefix:           accountScript,
+	assetScriptKeyPrefix:             assetScript,
+	accountScriptComplexityKeyPrefix: accountScriptComplexity,
+	assetScriptComplexityKeyPrefix:   assetScriptComplexity,
+	blockRewardKeyPrefix:             blockReward,
+	rewardVotesKeyPrefix:             rewardVotes,
+	invokeResultKeyPrefix:            invokeResult,
+	stateHashKeyPrefix:               stateHash,
+	aliasByAddrKeyPrefix:             aliasByAddr,
+	leaseByAddrKeyPrefix:             leaseByAddr,
+	sponsoredTxsCountKeyPrefix:       sponsoredTxsCount,
+}
+
+// CheckProblem is an inconsistency found in the state.
+type CheckProblem struct {
+	// Height of the first block affected by the problem.
+	// Zero height means that the problem can't be fixed by truncation of the state.
+	Height      uint64
+	Description string
+}
+
+func (p CheckProblem) String() string {
+	if p.Height == 0 {
+		return p.Description
+	}
+	return fmt.Sprintf("height %d: %s", p.Height, p.Description)
+}
+
+// CheckReport is the result of the state check.
+type CheckReport struct {
+	DBHeight          uint64
+	StorageHeight     uint64
+	RollbackMinHeight uint64
+	// LastConsistentHeight is the height the state can be truncated to in order to get rid of the problems.
+	LastConsistentHeight uint64
+	Problems             []CheckProblem
+	// OmittedProblems is the number of problems beyond maxReportedProblems.
+	OmittedProblems int
+}
+
+func (r *CheckReport) addProblem(height uint64, format string, args ...interface{}) {
+	if height != 0 && height-1 < r.LastConsistentHeight {
+		r.LastConsistentHeight = height - 1
+	}
+	if len(r.Problems) >= maxReportedProblems {
+		r.OmittedProblems++
+		return
+	}
+	r.Problems = append(r.Problems, CheckProblem{Height: height, Description: fmt.Sprintf(format, args...)})
+}
+
+// Consistent tells if no problems were found.
+func (r *CheckReport) Consistent() bool {
+	return len(r.Problems) == 0
+}
+
+// Repairable tells if all the problems can be fixed by truncation to LastConsistentHeight.
+func (r *CheckReport) Repairable() bool {
+	if r.OmittedProblems != 0 {
+		return false
+	}
+	for _, p := range r.Problems {
+		if p.Height == 0 {
+			return false
+		}
+	}
+	return r.LastConsistentHeight >= 1 && r.LastConsistentHeight >= r.RollbackMinHeight
+}
+
+// readOnlyDB drops all the writes, so the checked state is never modified.
+type readOnlyDB struct {
+	keyvalue.IterableKeyVal
+}
+
+func (readOnlyDB) Put(key, val []byte) error {
+	return nil
+}
+
+func (readOnlyDB) Delete(key []byte) error {
+	return nil
+}
+
+func (readOnlyDB) Flush(batch keyvalue.Batch) error {
+	return nil
+}
+
+func openStateDB(dataDir string, params StorageParams) (keyvalue.IterableKeyVal, error) {
+	if !params.DbParams.Backend.Persistent() {
+		return nil, errors.Errorf("state of '%s' database backend can't be opened offline", params.DbParams.Backend)
+	}
+	for _, dir := range []string{keyvalueDir, blocksStorDir} {
+		if _, err := os.Stat(filepath.Join(dataDir, dir)); err != nil {
+			return nil, errors.Wrapf(err, "invalid state directory '%s'", dataDir)
+		}
+	}
+	params.DbParams.BloomFilterParams.Store.WithPath(filepath.Join(dataDir, blocksStorDir, bloomFilterFile))
+	return keyvalue.NewIterableKeyVal(filepath.Join(dataDir, keyvalueDir), params.DbParams)
+}
+
+func getHeightKey(db keyvalue.KeyValue, key []byte) (uint64, error) {
+	heightBytes, err := db.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(heightBytes) != 8 {
+		return 0, errInvalidDataSize
+	}
+	return binary.LittleEndian.Uint64(heightBytes), nil
+}
+
+type stateChecker struct {
+	db        keyvalue.IterableKeyVal
+	rw        *blockReadWriter
+	scheme    proto.Scheme
+	verifyTxs bool
+	report    *CheckReport
+
+	lastBlockNum uint32
+	// Valid block nums as they are in database.
+	validNums map[uint32]struct{}
+	// Block nums of the blocks found in block storage.
+	numToHeight map[uint32]uint64
+}
+
+// CheckState verifies the integrity of the state in dataDir, the state must not be opened by anybody else.
+// Block storage files are checked against database, as well as the maps of heights, block IDs and block nums.
+// History records are checked against the list of valid blocks, lease balances and assets quantities
+// are recalculated from histories and compared with the stored ones.
+// If verifyTxs is true, every transaction is read from block storage to check its offset and height.
+// The state is never modified by the check.
+func CheckState(dataDir string, params StorageParams, sets *settings.BlockchainSettings, verifyTxs bool) (*CheckReport, error) {
+	db, err := openStateDB(dataDir, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			zap.S().Errorf("Failed to close database: %v", err)
+		}
+	}()
+	ro := readOnlyDB{db}
+	report := &CheckReport{}
+	for _, key := range [][]byte{stateInfoKeyBytes, dbHeightKeyBytes, rwHeightKeyBytes, rollbackMinHeightKeyBytes} {
+		has, err := db.Has(key)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			report.addProblem(0, "state metadata key %d is missing, the state was never initialized or is damaged", key[0])
+			return report, nil
+		}
+	}
+	stateInfoBytes, err := db.Get(stateInfoKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	var info stateInfo
+	if err := info.unmarshalBinary(stateInfoBytes); err != nil {
+		report.addProblem(0, "failed to unmarshal state info: %v", err)
+	} else if info.version != StateVersion {
+		report.addProblem(0, "state version %d is not supported, current version is %d", info.version, StateVersion)
+	}
+	if report.DBHeight, err = getHeightKey(db, dbHeightKeyBytes); err != nil {
+		return nil, errors.Wrap(err, "failed to get database height")
+	}
+	if report.StorageHeight, err = getHeightKey(db, rwHeightKeyBytes); err != nil {
+		return nil, errors.Wrap(err, "failed to get block storage height")
+	}
+	if report.RollbackMinHeight, err = getHeightKey(db, rollbackMinHeightKeyBytes); err != nil {
+		return nil, errors.Wrap(err, "failed to get rollback min height")
+	}
+	report.LastConsistentHeight = report.DBHeight
+	roBatch, err := ro.NewBatch()
+	if err != nil {
+		return nil, err
+	}
+	rw, err := newBlockReadWriter(filepath.Join(dataDir, blocksStorDir), params.OffsetLen, params.HeaderOffsetLen, ro, roBatch, sets.AddressSchemeCharacter)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open block storage")
+	}
+	defer func() {
+		if err := rw.close(); err != nil {
+			zap.S().Errorf("Failed to close block storage: %v", err)
+		}
+	}()
+	c := &stateChecker{
+		db:          ro,
+		rw:          rw,
+		scheme:      sets.AddressSchemeCharacter,
+		verifyTxs:   verifyTxs,
+		report:      report,
+		validNums:   make(map[uint32]struct{}),
+		numToHeight: make(map[uint32]uint64),
+	}
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (c *stateChecker) check() error {
+	lastBlockNumBytes, err := c.db.Get(lastBlockNumKeyBytes)
+	if err != nil && err != keyvalue.ErrNotFound {
+		return err
+	}
+	if len(lastBlockNumBytes) == 4 {
+		c.lastBlockNum = binary.LittleEndian.Uint32(lastBlockNumBytes)
+	}
+	zap.S().Info("Checking block storage...")
+	if err := c.checkBlocks(); err != nil {
+		return errors.Wrap(err, "failed to check blocks")
+	}
+	zap.S().Info("Checking valid blocks...")
+	if err := c.checkValidBlocks(); err != nil {
+		return errors.Wrap(err, "failed to check valid blocks")
+	}
+	zap.S().Info("Checking histories and balances...")
+	if err := c.checkHistories(); err != nil {
+		return errors.Wrap(err, "failed to check histories")
+	}
+	return nil
+}
+
+type blockBounds struct {
+	blockStart, blockEnd   uint64
+	headerStart, headerEnd uint64
+	height                 uint64
+}
+
+func (c *stateChecker) blockBounds(blockID proto.BlockID) (*blockBounds, error) {
+	key := blockOffsetKey{blockID: blockID}
+	info, err := c.db.Get(key.bytes())
+	if err != nil {
+		return nil, err
+	}
+	offsetLen, headerOffsetLen := c.rw.offsetLen, c.rw.headerOffsetLen
+	if len(info) != offsetLen*2+headerOffsetLen*2+8 {
+		return nil, errInvalidDataSize
+	}
+	return &blockBounds{
+		blockStart:  binary.LittleEndian.Uint64(info[:offsetLen]),
+		blockEnd:    binary.LittleEndian.Uint64(info[offsetLen : offsetLen*2]),
+		headerStart: binary.LittleEndian.Uint64(info[offsetLen*2 : offsetLen*2+headerOffsetLen]),
+		headerEnd:   binary.LittleEndian.Uint64(info[offsetLen*2+headerOffsetLen : len(info)-8]),
+		height:      binary.LittleEndian.Uint64(info[len(info)-8:]),
+	}, nil
+}
+
+// checkBlocks scans blocks by heights and stops at the first broken one.
+func (c *stateChecker) checkBlocks() error {
+	r := c.report
+	maxHeight := r.StorageHeight
+	if r.DBHeight > maxHeight {
+		maxHeight = r.DBHeight
+	}
+	var prevID proto.BlockID
+	var prevNum uint32
+	var prev blockBounds
+	for h := uint64(1); h <= maxHeight; h++ {
+		if h%10000 == 0 {
+			zap.S().Infof("Checked %d blocks", h)
+		}
+		if h > r.StorageHeight {
+			r.addProblem(h, "database height %d is ahead of block storage height %d", r.DBHeight, r.StorageHeight)
+			return nil
+		}
+		blockID, ok, err := c.checkBlock(h, prevID, &prev)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if h <= r.DBHeight {
+			num, ok, err := c.checkBlockNum(h, blockID, prevNum)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			prevNum = num
+		}
+		prevID = blockID
+	}
+	if r.StorageHeight > r.DBHeight {
+		r.addProblem(r.DBHeight+1, "blocks up to height %d are in block storage but not in database, the flush was interrupted", r.StorageHeight)
+	}
+	// Data after the last block is left by interrupted writes.
+	if size, err := fileSize(c.rw.blockchain); err != nil {
+		return err
+	} else if size > prev.blockEnd {
+		r.addProblem(r.StorageHeight+1, "%d bytes of unfinished transactions at the end of blockchain file", size-prev.blockEnd)
+	}
+	if size, err := fileSize(c.rw.headers); err != nil {
+		return err
+	} else if size > prev.headerEnd {
+		r.addProblem(r.StorageHeight+1, "%d bytes of unfinished headers at the end of headers file", size-prev.headerEnd)
+	}
+	if size, err := fileSize(c.rw.blockHeight2ID); err != nil {
+		return err
+	} else if offset := c.rw.heightToIDOffset(r.StorageHeight); size > offset {
+		r.addProblem(r.StorageHeight+1, "%d bytes of unfinished block IDs at the end of block IDs file", size-offset)
+	}
+	return nil
+}
+
+func fileSize(f *os.File) (uint64, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(stat.Size()), nil
+}
+
+// checkBlock verifies block storage data of the block at height h.
+// prev contains the bounds of previous block and is updated with the bounds of this one.
+func (c *stateChecker) checkBlock(h uint64, prevID proto.BlockID, prev *blockBounds) (proto.BlockID, bool, error) {
+	r := c.report
+	blockID, err := c.rw.blockIDByHeight(h)
+	if err != nil {
+		r.addProblem(h, "failed to read block ID from block storage: %v", err)
+		return proto.BlockID{}, false, nil
+	}
+	bounds, err := c.blockBounds(blockID)
+	if err != nil {
+		r.addProblem(h, "failed to get offsets of block %s: %v", blockID.String(), err)
+		return proto.BlockID{}, false, nil
+	}
+	if bounds.height != h {
+		r.addProblem(h, "block %s is stored at height %d, but its offsets record has height %d", blockID.String(), h, bounds.height)
+		return proto.BlockID{}, false, nil
+	}
+	if bounds.blockStart != prev.blockEnd || bounds.blockEnd < bounds.blockStart {
+		r.addProblem(h, "transactions bounds [%d, %d) of block %s do not follow previous block end %d", bounds.blockStart, bounds.blockEnd, blockID.String(), prev.blockEnd)
+		return proto.BlockID{}, false, nil
+	}
+	if bounds.headerStart != prev.headerEnd || bounds.headerEnd <= bounds.headerStart {
+		r.addProblem(h, "header bounds [%d, %d) of block %s do not follow previous header end %d", bounds.headerStart, bounds.headerEnd, blockID.String(), prev.headerEnd)
+		return proto.BlockID{}, false, nil
+	}
+	if size, err := fileSize(c.rw.blockchain); err != nil {
+		return proto.BlockID{}, false, err
+	} else if bounds.blockEnd > size {
+		r.addProblem(h, "transactions of block %s end at %d beyond blockchain file size %d", blockID.String(), bounds.blockEnd, size)
+		return proto.BlockID{}, false, nil
+	}
+	if size, err := fileSize(c.rw.headers); err != nil {
+		return proto.BlockID{}, false, err
+	} else if bounds.headerEnd > size {
+		r.addProblem(h, "header of block %s ends at %d beyond headers file size %d", blockID.String(), bounds.headerEnd, size)
+		return proto.BlockID{}, false, nil
+	}
+	header, err := c.rw.headerByBounds(bounds.headerStart, bounds.headerEnd)
+	if err != nil {
+		r.addProblem(h, "failed to read header of block %s: %v", blockID.String(), err)
+		return proto.BlockID{}, false, nil
+	}
+	if err := header.GenerateBlockID(c.scheme); err != nil {
+		r.addProblem(h, "failed to generate ID of block %s: %v", blockID.String(), err)
+		return proto.BlockID{}, false, nil
+	}
+	if header.BlockID() != blockID {
+		r.addProblem(h, "header has ID %s instead of %s", header.BlockID().String(), blockID.String())
+		return proto.BlockID{}, false, nil
+	}
+	if h > 1 && header.Parent != prevID {
+		r.addProblem(h, "parent of block %s is %s instead of %s", blockID.String(), header.Parent.String(), prevID.String())
+		return proto.BlockID{}, false, nil
+	}
+	if c.verifyTxs {
+		if ok, err := c.checkTransactions(h, blockID, header, bounds); err != nil || !ok {
+			return proto.BlockID{}, ok, err
+		}
+	}
+	*prev = *bounds
+	return blockID, true, nil
+}
+
+func (c *stateChecker) checkTransactions(h uint64, blockID proto.BlockID, header *proto.BlockHeader, bounds *blockBounds) (bool, error) {
+	r := c.report
+	count := 0
+	for pos := bounds.blockStart; pos < bounds.blockEnd; count++ {
+		size, err := c.rw.readTransactionSize(pos)
+		if err != nil {
+			r.addProblem(h, "failed to read size of transaction at %d in block %s: %v", pos, blockID.String(), err)
+			return false, nil
+		}
+		txStart := pos + 4
+		txEnd := txStart + uint64(size)
+		if txEnd > bounds.blockEnd {
+			r.addProblem(h, "transaction at %d exceeds the bounds of block %s", pos, blockID.String())
+			return false, nil
+		}
+		tx, err := c.rw.txByBounds(txStart, txEnd)
+		if err != nil {
+			r.addProblem(h, "failed to read transaction at %d in block %s: %v", pos, blockID.String(), err)
+			return false, nil
+		}
+		txID, err := tx.GetID(c.scheme)
+		if err != nil {
+			r.addProblem(h, "failed to get ID of transaction at %d in block %s: %v", pos, blockID.String(), err)
+			return false, nil
+		}
+		offset, err := c.rw.transactionOffsetByID(txID)
+		if err != nil {
+			r.addProblem(h, "failed to get offset of transaction %s: %v", txIDString(txID), err)
+			return false, nil
+		}
+		if offset != pos {
+			r.addProblem(h, "offset of transaction %s is %d instead of %d", txIDString(txID), offset, pos)
+			return false, nil
+		}
+		txHeight, err := c.rw.transactionHeightByID(txID)
+		if err != nil {
+			r.addProblem(h, "failed to get height of transaction %s: %v", txIDString(txID), err)
+			return false, nil
+		}
+		if txHeight != h {
+			r.addProblem(h, "height of transaction %s is %d instead of %d", txIDString(txID), txHeight, h)
+			return false, nil
+		}
+		pos = txEnd
+	}
+	if count != header.TransactionCount {
+		r.addProblem(h, "block %s contains %d transactions instead of %d", blockID.String(), count, header.TransactionCount)
+		return false, nil
+	}
+	return true, nil
+}
+
+func txIDString(id []byte) string {
+	d, err := crypto.NewDigestFromBytes(id)
+	if err != nil {
+		return fmt.Sprintf("%x", id)
+	}
+	return d.String()
+}
+
+// checkBlockNum verifies database maps of block nums for the block at height h.
+func (c *stateChecker) checkBlockNum(h uint64, blockID proto.BlockID, prevNum uint32) (uint32, bool, error) {
+	r := c.report
+	idToNumKey := blockIdToNumKey{blockID}
+	numBytes, err := c.db.Get(idToNumKey.bytes())
+	if err != nil {
+		r.addProblem(h, "failed to get num of block %s: %v", blockID.String(), err)
+		return 0, false, nil
+	}
+	if len(numBytes) != 4 {
+		r.addProblem(h, "invalid num of block %s", blockID.String())
+		return 0, false, nil
+	}
+	num := binary.LittleEndian.Uint32(numBytes)
+	if h > 1 && num <= prevNum {
+		r.addProblem(h, "num %d of block %s is not greater than num %d of previous block", num, blockID.String(), prevNum)
+		return 0, false, nil
+	}
+	numToIdKey := blockNumToIdKey{num}
+	idBytes, err := c.db.Get(numToIdKey.bytes())
+	if err != nil {
+		r.addProblem(h, "failed to get block ID by num %d: %v", num, err)
+		return 0, false, nil
+	}
+	if id, err := proto.NewBlockIDFromBytes(idBytes); err != nil || id != blockID {
+		r.addProblem(h, "block num %d does not point back to block %s", num, blockID.String())
+		return 0, false, nil
+	}
+	validKey := validBlockNumKey{num}
+	valid, err := c.db.Has(validKey.bytes())
+	if err != nil {
+		return 0, false, err
+	}
+	if !valid {
+		r.addProblem(h, "block %s with num %d is not marked as valid", blockID.String(), num)
+		return 0, false, nil
+	}
+	sk := scoreKey{height: h}
+	hasScore, err := c.db.Has(sk.bytes())
+	if err != nil {
+		return 0, false, err
+	}
+	if !hasScore {
+		r.addProblem(h, "score of block %s is missing", blockID.String())
+		return 0, false, nil
+	}
+	c.numToHeight[num] = h
+	return num, true, nil
+}
+
+// checkValidBlocks looks for the valid block nums that do not belong to any block of the chain.
+func (c *stateChecker) checkValidBlocks() error {
+	r := c.report
+	iter, err := c.db.NewKeyIterator([]byte{validBlockNumKeyPrefix})
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+	var maxChainNum uint32
+	for num := range c.numToHeight {
+		if num > maxChainNum {
+			maxChainNum = num
+		}
+	}
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) != 5 {
+			r.addProblem(0, "invalid valid block num key %x", key)
+			continue
+		}
+		num := binary.LittleEndian.Uint32(key[1:])
+		c.validNums[num] = struct{}{}
+		if _, ok := c.numToHeight[num]; ok {
+			continue
+		}
+		switch {
+		case num >= c.lastBlockNum:
+			r.addProblem(0, "valid block num %d is not less than last block num %d", num, c.lastBlockNum)
+		case num > maxChainNum:
+			// Left by interrupted rollback, truncation invalidates it.
+			r.addProblem(r.DBHeight+1, "block num %d is valid, but its block is not in the chain", num)
+		default:
+			r.addProblem(0, "block num %d is valid, but its block is not in the chain", num)
+		}
+	}
+	return iter.Error()
+}
+
+// historyProblemHeight returns the height truncation to which removes the entry from history.
+func (c *stateChecker) historyProblemHeight(num uint32) uint64 {
+	if h, ok := c.numToHeight[num]; ok {
+		return h
+	}
+	return 0
+}
+
+// latestValidEntry checks the history record and returns its latest valid entry.
+func (c *stateChecker) latestValidEntry(key, value []byte, entity blockchainEntity) (*historyEntry, bool) {
+	r := c.report
+	history, err := newHistoryRecordFromBytes(value)
+	if err != nil {
+		r.addProblem(0, "failed to unmarshal history record %x: %v", key, err)
+		return nil, false
+	}
+	if history.entityType != entity {
+		r.addProblem(0, "history record %x has entity type %d instead of %d", key, history.entityType, entity)
+		return nil, false
+	}
+	if len(history.entries) == 0 {
+		r.addProblem(0, "history record %x is empty", key)
+		return nil, false
+	}
+	var latest *historyEntry
+	for i := range history.entries {
+		entry := &history.entries[i]
+		if i > 0 && entry.blockNum <= history.entries[i-1].blockNum {
+			r.addProblem(0, "entries of history record %x are not sorted by block nums", key)
+			return nil, false
+		}
+		if entry.blockNum >= c.lastBlockNum {
+			r.addProblem(0, "history record %x has entry of block num %d which is not less than last block num %d", key, entry.blockNum, c.lastBlockNum)
+			return nil, false
+		}
+		// Entries of rolled back blocks are filtered out when the record is read,
+		// import without filtering may also leave them in the middle of history.
+		if _, ok := c.validNums[entry.blockNum]; ok {
+			latest = entry
+		}
+	}
+	return latest, true
+}
+
+type balancesCheck struct {
+	leaseIn, leaseOut map[proto.Address]int64
+	wavesBalances     map[proto.Address]balanceProfile
+	assetsSupply      map[crypto.Digest]*big.Int
+	assetsQuantity    map[crypto.Digest]*big.Int
+}
+
+func (c *stateChecker) checkHistories() error {
+	prefixes := make([]int, 0, len(historyKeyPrefixes))
+	for prefix := range historyKeyPrefixes {
+		prefixes = append(prefixes, int(prefix))
+	}
+	sort.Ints(prefixes)
+	bc := &balancesCheck{
+		leaseIn:        make(map[proto.Address]int64),
+		leaseOut:       make(map[proto.Address]int64),
+		wavesBalances:  make(map[proto.Address]balanceProfile),
+		assetsSupply:   make(map[crypto.Digest]*big.Int),
+		assetsQuantity: make(map[crypto.Digest]*big.Int),
+	}
+	for _, p := range prefixes {
+		prefix := byte(p)
+		if err := c.checkHistoriesOfPrefix(prefix, historyKeyPrefixes[prefix], bc); err != nil {
+			return err
+		}
+	}
+	c.checkBalances(bc)
+	return nil
+}
+
+func (c *stateChecker) checkHistoriesOfPrefix(prefix byte, entity blockchainEntity, bc *balancesCheck) error {
+	r := c.report
+	iter, err := c.db.NewKeyIterator([]byte{prefix})
+	if err != nil {
+		return err
+	}
+	defer iter.Release()
+	for iter.Next() {
+		key := keyvalue.SafeKey(iter)
+		entry, ok := c.latestValidEntry(key, iter.Value(), entity)
+		if !ok || entry == nil {
+			continue
+		}
+		height := c.historyProblemHeight(entry.blockNum)
+		switch entity {
+		case wavesBalance:
+			var k wavesBalanceKey
+			if err := k.unmarshal(key); err != nil {
+				r.addProblem(0, "invalid waves balance key %x: %v", key, err)
+				continue
+			}
+			var record wavesBalanceRecord
+			if err := record.unmarshalBinary(entry.data); err != nil {
+				r.addProblem(height, "failed to unmarshal waves balance of %s: %v", k.address.String(), err)
+				continue
+			}
+			bc.wavesBalances[k.address] = record.balanceProfile
+		case assetBalance:
+			var k assetBalanceKey
+			if err := k.unmarshal(key); err != nil {
+				r.addProblem(0, "invalid asset balance key %x: %v", key, err)
+				continue
+			}
+			var record assetBalanceRecord
+			if err := record.unmarshalBinary(entry.data); err != nil {
+				r.addProblem(height, "failed to unmarshal asset balance of %s: %v", k.address.String(), err)
+				continue
+			}
+			assetID, err := crypto.NewDigestFromBytes(k.asset)
+			if err != nil {
+				return err
+			}
+			supply, ok := bc.assetsSupply[assetID]
+			if !ok {
+				supply = big.NewInt(0)
+				bc.assetsSupply[assetID] = supply
+			}
+			supply.Add(supply, new(big.Int).SetUint64(record.balance))
+		case asset:
+			assetID, err := crypto.NewDigestFromBytes(key[1:])
+			if err != nil {
+				r.addProblem(0, "invalid asset key %x: %v", key, err)
+				continue
+			}
+			var record assetHistoryRecord
+			if err := unmarshalAssetRecord(&record, entry.data); err != nil {
+				r.addProblem(height, "failed to unmarshal asset %s: %v", assetID.String(), err)
+				continue
+			}
+			bc.assetsQuantity[assetID] = &record.quantity
+		case lease:
+			var record leasingRecord
+			if err := record.unmarshalBinary(entry.data); err != nil {
+				r.addProblem(height, "failed to unmarshal lease %x: %v", key[1:], err)
+				continue
+			}
+			if record.isActive {
+				bc.leaseIn[record.recipient] += int64(record.leaseAmount)
+				bc.leaseOut[record.sender] += int64(record.leaseAmount)
+			}
+		}
+	}
+	return iter.Error()
+}
+
+// unmarshalAssetRecord protects from panics on records of invalid size.
+func unmarshalAssetRecord(record *assetHistoryRecord, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("invalid asset record: %v", r)
+		}
+	}()
+	if len(data) < maxQuantityLen {
+		return errInvalidDataSize
+	}
+	return record.unmarshalBinary(data)
+}
+
+// checkBalances compares stored lease balances and assets quantities with the ones recalculated from histories.
+func (c *stateChecker) checkBalances(bc *balancesCheck) {
+	r := c.report
+	addresses := make(map[proto.Address]struct{})
+	for addr := range bc.wavesBalances {
+		addresses[addr] = struct{}{}
+	}
+	for addr := range bc.leaseIn {
+		addresses[addr] = struct{}{}
+	}
+	for addr := range bc.leaseOut {
+		addresses[addr] = struct{}{}
+	}
+	for addr := range addresses {
+		profile := bc.wavesBalances[addr]
+		if profile.leaseIn != bc.leaseIn[addr] || profile.leaseOut != bc.leaseOut[addr] {
+			r.addProblem(0, "lease balance of %s is (in: %d, out: %d), active leases give (in: %d, out: %d)",
+				addr.String(), profile.leaseIn, profile.leaseOut, bc.leaseIn[addr], bc.leaseOut[addr])
+		}
+	}
+	for assetID, supply := range bc.assetsSupply {
+		quantity, ok := bc.assetsQuantity[assetID]
+		if !ok {
+			r.addProblem(0, "asset %s has balances but it was never issued", assetID.String())
+			continue
+		}
+		if quantity.Cmp(supply) != 0 {
+			r.addProblem(0, "quantity of asset %s is %s, but the sum of balances is %s", assetID.String(), quantity.String(), supply.String())
+		}
+	}
+	for assetID, quantity := range bc.assetsQuantity {
+		if _, ok := bc.assetsSupply[assetID]; !ok && quantity.Sign() != 0 {
+			r.addProblem(0, "quantity of asset %s is %s, but nobody has it", assetID.String(), quantity.String())
+		}
+	}
+}
+
+// TruncateState removes the blocks above height from the state in dataDir, the state must not be opened by anybody else.
+// Unlike the rollback of state, it does not rely on the data of removed blocks, so it can be used to get rid of damaged blocks.
+// Histories are not rewritten, the entries of removed blocks are filtered out when the records are read.
+func TruncateState(dataDir string, params StorageParams, sets *settings.BlockchainSettings, height uint64) error {
+	db, err := openStateDB(dataDir, params)
+	if err != nil {
+		return errors.Wrap(err, "failed to open database")
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			zap.S().Errorf("Failed to close database: %v", err)
+		}
+	}()
+	dbHeight, err := getHeightKey(db, dbHeightKeyBytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to get database height")
+	}
+	rollbackMinHeight, err := getHeightKey(db, rollbackMinHeightKeyBytes)
+	if err != nil {
+		return errors.Wrap(err, "failed to get rollback min height")
+	}
+	if height < 1 || height > dbHeight {
+		return errors.Errorf("height %d is out of range [1, %d]", height, dbHeight)
+	}
+	if height < rollbackMinHeight {
+		return errors.Errorf("histories are cut at height %d, truncation to height %d is impossible", rollbackMinHeight, height)
+	}
+	dbBatch, err := db.NewBatch()
+	if err != nil {
+		return err
+	}
+	rw, err := newBlockReadWriter(filepath.Join(dataDir, blocksStorDir), params.OffsetLen, params.HeaderOffsetLen, db, dbBatch, sets.AddressSchemeCharacter)
+	if err != nil {
+		return errors.Wrap(err, "failed to open block storage")
+	}
+	defer func() {
+		if err := rw.close(); err != nil {
+			zap.S().Errorf("Failed to close block storage: %v", err)
+		}
+	}()
+	storageHeight, err := rw.getHeight()
+	if err != nil {
+		return errors.Wrap(err, "failed to get block storage height")
+	}
+	edge, err := rw.blockIDByHeight(height)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block ID at height %d", height)
+	}
+	idToNumKey := blockIdToNumKey{edge}
+	edgeNumBytes, err := db.Get(idToNumKey.bytes())
+	if err != nil {
+		return errors.Wrapf(err, "failed to get num of block %s", edge.String())
+	}
+	edgeNum := binary.LittleEndian.Uint32(edgeNumBytes)
+	// Block nums grow with heights, so the blocks above the edge have greater nums.
+	var removedNums []uint32
+	iter, err := db.NewKeyIterator([]byte{validBlockNumKeyPrefix})
+	if err != nil {
+		return err
+	}
+	for iter.Next() {
+		if num := binary.LittleEndian.Uint32(iter.Key()[1:]); num > edgeNum {
+			removedNums = append(removedNums, num)
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	// The height is set first of all, like in rollback.
+	heightBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(heightBytes, height)
+	if err := db.Put(dbHeightKeyBytes, heightBytes); err != nil {
+		return err
+	}
+	for _, num := range removedNums {
+		validKey := validBlockNumKey{num}
+		if err := db.Delete(validKey.bytes()); err != nil {
+			return err
+		}
+		numToIdKey := blockNumToIdKey{num}
+		idBytes, err := db.Get(numToIdKey.bytes())
+		if err == nil {
+			if id, err := proto.NewBlockIDFromBytes(idBytes); err == nil {
+				idKey := blockIdToNumKey{id}
+				if err := db.Delete(idKey.bytes()); err != nil {
+					return err
+				}
+				infoKey := blocksInfoKey{id}
+				if err := db.Delete(infoKey.bytes()); err != nil {
+					return err
+				}
+			}
+		}
+		if err := db.Delete(numToIdKey.bytes()); err != nil {
+			return err
+		}
+	}
+	maxHeight := dbHeight
+	if storageHeight > maxHeight {
+		maxHeight = storageHeight
+	}
+	for h := maxHeight; h > height; h-- {
+		key := scoreKey{height: h}
+		if err := db.Delete(key.bytes()); err != nil {
+			return err
+		}
+	}
+	if err := rw.rollback(edge, true); err != nil {
+		// IDs of damaged blocks might be unreadable, stale offsets of transactions are overwritten on reapplying.
+		zap.S().Warnf("Failed to clean IDs of removed blocks: %v", err)
+		if err := rw.rollback(edge, false); err != nil {
+			return errors.Wrap(err, "failed to truncate block storage")
+		}
+	}
+	return nil
+}
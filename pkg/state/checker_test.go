@@ -0,0 +1,121 @@
+package state
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/importer"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+)
+
+func checkState(t *testing.T, dataDir string) *CheckReport {
+	report, err := CheckState(dataDir, DefaultTestingStorageParams(), settings.MainNetSettings, true)
+	require.NoError(t, err, "CheckState() failed")
+	return report
+}
+
+func modifyStateDB(t *testing.T, dataDir string, modify func(db keyvalue.IterableKeyVal)) {
+	db, err := openStateDB(dataDir, DefaultTestingStorageParams())
+	require.NoError(t, err)
+	modify(db)
+	require.NoError(t, db.Close())
+}
+
+func TestCheckAndTruncateState(t *testing.T) {
+	blocksPath, err := blocksPath()
+	require.NoError(t, err)
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	require.NoError(t, err, "failed to create dir for test data")
+	defer func() {
+		err := os.RemoveAll(dataDir)
+		assert.NoError(t, err, "failed to remove test data dirs")
+	}()
+	manager, err := newStateManager(dataDir, DefaultTestingStateParams(), settings.MainNetSettings)
+	require.NoError(t, err, "newStateManager() failed")
+	height := proto.Height(100)
+	err = importer.ApplyFromFile(manager, blocksPath, height-1, 1, false)
+	require.NoError(t, err, "ApplyFromFile() failed")
+	damagedID, err := manager.HeightToBlockID(90)
+	require.NoError(t, err)
+	require.NoError(t, manager.Close())
+
+	report := checkState(t, dataDir)
+	assert.True(t, report.Consistent(), "problems in healthy state: %v", report.Problems)
+	assert.Equal(t, height, report.DBHeight)
+	assert.Equal(t, height, report.StorageHeight)
+	assert.Equal(t, height, report.LastConsistentHeight)
+
+	// Unfinished write at the end of headers file.
+	headers, err := os.OpenFile(filepath.Join(dataDir, blocksStorDir, "headers"), os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = headers.Write([]byte{1, 2, 3})
+	require.NoError(t, err)
+	require.NoError(t, headers.Close())
+	report = checkState(t, dataDir)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, height+1, report.Problems[0].Height)
+	assert.Equal(t, height, report.LastConsistentHeight)
+	assert.True(t, report.Repairable())
+	require.NoError(t, TruncateState(dataDir, DefaultTestingStorageParams(), settings.MainNetSettings, height))
+	report = checkState(t, dataDir)
+	assert.True(t, report.Consistent(), "problems after truncation: %v", report.Problems)
+
+	// Offsets of block are lost.
+	modifyStateDB(t, dataDir, func(db keyvalue.IterableKeyVal) {
+		key := blockOffsetKey{blockID: damagedID}
+		require.NoError(t, db.Delete(key.bytes()))
+	})
+	report = checkState(t, dataDir)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, uint64(90), report.Problems[0].Height)
+	assert.Equal(t, uint64(89), report.LastConsistentHeight)
+	assert.True(t, report.Repairable())
+	err = TruncateState(dataDir, DefaultTestingStorageParams(), settings.MainNetSettings, report.LastConsistentHeight+1)
+	assert.Error(t, err, "TruncateState() did not fail with damaged block")
+	require.NoError(t, TruncateState(dataDir, DefaultTestingStorageParams(), settings.MainNetSettings, report.LastConsistentHeight))
+	report = checkState(t, dataDir)
+	assert.True(t, report.Consistent(), "problems after truncation: %v", report.Problems)
+	assert.Equal(t, uint64(89), report.DBHeight)
+	assert.Equal(t, uint64(89), report.StorageHeight)
+
+	// Truncated state can be opened and continued.
+	manager, err = newStateManager(dataDir, DefaultTestingStateParams(), settings.MainNetSettings)
+	require.NoError(t, err, "newStateManager() failed after truncation")
+	err = importer.ApplyFromFile(manager, blocksPath, height-1, 89, false)
+	require.NoError(t, err, "ApplyFromFile() failed after truncation")
+	require.NoError(t, manager.Close())
+	report = checkState(t, dataDir)
+	assert.True(t, report.Consistent(), "problems after reapplying blocks: %v", report.Problems)
+	assert.Equal(t, height, report.DBHeight)
+
+	// Lease balance that does not match leases can't be fixed by truncation.
+	modifyStateDB(t, dataDir, func(db keyvalue.IterableKeyVal) {
+		iter, err := db.NewKeyIterator([]byte{wavesBalanceKeyPrefix})
+		require.NoError(t, err)
+		require.True(t, iter.Next())
+		key := keyvalue.SafeKey(iter)
+		history, err := newHistoryRecordFromBytes(iter.Value())
+		require.NoError(t, err)
+		iter.Release()
+		last := &history.entries[len(history.entries)-1]
+		var record wavesBalanceRecord
+		require.NoError(t, record.unmarshalBinary(last.data))
+		record.leaseIn++
+		last.data, err = record.marshalBinary()
+		require.NoError(t, err)
+		historyBytes, err := history.marshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, db.Put(key, historyBytes))
+	})
+	report = checkState(t, dataDir)
+	require.Len(t, report.Problems, 1)
+	assert.Equal(t, uint64(0), report.Problems[0].Height)
+	assert.Contains(t, report.Problems[0].Description, "lease balance")
+	assert.False(t, report.Repairable())
+}
@@ -226,7 +226,7 @@ func createStorageObjects() (*testStorageObjects, []string, error) {
 	if err != nil {
 		return nil, res, err
 	}
-	stateDB, err := newStateDB(db, dbBatch, rw, false)
+	stateDB, err := newStateDB(db, dbBatch, rw, false, false)
 	if err != nil {
 		return nil, res, err
 	}
@@ -234,7 +234,7 @@ func createStorageObjects() (*testStorageObjects, []string, error) {
 	if err != nil {
 		return nil, res, err
 	}
-	entities, err := newBlockchainEntitiesStorage(hs, settings.MainNetSettings, rw)
+	entities, err := newBlockchainEntitiesStorage(hs, settings.MainNetSettings, rw, false)
 	if err != nil {
 		return nil, res, err
 	}
@@ -386,3 +386,12 @@ func existingGenesisTx(t *testing.T) proto.Transaction {
 		Amount:    9999999500000000,
 	}
 }
+
+// assertScriptsEqual compares the trees of scripts, compiled forms of scripts can't be compared.
+func assertScriptsEqual(t *testing.T, expected, actual ast.Script) {
+	assert.Equal(t, expected.Version, actual.Version)
+	assert.Equal(t, expected.HasBlockV2, actual.HasBlockV2)
+	assert.Equal(t, expected.IsDapp(), actual.IsDapp())
+	assert.Equal(t, expected.Verifier, actual.Verifier)
+	assert.Equal(t, expected.DApp, actual.DApp)
+}
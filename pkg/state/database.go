@@ -11,7 +11,9 @@ import (
 )
 
 const (
-	stateInfoSize = 3
+	// Size of state info without state hashes flag, the flag was added later.
+	stateInfoSizeV1 = 3
+	stateInfoSize   = stateInfoSizeV1 + 1
 )
 
 var (
@@ -27,17 +29,19 @@ var (
 type stateInfo struct {
 	version            uint16
 	hasExtendedApiData bool
+	hasStateHashes     bool
 }
 
 func (inf *stateInfo) marshalBinary() []byte {
-	buf := make([]byte, 2+1)
+	buf := make([]byte, stateInfoSize)
 	binary.BigEndian.PutUint16(buf[:2], inf.version)
 	proto.PutBool(buf[2:], inf.hasExtendedApiData)
+	proto.PutBool(buf[3:], inf.hasStateHashes)
 	return buf
 }
 
 func (inf *stateInfo) unmarshalBinary(data []byte) error {
-	if len(data) != stateInfoSize {
+	if len(data) != stateInfoSize && len(data) != stateInfoSizeV1 {
 		return errInvalidDataSize
 	}
 	inf.version = binary.BigEndian.Uint16(data[:2])
@@ -46,10 +50,19 @@ func (inf *stateInfo) unmarshalBinary(data []byte) error {
 	if err != nil {
 		return err
 	}
+	if len(data) == stateInfoSizeV1 {
+		// State info was saved before state hashes were introduced.
+		inf.hasStateHashes = false
+		return nil
+	}
+	inf.hasStateHashes, err = proto.Bool(data[3:])
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
-func saveStateInfo(db keyvalue.KeyValue, storeApiData bool) error {
+func saveStateInfo(db keyvalue.KeyValue, storeApiData, storeStateHashes bool) error {
 	has, err := db.Has(stateInfoKeyBytes)
 	if err != nil {
 		return err
@@ -57,7 +70,7 @@ func saveStateInfo(db keyvalue.KeyValue, storeApiData bool) error {
 	if has {
 		return nil
 	}
-	info := &stateInfo{version: StateVersion, hasExtendedApiData: storeApiData}
+	info := &stateInfo{version: StateVersion, hasExtendedApiData: storeApiData, hasStateHashes: storeStateHashes}
 	infoBytes := info.marshalBinary()
 	if err := db.Put(stateInfoKeyBytes, infoBytes); err != nil {
 		return err
@@ -79,7 +92,7 @@ type stateDB struct {
 	blocksNum int
 }
 
-func newStateDB(db keyvalue.KeyValue, dbBatch keyvalue.Batch, rw *blockReadWriter, storeApiData bool) (*stateDB, error) {
+func newStateDB(db keyvalue.KeyValue, dbBatch keyvalue.Batch, rw *blockReadWriter, storeApiData, storeStateHashes bool) (*stateDB, error) {
 	heightBuf := make([]byte, 8)
 	has, err := db.Has(dbHeightKeyBytes)
 	if err != nil {
@@ -102,7 +115,7 @@ func newStateDB(db keyvalue.KeyValue, dbBatch keyvalue.Batch, rw *blockReadWrite
 		}
 	}
 	dbWriteLock := &sync.Mutex{}
-	if err := saveStateInfo(db, storeApiData); err != nil {
+	if err := saveStateInfo(db, storeApiData, storeStateHashes); err != nil {
 		return nil, err
 	}
 	return &stateDB{
@@ -346,6 +359,19 @@ func (s *stateDB) stateStoresApiData() (bool, error) {
 	return info.hasExtendedApiData, nil
 }
 
+// stateStoresHashes indicates if state hashes of blocks must be calculated and stored.
+func (s *stateDB) stateStoresHashes() (bool, error) {
+	stateInfoBytes, err := s.db.Get(stateInfoKeyBytes)
+	if err != nil {
+		return false, err
+	}
+	var info stateInfo
+	if err := info.unmarshalBinary(stateInfoBytes); err != nil {
+		return false, err
+	}
+	return info.hasStateHashes, nil
+}
+
 func (s *stateDB) calculateNewRollbackMinHeight(newHeight uint64) (uint64, error) {
 	prevRollbackMinHeight, err := s.getRollbackMinHeight()
 	if err != nil {
@@ -240,6 +240,33 @@ func (f *features) isActivated(featureID int16) (bool, error) {
 	return true, nil
 }
 
+// estimatorVersionByActivation returns the version of script estimator selected by activated features.
+func estimatorVersionByActivation(isActivated func(featureID int16) (bool, error)) (int, error) {
+	blockV5Activated, err := isActivated(int16(settings.BlockV5))
+	if err != nil {
+		return 0, err
+	}
+	if blockV5Activated {
+		return 3, nil
+	}
+	blockRewardActivated, err := isActivated(int16(settings.BlockReward))
+	if err != nil {
+		return 0, err
+	}
+	if blockRewardActivated {
+		return 2, nil
+	}
+	return 1, nil
+}
+
+func (f *features) newestEstimatorVersion() (int, error) {
+	return estimatorVersionByActivation(f.newestIsActivated)
+}
+
+func (f *features) estimatorVersion() (int, error) {
+	return estimatorVersionByActivation(f.isActivated)
+}
+
 func (f *features) isActivatedAtHeight(featureID int16, height uint64) bool {
 	activationHeight, err := f.activationHeight(featureID)
 	if err == nil {
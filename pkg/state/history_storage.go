@@ -32,6 +32,10 @@ const (
 	rewardVotes
 	blockReward
 	invokeResult
+	stateHash
+	aliasByAddr
+	leaseByAddr
+	sponsoredTxsCount
 )
 
 type blockchainEntityProperties struct {
@@ -99,7 +103,7 @@ var properties = map[blockchainEntity]blockchainEntityProperties{
 	},
 	sponsorship: {
 		needToFilter: true,
-		needToCut:    true,
+		needToCut:    false, // Do not cut for sponsorship history.
 		fixedSize:    true,
 		recordSize:   sponsorshipRecordSize + 4,
 	},
@@ -110,22 +114,22 @@ var properties = map[blockchainEntity]blockchainEntityProperties{
 	},
 	accountScript: {
 		needToFilter: true,
-		needToCut:    true,
+		needToCut:    false, // Do not cut for script history.
 		fixedSize:    false,
 	},
 	assetScript: {
 		needToFilter: true,
-		needToCut:    true,
+		needToCut:    false, // Do not cut for script history.
 		fixedSize:    false,
 	},
 	accountScriptComplexity: {
 		needToFilter: true,
-		needToCut:    true,
+		needToCut:    false, // Do not cut for script history.
 		fixedSize:    false,
 	},
 	assetScriptComplexity: {
 		needToFilter: true,
-		needToCut:    true,
+		needToCut:    false, // Do not cut for script history.
 		fixedSize:    true,
 		recordSize:   assetScriptComplexityRecordSize + 4,
 	},
@@ -146,6 +150,29 @@ var properties = map[blockchainEntity]blockchainEntityProperties{
 		needToCut:    true,
 		fixedSize:    false,
 	},
+	stateHash: {
+		needToFilter: true,
+		needToCut:    true,
+		fixedSize:    false,
+	},
+	aliasByAddr: {
+		needToFilter: true,
+		needToCut:    true,
+		fixedSize:    true,
+		recordSize:   aliasByAddrRecordSize + 4,
+	},
+	leaseByAddr: {
+		needToFilter: true,
+		needToCut:    true,
+		fixedSize:    true,
+		recordSize:   leaseByAddrRecordSize + 4,
+	},
+	sponsoredTxsCount: {
+		needToFilter: true,
+		needToCut:    true,
+		fixedSize:    true,
+		recordSize:   sponsoredTxsCountRecordSize + 4,
+	},
 }
 
 type historyEntry struct {
@@ -602,6 +629,58 @@ func (hs *historyStorage) entriesDataInHeightRange(key []byte, startHeight, endH
 	return hs.entriesDataInHeightRangeCommon(history, startBlockNum, endBlockNum), nil
 }
 
+type entryDataWithBlock struct {
+	data    []byte
+	blockID proto.BlockID
+}
+
+// entriesDataWithBlocks() returns bytes of all the entries from DB, from the oldest to the newest,
+// along with IDs of the blocks they were added in.
+func (hs *historyStorage) entriesDataWithBlocks(key []byte, filter bool) ([]entryDataWithBlock, error) {
+	history, err := hs.getHistory(key, filter, false)
+	if err != nil {
+		return nil, err
+	}
+	return hs.entriesWithBlocks(history.entries)
+}
+
+// entriesDataWithBlocksAfterHeight() returns bytes of at most limit entries from DB added after the given height,
+// from the oldest to the newest, along with IDs of the blocks they were added in. Zero height selects entries
+// from the first one, zero limit selects all the entries.
+func (hs *historyStorage) entriesDataWithBlocksAfterHeight(key []byte, height uint64, limit int, filter bool) ([]entryDataWithBlock, error) {
+	history, err := hs.getHistory(key, filter, false)
+	if err != nil {
+		return nil, err
+	}
+	entries := history.entries
+	if height > 0 {
+		blockNum, err := hs.stateDB.blockNumByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		for len(entries) > 0 && entries[0].blockNum <= blockNum {
+			entries = entries[1:]
+		}
+	}
+	// Block IDs are looked up for the selected entries only.
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return hs.entriesWithBlocks(entries)
+}
+
+func (hs *historyStorage) entriesWithBlocks(entries []historyEntry) ([]entryDataWithBlock, error) {
+	res := make([]entryDataWithBlock, len(entries))
+	for i, entry := range entries {
+		blockID, err := hs.stateDB.blockNumToId(entry.blockNum)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = entryDataWithBlock{entry.data, blockID}
+	}
+	return res, nil
+}
+
 func (hs *historyStorage) reset() {
 	hs.stor.reset()
 }
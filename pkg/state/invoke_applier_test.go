@@ -239,6 +239,16 @@ func TestApplyInvokeScriptWithProofsTransfers(t *testing.T) {
 	}
 	assert.Equal(t, correctAddrs, ch.addrs)
 
+	// Both calls are recorded for scripts statistics.
+	executions := to.state.appender.sc.takeExecutions()
+	assert.Len(t, executions, 2)
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	for _, e := range executions {
+		assert.Equal(t, string(key.bytes()), e.key)
+		assert.Equal(t, testGlobal.senderInfo.addr, e.caller)
+		assert.Empty(t, e.failure)
+	}
+
 	// Check newest result state here.
 	senderBalance, err = to.state.NewestAccountBalance(proto.NewRecipientFromAddress(testGlobal.senderInfo.addr), nil)
 	assert.NoError(t, err)
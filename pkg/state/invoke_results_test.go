@@ -20,7 +20,7 @@ func createInvokeResults() (*invokeResultsTestObjects, []string, error) {
 	if err != nil {
 		return nil, path, err
 	}
-	aliases, err := newAliases(stor.db, stor.dbBatch, stor.hs)
+	aliases, err := newAliases(stor.db, stor.dbBatch, stor.hs, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -5,6 +5,7 @@ package state
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
@@ -18,6 +19,7 @@ const (
 	wavesBalanceKeySize     = 1 + proto.AddressSize
 	assetBalanceKeySize     = 1 + proto.AddressSize + crypto.DigestSize
 	leaseKeySize            = 1 + crypto.DigestSize
+	leaseByAddrKeySize      = 1 + proto.AddressSize + 1 + 8 + crypto.DigestSize
 	aliasKeySize            = 1 + 2 + proto.AliasMaxLength
 	disabledAliasKeySize    = 1 + 2 + proto.AliasMaxLength
 	approvedFeaturesKeySize = 1 + 2
@@ -115,6 +117,18 @@ const (
 
 	// Stores protobuf-related info for blockReadWriter.
 	rwProtobufInfoKeyPrefix
+
+	// State hashes by heights.
+	stateHashKeyPrefix
+
+	// Aliases by address.
+	aliasByAddrKeyPrefix
+
+	// Leases by sender and recipient addresses.
+	leaseByAddrKeyPrefix
+
+	// Numbers of transactions with fees in sponsored assets.
+	sponsoredTxsCountKeyPrefix
 )
 
 var (
@@ -340,6 +354,96 @@ func (k *disabledAliasKey) bytes() []byte {
 	return buf
 }
 
+type aliasByAddrKey struct {
+	addr  proto.Address
+	alias string
+}
+
+func (k *aliasByAddrKey) bytes() []byte {
+	buf := make([]byte, 1+proto.AddressSize+2+len(k.alias))
+	buf[0] = aliasByAddrKeyPrefix
+	copy(buf[1:], k.addr[:])
+	proto.PutStringWithUInt16Len(buf[1+proto.AddressSize:], k.alias)
+	return buf
+}
+
+func (k *aliasByAddrKey) unmarshal(data []byte) error {
+	if len(data) < 1+proto.AddressSize+2 {
+		return errInvalidDataSize
+	}
+	if data[0] != aliasByAddrKeyPrefix {
+		return errInvalidPrefix
+	}
+	var err error
+	k.addr, err = proto.NewAddressFromBytes(data[1 : 1+proto.AddressSize])
+	if err != nil {
+		return err
+	}
+	k.alias, err = proto.StringWithUInt16Len(data[1+proto.AddressSize:])
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// aliasesByAddrPrefix is the common prefix of keys of all the aliases of address.
+func aliasesByAddrPrefix(addr proto.Address) []byte {
+	buf := make([]byte, 1+proto.AddressSize)
+	buf[0] = aliasByAddrKeyPrefix
+	copy(buf[1:], addr[:])
+	return buf
+}
+
+// leaseByAddrKey orders the leases of address by direction, then from the newest to the oldest.
+type leaseByAddrKey struct {
+	addr     proto.Address
+	incoming bool
+	height   uint64
+	leaseID  crypto.Digest
+}
+
+func (k *leaseByAddrKey) bytes() []byte {
+	buf := make([]byte, leaseByAddrKeySize)
+	copy(buf, leasesByAddrPrefix(k.addr, k.incoming))
+	// Inverted height makes the newest leases go first.
+	binary.BigEndian.PutUint64(buf[2+proto.AddressSize:], math.MaxUint64-k.height)
+	copy(buf[2+proto.AddressSize+8:], k.leaseID[:])
+	return buf
+}
+
+func (k *leaseByAddrKey) unmarshal(data []byte) error {
+	if len(data) != leaseByAddrKeySize {
+		return errInvalidDataSize
+	}
+	if data[0] != leaseByAddrKeyPrefix {
+		return errInvalidPrefix
+	}
+	var err error
+	k.addr, err = proto.NewAddressFromBytes(data[1 : 1+proto.AddressSize])
+	if err != nil {
+		return err
+	}
+	k.incoming, err = proto.Bool(data[1+proto.AddressSize:])
+	if err != nil {
+		return err
+	}
+	k.height = math.MaxUint64 - binary.BigEndian.Uint64(data[2+proto.AddressSize:])
+	k.leaseID, err = crypto.NewDigestFromBytes(data[2+proto.AddressSize+8:])
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// leasesByAddrPrefix is the common prefix of keys of all the leases received or sent by address.
+func leasesByAddrPrefix(addr proto.Address, incoming bool) []byte {
+	buf := make([]byte, 2+proto.AddressSize)
+	buf[0] = leaseByAddrKeyPrefix
+	copy(buf[1:], addr[:])
+	proto.PutBool(buf[1+proto.AddressSize:], incoming)
+	return buf
+}
+
 type activatedFeaturesKey struct {
 	featureID int16
 }
@@ -494,6 +598,17 @@ func (k *sponsorshipKey) bytes() []byte {
 	return buf
 }
 
+type sponsoredTxsCountKey struct {
+	assetID crypto.Digest
+}
+
+func (k *sponsoredTxsCountKey) bytes() []byte {
+	buf := make([]byte, 1+crypto.DigestSize)
+	buf[0] = sponsoredTxsCountKeyPrefix
+	copy(buf[1:], k.assetID[:])
+	return buf
+}
+
 type accountScriptKey struct {
 	addr proto.Address
 }
@@ -505,6 +620,21 @@ func (k *accountScriptKey) bytes() []byte {
 	return buf
 }
 
+func (k *accountScriptKey) unmarshal(data []byte) error {
+	if len(data) != 1+proto.AddressSize {
+		return errInvalidDataSize
+	}
+	if data[0] != accountScriptKeyPrefix {
+		return errInvalidPrefix
+	}
+	var err error
+	k.addr, err = proto.NewAddressFromBytes(data[1:])
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 type assetScriptKey struct {
 	asset crypto.Digest
 }
@@ -516,25 +646,45 @@ func (k *assetScriptKey) bytes() []byte {
 	return buf
 }
 
+func (k *assetScriptKey) unmarshal(data []byte) error {
+	if len(data) != 1+crypto.DigestSize {
+		return errInvalidDataSize
+	}
+	if data[0] != assetScriptKeyPrefix {
+		return errInvalidPrefix
+	}
+	var err error
+	k.asset, err = crypto.NewDigestFromBytes(data[1:])
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Complexities of script are stored separately for each version of estimator.
 type accountScriptComplexityKey struct {
-	addr proto.Address
+	addr      proto.Address
+	estimator byte
 }
 
 func (k *accountScriptComplexityKey) bytes() []byte {
-	buf := make([]byte, 1+proto.AddressSize)
+	buf := make([]byte, 2+proto.AddressSize)
 	buf[0] = accountScriptComplexityKeyPrefix
 	copy(buf[1:], k.addr[:])
+	buf[1+proto.AddressSize] = k.estimator
 	return buf
 }
 
 type assetScriptComplexityKey struct {
-	asset crypto.Digest
+	asset     crypto.Digest
+	estimator byte
 }
 
 func (k *assetScriptComplexityKey) bytes() []byte {
-	buf := make([]byte, 1+crypto.DigestSize)
+	buf := make([]byte, 2+crypto.DigestSize)
 	buf[0] = assetScriptComplexityKeyPrefix
 	copy(buf[1:], k.asset[:])
+	buf[1+crypto.DigestSize] = k.estimator
 	return buf
 }
 
@@ -585,3 +735,14 @@ func (k *invokeResultKey) bytes() []byte {
 	copy(res[1:], k.invokeID[:])
 	return res
 }
+
+type stateHashKey struct {
+	height uint64
+}
+
+func (k *stateHashKey) bytes() []byte {
+	buf := make([]byte, 9)
+	buf[0] = stateHashKeyPrefix
+	binary.BigEndian.PutUint64(buf[1:], k.height)
+	return buf
+}
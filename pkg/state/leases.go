@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"encoding/binary"
 
 	"github.com/pkg/errors"
@@ -11,7 +12,8 @@ import (
 )
 
 const (
-	leasingRecordSize = 1 + 8 + proto.AddressSize*2
+	leasingRecordSize     = 1 + 8 + proto.AddressSize*2 + 8 + 8 + 1 + crypto.DigestSize
+	leaseByAddrRecordSize = 1
 )
 
 type leasing struct {
@@ -19,6 +21,12 @@ type leasing struct {
 	leaseAmount uint64
 	recipient   proto.Address
 	sender      proto.Address
+	// Height of the block with the lease transaction.
+	originHeight uint64
+	// Height of the block where the lease was cancelled, zero for active lease.
+	cancelHeight uint64
+	// ID of the lease cancel transaction, nil if the lease was cancelled by the node itself (see cancelLeases()).
+	cancelTransactionID *crypto.Digest
 }
 
 type leasingRecord struct {
@@ -31,6 +39,13 @@ func (l *leasingRecord) marshalBinary() ([]byte, error) {
 	binary.BigEndian.PutUint64(res[1:9], l.leaseAmount)
 	copy(res[9:9+proto.AddressSize], l.recipient[:])
 	copy(res[9+proto.AddressSize:9+proto.AddressSize*2], l.sender[:])
+	pos := 9 + proto.AddressSize*2
+	binary.BigEndian.PutUint64(res[pos:pos+8], l.originHeight)
+	binary.BigEndian.PutUint64(res[pos+8:pos+16], l.cancelHeight)
+	if l.cancelTransactionID != nil {
+		proto.PutBool(res[pos+16:pos+17], true)
+		copy(res[pos+17:], l.cancelTransactionID[:])
+	}
 	return res, nil
 }
 
@@ -46,19 +61,79 @@ func (l *leasingRecord) unmarshalBinary(data []byte) error {
 	l.leaseAmount = binary.BigEndian.Uint64(data[1:9])
 	copy(l.recipient[:], data[9:9+proto.AddressSize])
 	copy(l.sender[:], data[9+proto.AddressSize:9+proto.AddressSize*2])
+	pos := 9 + proto.AddressSize*2
+	l.originHeight = binary.BigEndian.Uint64(data[pos : pos+8])
+	l.cancelHeight = binary.BigEndian.Uint64(data[pos+8 : pos+16])
+	hasCancelTx, err := proto.Bool(data[pos+16 : pos+17])
+	if err != nil {
+		return err
+	}
+	l.cancelTransactionID = nil
+	if hasCancelTx {
+		id, err := crypto.NewDigestFromBytes(data[pos+17:])
+		if err != nil {
+			return err
+		}
+		l.cancelTransactionID = &id
+	}
 	return nil
 }
 
+func (l *leasing) toLeaseInfo(id crypto.Digest) proto.LeaseInfo {
+	status := proto.LeaseCanceled
+	if l.isActive {
+		status = proto.LeaseActive
+	}
+	return proto.LeaseInfo{
+		ID:                  id,
+		Sender:              l.sender,
+		Recipient:           l.recipient,
+		Amount:              l.leaseAmount,
+		Status:              status,
+		Height:              l.originHeight,
+		CancelHeight:        l.cancelHeight,
+		CancelTransactionID: l.cancelTransactionID,
+	}
+}
+
+// leaseByAddrRecord marks the lease as sent or received by the address.
+type leaseByAddrRecord struct {
+	incoming bool
+}
+
+func (r *leaseByAddrRecord) marshalBinary() ([]byte, error) {
+	res := make([]byte, leaseByAddrRecordSize)
+	proto.PutBool(res, r.incoming)
+	return res, nil
+}
+
+func (r *leaseByAddrRecord) unmarshalBinary(data []byte) error {
+	if len(data) != leaseByAddrRecordSize {
+		return errInvalidDataSize
+	}
+	var err error
+	r.incoming, err = proto.Bool(data)
+	return err
+}
+
 type leases struct {
 	db keyvalue.IterableKeyVal
 	hs *historyStorage
+
+	calculateHashes bool
+	hasher          *stateHasher
 }
 
-func newLeases(db keyvalue.IterableKeyVal, hs *historyStorage) (*leases, error) {
-	return &leases{db, hs}, nil
+func newLeases(db keyvalue.IterableKeyVal, hs *historyStorage, calcHashes bool) (*leases, error) {
+	return &leases{
+		db:              db,
+		hs:              hs,
+		calculateHashes: calcHashes,
+		hasher:          newStateHasher(),
+	}, nil
 }
 
-func (l *leases) cancelLeases(bySenders map[proto.Address]struct{}, blockID proto.BlockID) error {
+func (l *leases) cancelLeases(bySenders map[proto.Address]struct{}, height uint64, blockID proto.BlockID) error {
 	leaseIter, err := l.db.NewKeyIterator([]byte{leaseKeyPrefix})
 	if err != nil {
 		return errors.Errorf("failed to create key iterator to cancel leases: %v", err)
@@ -94,6 +169,8 @@ func (l *leases) cancelLeases(bySenders map[proto.Address]struct{}, blockID prot
 			}
 			zap.S().Infof("State: cancelling lease %s", k.leaseID.String())
 			leaseRecord.isActive = false
+			leaseRecord.cancelHeight = height
+			leaseRecord.cancelTransactionID = nil
 			leaseBytes, err := leaseRecord.marshalBinary()
 			if err != nil {
 				return errors.Errorf("failed to marshal lease: %v", err)
@@ -101,6 +178,9 @@ func (l *leases) cancelLeases(bySenders map[proto.Address]struct{}, blockID prot
 			if err := l.hs.addNewEntry(lease, key, leaseBytes, blockID); err != nil {
 				return errors.Errorf("failed to save lease to storage: %v", err)
 			}
+			if l.calculateHashes {
+				l.pushHash(k.leaseID, false, blockID)
+			}
 		}
 	}
 	zap.S().Info("Finished to cancel leases")
@@ -175,7 +255,97 @@ func (l *leases) isActive(id crypto.Digest, filter bool) (bool, error) {
 	return info.isActive, nil
 }
 
+// leasesByAddr calls the function for the leases sent or received by the address, the newest first, until it returns
+// false. If after is not nil the iteration starts from the lease next to it, keyvalue.ErrNotFound is returned if
+// the address doesn't have such lease. Only the leases from the blocks saved to DB are iterated.
+func (l *leases) leasesByAddr(
+	addr proto.Address,
+	incoming bool,
+	after *crypto.Digest,
+	filter bool,
+	f func(id crypto.Digest, l *leasing) (bool, error),
+) error {
+	iter, err := l.db.NewKeyIterator(leasesByAddrPrefix(addr, incoming))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	var ok bool
+	if after != nil {
+		info, err := l.leasingInfo(*after, filter)
+		if err == keyvalue.ErrNotFound || err == errEmptyHist {
+			return keyvalue.ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		start := leaseByAddrKey{addr: addr, incoming: incoming, height: info.originHeight, leaseID: *after}
+		startKey := start.bytes()
+		if _, err := l.hs.latestEntryData(startKey, filter); err == keyvalue.ErrNotFound || err == errEmptyHist {
+			return keyvalue.ErrNotFound
+		} else if err != nil {
+			return err
+		}
+		ok = iter.Seek(startKey)
+		if ok && bytes.Equal(iter.Key(), startKey) {
+			ok = iter.Next()
+		}
+	} else {
+		ok = iter.Next()
+	}
+	for ; ok; ok = iter.Next() {
+		if _, err := l.hs.latestEntryData(iter.Key(), filter); err == keyvalue.ErrNotFound || err == errEmptyHist {
+			// The lease was rolled back.
+			continue
+		} else if err != nil {
+			return err
+		}
+		var key leaseByAddrKey
+		if err := key.unmarshal(iter.Key()); err != nil {
+			return err
+		}
+		info, err := l.leasingInfo(key.leaseID, filter)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get leasing %s", key.leaseID.String())
+		}
+		next, err := f(key.leaseID, info)
+		if err != nil {
+			return err
+		}
+		if !next {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *leases) addToAddrIndex(id crypto.Digest, addr proto.Address, incoming bool, height uint64, blockID proto.BlockID) error {
+	key := leaseByAddrKey{addr: addr, incoming: incoming, height: height, leaseID: id}
+	r := leaseByAddrRecord{incoming: incoming}
+	recordBytes, err := r.marshalBinary()
+	if err != nil {
+		return err
+	}
+	return l.hs.addNewEntry(leaseByAddr, key.bytes(), recordBytes, blockID)
+}
+
+// addLeasing saves the new lease and adds it to the leases of its sender and recipient.
 func (l *leases) addLeasing(id crypto.Digest, leasing *leasing, blockID proto.BlockID) error {
+	if err := l.addToAddrIndex(id, leasing.sender, false, leasing.originHeight, blockID); err != nil {
+		return errors.Wrap(err, "failed to add lease to sender's leases")
+	}
+	if err := l.addToAddrIndex(id, leasing.recipient, true, leasing.originHeight, blockID); err != nil {
+		return errors.Wrap(err, "failed to add lease to recipient's leases")
+	}
+	return l.saveLeasing(id, leasing, blockID)
+}
+
+func (l *leases) saveLeasing(id crypto.Digest, leasing *leasing, blockID proto.BlockID) error {
 	key := leaseKey{leaseID: id}
 	r := &leasingRecord{*leasing}
 	recordBytes, err := r.marshalBinary()
@@ -185,14 +355,31 @@ func (l *leases) addLeasing(id crypto.Digest, leasing *leasing, blockID proto.Bl
 	if err := l.hs.addNewEntry(lease, key.bytes(), recordBytes, blockID); err != nil {
 		return err
 	}
+	if l.calculateHashes {
+		l.pushHash(id, leasing.isActive, blockID)
+	}
 	return nil
 }
 
-func (l *leases) cancelLeasing(id crypto.Digest, blockID proto.BlockID, filter bool) error {
+func (l *leases) pushHash(id crypto.Digest, isActive bool, blockID proto.BlockID) {
+	status := []byte{0}
+	if isActive {
+		status[0] = 1
+	}
+	l.hasher.push(id.Bytes(), status, blockID)
+}
+
+func (l *leases) resetHashes() {
+	l.hasher.reset()
+}
+
+func (l *leases) cancelLeasing(id crypto.Digest, height uint64, txID *crypto.Digest, blockID proto.BlockID, filter bool) error {
 	leasing, err := l.newestLeasingInfo(id, filter)
 	if err != nil {
 		return errors.Errorf("failed to get leasing info: %v", err)
 	}
 	leasing.isActive = false
-	return l.addLeasing(id, leasing, blockID)
+	leasing.cancelHeight = height
+	leasing.cancelTransactionID = txID
+	return l.saveLeasing(id, leasing, blockID)
 }
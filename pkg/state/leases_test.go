@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 )
@@ -20,7 +21,7 @@ func createLeases() (*leasesTestObjects, []string, error) {
 	if err != nil {
 		return nil, path, err
 	}
-	leases, err := newLeases(stor.db, stor.hs)
+	leases, err := newLeases(stor.db, stor.hs, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -33,10 +34,11 @@ func createLease(t *testing.T, sender string) *leasing {
 	recipientAddr, err := proto.NewAddressFromString("3PDdGex1meSUf4Yq5bjPBpyAbx6us9PaLfo")
 	assert.NoError(t, err, "failed to create address from string")
 	return &leasing{
-		isActive:    true,
-		leaseAmount: 10,
-		recipient:   recipientAddr,
-		sender:      senderAddr,
+		isActive:     true,
+		leaseAmount:  10,
+		recipient:    recipientAddr,
+		sender:       senderAddr,
+		originHeight: 1,
 	}
 }
 
@@ -74,7 +76,7 @@ func TestCancelLeases(t *testing.T) {
 	sendersToCancel := make(map[proto.Address]struct{})
 	var empty struct{}
 	sendersToCancel[badSender] = empty
-	err = to.leases.cancelLeases(sendersToCancel, blockID0)
+	err = to.leases.cancelLeases(sendersToCancel, 1, blockID0)
 	assert.NoError(t, err, "cancelLeases() failed")
 	to.stor.flush(t)
 	for _, l := range leasings {
@@ -87,13 +89,15 @@ func TestCancelLeases(t *testing.T) {
 		if l.sender == badSenderStr {
 			assert.Equal(t, false, active)
 			assert.Equal(t, leasing.isActive, false, "did not cancel leasing by sender")
+			assert.Equal(t, uint64(1), leasing.cancelHeight)
+			assert.Nil(t, leasing.cancelTransactionID)
 		} else {
 			assert.Equal(t, true, active)
 			assert.Equal(t, leasing.isActive, true, "cancelled leasing with different sender")
 		}
 	}
 	// Cancel all the leases and check.
-	err = to.leases.cancelLeases(nil, blockID0)
+	err = to.leases.cancelLeases(nil, 2, blockID0)
 	assert.NoError(t, err, "cancelLeases() failed")
 	to.stor.flush(t)
 	for _, l := range leasings {
@@ -191,11 +195,117 @@ func TestCancelLeasing(t *testing.T) {
 	r := createLease(t, senderStr)
 	err = to.leases.addLeasing(leaseID, r, blockID0)
 	assert.NoError(t, err, "failed to add leasing")
-	err = to.leases.cancelLeasing(leaseID, blockID0, true)
+	txID, err := crypto.NewDigestFromBytes(bytes.Repeat([]byte{0xaa}, crypto.DigestSize))
+	assert.NoError(t, err, "failed to create digest from bytes")
+	err = to.leases.cancelLeasing(leaseID, 2, &txID, blockID0, true)
 	assert.NoError(t, err, "failed to cancel leasing")
 	r.isActive = false
+	r.cancelHeight = 2
+	r.cancelTransactionID = &txID
 	to.stor.flush(t)
 	resLeasing, err := to.leases.leasingInfo(leaseID, true)
 	assert.NoError(t, err, "failed to get leasing info")
 	assert.Equal(t, resLeasing, r, "invalid leasing record after cancelation")
 }
+
+func TestLeasesByAddr(t *testing.T) {
+	to, path, err := createLeases()
+	assert.NoError(t, err, "createLeases() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	sender := testGlobal.senderInfo.addr
+	recipient := testGlobal.recipientInfo.addr
+	id0, err := crypto.NewDigestFromBytes(bytes.Repeat([]byte{0xff}, crypto.DigestSize))
+	assert.NoError(t, err, "failed to create digest from bytes")
+	id1, err := crypto.NewDigestFromBytes(bytes.Repeat([]byte{0xaa}, crypto.DigestSize))
+	assert.NoError(t, err, "failed to create digest from bytes")
+	id2, err := crypto.NewDigestFromBytes(bytes.Repeat([]byte{0x11}, crypto.DigestSize))
+	assert.NoError(t, err, "failed to create digest from bytes")
+	l0 := &leasing{isActive: true, leaseAmount: 10, recipient: recipient, sender: sender, originHeight: 1}
+	l1 := &leasing{isActive: true, leaseAmount: 20, recipient: sender, sender: recipient, originHeight: 2}
+	l2 := &leasing{isActive: true, leaseAmount: 30, recipient: recipient, sender: sender, originHeight: 2}
+
+	to.stor.addBlock(t, blockID0)
+	err = to.leases.addLeasing(id0, l0, blockID0)
+	assert.NoError(t, err, "addLeasing() failed")
+	to.stor.flush(t)
+	to.stor.addBlock(t, blockID1)
+	err = to.leases.addLeasing(id1, l1, blockID1)
+	assert.NoError(t, err, "addLeasing() failed")
+	err = to.leases.addLeasing(id2, l2, blockID1)
+	assert.NoError(t, err, "addLeasing() failed")
+	err = to.leases.cancelLeasing(id0, 2, &id1, blockID1, true)
+	assert.NoError(t, err, "cancelLeasing() failed")
+	to.stor.flush(t)
+
+	collect := func(addr proto.Address, incoming bool, after *crypto.Digest, limit int) ([]crypto.Digest, error) {
+		var ids []crypto.Digest
+		err := to.leases.leasesByAddr(addr, incoming, after, true, func(id crypto.Digest, l *leasing) (bool, error) {
+			ids = append(ids, id)
+			return limit == 0 || len(ids) < limit, nil
+		})
+		return ids, err
+	}
+
+	// The newest lease goes first, sent and received leases are indexed separately.
+	ids, err := collect(sender, false, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id2, id0}, ids)
+	ids, err = collect(sender, true, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id1}, ids)
+	ids, err = collect(recipient, true, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id2, id0}, ids)
+	ids, err = collect(recipient, false, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id1}, ids)
+
+	// Iteration stops when the callback asks for it and continues from the cursor.
+	ids, err = collect(sender, false, nil, 1)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id2}, ids)
+	ids, err = collect(sender, false, &id2, 1)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id0}, ids)
+	ids, err = collect(sender, false, &id0, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Empty(t, ids)
+
+	// Cursor must be one of the leases of the address in the same direction.
+	_, err = collect(sender, false, &id1, 0)
+	assert.Equal(t, keyvalue.ErrNotFound, err)
+	unknown := crypto.MustDigestFromBase58("2R9H5ARbKG7UGHgRXs5A8aV7fZQUvbiPfq8yWpGQPcd1")
+	_, err = collect(sender, false, &unknown, 0)
+	assert.Equal(t, keyvalue.ErrNotFound, err)
+
+	// Leases are returned with their latest state.
+	var res *leasing
+	err = to.leases.leasesByAddr(sender, false, &id2, true, func(id crypto.Digest, l *leasing) (bool, error) {
+		res = l
+		return false, nil
+	})
+	assert.NoError(t, err, "leasesByAddr() failed")
+	l0.isActive = false
+	l0.cancelHeight = 2
+	l0.cancelTransactionID = &id1
+	assert.Equal(t, l0, res)
+
+	// Rolled back leases are not returned.
+	err = to.stor.stateDB.rollbackBlock(blockID1)
+	assert.NoError(t, err, "rollbackBlock() failed")
+	ids, err = collect(sender, false, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Equal(t, []crypto.Digest{id0}, ids)
+	ids, err = collect(sender, true, nil, 0)
+	assert.NoError(t, err, "leasesByAddr() failed")
+	assert.Empty(t, ids)
+	_, err = collect(sender, false, &id2, 0)
+	assert.Equal(t, keyvalue.ErrNotFound, err)
+}
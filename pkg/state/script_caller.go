@@ -17,6 +17,8 @@ type scriptCaller struct {
 	settings *settings.BlockchainSettings
 
 	totalComplexity uint64
+	// Scripts executed since the last takeExecutions() call, for scripts statistics.
+	executions []scriptExecution
 }
 
 func newScriptCaller(
@@ -57,16 +59,23 @@ func (a *scriptCaller) callAccountScriptWithOrder(order proto.Order, lastBlockIn
 	}
 	this := ast.NewAddressFromProtoAddress(sender)
 	lastBlock := ast.NewObjectFromBlockInfo(*lastBlockInfo)
+	key := accountScriptKey{sender}
 	if err := a.callVerifyScript(script, obj, this, lastBlock); err != nil {
+		a.recordFailure(key.bytes(), sender, err)
 		id, _ := order.GetID()
 		return errors.Errorf("account script; order ID %s: %v\n", base58.Encode(id), err)
 	}
 	// Increase complexity.
-	complexity, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(sender, !initialisation)
+	estimatorVersion, err := a.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return err
+	}
+	complexity, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(sender, estimatorVersion, !initialisation)
 	if err != nil {
 		return errors.Wrap(err, "newestScriptComplexityByAddr")
 	}
 	a.totalComplexity += complexity.verifierComplexity
+	a.recordExecution(key.bytes(), sender, complexity.verifierComplexity)
 	return nil
 }
 
@@ -85,20 +94,27 @@ func (a *scriptCaller) callAccountScriptWithTx(tx proto.Transaction, lastBlockIn
 	}
 	this := ast.NewAddressFromProtoAddress(senderAddr)
 	lastBlock := ast.NewObjectFromBlockInfo(*lastBlockInfo)
+	key := accountScriptKey{senderAddr}
 	if err := a.callVerifyScript(script, obj, this, lastBlock); err != nil {
+		a.recordFailure(key.bytes(), senderAddr, err)
 		id, _ := tx.GetID(a.settings.AddressSchemeCharacter)
 		return errors.Errorf("account script; transaction ID %s: %v\n", base58.Encode(id), err)
 	}
 	// Increase complexity.
-	complexity, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(senderAddr, !initialisation)
+	estimatorVersion, err := a.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return err
+	}
+	complexity, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(senderAddr, estimatorVersion, !initialisation)
 	if err != nil {
 		return errors.Wrap(err, "newestScriptComplexityByAddr")
 	}
 	a.totalComplexity += complexity.verifierComplexity
+	a.recordExecution(key.bytes(), senderAddr, complexity.verifierComplexity)
 	return nil
 }
 
-func (a *scriptCaller) callAssetScriptCommon(obj map[string]ast.Expr, assetID crypto.Digest, lastBlockInfo *proto.BlockInfo, initialisation bool) error {
+func (a *scriptCaller) callAssetScriptCommon(obj map[string]ast.Expr, assetID crypto.Digest, caller proto.Address, lastBlockInfo *proto.BlockInfo, initialisation bool) error {
 	script, err := a.stor.scriptsStorage.newestScriptByAsset(assetID, !initialisation)
 	if err != nil {
 		return errors.Errorf("failed to retrieve asset script: %v\n", err)
@@ -109,15 +125,22 @@ func (a *scriptCaller) callAssetScriptCommon(obj map[string]ast.Expr, assetID cr
 	}
 	this := ast.NewObjectFromAssetInfo(*assetInfo)
 	lastBlock := ast.NewObjectFromBlockInfo(*lastBlockInfo)
+	key := assetScriptKey{assetID}
 	if err := a.callVerifyScript(script, obj, this, lastBlock); err != nil {
+		a.recordFailure(key.bytes(), caller, err)
 		return errors.Wrap(err, "callVerifyScript failed")
 	}
 	// Increase complexity.
-	complexityRecord, err := a.stor.scriptsComplexity.newestScriptComplexityByAsset(assetID, !initialisation)
+	estimatorVersion, err := a.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return err
+	}
+	complexityRecord, err := a.stor.scriptsComplexity.newestScriptComplexityByAsset(assetID, estimatorVersion, !initialisation)
 	if err != nil {
 		return errors.Wrap(err, "newestScriptComplexityByAsset()")
 	}
 	a.totalComplexity += complexityRecord.complexity
+	a.recordExecution(key.bytes(), caller, complexityRecord.complexity)
 	return nil
 }
 
@@ -126,18 +149,22 @@ func (a *scriptCaller) callAssetScriptWithScriptTransfer(tr *proto.FullScriptTra
 	if err != nil {
 		return errors.Wrap(err, "failed to convert transaction")
 	}
-	if err := a.callAssetScriptCommon(obj, assetID, lastBlockInfo, initialisation); err != nil {
+	if err := a.callAssetScriptCommon(obj, assetID, tr.Sender, lastBlockInfo, initialisation); err != nil {
 		return errors.Errorf("asset script; script transfer ID %s: %v\n", tr.ID.String(), err)
 	}
 	return nil
 }
 
 func (a *scriptCaller) callAssetScript(tx proto.Transaction, assetID crypto.Digest, lastBlockInfo *proto.BlockInfo, initialisation bool) error {
+	sender, err := proto.NewAddressFromPublicKey(a.settings.AddressSchemeCharacter, tx.GetSenderPK())
+	if err != nil {
+		return err
+	}
 	obj, err := ast.NewVariablesFromTransaction(a.settings.AddressSchemeCharacter, tx)
 	if err != nil {
 		return errors.Wrap(err, "failed to convert transaction")
 	}
-	if err := a.callAssetScriptCommon(obj, assetID, lastBlockInfo, initialisation); err != nil {
+	if err := a.callAssetScriptCommon(obj, assetID, sender, lastBlockInfo, initialisation); err != nil {
 		id, _ := tx.GetID(a.settings.AddressSchemeCharacter)
 		return errors.Errorf("asset script; transaction ID %s: %v\n", base58.Encode(id), err)
 	}
@@ -153,19 +180,30 @@ func (a *scriptCaller) invokeFunction(tx *proto.InvokeScriptWithProofs, lastBloc
 	if err != nil {
 		return nil, err
 	}
+	caller, err := proto.NewAddressFromPublicKey(a.settings.AddressSchemeCharacter, tx.SenderPK)
+	if err != nil {
+		return nil, err
+	}
+	key := accountScriptKey{*scriptAddr}
 	this := ast.NewAddressFromProtoAddress(*scriptAddr)
 	lastBlock := ast.NewObjectFromBlockInfo(*lastBlockInfo)
 	sr, err := script.CallFunction(a.settings.AddressSchemeCharacter, a.state, tx, this, lastBlock)
 	if err != nil {
+		a.recordFailure(key.bytes(), caller, err)
 		return nil, errors.Errorf("transaction ID %s: %v\n", tx.ID.String(), err)
 	}
 	// Increase complexity.
-	complexityRecord, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(*scriptAddr, !initialisation)
+	estimatorVersion, err := a.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return nil, err
+	}
+	complexityRecord, err := a.stor.scriptsComplexity.newestScriptComplexityByAddr(*scriptAddr, estimatorVersion, !initialisation)
 	if err != nil {
 		return nil, errors.Wrap(err, "newestScriptComplexityByAsset()")
 	}
 	// TODO: check this!
 	a.totalComplexity += complexityRecord.byFuncs[tx.FunctionCall.Name]
+	a.recordExecution(key.bytes(), caller, complexityRecord.byFuncs[tx.FunctionCall.Name])
 	return sr, nil
 }
 
@@ -176,3 +214,18 @@ func (a *scriptCaller) getTotalComplexity() uint64 {
 func (a *scriptCaller) resetComplexity() {
 	a.totalComplexity = 0
 }
+
+func (a *scriptCaller) recordExecution(key []byte, caller proto.Address, complexity uint64) {
+	a.executions = append(a.executions, scriptExecution{key: string(key), caller: caller, complexity: complexity})
+}
+
+func (a *scriptCaller) recordFailure(key []byte, caller proto.Address, err error) {
+	a.executions = append(a.executions, scriptExecution{key: string(key), caller: caller, failure: err.Error()})
+}
+
+// takeExecutions returns the scripts executed since the previous call.
+func (a *scriptCaller) takeExecutions() []scriptExecution {
+	executions := a.executions
+	a.executions = nil
+	return executions
+}
@@ -5,7 +5,12 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/estimation"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"go.uber.org/zap"
 )
 
 const (
@@ -101,8 +106,8 @@ func newScriptsComplexity(hs *historyStorage) (*scriptsComplexity, error) {
 	return &scriptsComplexity{hs: hs}, nil
 }
 
-func (sc *scriptsComplexity) newestScriptComplexityByAddr(addr proto.Address, filter bool) (*accountScriptComplexityRecord, error) {
-	key := accountScriptComplexityKey{addr}
+func (sc *scriptsComplexity) newestScriptComplexityByAddr(addr proto.Address, estimator int, filter bool) (*accountScriptComplexityRecord, error) {
+	key := accountScriptComplexityKey{addr, byte(estimator)}
 	recordBytes, err := sc.hs.freshLatestEntryData(key.bytes(), filter)
 	if err != nil {
 		return nil, err
@@ -114,8 +119,8 @@ func (sc *scriptsComplexity) newestScriptComplexityByAddr(addr proto.Address, fi
 	return &record, nil
 }
 
-func (sc *scriptsComplexity) newestScriptComplexityByAsset(asset crypto.Digest, filter bool) (*assetScriptComplexityRecord, error) {
-	key := assetScriptComplexityKey{asset}
+func (sc *scriptsComplexity) newestScriptComplexityByAsset(asset crypto.Digest, estimator int, filter bool) (*assetScriptComplexityRecord, error) {
+	key := assetScriptComplexityKey{asset, byte(estimator)}
 	recordBytes, err := sc.hs.freshLatestEntryData(key.bytes(), filter)
 	if err != nil {
 		return nil, err
@@ -127,8 +132,8 @@ func (sc *scriptsComplexity) newestScriptComplexityByAsset(asset crypto.Digest,
 	return &record, nil
 }
 
-func (sc *scriptsComplexity) scriptComplexityByAsset(asset crypto.Digest, filter bool) (*assetScriptComplexityRecord, error) {
-	key := assetScriptComplexityKey{asset}
+func (sc *scriptsComplexity) scriptComplexityByAsset(asset crypto.Digest, estimator int, filter bool) (*assetScriptComplexityRecord, error) {
+	key := assetScriptComplexityKey{asset, byte(estimator)}
 	recordBytes, err := sc.hs.latestEntryData(key.bytes(), filter)
 	if err != nil {
 		return nil, err
@@ -140,25 +145,27 @@ func (sc *scriptsComplexity) scriptComplexityByAsset(asset crypto.Digest, filter
 	return &record, nil
 }
 
-func (sc *scriptsComplexity) scriptComplexityByAddress(addr proto.Address, filter bool) (*accountScriptComplexityRecord, error) {
-	key := accountScriptComplexityKey{addr}
+func (sc *scriptsComplexity) scriptComplexityByAddress(addr proto.Address, estimator int, filter bool) (*accountScriptComplexityRecord, error) {
+	key := accountScriptComplexityKey{addr, byte(estimator)}
 	recordBytes, err := sc.hs.latestEntryData(key.bytes(), filter)
 	if err != nil {
 		return nil, err
 	}
-	var record accountScriptComplexityRecord
+	record := newAccountScriptComplexityRecord()
 	if err := record.unmarshalBinary(recordBytes); err != nil {
 		return nil, errors.Errorf("failed to unmarshal account script complexity record: %v\n", err)
 	}
 	return &record, nil
 }
 
+// saveComplexityForAddr stores the complexity under the version of estimator it was calculated with,
+// the complexities calculated by other estimators are kept intact.
 func (sc *scriptsComplexity) saveComplexityForAddr(addr proto.Address, record *accountScriptComplexityRecord, blockID proto.BlockID) error {
 	recordBytes, err := record.marshalBinary()
 	if err != nil {
 		return err
 	}
-	key := accountScriptComplexityKey{addr}
+	key := accountScriptComplexityKey{addr, record.estimator}
 	return sc.hs.addNewEntry(accountScriptComplexity, key.bytes(), recordBytes, blockID)
 }
 
@@ -167,6 +174,243 @@ func (sc *scriptsComplexity) saveComplexityForAsset(asset crypto.Digest, record
 	if err != nil {
 		return err
 	}
-	key := assetScriptComplexityKey{asset}
+	key := assetScriptComplexityKey{asset, record.estimator}
 	return sc.hs.addNewEntry(assetScriptComplexity, key.bytes(), recordBytes, blockID)
 }
+
+// entryDataAtBlock returns the complexity calculated in the given block by the newest of estimators.
+func (sc *scriptsComplexity) entryDataAtBlock(key func(estimator byte) []byte, blockID proto.BlockID, filter bool) ([]byte, error) {
+	for v := estimation.LatestVersion; v > 0; v-- {
+		entries, err := sc.hs.entriesDataWithBlocks(key(byte(v)), filter)
+		if err == keyvalue.ErrNotFound || err == errEmptyHist {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.blockID == blockID {
+				return entry.data, nil
+			}
+		}
+	}
+	return nil, keyvalue.ErrNotFound
+}
+
+func (sc *scriptsComplexity) scriptComplexityByAddrAtBlock(addr proto.Address, blockID proto.BlockID, filter bool) (*accountScriptComplexityRecord, error) {
+	key := func(estimator byte) []byte {
+		k := accountScriptComplexityKey{addr, estimator}
+		return k.bytes()
+	}
+	recordBytes, err := sc.entryDataAtBlock(key, blockID, filter)
+	if err != nil {
+		return nil, err
+	}
+	record := newAccountScriptComplexityRecord()
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return nil, errors.Errorf("failed to unmarshal account script complexity record: %v\n", err)
+	}
+	return &record, nil
+}
+
+func (sc *scriptsComplexity) scriptComplexityByAssetAtBlock(asset crypto.Digest, blockID proto.BlockID, filter bool) (*assetScriptComplexityRecord, error) {
+	key := func(estimator byte) []byte {
+		k := assetScriptComplexityKey{asset, estimator}
+		return k.bytes()
+	}
+	recordBytes, err := sc.entryDataAtBlock(key, blockID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var record assetScriptComplexityRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return nil, errors.Errorf("failed to unmarshal asset script complexity record: %v\n", err)
+	}
+	return &record, nil
+}
+
+// scriptAst returns the AST of the script stored by the key or nil if the script was removed.
+func (sc *scriptsComplexity) scriptAst(key []byte) (*ast.Script, error) {
+	recordBytes, err := sc.hs.latestEntryData(key, true)
+	if err != nil {
+		return nil, err
+	}
+	var record scriptRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return nil, err
+	}
+	if len(record.script) == 0 {
+		return nil, nil
+	}
+	return ast.BuildScript(reader.NewBytesReader(record.script))
+}
+
+// recalculate estimates all the scripts of accounts and assets with the given version of estimator.
+// It is called when the new estimator activates. Scripts are read from DB, so the scripts changed by blocks
+// must be flushed beforehand, otherwise the recalculation fails.
+func (sc *scriptsComplexity) recalculate(estimatorVersion int, blockID proto.BlockID) error {
+	for _, e := range sc.hs.stor.getEntries() {
+		if p := e.key[0]; p == accountScriptKeyPrefix || p == assetScriptKeyPrefix {
+			return errors.New("failed to recalculate complexities: scripts are not flushed")
+		}
+	}
+	zap.S().Infof("Started to recalculate complexities of scripts with estimator V%d", estimatorVersion)
+	if err := sc.recalculateAccountScripts(estimatorVersion, blockID); err != nil {
+		return err
+	}
+	if err := sc.recalculateAssetScripts(estimatorVersion, blockID); err != nil {
+		return err
+	}
+	zap.S().Info("Finished to recalculate complexities of scripts")
+	return nil
+}
+
+func (sc *scriptsComplexity) recalculateAccountScripts(estimatorVersion int, blockID proto.BlockID) error {
+	iter, err := sc.hs.db.NewKeyIterator([]byte{accountScriptKeyPrefix})
+	if err != nil {
+		return errors.Errorf("failed to create key iterator to recalculate complexities: %v", err)
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	for iter.Next() {
+		key := keyvalue.SafeKey(iter)
+		var k accountScriptKey
+		if err := k.unmarshal(key); err != nil {
+			return errors.Errorf("failed to unmarshal account script key: %v", err)
+		}
+		script, err := sc.scriptAst(key)
+		if err != nil {
+			return errors.Errorf("failed to load script of account %s: %v", k.addr.String(), err)
+		}
+		if script == nil {
+			continue
+		}
+		estimator := estimation.NewEstimatorForScript(estimatorVersion, script)
+		costs, err := estimator.Estimate(script)
+		if err != nil {
+			zap.S().Warnf("Failed to estimate script of account %s with estimator V%d, previous complexity is kept: %v", k.addr.String(), estimatorVersion, err)
+			if err := sc.keepComplexityForAddr(k.addr, estimatorVersion, blockID); err != nil {
+				return err
+			}
+			continue
+		}
+		record := &accountScriptComplexityRecord{
+			verifierComplexity: costs.Verifier,
+			estimator:          byte(estimatorVersion),
+		}
+		if script.IsDapp() {
+			record.byFuncs = costs.Functions
+		}
+		if err := sc.saveComplexityForAddr(k.addr, record, blockID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sc *scriptsComplexity) recalculateAssetScripts(estimatorVersion int, blockID proto.BlockID) error {
+	iter, err := sc.hs.db.NewKeyIterator([]byte{assetScriptKeyPrefix})
+	if err != nil {
+		return errors.Errorf("failed to create key iterator to recalculate complexities: %v", err)
+	}
+	defer func() {
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			zap.S().Fatalf("Iterator error: %v", err)
+		}
+	}()
+
+	for iter.Next() {
+		key := keyvalue.SafeKey(iter)
+		var k assetScriptKey
+		if err := k.unmarshal(key); err != nil {
+			return errors.Errorf("failed to unmarshal asset script key: %v", err)
+		}
+		script, err := sc.scriptAst(key)
+		if err != nil {
+			return errors.Errorf("failed to load script of asset %s: %v", k.asset.String(), err)
+		}
+		if script == nil {
+			continue
+		}
+		estimator := estimation.NewEstimatorForScript(estimatorVersion, script)
+		costs, err := estimator.Estimate(script)
+		if err != nil {
+			zap.S().Warnf("Failed to estimate script of asset %s with estimator V%d, previous complexity is kept: %v", k.asset.String(), estimatorVersion, err)
+			if err := sc.keepComplexityForAsset(k.asset, estimatorVersion, blockID); err != nil {
+				return err
+			}
+			continue
+		}
+		record := &assetScriptComplexityRecord{complexity: costs.Verifier, estimator: byte(estimatorVersion)}
+		if err := sc.saveComplexityForAsset(k.asset, record, blockID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// previousComplexity returns the complexity calculated by the newest of estimators preceding the given one.
+func (sc *scriptsComplexity) previousComplexity(key func(estimator byte) []byte, estimatorVersion int) ([]byte, error) {
+	for v := estimatorVersion - 1; v > 0; v-- {
+		recordBytes, err := sc.hs.freshLatestEntryData(key(byte(v)), true)
+		if err == keyvalue.ErrNotFound || err == errEmptyHist {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return recordBytes, nil
+	}
+	return nil, keyvalue.ErrNotFound
+}
+
+// keepComplexityForAddr stores the complexity calculated by the previous estimator under the given version of estimator.
+// It is used for the scripts the new estimator fails to estimate, such scripts are kept working with the old complexity.
+func (sc *scriptsComplexity) keepComplexityForAddr(addr proto.Address, estimatorVersion int, blockID proto.BlockID) error {
+	key := func(estimator byte) []byte {
+		k := accountScriptComplexityKey{addr, estimator}
+		return k.bytes()
+	}
+	recordBytes, err := sc.previousComplexity(key, estimatorVersion)
+	if err == keyvalue.ErrNotFound {
+		zap.S().Warnf("No previous complexity of script of account %s", addr.String())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	record := newAccountScriptComplexityRecord()
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return errors.Errorf("failed to unmarshal account script complexity record: %v\n", err)
+	}
+	record.estimator = byte(estimatorVersion)
+	return sc.saveComplexityForAddr(addr, &record, blockID)
+}
+
+// keepComplexityForAsset is the same as keepComplexityForAddr for the scripts of assets.
+func (sc *scriptsComplexity) keepComplexityForAsset(asset crypto.Digest, estimatorVersion int, blockID proto.BlockID) error {
+	key := func(estimator byte) []byte {
+		k := assetScriptComplexityKey{asset, estimator}
+		return k.bytes()
+	}
+	recordBytes, err := sc.previousComplexity(key, estimatorVersion)
+	if err == keyvalue.ErrNotFound {
+		zap.S().Warnf("No previous complexity of script of asset %s", asset.String())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var record assetScriptComplexityRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return errors.Errorf("failed to unmarshal asset script complexity record: %v\n", err)
+	}
+	record.estimator = byte(estimatorVersion)
+	return sc.saveComplexityForAsset(asset, &record, blockID)
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/estimation"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 )
 
@@ -44,13 +46,13 @@ func TestSaveComplexityForAddr(t *testing.T) {
 	r := &accountScriptComplexityRecord{verifierComplexity: 100500, byFuncs: byFuncs, estimator: 1}
 	err = to.scriptsComplexity.saveComplexityForAddr(addr, r, blockID0)
 	assert.NoError(t, err)
-	res, err := to.scriptsComplexity.newestScriptComplexityByAddr(addr, true)
+	res, err := to.scriptsComplexity.newestScriptComplexityByAddr(addr, 1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, r, res)
 
 	to.stor.flush(t)
 
-	res, err = to.scriptsComplexity.newestScriptComplexityByAddr(addr, true)
+	res, err = to.scriptsComplexity.newestScriptComplexityByAddr(addr, 1, true)
 	assert.NoError(t, err)
 	assert.Equal(t, r, res)
 }
@@ -71,13 +73,135 @@ func TestSaveComplexityForAsset(t *testing.T) {
 	r := &assetScriptComplexityRecord{500, 2}
 	err = to.scriptsComplexity.saveComplexityForAsset(asset, r, blockID0)
 	assert.NoError(t, err)
-	res, err := to.scriptsComplexity.newestScriptComplexityByAsset(asset, true)
+	res, err := to.scriptsComplexity.newestScriptComplexityByAsset(asset, 2, true)
 	assert.NoError(t, err)
 	assert.Equal(t, r, res)
 
 	to.stor.flush(t)
 
-	res, err = to.scriptsComplexity.newestScriptComplexityByAsset(asset, true)
+	res, err = to.scriptsComplexity.newestScriptComplexityByAsset(asset, 2, true)
 	assert.NoError(t, err)
 	assert.Equal(t, r, res)
 }
+
+func TestRecalculateComplexities(t *testing.T) {
+	to, path, err := createScriptsComplexityStorageObjects()
+	assert.NoError(t, err, "createScriptsComplexityStorageObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	addr := testGlobal.senderInfo.addr
+	asset := testGlobal.asset0.asset.ID
+	to.stor.addBlock(t, blockID0)
+	err = to.stor.entities.scriptsStorage.setAccountScript(addr, testGlobal.scriptBytes, blockID0)
+	assert.NoError(t, err)
+	err = to.stor.entities.scriptsStorage.setAssetScript(asset, testGlobal.scriptBytes, blockID0)
+	assert.NoError(t, err)
+	err = to.scriptsComplexity.saveComplexityForAddr(addr, &accountScriptComplexityRecord{verifierComplexity: 100, estimator: 1}, blockID0)
+	assert.NoError(t, err)
+	// Scripts are read from DB, so the recalculation before the flush would miss them.
+	err = to.scriptsComplexity.recalculate(3, blockID0)
+	assert.EqualError(t, err, "failed to recalculate complexities: scripts are not flushed")
+	to.stor.flush(t)
+
+	to.stor.addBlock(t, blockID1)
+	err = to.scriptsComplexity.recalculate(3, blockID1)
+	assert.NoError(t, err)
+	costs, err := estimation.NewEstimatorForScript(3, &testGlobal.scriptAst).Estimate(&testGlobal.scriptAst)
+	assert.NoError(t, err)
+	accountRecord, err := to.scriptsComplexity.newestScriptComplexityByAddr(addr, 3, true)
+	assert.NoError(t, err)
+	assert.Equal(t, costs.Verifier, accountRecord.verifierComplexity)
+	assert.Equal(t, byte(3), accountRecord.estimator)
+	assetRecord, err := to.scriptsComplexity.newestScriptComplexityByAsset(asset, 3, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &assetScriptComplexityRecord{costs.Verifier, 3}, assetRecord)
+	// Complexity calculated by previous estimator is kept.
+	accountRecord, err = to.scriptsComplexity.newestScriptComplexityByAddr(addr, 1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(100), accountRecord.verifierComplexity)
+	_, err = to.scriptsComplexity.newestScriptComplexityByAsset(asset, 1, true)
+	assert.Error(t, err)
+}
+
+func TestRecalculateComplexitiesKeepsFailedScripts(t *testing.T) {
+	to, path, err := createScriptsComplexityStorageObjects()
+	assert.NoError(t, err, "createScriptsComplexityStorageObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	// Script `z` references undefined variable, so estimator V3 fails on it.
+	script := []byte{3, 5, 0, 0, 0, 1, 'z'}
+	addr := testGlobal.senderInfo.addr
+	asset := testGlobal.asset0.asset.ID
+	asset1 := testGlobal.asset1.asset.ID
+	to.stor.addBlock(t, blockID0)
+	err = to.stor.entities.scriptsStorage.setAccountScript(addr, script, blockID0)
+	assert.NoError(t, err)
+	err = to.stor.entities.scriptsStorage.setAssetScript(asset, script, blockID0)
+	assert.NoError(t, err)
+	err = to.stor.entities.scriptsStorage.setAssetScript(asset1, script, blockID0)
+	assert.NoError(t, err)
+	accountRecord := &accountScriptComplexityRecord{verifierComplexity: 100, byFuncs: map[string]uint64{}, estimator: 1}
+	err = to.scriptsComplexity.saveComplexityForAddr(addr, accountRecord, blockID0)
+	assert.NoError(t, err)
+	err = to.scriptsComplexity.saveComplexityForAsset(asset, &assetScriptComplexityRecord{200, 2}, blockID0)
+	assert.NoError(t, err)
+	to.stor.flush(t)
+
+	to.stor.addBlock(t, blockID1)
+	err = to.scriptsComplexity.recalculate(3, blockID1)
+	assert.NoError(t, err)
+	r, err := to.scriptsComplexity.newestScriptComplexityByAddr(addr, 3, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &accountScriptComplexityRecord{verifierComplexity: 100, byFuncs: map[string]uint64{}, estimator: 3}, r)
+	ar, err := to.scriptsComplexity.newestScriptComplexityByAsset(asset, 3, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &assetScriptComplexityRecord{200, 3}, ar)
+	// The script without previous complexity is skipped.
+	_, err = to.scriptsComplexity.newestScriptComplexityByAsset(asset1, 3, true)
+	assert.Error(t, err)
+}
+
+func TestScriptComplexityAtBlock(t *testing.T) {
+	to, path, err := createScriptsComplexityStorageObjects()
+	assert.NoError(t, err, "createScriptsComplexityStorageObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	asset := testGlobal.asset0.asset.ID
+	to.stor.addBlock(t, blockID0)
+	err = to.scriptsComplexity.saveComplexityForAsset(asset, &assetScriptComplexityRecord{100, 1}, blockID0)
+	assert.NoError(t, err)
+	to.stor.flush(t)
+	to.stor.addBlock(t, blockID1)
+	err = to.scriptsComplexity.saveComplexityForAsset(asset, &assetScriptComplexityRecord{200, 3}, blockID1)
+	assert.NoError(t, err)
+	to.stor.flush(t)
+
+	r, err := to.scriptsComplexity.scriptComplexityByAssetAtBlock(asset, blockID0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &assetScriptComplexityRecord{100, 1}, r)
+	r, err = to.scriptsComplexity.scriptComplexityByAssetAtBlock(asset, blockID1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, &assetScriptComplexityRecord{200, 3}, r)
+	blockID2 := genBlockId(3)
+	to.stor.addBlock(t, blockID2)
+	_, err = to.scriptsComplexity.scriptComplexityByAssetAtBlock(asset, blockID2, true)
+	assert.Equal(t, keyvalue.ErrNotFound, err)
+}
@@ -0,0 +1,250 @@
+package state
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	// Statistics are kept for this number of last blocks.
+	maxScriptsStatsWindow = 10000
+	// Distinct failure reasons kept for the script at one height, the rest are counted as otherFailureReason.
+	maxFailureReasonsAtHeight = 100
+	maxFailureReasonLength    = 256
+	// Failure reasons returned for the window, the most frequent first.
+	maxFailureReasonsInStats = 10
+	// Distinct callers kept for the script at one height and counted for the window.
+	maxCallersAtHeight = 1000
+	maxCallersInStats  = 10000
+	// Rejected transactions remembered to count their failures once.
+	maxRejectedTransactions = 100000
+
+	otherFailureReason = "other"
+)
+
+// scriptExecution is a single run of account or asset script.
+type scriptExecution struct {
+	// Key of the script in scripts storage.
+	key        string
+	caller     proto.Address
+	complexity uint64
+	// Reason of failure, empty if the script allowed the transaction.
+	failure string
+}
+
+type scriptExecutionsAtHeight struct {
+	height     uint64
+	executions []scriptExecution
+}
+
+type scriptStatsBucket struct {
+	invocations   uint64
+	failures      uint64
+	complexity    uint64
+	maxComplexity uint64
+	reasons       map[string]uint64
+	callers       map[proto.Address]struct{}
+}
+
+func newScriptStatsBucket() *scriptStatsBucket {
+	return &scriptStatsBucket{reasons: make(map[string]uint64), callers: make(map[proto.Address]struct{})}
+}
+
+func (b *scriptStatsBucket) add(e scriptExecution) {
+	b.invocations++
+	if len(b.callers) < maxCallersAtHeight {
+		b.callers[e.caller] = empty
+	}
+	if e.failure != "" {
+		b.failures++
+		reason := e.failure
+		if len(reason) > maxFailureReasonLength {
+			reason = reason[:maxFailureReasonLength]
+		}
+		if _, ok := b.reasons[reason]; !ok && len(b.reasons) >= maxFailureReasonsAtHeight {
+			reason = otherFailureReason
+		}
+		b.reasons[reason]++
+		return
+	}
+	b.complexity += e.complexity
+	if e.complexity > b.maxComplexity {
+		b.maxComplexity = e.complexity
+	}
+}
+
+// scriptsStats is the in-memory index of scripts executions by height.
+// Successful executions are taken from the applied blocks, failed ones from the transactions rejected by the node.
+// The index is not persisted and starts empty after restart, collectedFrom is the first height it covers.
+type scriptsStats struct {
+	mu            sync.Mutex
+	collectedFrom uint64
+	// Key of the script -> height -> executions at this height.
+	byKey      map[string]map[uint64]*scriptStatsBucket
+	lastPruned uint64
+	// IDs of the last rejected transactions which failures are counted, the oldest first.
+	rejected      map[string]struct{}
+	rejectedOrder []string
+}
+
+func newScriptsStats() *scriptsStats {
+	return &scriptsStats{
+		byKey:    make(map[string]map[uint64]*scriptStatsBucket),
+		rejected: make(map[string]struct{}),
+	}
+}
+
+// start sets the height of the first block which executions are collected.
+func (s *scriptsStats) start(height uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collectedFrom = height
+}
+
+func (s *scriptsStats) add(height uint64, executions []scriptExecution) {
+	if len(executions) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addLocked(height, executions)
+}
+
+func (s *scriptsStats) addLocked(height uint64, executions []scriptExecution) {
+	for _, e := range executions {
+		buckets, ok := s.byKey[e.key]
+		if !ok {
+			buckets = make(map[uint64]*scriptStatsBucket)
+			s.byKey[e.key] = buckets
+		}
+		b, ok := buckets[height]
+		if !ok {
+			b = newScriptStatsBucket()
+			buckets[height] = b
+		}
+		b.add(e)
+	}
+	if height >= s.lastPruned+maxScriptsStatsWindow/10 {
+		s.removeIf(func(h uint64) bool { return h+maxScriptsStatsWindow <= height })
+		s.lastPruned = height
+	}
+}
+
+// addFailures adds only failed executions, successful runs of the rejected transaction are not counted.
+// The node validates the same transaction many times, so the failures of the transaction are counted once.
+func (s *scriptsStats) addFailures(height uint64, txID []byte, executions []scriptExecution) {
+	var failures []scriptExecution
+	for _, e := range executions {
+		if e.failure != "" {
+			failures = append(failures, e)
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rejected[string(txID)]; ok {
+		return
+	}
+	s.rejected[string(txID)] = empty
+	s.rejectedOrder = append(s.rejectedOrder, string(txID))
+	if len(s.rejectedOrder) > maxRejectedTransactions {
+		delete(s.rejected, s.rejectedOrder[0])
+		s.rejectedOrder = s.rejectedOrder[1:]
+	}
+	s.addLocked(height, failures)
+}
+
+// rollback removes executions of the blocks above the height.
+func (s *scriptsStats) rollback(height uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removeIf(func(h uint64) bool { return h > height })
+	// Blocks above the height are collected again when applied.
+	if height+1 < s.collectedFrom {
+		s.collectedFrom = height + 1
+	}
+}
+
+func (s *scriptsStats) removeIf(remove func(height uint64) bool) {
+	for key, buckets := range s.byKey {
+		for h := range buckets {
+			if remove(h) {
+				delete(buckets, h)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(s.byKey, key)
+		}
+	}
+}
+
+// stats returns the statistics of the script for each window of the last blocks up to the height.
+func (s *scriptsStats) stats(key []byte, height uint64, windows []uint64) []proto.ScriptStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buckets := s.byKey[string(key)]
+	res := make([]proto.ScriptStats, len(windows))
+	for i, window := range windows {
+		from := uint64(1)
+		if height > window {
+			from = height - window + 1
+		}
+		total := newScriptStatsBucket()
+		for h, b := range buckets {
+			if h < from || h > height {
+				continue
+			}
+			total.invocations += b.invocations
+			total.failures += b.failures
+			total.complexity += b.complexity
+			if b.maxComplexity > total.maxComplexity {
+				total.maxComplexity = b.maxComplexity
+			}
+			for reason, count := range b.reasons {
+				total.reasons[reason] += count
+			}
+			for caller := range b.callers {
+				if len(total.callers) >= maxCallersInStats {
+					break
+				}
+				total.callers[caller] = empty
+			}
+		}
+		res[i] = total.toStats(window, from, height)
+		res[i].CollectedFrom = s.collectedFrom
+	}
+	return res
+}
+
+func (b *scriptStatsBucket) toStats(window, from, to uint64) proto.ScriptStats {
+	r := proto.ScriptStats{
+		Window:          window,
+		FromHeight:      from,
+		ToHeight:        to,
+		Invocations:     b.invocations,
+		Failures:        b.failures,
+		MaxComplexity:   b.maxComplexity,
+		DistinctCallers: uint64(len(b.callers)),
+		FailureReasons:  make([]proto.ScriptFailureReason, 0, len(b.reasons)),
+	}
+	if succeeded := b.invocations - b.failures; succeeded > 0 {
+		r.AverageComplexity = b.complexity / succeeded
+	}
+	for reason, count := range b.reasons {
+		r.FailureReasons = append(r.FailureReasons, proto.ScriptFailureReason{Reason: reason, Count: count})
+	}
+	sort.Slice(r.FailureReasons, func(i, j int) bool {
+		if r.FailureReasons[i].Count != r.FailureReasons[j].Count {
+			return r.FailureReasons[i].Count > r.FailureReasons[j].Count
+		}
+		return r.FailureReasons[i].Reason < r.FailureReasons[j].Reason
+	})
+	if len(r.FailureReasons) > maxFailureReasonsInStats {
+		r.FailureReasons = r.FailureReasons[:maxFailureReasonsInStats]
+	}
+	return r
+}
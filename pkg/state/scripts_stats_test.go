@@ -0,0 +1,141 @@
+package state
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestScriptsStats(t *testing.T) {
+	stats := newScriptsStats()
+	stats.start(1)
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	other := assetScriptKey{testGlobal.asset0.asset.ID}
+	sender := testGlobal.senderInfo.addr
+	recipient := testGlobal.recipientInfo.addr
+
+	stats.add(1, []scriptExecution{
+		{key: string(key.bytes()), caller: sender, complexity: 100},
+		{key: string(other.bytes()), caller: sender, complexity: 10},
+	})
+	stats.add(5, []scriptExecution{
+		{key: string(key.bytes()), caller: recipient, complexity: 300},
+	})
+	stats.addFailures(6, []byte("tx"), []scriptExecution{
+		{key: string(key.bytes()), caller: sender, complexity: 10},
+		{key: string(key.bytes()), caller: sender, failure: "throw"},
+		{key: string(key.bytes()), caller: recipient, failure: "throw"},
+		{key: string(key.bytes()), caller: recipient, failure: "not allowed"},
+	})
+
+	res := stats.stats(key.bytes(), 6, []uint64{1, 2, 100})
+	expected := []proto.ScriptStats{
+		{
+			Window: 1, FromHeight: 6, ToHeight: 6, CollectedFrom: 1, Invocations: 3, Failures: 3, DistinctCallers: 2,
+			FailureReasons: []proto.ScriptFailureReason{{Reason: "throw", Count: 2}, {Reason: "not allowed", Count: 1}},
+		},
+		{
+			Window: 2, FromHeight: 5, ToHeight: 6, CollectedFrom: 1, Invocations: 4, Failures: 3, DistinctCallers: 2,
+			AverageComplexity: 300, MaxComplexity: 300,
+			FailureReasons: []proto.ScriptFailureReason{{Reason: "throw", Count: 2}, {Reason: "not allowed", Count: 1}},
+		},
+		{
+			Window: 100, FromHeight: 1, ToHeight: 6, CollectedFrom: 1, Invocations: 5, Failures: 3, DistinctCallers: 2,
+			AverageComplexity: 200, MaxComplexity: 300,
+			FailureReasons: []proto.ScriptFailureReason{{Reason: "throw", Count: 2}, {Reason: "not allowed", Count: 1}},
+		},
+	}
+	assert.Equal(t, expected, res)
+
+	// Executions of removed blocks are not counted.
+	stats.rollback(4)
+	res = stats.stats(key.bytes(), 4, []uint64{100})
+	assert.Equal(t, uint64(1), res[0].Invocations)
+	assert.Equal(t, uint64(0), res[0].Failures)
+	assert.Empty(t, res[0].FailureReasons)
+	res = stats.stats(other.bytes(), 4, []uint64{100})
+	assert.Equal(t, uint64(1), res[0].Invocations)
+
+	// Old executions are removed.
+	stats.add(maxScriptsStatsWindow+1, []scriptExecution{{key: string(other.bytes()), caller: sender, complexity: 20}})
+	_, ok := stats.byKey[string(key.bytes())]
+	assert.False(t, ok)
+	res = stats.stats(other.bytes(), maxScriptsStatsWindow+1, []uint64{maxScriptsStatsWindow})
+	assert.Equal(t, uint64(1), res[0].Invocations)
+	assert.Equal(t, uint64(20), res[0].MaxComplexity)
+}
+
+func TestScriptsStatsCollectedFrom(t *testing.T) {
+	stats := newScriptsStats()
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	stats.start(11)
+	stats.add(11, []scriptExecution{{key: string(key.bytes()), caller: testGlobal.senderInfo.addr, complexity: 10}})
+
+	res := stats.stats(key.bytes(), 11, []uint64{1, 100})
+	assert.Equal(t, uint64(11), res[0].CollectedFrom)
+	assert.Equal(t, uint64(11), res[0].FromHeight)
+	assert.Equal(t, uint64(11), res[1].CollectedFrom)
+	assert.Equal(t, uint64(1), res[1].FromHeight)
+
+	// Blocks reapplied after rollback below the start are collected.
+	stats.rollback(12)
+	assert.Equal(t, uint64(11), stats.stats(key.bytes(), 12, []uint64{100})[0].CollectedFrom)
+	stats.rollback(7)
+	assert.Equal(t, uint64(8), stats.stats(key.bytes(), 7, []uint64{100})[0].CollectedFrom)
+}
+
+func TestScriptsStatsFailureReasons(t *testing.T) {
+	stats := newScriptsStats()
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	executions := make([]scriptExecution, 0, maxFailureReasonsAtHeight+10)
+	for i := 0; i < maxFailureReasonsAtHeight+10; i++ {
+		executions = append(executions, scriptExecution{key: string(key.bytes()), caller: testGlobal.senderInfo.addr, failure: fmt.Sprintf("error %d", i)})
+	}
+	stats.addFailures(1, []byte("tx"), executions)
+	res := stats.stats(key.bytes(), 1, []uint64{1})
+	assert.Equal(t, uint64(maxFailureReasonsAtHeight+10), res[0].Failures)
+	assert.Len(t, res[0].FailureReasons, maxFailureReasonsInStats)
+	assert.Equal(t, proto.ScriptFailureReason{Reason: otherFailureReason, Count: 10}, res[0].FailureReasons[0])
+}
+
+func TestScriptsStatsLimits(t *testing.T) {
+	stats := newScriptsStats()
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	failure := []scriptExecution{{key: string(key.bytes()), caller: testGlobal.senderInfo.addr, failure: "throw"}}
+
+	// Failures of the same transaction are counted once.
+	stats.addFailures(1, []byte("tx1"), failure)
+	stats.addFailures(2, []byte("tx1"), failure)
+	stats.addFailures(2, []byte("tx2"), failure)
+	res := stats.stats(key.bytes(), 2, []uint64{2})
+	assert.Equal(t, uint64(2), res[0].Failures)
+	assert.Equal(t, uint64(2), res[0].Invocations)
+	for i := 0; i < maxRejectedTransactions-1; i++ {
+		stats.addFailures(3, []byte(fmt.Sprintf("tx%d", i+3)), failure)
+	}
+	assert.Len(t, stats.rejected, maxRejectedTransactions)
+	_, ok := stats.rejected["tx1"]
+	assert.False(t, ok, "the oldest transaction is forgotten")
+	stats.addFailures(3, []byte("tx1"), failure)
+	res = stats.stats(key.bytes(), 3, []uint64{3})
+	assert.Equal(t, uint64(maxRejectedTransactions+2), res[0].Failures)
+
+	// Distinct callers are capped.
+	for h := uint64(10); h < 30; h++ {
+		executions := make([]scriptExecution, 0, 2*maxCallersAtHeight)
+		for i := 0; i < 2*maxCallersAtHeight; i++ {
+			var caller proto.Address
+			caller[1] = byte(h)
+			caller[2] = byte(i)
+			caller[3] = byte(i >> 8)
+			executions = append(executions, scriptExecution{key: string(key.bytes()), caller: caller, complexity: 1})
+		}
+		stats.add(h, executions)
+		assert.Len(t, stats.byKey[string(key.bytes())][h].callers, maxCallersAtHeight)
+	}
+	res = stats.stats(key.bytes(), 29, []uint64{20})
+	assert.Equal(t, uint64(20*2*maxCallersAtHeight), res[0].Invocations)
+	assert.Equal(t, uint64(maxCallersInStats), res[0].DistinctCallers)
+}
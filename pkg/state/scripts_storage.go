@@ -5,6 +5,7 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
 	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"go.uber.org/zap"
 )
 
 const (
@@ -42,14 +43,24 @@ func (r *scriptRecord) unmarshalBinary(data []byte) error {
 type scriptsStorage struct {
 	hs    *historyStorage
 	cache *lru
+
+	calculateHashes     bool
+	accountScriptHasher *stateHasher
+	assetScriptHasher   *stateHasher
 }
 
-func newScriptsStorage(hs *historyStorage) (*scriptsStorage, error) {
+func newScriptsStorage(hs *historyStorage, calcHashes bool) (*scriptsStorage, error) {
 	cache, err := newLru(maxCacheSize, maxCacheBytes)
 	if err != nil {
 		return nil, err
 	}
-	return &scriptsStorage{hs, cache}, nil
+	return &scriptsStorage{
+		hs:                  hs,
+		cache:               cache,
+		calculateHashes:     calcHashes,
+		accountScriptHasher: newStateHasher(),
+		assetScriptHasher:   newStateHasher(),
+	}, nil
 }
 
 func (ss *scriptsStorage) setScript(scriptType blockchainEntity, key []byte, record scriptRecord, blockID proto.BlockID) error {
@@ -69,10 +80,20 @@ func (ss *scriptsStorage) setScript(scriptType blockchainEntity, key []byte, rec
 	if err != nil {
 		return err
 	}
-	ss.cache.set(key, scriptAst, scriptSize)
+	ss.cacheScript(key, scriptAst)
 	return nil
 }
 
+// cacheScript compiles the script before putting it to cache, so the cached scripts are evaluated without walking the tree.
+// The script that fails to compile is cached as is and evaluated by walking the tree.
+func (ss *scriptsStorage) cacheScript(key []byte, script ast.Script) ast.Script {
+	if err := script.Compile(); err != nil {
+		zap.S().Warnf("Script will be evaluated without compilation: %v", err)
+	}
+	ss.cache.set(key, script, scriptSize)
+	return script
+}
+
 func (ss *scriptsStorage) scriptBytesByKey(key []byte, filter bool) (proto.Script, error) {
 	recordBytes, err := ss.hs.latestEntryData(key, filter)
 	if err != nil {
@@ -116,6 +137,9 @@ func (ss *scriptsStorage) scriptAstByKey(key []byte, filter bool) (ast.Script, e
 func (ss *scriptsStorage) setAssetScript(assetID crypto.Digest, script proto.Script, blockID proto.BlockID) error {
 	key := assetScriptKey{assetID}
 	record := scriptRecord{script}
+	if ss.calculateHashes {
+		ss.assetScriptHasher.push(assetID.Bytes(), script, blockID)
+	}
 	return ss.setScript(assetScript, key.bytes(), record, blockID)
 }
 
@@ -151,8 +175,7 @@ func (ss *scriptsStorage) newestScriptByAsset(assetID crypto.Digest, filter bool
 	if err != nil {
 		return ast.Script{}, err
 	}
-	ss.cache.set(keyBytes, script, scriptSize)
-	return script, nil
+	return ss.cacheScript(keyBytes, script), nil
 }
 
 func (ss *scriptsStorage) scriptByAsset(assetID crypto.Digest, filter bool) (ast.Script, error) {
@@ -168,6 +191,9 @@ func (ss *scriptsStorage) scriptBytesByAsset(assetID crypto.Digest, filter bool)
 func (ss *scriptsStorage) setAccountScript(addr proto.Address, script proto.Script, blockID proto.BlockID) error {
 	key := accountScriptKey{addr}
 	record := scriptRecord{script}
+	if ss.calculateHashes {
+		ss.accountScriptHasher.push(addr.Bytes(), script, blockID)
+	}
 	return ss.setScript(accountScript, key.bytes(), record, blockID)
 }
 
@@ -226,8 +252,7 @@ func (ss *scriptsStorage) newestScriptByAddr(addr proto.Address, filter bool) (a
 	if err != nil {
 		return ast.Script{}, err
 	}
-	ss.cache.set(keyBytes, script, scriptSize)
-	return script, nil
+	return ss.cacheScript(keyBytes, script), nil
 }
 
 func (ss *scriptsStorage) scriptByAddr(addr proto.Address, filter bool) (ast.Script, error) {
@@ -240,6 +265,39 @@ func (ss *scriptsStorage) scriptBytesByAddr(addr proto.Address, filter bool) (pr
 	return ss.scriptBytesByKey(key.bytes(), filter)
 }
 
+type scriptHistoryRecord struct {
+	script  proto.Script
+	blockID proto.BlockID
+}
+
+// scriptHistoryByKey returns at most limit scripts stored by the key after the given height, from the oldest
+// to the newest one. Zero limit selects all the scripts.
+func (ss *scriptsStorage) scriptHistoryByKey(key []byte, after uint64, limit int, filter bool) ([]scriptHistoryRecord, error) {
+	entries, err := ss.hs.entriesDataWithBlocksAfterHeight(key, after, limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]scriptHistoryRecord, len(entries))
+	for i, entry := range entries {
+		var record scriptRecord
+		if err := record.unmarshalBinary(entry.data); err != nil {
+			return nil, err
+		}
+		res[i] = scriptHistoryRecord{record.script, entry.blockID}
+	}
+	return res, nil
+}
+
+func (ss *scriptsStorage) scriptHistoryByAddr(addr proto.Address, after uint64, limit int, filter bool) ([]scriptHistoryRecord, error) {
+	key := accountScriptKey{addr}
+	return ss.scriptHistoryByKey(key.bytes(), after, limit, filter)
+}
+
+func (ss *scriptsStorage) scriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int, filter bool) ([]scriptHistoryRecord, error) {
+	key := assetScriptKey{assetID}
+	return ss.scriptHistoryByKey(key.bytes(), after, limit, filter)
+}
+
 func (ss *scriptsStorage) clear() error {
 	var err error
 	ss.cache, err = newLru(maxCacheSize, maxCacheBytes)
@@ -248,3 +306,8 @@ func (ss *scriptsStorage) clear() error {
 	}
 	return nil
 }
+
+func (ss *scriptsStorage) resetHashes() {
+	ss.accountScriptHasher.reset()
+	ss.assetScriptHasher.reset()
+}
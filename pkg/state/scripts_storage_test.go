@@ -1,10 +1,17 @@
 package state
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
+	"github.com/mr-tron/base58/base58"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
+	"github.com/wavesplatform/gowaves/pkg/ride/mockstate"
 	"github.com/wavesplatform/gowaves/pkg/util/common"
 )
 
@@ -18,7 +25,7 @@ func createScriptsStorageTestObjects() (*scriptsStorageTestObjects, []string, er
 	if err != nil {
 		return nil, path, err
 	}
-	scriptsStorage, err := newScriptsStorage(stor.hs)
+	scriptsStorage, err := newScriptsStorage(stor.hs, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -50,7 +57,7 @@ func TestSetAccountScript(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err := to.scriptsStorage.newestScriptByAddr(addr, true)
 	assert.NoError(t, err, "newestScriptByAddr() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable before flushing.
 	accountHasScript, err = to.scriptsStorage.accountHasScript(addr, true)
@@ -73,7 +80,7 @@ func TestSetAccountScript(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err = to.scriptsStorage.newestScriptByAddr(addr, true)
 	assert.NoError(t, err, "newestScriptByAddr() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable after flushing.
 	accountHasScript, err = to.scriptsStorage.accountHasScript(addr, true)
@@ -84,7 +91,7 @@ func TestSetAccountScript(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err = to.scriptsStorage.scriptByAddr(addr, true)
 	assert.NoError(t, err, "scriptByAddr() failed after flushing")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test discarding script.
 	err = to.scriptsStorage.setAccountScript(addr, proto.Script{}, blockID0)
@@ -109,7 +116,7 @@ func TestSetAccountScript(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err = to.scriptsStorage.scriptByAddr(addr, true)
 	assert.NoError(t, err)
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	to.stor.flush(t)
 
@@ -156,7 +163,7 @@ func TestSetAssetScript(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err := to.scriptsStorage.newestScriptByAsset(assetID, true)
 	assert.NoError(t, err, "newestScriptByAsset() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable before flushing.
 	isSmartAsset, err = to.scriptsStorage.isSmartAsset(assetID, true)
@@ -173,7 +180,7 @@ func TestSetAssetScript(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.scriptsStorage.newestScriptByAsset(assetID, true)
 	assert.NoError(t, err, "newestScriptByAsset() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable after flushing.
 	isSmartAsset, err = to.scriptsStorage.isSmartAsset(assetID, true)
@@ -181,7 +188,7 @@ func TestSetAssetScript(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.scriptsStorage.scriptByAsset(assetID, true)
 	assert.NoError(t, err, "scriptByAsset() failed after flushing")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test discarding script.
 	err = to.scriptsStorage.setAssetScript(assetID, proto.Script{}, blockID0)
@@ -200,7 +207,7 @@ func TestSetAssetScript(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.scriptsStorage.scriptByAsset(assetID, true)
 	assert.NoError(t, err)
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	to.stor.flush(t)
 
@@ -218,3 +225,338 @@ func TestSetAssetScript(t *testing.T) {
 	_, err = to.scriptsStorage.scriptByAsset(assetID, true)
 	assert.Error(t, err)
 }
+
+// The script that fails to compile is cached as is and evaluated by walking the tree.
+func TestCacheScriptWithoutCompilation(t *testing.T) {
+	to, path, err := createScriptsStorageTestObjects()
+	assert.NoError(t, err, "createScriptsStorageTestObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	dir, err := getLocalDir()
+	require.NoError(t, err, "getLocalDir() failed")
+	scriptBase64, err := ioutil.ReadFile(filepath.Join(dir, "testdata", "scripts", "dapp.base64"))
+	require.NoError(t, err, "ReadFile() failed")
+	scriptBytes, err := reader.ScriptBytesFromBase64(scriptBase64)
+	require.NoError(t, err, "ScriptBytesFromBase64() failed")
+	script, err := scriptBytesToAst(scriptBytes)
+	require.NoError(t, err)
+	// Expressions among global declarations are evaluated and ignored by tree walking, but can't be compiled.
+	script.DApp.Declarations = append(script.DApp.Declarations, ast.NewLong(1))
+	broken := script
+	require.Error(t, broken.Compile())
+
+	key := accountScriptKey{testGlobal.recipientInfo.addr}
+	cached := to.scriptsStorage.cacheScript(key.bytes(), script)
+	fromCache, ok := to.scriptsStorage.cache.get(key.bytes())
+	require.True(t, ok)
+	assertScriptsEqual(t, cached, fromCache)
+
+	lastBlock := ast.NewObjectFromBlockInfo(proto.BlockInfo{Height: 10})
+	dApp := ast.NewAddressFromProtoAddress(testGlobal.recipientInfo.addr)
+	st := compiledScriptsTestStates()[0]
+	for _, tx := range compiledScriptsTestTransactions(t) {
+		invoke, ok := tx.(*proto.InvokeScriptWithProofs)
+		if !ok {
+			continue
+		}
+		expectedRes, expectedErr := script.CallFunction(proto.MainNetScheme, st, invoke, dApp, lastBlock)
+		actualRes, actualErr := fromCache.CallFunction(proto.MainNetScheme, st, invoke, dApp, lastBlock)
+		assert.Equal(t, expectedRes, actualRes)
+		assert.Equal(t, errorMessage(expectedErr), errorMessage(actualErr))
+	}
+	invoke := compiledScriptsTestTransactions(t)[6].(*proto.InvokeScriptWithProofs)
+	require.Equal(t, "deposit", invoke.FunctionCall.Name)
+	_, err = fromCache.CallFunction(proto.MainNetScheme, st, invoke, dApp, lastBlock)
+	assert.NoError(t, err)
+}
+
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func compiledScriptsTestStates() []mockstate.State {
+	sender, dApp := testGlobal.senderInfo, testGlobal.recipientInfo
+	empty := mockstate.New()
+	empty.NewestHeightVal = 10
+	started := mockstate.New()
+	started.NewestHeightVal = 10
+	acc := started.Account(sender.addr)
+	acc.DataEntries["gameState"] = &proto.IntegerDataEntry{Key: "gameState", Value: 1}
+	acc.DataEntries["player1"] = &proto.StringDataEntry{Key: "player1", Value: base58.Encode(sender.pk[:])}
+	started.Account(dApp.addr).DataEntries[sender.addr.String()] = &proto.IntegerDataEntry{Key: sender.addr.String(), Value: 100}
+	ended := mockstate.New()
+	ended.NewestHeightVal = 10
+	ended.Account(sender.addr).DataEntries["gameState"] = &proto.IntegerDataEntry{Key: "gameState", Value: 6}
+	return []mockstate.State{empty, started, ended}
+}
+
+func compiledScriptsTestTransactions(t *testing.T) []proto.Transaction {
+	sender, dApp := testGlobal.senderInfo, testGlobal.recipientInfo
+	transfer := proto.NewUnsignedTransferWithProofs(2, sender.pk, proto.OptionalAsset{}, proto.OptionalAsset{}, 1600000000000, 100, 100000, proto.NewRecipientFromAddress(dApp.addr), &proto.LegacyAttachment{})
+	require.NoError(t, transfer.Sign(proto.MainNetScheme, sender.sk))
+	txs := []proto.Transaction{transfer}
+
+	data := [][]proto.DataEntry{
+		nil,
+		{&proto.IntegerDataEntry{Key: "command", Value: 0}, &proto.IntegerDataEntry{Key: "gameState", Value: 0}},
+		{&proto.IntegerDataEntry{Key: "command", Value: 1}, &proto.IntegerDataEntry{Key: "gameState", Value: 2}, &proto.StringDataEntry{Key: "player1", Value: ""}, &proto.StringDataEntry{Key: "player2", Value: ""}},
+		{&proto.IntegerDataEntry{Key: "command", Value: 2}, &proto.IntegerDataEntry{Key: "gameState", Value: 2}, &proto.StringDataEntry{Key: "player1", Value: base58.Encode(sender.pk[:])}},
+		{&proto.IntegerDataEntry{Key: "command", Value: 7}, &proto.StringDataEntry{Key: "gameState", Value: "ended"}},
+	}
+	for _, entries := range data {
+		tx := proto.NewUnsignedData(1, sender.pk, 100000, 1600000000000)
+		for _, e := range entries {
+			require.NoError(t, tx.AppendEntry(e))
+		}
+		require.NoError(t, tx.Sign(proto.MainNetScheme, sender.sk))
+		body, err := tx.BodyMarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, tx.Proofs.Sign(1, dApp.sk, body))
+		txs = append(txs, tx)
+	}
+
+	waves := proto.ScriptPayments{{Amount: 50}}
+	asset := proto.ScriptPayments{{Amount: 50, Asset: *testGlobal.asset0.asset}}
+	calls := []struct {
+		fc       proto.FunctionCall
+		payments proto.ScriptPayments
+	}{
+		{proto.FunctionCall{Name: "deposit"}, waves},
+		{proto.FunctionCall{Name: "deposit"}, asset},
+		{proto.FunctionCall{Name: "deposit"}, nil},
+		{proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{proto.NewIntegerArgument(10)}}, nil},
+		{proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{proto.NewIntegerArgument(-1)}}, nil},
+		{proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{proto.NewIntegerArgument(1000)}}, nil},
+		{proto.FunctionCall{Name: "withdraw", Arguments: proto.Arguments{proto.NewStringArgument("10")}}, nil},
+		{proto.FunctionCall{Name: "tellme", Arguments: proto.Arguments{proto.NewStringArgument("abc")}}, nil},
+		{proto.FunctionCall{Name: "tellme", Arguments: proto.Arguments{proto.NewIntegerArgument(1)}}, nil},
+		{proto.FunctionCall{Name: "tellme"}, nil},
+		{proto.FunctionCall{Name: "compute", Arguments: proto.Arguments{proto.NewIntegerArgument(10)}}, nil},
+		{proto.FunctionCall{Name: "compute", Arguments: proto.Arguments{proto.NewIntegerArgument(1000)}}, nil},
+		{proto.FunctionCall{Name: "compute", Arguments: proto.Arguments{proto.NewStringArgument("10")}}, nil},
+		{proto.FunctionCall{Name: "nested", Arguments: proto.Arguments{proto.NewIntegerArgument(5)}}, nil},
+		{proto.FunctionCall{Name: "nested", Arguments: proto.Arguments{proto.NewIntegerArgument(-5)}}, nil},
+		{proto.FunctionCall{Name: "fail"}, nil},
+		{proto.FunctionCall{Default: true}, nil},
+	}
+	for _, c := range calls {
+		tx := proto.NewUnsignedInvokeScriptWithProofs(1, proto.MainNetScheme, sender.pk, proto.NewRecipientFromAddress(dApp.addr), c.fc, c.payments, proto.OptionalAsset{}, 500000, 1600000000000)
+		require.NoError(t, tx.Sign(proto.MainNetScheme, sender.sk))
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func userCall(name string, args ...ast.Expr) ast.Expr {
+	return ast.NewFuncCall(ast.NewFunctionCall(name, ast.NewExprs(args...)))
+}
+
+func writeSetOf(key string, value ast.Expr) ast.Expr {
+	return userCall("WriteSet", ast.NewFuncCall(ast.NewFunctionCall("1100", ast.NewExprs(userCall("DataEntry", ast.NewString(key), value), &ast.RefExpr{Name: "nil"}))))
+}
+
+// addUserFunctionCallables adds the global user functions and the callables using them to the dApp:
+//
+//	let limit = 100
+//	func double(x: Int) = x + x
+//	func check(x: Int) = if (x > limit) then throw("too big") else x
+//
+//	@Callable(i)
+//	func compute(n: Int) = {
+//	    let d = double(n)
+//	    WriteSet([DataEntry("d", check(d))])
+//	}
+//
+//	@Callable(i)
+//	func nested(n: Int) = {
+//	    func inc(x: Int) = if (x < 0) then throw() else x + 1
+//	    WriteSet([DataEntry("n", inc(inc(n)))])
+//	}
+//
+//	@Callable(i)
+//	func fail() = throw("failed")
+func addUserFunctionCallables(script *ast.Script) {
+	ref := func(name string) ast.Expr { return &ast.RefExpr{Name: name} }
+	native := func(id string, args ...ast.Expr) ast.Expr {
+		return ast.NewFuncCall(ast.NewFunctionCall(id, ast.NewExprs(args...)))
+	}
+	script.DApp.Declarations = append(script.DApp.Declarations,
+		ast.NewLet("limit", ast.NewLong(100)),
+		&ast.FuncDeclaration{Name: "double", Args: []string{"x"}, Body: native("100", ref("x"), ref("x"))},
+		&ast.FuncDeclaration{Name: "check", Args: []string{"x"}, Body: ast.NewIf(native("102", ref("x"), ref("limit")), native("2", ast.NewString("too big")), ref("x"))},
+	)
+	callable := func(name string, args []string, body ast.Expr) {
+		script.DApp.CallableFuncs[name] = &ast.DappCallableFunc{AnnotationInvokeName: "i", FuncDecl: &ast.FuncDeclaration{Name: name, Args: args, Body: body}}
+	}
+	callable("compute", []string{"n"}, &ast.BlockV2{
+		Decl: ast.NewLet("d", userCall("double", ref("n"))),
+		Body: writeSetOf("d", userCall("check", ref("d"))),
+	})
+	callable("nested", []string{"n"}, &ast.BlockV2{
+		Decl: &ast.FuncDeclaration{Name: "inc", Args: []string{"x"}, Body: ast.NewIf(native("102", ast.NewLong(0), ref("x")), userCall("throw"), native("100", ref("x"), ast.NewLong(1)))},
+		Body: writeSetOf("n", userCall("inc", userCall("inc", ref("n")))),
+	})
+	callable("fail", nil, native("2", ast.NewString("failed")))
+}
+
+type compiledTestScript struct {
+	bytes  []byte
+	extend func(*ast.Script)
+}
+
+func (s compiledTestScript) build() (ast.Script, error) {
+	script, err := scriptBytesToAst(s.bytes)
+	if err != nil {
+		return ast.Script{}, err
+	}
+	if s.extend != nil {
+		s.extend(&script)
+	}
+	return script, nil
+}
+
+// Compiled scripts must give the same results and errors as the scripts evaluated by walking the tree.
+func TestCompiledScriptsMatchTreeWalking(t *testing.T) {
+	dir, err := getLocalDir()
+	require.NoError(t, err, "getLocalDir() failed")
+	paths, err := filepath.Glob(filepath.Join(dir, "testdata", "scripts", "*.base64"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	scripts := map[string]compiledTestScript{"multisig": {bytes: testGlobal.scriptBytes}}
+	for _, path := range paths {
+		scriptBase64, err := ioutil.ReadFile(path)
+		require.NoError(t, err, "ReadFile() failed")
+		scriptBytes, err := reader.ScriptBytesFromBase64(scriptBase64)
+		require.NoError(t, err, "ScriptBytesFromBase64() failed")
+		scripts[filepath.Base(path)] = compiledTestScript{bytes: scriptBytes}
+	}
+	scripts["dapp.base64 with user functions"] = compiledTestScript{scripts["dapp.base64"].bytes, addUserFunctionCallables}
+	lastBlock := ast.NewObjectFromBlockInfo(proto.BlockInfo{Height: 10})
+	this := ast.NewAddressFromProtoAddress(testGlobal.senderInfo.addr)
+	dApp := ast.NewAddressFromProtoAddress(testGlobal.recipientInfo.addr)
+	txs := compiledScriptsTestTransactions(t)
+	for name, script := range scripts {
+		tree, err := script.build()
+		require.NoError(t, err, name)
+		compiled, err := script.build()
+		require.NoError(t, err, name)
+		require.NoError(t, compiled.Compile(), name)
+		for i, st := range compiledScriptsTestStates() {
+			for j, tx := range txs {
+				obj, err := ast.NewVariablesFromTransaction(proto.MainNetScheme, tx)
+				require.NoError(t, err)
+				expected, expectedErr := tree.Verify(proto.MainNetScheme, st, obj, this, lastBlock)
+				actual, actualErr := compiled.Verify(proto.MainNetScheme, st, obj, this, lastBlock)
+				assert.Equal(t, expected, actual, "%s: verify tx %d in state %d", name, j, i)
+				assert.Equal(t, errorMessage(expectedErr), errorMessage(actualErr), "%s: verify tx %d in state %d", name, j, i)
+
+				invoke, ok := tx.(*proto.InvokeScriptWithProofs)
+				if !ok || !tree.IsDapp() {
+					continue
+				}
+				expectedRes, expectedErr := tree.CallFunction(proto.MainNetScheme, st, invoke, dApp, lastBlock)
+				actualRes, actualErr := compiled.CallFunction(proto.MainNetScheme, st, invoke, dApp, lastBlock)
+				assert.Equal(t, expectedRes, actualRes, "%s: call tx %d in state %d", name, j, i)
+				assert.Equal(t, errorMessage(expectedErr), errorMessage(actualErr), "%s: call tx %d in state %d", name, j, i)
+			}
+		}
+	}
+}
+
+func TestScriptHistory(t *testing.T) {
+	to, path, err := createScriptsStorageTestObjects()
+	assert.NoError(t, err, "createScriptsStorageTestObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	addr := testGlobal.senderInfo.addr
+	_, err = to.scriptsStorage.scriptHistoryByAddr(addr, 0, 0, true)
+	assert.Error(t, err)
+
+	to.stor.addBlock(t, blockID0)
+	err = to.scriptsStorage.setAccountScript(addr, proto.Script(testGlobal.scriptBytes), blockID0)
+	assert.NoError(t, err, "setAccountScript() failed")
+	to.stor.flush(t)
+	to.stor.addBlock(t, blockID1)
+	err = to.scriptsStorage.setAccountScript(addr, proto.Script{}, blockID1)
+	assert.NoError(t, err, "setAccountScript() failed")
+	to.stor.flush(t)
+
+	history, err := to.scriptsStorage.scriptHistoryByAddr(addr, 0, 0, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	require.Len(t, history, 2)
+	assert.Equal(t, proto.Script(testGlobal.scriptBytes), history[0].script)
+	assert.Equal(t, blockID0, history[0].blockID)
+	assert.Empty(t, history[1].script)
+	assert.Equal(t, blockID1, history[1].blockID)
+}
+
+func TestScriptHistoryPages(t *testing.T) {
+	to, path, err := createScriptsStorageTestObjects()
+	assert.NoError(t, err, "createScriptsStorageTestObjects() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	addr := testGlobal.senderInfo.addr
+	ids := make([]proto.BlockID, 5)
+	for i := range ids {
+		ids[i] = genBlockId(byte(i + 1))
+		to.stor.addBlock(t, ids[i])
+		script := proto.Script(testGlobal.scriptBytes)
+		if i%2 == 1 {
+			script = proto.Script{}
+		}
+		err = to.scriptsStorage.setAccountScript(addr, script, ids[i])
+		assert.NoError(t, err, "setAccountScript() failed")
+	}
+	to.stor.flush(t)
+	heightOf := func(id proto.BlockID) uint64 {
+		h, err := to.stor.rw.heightByBlockID(id)
+		require.NoError(t, err)
+		return h
+	}
+	blocks := func(history []scriptHistoryRecord) []proto.BlockID {
+		r := make([]proto.BlockID, len(history))
+		for i, h := range history {
+			r[i] = h.blockID
+		}
+		return r
+	}
+
+	history, err := to.scriptsStorage.scriptHistoryByAddr(addr, 0, 0, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	assert.Equal(t, ids, blocks(history))
+	history, err = to.scriptsStorage.scriptHistoryByAddr(addr, 0, 2, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	assert.Equal(t, ids[:2], blocks(history))
+	assert.Equal(t, proto.Script(testGlobal.scriptBytes), history[0].script)
+	assert.Empty(t, history[1].script)
+	history, err = to.scriptsStorage.scriptHistoryByAddr(addr, heightOf(ids[1]), 2, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	assert.Equal(t, ids[2:4], blocks(history))
+	history, err = to.scriptsStorage.scriptHistoryByAddr(addr, heightOf(ids[3]), 2, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	assert.Equal(t, ids[4:], blocks(history))
+	history, err = to.scriptsStorage.scriptHistoryByAddr(addr, heightOf(ids[4]), 2, true)
+	assert.NoError(t, err, "scriptHistoryByAddr() failed")
+	assert.Empty(t, history)
+}
@@ -6,12 +6,14 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 )
 
 const (
-	sponsorshipRecordSize = 8
+	sponsorshipRecordSize       = 8
+	sponsoredTxsCountRecordSize = 8
 )
 
 type sponsorshipRecord struct {
@@ -33,11 +35,38 @@ func (s *sponsorshipRecord) unmarshalBinary(data []byte) error {
 	return nil
 }
 
+// sponsoredTxsCountRecord is the number of transactions which fee was paid in the sponsored asset.
+type sponsoredTxsCountRecord struct {
+	count uint64
+}
+
+func (r *sponsoredTxsCountRecord) marshalBinary() ([]byte, error) {
+	res := make([]byte, sponsoredTxsCountRecordSize)
+	binary.BigEndian.PutUint64(res[:8], r.count)
+	return res, nil
+}
+
+func (r *sponsoredTxsCountRecord) unmarshalBinary(data []byte) error {
+	if len(data) != sponsoredTxsCountRecordSize {
+		return errInvalidDataSize
+	}
+	r.count = binary.BigEndian.Uint64(data[:8])
+	return nil
+}
+
+type sponsorshipHistoryRecord struct {
+	assetCost uint64
+	blockID   proto.BlockID
+}
+
 type sponsoredAssets struct {
 	rw       *blockReadWriter
 	features *features
 	hs       *historyStorage
 	settings *settings.BlockchainSettings
+
+	calculateHashes bool
+	hasher          *stateHasher
 }
 
 func newSponsoredAssets(
@@ -45,8 +74,16 @@ func newSponsoredAssets(
 	features *features,
 	hs *historyStorage,
 	settings *settings.BlockchainSettings,
+	calcHashes bool,
 ) (*sponsoredAssets, error) {
-	return &sponsoredAssets{rw, features, hs, settings}, nil
+	return &sponsoredAssets{
+		rw:              rw,
+		features:        features,
+		hs:              hs,
+		settings:        settings,
+		calculateHashes: calcHashes,
+		hasher:          newStateHasher(),
+	}, nil
 }
 
 func (s *sponsoredAssets) sponsorAsset(assetID crypto.Digest, assetCost uint64, blockID proto.BlockID) error {
@@ -59,6 +96,9 @@ func (s *sponsoredAssets) sponsorAsset(assetID crypto.Digest, assetCost uint64,
 	if err := s.hs.addNewEntry(sponsorship, key.bytes(), recordBytes, blockID); err != nil {
 		return err
 	}
+	if s.calculateHashes {
+		s.hasher.push(assetID.Bytes(), recordBytes, blockID)
+	}
 	return nil
 }
 
@@ -122,11 +162,81 @@ func (s *sponsoredAssets) assetCost(assetID crypto.Digest, filter bool) (uint64,
 	return record.assetCost, nil
 }
 
+// sponsorshipHistory returns all the asset costs ever set for the asset, from the first to the current one.
+func (s *sponsoredAssets) sponsorshipHistory(assetID crypto.Digest, filter bool) ([]sponsorshipHistoryRecord, error) {
+	key := sponsorshipKey{assetID}
+	entries, err := s.hs.entriesDataWithBlocks(key.bytes(), filter)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]sponsorshipHistoryRecord, len(entries))
+	for i, entry := range entries {
+		var record sponsorshipRecord
+		if err := record.unmarshalBinary(entry.data); err != nil {
+			return nil, errors.Errorf("failed to unmarshal sponsorship record: %v\n", err)
+		}
+		res[i] = sponsorshipHistoryRecord{record.assetCost, entry.blockID}
+	}
+	return res, nil
+}
+
+func (s *sponsoredAssets) newestSponsoredTxsCount(assetID crypto.Digest, filter bool) (uint64, error) {
+	key := sponsoredTxsCountKey{assetID}
+	recordBytes, err := s.hs.freshLatestEntryData(key.bytes(), filter)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var record sponsoredTxsCountRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return 0, errors.Errorf("failed to unmarshal sponsored transactions count record: %v\n", err)
+	}
+	return record.count, nil
+}
+
+func (s *sponsoredAssets) sponsoredTxsCount(assetID crypto.Digest, filter bool) (uint64, error) {
+	key := sponsoredTxsCountKey{assetID}
+	recordBytes, err := s.hs.latestEntryData(key.bytes(), filter)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var record sponsoredTxsCountRecord
+	if err := record.unmarshalBinary(recordBytes); err != nil {
+		return 0, errors.Errorf("failed to unmarshal sponsored transactions count record: %v\n", err)
+	}
+	return record.count, nil
+}
+
+// countSponsoredTxs adds the number of transactions of the block which fees were paid in the sponsored asset.
+func (s *sponsoredAssets) countSponsoredTxs(assetID crypto.Digest, txs uint64, blockID proto.BlockID, filter bool) error {
+	count, err := s.newestSponsoredTxsCount(assetID, filter)
+	if err != nil {
+		return err
+	}
+	record := &sponsoredTxsCountRecord{count + txs}
+	recordBytes, err := record.marshalBinary()
+	if err != nil {
+		return err
+	}
+	key := sponsoredTxsCountKey{assetID}
+	return s.hs.addNewEntry(sponsoredTxsCount, key.bytes(), recordBytes, blockID)
+}
+
 func (s *sponsoredAssets) sponsoredAssetToWaves(assetID crypto.Digest, assetAmount uint64) (uint64, error) {
 	cost, err := s.newestAssetCost(assetID, true)
 	if err != nil {
 		return 0, err
 	}
+	return SponsoredAssetToWaves(cost, assetAmount)
+}
+
+// SponsoredAssetToWaves converts the fee in the asset which cost is the minimal sponsored fee to the fee in Waves.
+func SponsoredAssetToWaves(cost, assetAmount uint64) (uint64, error) {
 	if cost == 0 {
 		return 0, errors.New("0 asset cost")
 	}
@@ -149,6 +259,11 @@ func (s *sponsoredAssets) wavesToSponsoredAsset(assetID crypto.Digest, wavesAmou
 	if err != nil {
 		return 0, err
 	}
+	return WavesToSponsoredAsset(cost, wavesAmount)
+}
+
+// WavesToSponsoredAsset converts the fee in Waves to the minimal fee in the asset which cost is the minimal sponsored fee.
+func WavesToSponsoredAsset(cost, wavesAmount uint64) (uint64, error) {
 	if cost == 0 || wavesAmount == 0 {
 		return 0, nil
 	}
@@ -166,6 +281,13 @@ func (s *sponsoredAssets) wavesToSponsoredAsset(assetID crypto.Digest, wavesAmou
 	return assetAmount.Uint64(), nil
 }
 
+// sponsorCanCoverFees tells if the sponsor is able to pay the fee of the minimal sponsored transaction.
+// The fee in the asset is converted to Waves by the rate where the minimal fee in the asset costs FeeUnit,
+// so the sponsor needs FeeUnit of Waves whatever the minimal fee in the asset is.
+func sponsorCanCoverFees(sponsorBalance uint64) bool {
+	return sponsorBalance >= FeeUnit
+}
+
 func (s *sponsoredAssets) isSponsorshipActivated() (bool, error) {
 	featureActivated, err := s.features.isActivated(int16(settings.FeeSponsorship))
 	if err != nil {
@@ -184,3 +306,7 @@ func (s *sponsoredAssets) isSponsorshipActivated() (bool, error) {
 	sponsorshipTrueActivationHeight := height + s.settings.ActivationWindowSize(height)
 	return curHeight >= sponsorshipTrueActivationHeight, nil
 }
+
+func (s *sponsoredAssets) resetHashes() {
+	s.hasher.reset()
+}
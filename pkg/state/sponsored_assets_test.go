@@ -23,7 +23,7 @@ func createSponsoredAssets() (*sponsoredAssetsTestObjects, []string, error) {
 	if err != nil {
 		return nil, path, err
 	}
-	sponsoredAssets, err := newSponsoredAssets(stor.rw, features, stor.hs, settings.MainNetSettings)
+	sponsoredAssets, err := newSponsoredAssets(stor.rw, features, stor.hs, settings.MainNetSettings, false)
 	if err != nil {
 		return nil, path, err
 	}
@@ -83,6 +83,75 @@ func TestSponsorAsset(t *testing.T) {
 	assert.Equal(t, isSponsored, false)
 }
 
+func TestSponsorshipHistory(t *testing.T) {
+	to, path, err := createSponsoredAssets()
+	assert.NoError(t, err, "createSponsoredAssets() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	id := testGlobal.asset0.asset.ID
+	to.stor.addBlock(t, blockID0)
+	err = to.sponsoredAssets.sponsorAsset(id, 100, blockID0)
+	assert.NoError(t, err, "sponsorAsset() failed")
+	to.stor.flush(t)
+	to.stor.addBlock(t, blockID1)
+	err = to.sponsoredAssets.sponsorAsset(id, 0, blockID1)
+	assert.NoError(t, err, "sponsorAsset() failed")
+	to.stor.flush(t)
+	history, err := to.sponsoredAssets.sponsorshipHistory(id, true)
+	assert.NoError(t, err, "sponsorshipHistory() failed")
+	assert.Equal(t, []sponsorshipHistoryRecord{{100, blockID0}, {0, blockID1}}, history)
+
+	err = to.stor.stateDB.rollbackBlock(blockID1)
+	assert.NoError(t, err, "rollbackBlock() failed")
+	history, err = to.sponsoredAssets.sponsorshipHistory(id, true)
+	assert.NoError(t, err, "sponsorshipHistory() failed")
+	assert.Equal(t, []sponsorshipHistoryRecord{{100, blockID0}}, history)
+}
+
+func TestCountSponsoredTx(t *testing.T) {
+	to, path, err := createSponsoredAssets()
+	assert.NoError(t, err, "createSponsoredAssets() failed")
+
+	defer func() {
+		to.stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	id := testGlobal.asset0.asset.ID
+	count, err := to.sponsoredAssets.sponsoredTxsCount(id, true)
+	assert.NoError(t, err, "sponsoredTxsCount() failed")
+	assert.Equal(t, uint64(0), count)
+	to.stor.addBlock(t, blockID0)
+	err = to.sponsoredAssets.countSponsoredTxs(id, 2, blockID0, true)
+	assert.NoError(t, err, "countSponsoredTxs() failed")
+	count, err = to.sponsoredAssets.newestSponsoredTxsCount(id, true)
+	assert.NoError(t, err, "newestSponsoredTxsCount() failed")
+	assert.Equal(t, uint64(2), count)
+	to.stor.flush(t)
+	to.stor.addBlock(t, blockID1)
+	err = to.sponsoredAssets.countSponsoredTxs(id, 3, blockID1, true)
+	assert.NoError(t, err, "countSponsoredTxs() failed")
+	to.stor.flush(t)
+	count, err = to.sponsoredAssets.sponsoredTxsCount(id, true)
+	assert.NoError(t, err, "sponsoredTxsCount() failed")
+	assert.Equal(t, uint64(5), count)
+
+	// Transactions of removed blocks are not counted.
+	err = to.stor.stateDB.rollbackBlock(blockID1)
+	assert.NoError(t, err, "rollbackBlock() failed")
+	count, err = to.sponsoredAssets.sponsoredTxsCount(id, true)
+	assert.NoError(t, err, "sponsoredTxsCount() failed")
+	assert.Equal(t, uint64(2), count)
+}
+
 func TestSponsoredAssetToWaves(t *testing.T) {
 	to, path, err := createSponsoredAssets()
 	assert.NoError(t, err, "createSponsoredAssets() failed")
@@ -157,3 +226,23 @@ func TestIsSponsorshipActivated(t *testing.T) {
 	assert.NoError(t, err, "isSponsorshipActivated() failed")
 	assert.Equal(t, true, isSponsorshipActivated)
 }
+
+func TestSponsorCanCoverFees(t *testing.T) {
+	for _, tc := range []struct {
+		minAssetFee uint64
+		balance     uint64
+		canCover    bool
+	}{
+		// Balance is above the minimal fee in the asset but below its cost in Waves.
+		{1000, 50000, false},
+		{1000, FeeUnit, true},
+		// Balance is below the minimal fee in the asset but enough to pay its cost in Waves.
+		{10000000, 200000, true},
+		{10000000, FeeUnit - 1, false},
+	} {
+		wavesFee, err := SponsoredAssetToWaves(tc.minAssetFee, tc.minAssetFee)
+		assert.NoError(t, err, "SponsoredAssetToWaves() failed")
+		assert.Equal(t, uint64(FeeUnit), wavesFee)
+		assert.Equal(t, tc.canCover, sponsorCanCoverFees(tc.balance))
+	}
+}
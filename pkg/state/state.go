@@ -1,8 +1,10 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -15,6 +17,9 @@ import (
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/decompiler"
+	"github.com/wavesplatform/gowaves/pkg/ride/evaluator/reader"
 	"github.com/wavesplatform/gowaves/pkg/settings"
 	"github.com/wavesplatform/gowaves/pkg/util/lock"
 	"go.uber.org/zap"
@@ -24,6 +29,7 @@ const (
 	rollbackMaxBlocks = 2000
 	blocksStorDir     = "blocks_storage"
 	keyvalueDir       = "key_value"
+	bloomFilterFile   = "bloom"
 
 	maxScriptsRunsInBlock       = 101
 	maxScriptsComplexityInBlock = 1000000
@@ -56,10 +62,13 @@ type blockchainEntitiesStorage struct {
 	scriptsStorage    *scriptsStorage
 	scriptsComplexity *scriptsComplexity
 	invokeResults     *invokeResults
+	stateHashes       *stateHashes
+
+	calculateHashes bool
 }
 
-func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainSettings, rw *blockReadWriter) (*blockchainEntitiesStorage, error) {
-	aliases, err := newAliases(hs.db, hs.dbBatch, hs)
+func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainSettings, rw *blockReadWriter, calcHashes bool) (*blockchainEntitiesStorage, error) {
+	aliases, err := newAliases(hs.db, hs.dbBatch, hs, calcHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +76,7 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 	if err != nil {
 		return nil, err
 	}
-	leases, err := newLeases(hs.db, hs)
+	leases, err := newLeases(hs.db, hs, calcHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +88,7 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 	if err != nil {
 		return nil, err
 	}
-	balances, err := newBalances(hs.db, hs)
+	balances, err := newBalances(hs.db, hs, calcHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +100,7 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 	if err != nil {
 		return nil, err
 	}
-	accountsDataStor, err := newAccountsDataStorage(hs.db, hs.dbBatch, hs)
+	accountsDataStor, err := newAccountsDataStorage(hs.db, hs.dbBatch, hs, calcHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -99,11 +108,11 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 	if err != nil {
 		return nil, err
 	}
-	sponsoredAssets, err := newSponsoredAssets(rw, features, hs, sets)
+	sponsoredAssets, err := newSponsoredAssets(rw, features, hs, sets, calcHashes)
 	if err != nil {
 		return nil, err
 	}
-	scriptsStorage, err := newScriptsStorage(hs)
+	scriptsStorage, err := newScriptsStorage(hs, calcHashes)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +124,10 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 	if err != nil {
 		return nil, err
 	}
+	stateHashes, err := newStateHashes(hs)
+	if err != nil {
+		return nil, err
+	}
 	return &blockchainEntitiesStorage{
 		hs,
 		aliases,
@@ -131,9 +144,56 @@ func newBlockchainEntitiesStorage(hs *historyStorage, sets *settings.BlockchainS
 		scriptsStorage,
 		scriptsComplexity,
 		invokeResults,
+		stateHashes,
+		calcHashes,
 	}, nil
 }
 
+// putStateHash calculates the state hash of the block from the changes collected by storages and saves it.
+func (s *blockchainEntitiesStorage) putStateHash(prevHash []byte, height uint64, blockID proto.BlockID) (*proto.StateHash, error) {
+	sh := &proto.StateHash{BlockID: blockID}
+	hashers := []struct {
+		hasher *stateHasher
+		dst    *crypto.Digest
+	}{
+		{s.balances.wavesHasher, &sh.WavesBalanceHash},
+		{s.balances.assetsHasher, &sh.AssetBalanceHash},
+		{s.accountsDataStor.hasher, &sh.DataEntryHash},
+		{s.scriptsStorage.accountScriptHasher, &sh.AccountScriptHash},
+		{s.scriptsStorage.assetScriptHasher, &sh.AssetScriptHash},
+		{s.balances.leaseHasher, &sh.LeaseBalanceHash},
+		{s.leases.hasher, &sh.LeaseStatusHash},
+		{s.sponsoredAssets.hasher, &sh.SponsorshipHash},
+		{s.aliases.hasher, &sh.AliasesHash},
+	}
+	for _, h := range hashers {
+		d, err := h.hasher.stateHashAt(blockID)
+		if err != nil {
+			return nil, err
+		}
+		*h.dst = d
+	}
+	if err := sh.GenerateSumHash(prevHash); err != nil {
+		return nil, err
+	}
+	if err := s.stateHashes.saveStateHash(sh, height); err != nil {
+		return nil, err
+	}
+	return sh, nil
+}
+
+// resetHashes drops the changes collected for state hashes.
+// Unlike reset(), it is not called on every flush, because the changes of the last block
+// are needed after the flush if the block is amended by breaker task (see cancelLeases()).
+func (s *blockchainEntitiesStorage) resetHashes() {
+	s.balances.resetHashes()
+	s.accountsDataStor.resetHashes()
+	s.scriptsStorage.resetHashes()
+	s.leases.resetHashes()
+	s.sponsoredAssets.resetHashes()
+	s.aliases.resetHashes()
+}
+
 func (s *blockchainEntitiesStorage) reset() {
 	s.hs.reset()
 	s.assets.reset()
@@ -150,7 +210,7 @@ func (s *blockchainEntitiesStorage) flush(initialisation bool) error {
 	return nil
 }
 
-func checkCompatibility(stateDB *stateDB, extendedApi bool) error {
+func checkCompatibility(stateDB *stateDB, extendedApi, stateHashes bool) error {
 	version, err := stateDB.stateVersion()
 	if err != nil {
 		return errors.Errorf("stateVersion: %v", err)
@@ -165,6 +225,13 @@ func checkCompatibility(stateDB *stateDB, extendedApi bool) error {
 	if extendedApi != hasDataForExtendedApi {
 		return errors.Errorf("extended API incompatibility: state stores: %v; want: %v", hasDataForExtendedApi, extendedApi)
 	}
+	hasStateHashes, err := stateDB.stateStoresHashes()
+	if err != nil {
+		return errors.Errorf("stateStoresHashes(): %v", err)
+	}
+	if stateHashes != hasStateHashes {
+		return errors.Errorf("state hashes incompatibility: state stores: %v; want: %v", hasStateHashes, stateHashes)
+	}
 	return nil
 }
 
@@ -208,6 +275,12 @@ func newStateManager(dataDir string, params StateParams, settings *settings.Bloc
 		}
 	}
 	blockStorageDir := filepath.Join(dataDir, blocksStorDir)
+	if !params.DbParams.Backend.Persistent() {
+		// Blocks left from the previous run would not match the empty database.
+		if files, err := ioutil.ReadDir(blockStorageDir); err == nil && len(files) > 0 {
+			return nil, wrapErr(Other, errors.Errorf("state directory '%s' must be empty for '%s' database backend", dataDir, params.DbParams.Backend))
+		}
+	}
 	if _, err := os.Stat(blockStorageDir); os.IsNotExist(err) {
 		if err := os.Mkdir(blockStorageDir, 0755); err != nil {
 			return nil, wrapErr(Other, errors.Errorf("failed to create blocks directory: %v", err))
@@ -216,8 +289,8 @@ func newStateManager(dataDir string, params StateParams, settings *settings.Bloc
 	// Initialize database.
 	dbDir := filepath.Join(dataDir, keyvalueDir)
 	zap.S().Info("Initializing state database, will take up to few minutes...")
-	params.DbParams.BloomFilterParams.Store.WithPath(filepath.Join(blockStorageDir, "bloom"))
-	db, err := keyvalue.NewKeyVal(dbDir, params.DbParams)
+	params.DbParams.BloomFilterParams.Store.WithPath(filepath.Join(blockStorageDir, bloomFilterFile))
+	db, err := keyvalue.NewIterableKeyVal(dbDir, params.DbParams)
 	if err != nil {
 		return nil, wrapErr(Other, errors.Errorf("failed to create db: %v", err))
 	}
@@ -238,11 +311,11 @@ func newStateManager(dataDir string, params StateParams, settings *settings.Bloc
 	if err != nil {
 		return nil, wrapErr(Other, errors.Errorf("failed to create block storage: %v", err))
 	}
-	stateDB, err := newStateDB(db, dbBatch, rw, params.StoreExtendedApiData)
+	stateDB, err := newStateDB(db, dbBatch, rw, params.StoreExtendedApiData, params.BuildStateHashes)
 	if err != nil {
 		return nil, wrapErr(Other, errors.Errorf("failed to create stateDB: %v", err))
 	}
-	if err := checkCompatibility(stateDB, params.StoreExtendedApiData); err != nil {
+	if err := checkCompatibility(stateDB, params.StoreExtendedApiData, params.BuildStateHashes); err != nil {
 		return nil, wrapErr(IncompatibilityError, err)
 	}
 	if err := stateDB.syncRw(); err != nil {
@@ -252,7 +325,7 @@ func newStateManager(dataDir string, params StateParams, settings *settings.Bloc
 	if err != nil {
 		return nil, wrapErr(Other, errors.Errorf("failed to create history storage: %v", err))
 	}
-	stor, err := newBlockchainEntitiesStorage(hs, settings, rw)
+	stor, err := newBlockchainEntitiesStorage(hs, settings, rw, params.BuildStateHashes)
 	if err != nil {
 		return nil, wrapErr(Other, errors.Errorf("failed to create blockchain entities storage: %v", err))
 	}
@@ -301,6 +374,11 @@ func newStateManager(dataDir string, params StateParams, settings *settings.Bloc
 	if err := state.loadLastBlock(); err != nil {
 		return nil, wrapErr(RetrievalError, err)
 	}
+	height, err := state.Height()
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	state.appender.stats.start(height + 1)
 	return state, nil
 }
 
@@ -330,6 +408,7 @@ func (s *stateManager) addGenesisBlock() error {
 	}
 	chans := newVerifierChans()
 	go launchVerifier(ctx, chans, s.verificationGoroutinesNum, s.settings.AddressSchemeCharacter)
+	s.stor.resetHashes()
 	if err := s.addNewBlock(&s.genesis, nil, true, chans, 0); err != nil {
 		return err
 	}
@@ -337,6 +416,9 @@ func (s *stateManager) addGenesisBlock() error {
 	if err := s.appender.applyAllDiffs(true); err != nil {
 		return err
 	}
+	if err := s.saveStateHashes(1, []proto.BlockID{s.genesis.BlockID()}); err != nil {
+		return err
+	}
 	verifyError := <-chans.errChan
 	if verifyError != nil {
 		return wrapErr(ValidationError, verifyError)
@@ -998,9 +1080,17 @@ func (s *stateManager) finishVoting(blockID proto.BlockID, initialisation bool)
 		return err
 	}
 	nextBlockHeight := height + 1
+	prevEstimatorVersion, err := s.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return err
+	}
 	if err := s.stor.features.finishVoting(nextBlockHeight, blockID); err != nil {
 		return err
 	}
+	estimatorVersion, err := s.stor.features.newestEstimatorVersion()
+	if err != nil {
+		return err
+	}
 	s.lastVotingHeight = nextBlockHeight
 	// Check if protobuf is now activated.
 	// blockReadWriter will mark current offset as
@@ -1014,6 +1104,24 @@ func (s *stateManager) finishVoting(blockID proto.BlockID, initialisation bool)
 	if err := s.reset(initialisation); err != nil {
 		return err
 	}
+	// Complexities of scripts are recalculated once the new estimator activates.
+	// The recalculation reads scripts from DB, so it goes after the flush.
+	if estimatorVersion != prevEstimatorVersion {
+		return s.recalculateScriptsComplexities(estimatorVersion, blockID, initialisation)
+	}
+	return nil
+}
+
+func (s *stateManager) recalculateScriptsComplexities(estimatorVersion int, blockID proto.BlockID, initialisation bool) error {
+	if err := s.stor.scriptsComplexity.recalculate(estimatorVersion, blockID); err != nil {
+		return errors.Wrap(err, "failed to recalculate complexities of scripts")
+	}
+	if err := s.flush(initialisation); err != nil {
+		return err
+	}
+	if err := s.reset(initialisation); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -1052,7 +1160,7 @@ func (s *stateManager) cancelLeases(blockID proto.BlockID) error {
 		}
 	}
 	if height == s.settings.ResetEffectiveBalanceAtHeight {
-		if err := s.stor.leases.cancelLeases(nil, blockID); err != nil {
+		if err := s.stor.leases.cancelLeases(nil, height, blockID); err != nil {
 			return err
 		}
 		if err := s.stor.balances.cancelAllLeases(blockID); err != nil {
@@ -1064,7 +1172,7 @@ func (s *stateManager) cancelLeases(blockID proto.BlockID) error {
 		if err != nil {
 			return err
 		}
-		if err := s.stor.leases.cancelLeases(overflowAddrs, blockID); err != nil {
+		if err := s.stor.leases.cancelLeases(overflowAddrs, height, blockID); err != nil {
 			return err
 		}
 		s.leasesCl1 = true
@@ -1078,6 +1186,11 @@ func (s *stateManager) cancelLeases(blockID proto.BlockID) error {
 		}
 		s.leasesCl2 = true
 	}
+	// Cancellations are part of the changes of the block, so its state hash has to be recalculated.
+	// Changes of the block itself are still kept by storages, because hashes are not reset on flush.
+	if err := s.saveStateHashes(height, []proto.BlockID{blockID}); err != nil {
+		return err
+	}
 	if err := s.flush(true); err != nil {
 		return err
 	}
@@ -1155,6 +1268,7 @@ func (s *stateManager) addBlocks(blocks []*proto.Block, initialisation bool) (*p
 	go launchVerifier(ctx, chans, s.verificationGoroutinesNum, s.settings.AddressSchemeCharacter)
 
 	var lastBlock *proto.Block
+	ids := make([]proto.BlockID, 0, blocksNumber)
 	for i, block := range blocks {
 		curHeight := height + uint64(i)
 		breakAdding, err := s.needToBreakAddingBlocks(curHeight, breakerInfo)
@@ -1166,6 +1280,11 @@ func (s *stateManager) addBlocks(blocks []*proto.Block, initialisation bool) (*p
 			blocksToFinish = blocks[i:]
 			break
 		}
+		if i == 0 {
+			// Changes for state hashes are reset only when new blocks are actually added,
+			// because breaker task of the parent block might need them.
+			s.stor.resetHashes()
+		}
 		breakerInfo.blockID = block.BlockID()
 		// Send block for signature verification, which works in separate goroutine.
 		task := &verifyTask{
@@ -1206,6 +1325,7 @@ func (s *stateManager) addBlocks(blocks []*proto.Block, initialisation bool) (*p
 			return nil, wrapErr(TxValidationError, err)
 		}
 		headers[i] = block.BlockHeader
+		ids = append(ids, block.BlockID())
 		parent = block
 	}
 	// Tasks chan can now be closed, since all the blocks and transactions have been already sent for verification.
@@ -1215,6 +1335,10 @@ func (s *stateManager) addBlocks(blocks []*proto.Block, initialisation bool) (*p
 	if err := s.appender.applyAllDiffs(initialisation); err != nil {
 		return nil, wrapErr(TxValidationError, err)
 	}
+	// Calculate state hashes after all the changes of blocks are applied.
+	if err := s.saveStateHashes(height+1, ids); err != nil {
+		return nil, wrapErr(ModificationError, err)
+	}
 	// Validate consensus (i.e. that all of the new blocks were mined fairly).
 	if err := s.cv.ValidateHeaders(headers[:len(headers)-len(blocksToFinish)], height); err != nil {
 		return nil, wrapErr(ValidationError, err)
@@ -1228,6 +1352,7 @@ func (s *stateManager) addBlocks(blocks []*proto.Block, initialisation bool) (*p
 	if err := s.flush(initialisation); err != nil {
 		return nil, wrapErr(ModificationError, err)
 	}
+	s.appender.commitScriptExecutions()
 	// Reset in-memory storages.
 	if err := s.reset(initialisation); err != nil {
 		return nil, wrapErr(ModificationError, err)
@@ -1320,6 +1445,7 @@ func (s *stateManager) rollbackToImpl(removalEdge proto.BlockID) error {
 	if err := s.stor.scriptsStorage.clear(); err != nil {
 		return wrapErr(RollbackError, err)
 	}
+	s.appender.stats.rollback(newHeight)
 	if err := s.loadLastBlock(); err != nil {
 		return wrapErr(RetrievalError, err)
 	}
@@ -1435,6 +1561,18 @@ func (s *stateManager) AddrByAlias(alias proto.Alias) (proto.Address, error) {
 	return *addr, nil
 }
 
+func (s *stateManager) AliasesByAddr(addr proto.Address) ([]proto.Alias, error) {
+	aliases, err := s.stor.aliases.aliasesByAddr(addr, true)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	res := make([]proto.Alias, len(aliases))
+	for i, a := range aliases {
+		res[i] = *proto.NewAlias(s.settings.AddressSchemeCharacter, a)
+	}
+	return res, nil
+}
+
 func (s *stateManager) VotesNumAtHeight(featureID int16, height proto.Height) (uint64, error) {
 	votesNum, err := s.stor.features.featureVotesAtHeight(featureID, height)
 	if err != nil {
@@ -1696,6 +1834,51 @@ func (s *stateManager) AssetIsSponsored(assetID crypto.Digest) (bool, error) {
 	return sponsored, nil
 }
 
+func (s *stateManager) SponsorshipInfo(assetID crypto.Digest) (*proto.SponsorshipInfo, error) {
+	ai, err := s.AssetInfo(assetID)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	res := &proto.SponsorshipInfo{AssetID: assetID, Sponsor: ai.Issuer, Sponsored: ai.Sponsored}
+	if ai.Sponsored {
+		res.MinAssetFee, err = s.stor.sponsoredAssets.assetCost(assetID, true)
+		if err != nil {
+			return nil, wrapErr(RetrievalError, err)
+		}
+	}
+	balance, err := s.FullWavesBalance(proto.NewRecipientFromAddress(ai.Issuer))
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	res.SponsorBalance = balance.Available
+	res.CanCoverFees = ai.Sponsored && sponsorCanCoverFees(balance.Available)
+	res.SponsoredTransactions, err = s.stor.sponsoredAssets.sponsoredTxsCount(assetID, true)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	records, err := s.stor.sponsoredAssets.sponsorshipHistory(assetID, true)
+	if err != nil && err != keyvalue.ErrNotFound && err != errEmptyHist {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	res.History = make([]proto.SponsorshipHistoryEntry, len(records))
+	setsSponsorship := func(tx proto.Transaction) (bool, error) {
+		sp, ok := tx.(*proto.SponsorshipWithProofs)
+		return ok && sp.AssetID == assetID, nil
+	}
+	for i, r := range records {
+		height, err := s.BlockIDToHeight(r.blockID)
+		if err != nil {
+			return nil, wrapErr(RetrievalError, err)
+		}
+		txID, err := s.lastTransactionID(height, setsSponsorship)
+		if err != nil {
+			return nil, wrapErr(RetrievalError, err)
+		}
+		res.History[i] = proto.SponsorshipHistoryEntry{Height: height, TransactionID: txID, MinAssetFee: r.assetCost}
+	}
+	return res, nil
+}
+
 func (s *stateManager) NewestAssetInfo(assetID crypto.Digest) (*proto.AssetInfo, error) {
 	info, err := s.stor.assets.newestAssetInfo(assetID, true)
 	if err != nil {
@@ -1819,7 +2002,11 @@ func (s *stateManager) ScriptInfoByAccount(account proto.Recipient) (*proto.Scri
 		return nil, wrapErr(RetrievalError, err)
 	}
 	text := base64.StdEncoding.EncodeToString(scriptBytes)
-	complexity, err := s.stor.scriptsComplexity.scriptComplexityByAddress(*addr, true)
+	estimatorVersion, err := s.stor.features.estimatorVersion()
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	complexity, err := s.stor.scriptsComplexity.scriptComplexityByAddress(*addr, estimatorVersion, true)
 	if err != nil {
 		return nil, wrapErr(RetrievalError, err)
 	}
@@ -1842,7 +2029,11 @@ func (s *stateManager) ScriptInfoByAsset(assetID crypto.Digest) (*proto.ScriptIn
 		return nil, wrapErr(RetrievalError, err)
 	}
 	text := base64.StdEncoding.EncodeToString(scriptBytes)
-	complexity, err := s.stor.scriptsComplexity.scriptComplexityByAsset(assetID, true)
+	estimatorVersion, err := s.stor.features.estimatorVersion()
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	complexity, err := s.stor.scriptsComplexity.scriptComplexityByAsset(assetID, estimatorVersion, true)
 	if err != nil {
 		return nil, wrapErr(RetrievalError, err)
 	}
@@ -1858,6 +2049,198 @@ func (s *stateManager) ScriptInfoByAsset(assetID crypto.Digest) (*proto.ScriptIn
 	}, nil
 }
 
+// lastTransactionID looks for the last matching transaction in the block at given height.
+// The last one is taken because history storages keep the latest change made by the block.
+func (s *stateManager) lastTransactionID(height uint64, matches func(tx proto.Transaction) (bool, error)) (*crypto.Digest, error) {
+	block, err := s.BlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	var res *crypto.Digest
+	for _, tx := range block.Transactions {
+		ok, err := matches(tx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		id, err := tx.GetID(s.settings.AddressSchemeCharacter)
+		if err != nil {
+			return nil, err
+		}
+		d, err := crypto.NewDigestFromBytes(id)
+		if err != nil {
+			return nil, err
+		}
+		res = &d
+	}
+	return res, nil
+}
+
+func (s *stateManager) scriptHistory(
+	records []scriptHistoryRecord,
+	complexity func(blockID proto.BlockID) (uint64, error),
+	setsScript func(tx proto.Transaction) (bool, error),
+) ([]proto.ScriptHistoryEntry, error) {
+	res := make([]proto.ScriptHistoryEntry, len(records))
+	for i, r := range records {
+		height, err := s.BlockIDToHeight(r.blockID)
+		if err != nil {
+			return nil, err
+		}
+		version, err := proto.VersionFromScriptBytes(r.script)
+		if err != nil {
+			return nil, err
+		}
+		txID, err := s.lastTransactionID(height, setsScript)
+		if err != nil {
+			return nil, err
+		}
+		entry := proto.ScriptHistoryEntry{
+			Height:        height,
+			TransactionID: txID,
+			Version:       version,
+			Bytes:         r.script,
+			Base64:        base64.StdEncoding.EncodeToString(r.script),
+		}
+		if len(r.script) != 0 {
+			script, err := ast.BuildScript(reader.NewBytesReader(r.script))
+			if err != nil {
+				return nil, err
+			}
+			entry.Text = decompiler.Decompile(script)
+			// Complexities of scripts set before the history was kept might be missing.
+			entry.Complexity, err = complexity(r.blockID)
+			if err != nil && err != keyvalue.ErrNotFound {
+				return nil, err
+			}
+		}
+		res[i] = entry
+	}
+	return res, nil
+}
+
+// checkScriptHistoryCursor checks that the height scripts history is requested after is not above the blockchain.
+func (s *stateManager) checkScriptHistoryCursor(after uint64) error {
+	height, err := s.Height()
+	if err != nil {
+		return err
+	}
+	if after > height {
+		return wrapErr(NotFoundError, errors.Errorf("height %d is above the blockchain height %d", after, height))
+	}
+	return nil
+}
+
+func (s *stateManager) ScriptHistoryByAccount(account proto.Recipient, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	if err := s.checkScriptHistoryCursor(after); err != nil {
+		return nil, err
+	}
+	records, err := s.stor.scriptsStorage.scriptHistoryByAddr(*addr, after, limit, true)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	complexity := func(blockID proto.BlockID) (uint64, error) {
+		r, err := s.stor.scriptsComplexity.scriptComplexityByAddrAtBlock(*addr, blockID, true)
+		if err != nil {
+			return 0, err
+		}
+		res := r.verifierComplexity
+		for _, c := range r.byFuncs {
+			if c > res {
+				res = c
+			}
+		}
+		return res, nil
+	}
+	setsScript := func(tx proto.Transaction) (bool, error) {
+		ss, ok := tx.(*proto.SetScriptWithProofs)
+		if !ok {
+			return false, nil
+		}
+		sender, err := proto.NewAddressFromPublicKey(s.settings.AddressSchemeCharacter, ss.SenderPK)
+		if err != nil {
+			return false, err
+		}
+		return sender == *addr, nil
+	}
+	res, err := s.scriptHistory(records, complexity, setsScript)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return res, nil
+}
+
+func (s *stateManager) ScriptHistoryByAsset(assetID crypto.Digest, after uint64, limit int) ([]proto.ScriptHistoryEntry, error) {
+	if err := s.checkScriptHistoryCursor(after); err != nil {
+		return nil, err
+	}
+	records, err := s.stor.scriptsStorage.scriptHistoryByAsset(assetID, after, limit, true)
+	if err == keyvalue.ErrNotFound || err == errEmptyHist {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	complexity := func(blockID proto.BlockID) (uint64, error) {
+		r, err := s.stor.scriptsComplexity.scriptComplexityByAssetAtBlock(assetID, blockID, true)
+		if err != nil {
+			return 0, err
+		}
+		return r.complexity, nil
+	}
+	setsScript := func(tx proto.Transaction) (bool, error) {
+		switch t := tx.(type) {
+		case *proto.SetAssetScriptWithProofs:
+			return t.AssetID == assetID, nil
+		case *proto.IssueWithProofs:
+			// ID of asset is the ID of transaction that issued it.
+			id, err := t.GetID(s.settings.AddressSchemeCharacter)
+			if err != nil {
+				return false, err
+			}
+			return bytes.Equal(id, assetID.Bytes()), nil
+		default:
+			return false, nil
+		}
+	}
+	res, err := s.scriptHistory(records, complexity, setsScript)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return res, nil
+}
+
+func (s *stateManager) ScriptStatsByAccount(account proto.Recipient, windows []uint64) ([]proto.ScriptStats, error) {
+	addr, err := s.recipientToAddress(account)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	height, err := s.Height()
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	key := accountScriptKey{*addr}
+	return s.appender.stats.stats(key.bytes(), height, windows), nil
+}
+
+func (s *stateManager) ScriptStatsByAsset(assetID crypto.Digest, windows []uint64) ([]proto.ScriptStats, error) {
+	height, err := s.Height()
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	key := assetScriptKey{assetID}
+	return s.appender.stats.stats(key.bytes(), height, windows), nil
+}
+
 func (s *stateManager) IsActiveLeasing(leaseID crypto.Digest) (bool, error) {
 	isActive, err := s.stor.leases.isActive(leaseID, true)
 	if err != nil {
@@ -1866,6 +2249,34 @@ func (s *stateManager) IsActiveLeasing(leaseID crypto.Digest) (bool, error) {
 	return isActive, nil
 }
 
+func (s *stateManager) LeaseInfo(leaseID crypto.Digest) (*proto.LeaseInfo, error) {
+	l, err := s.stor.leases.leasingInfo(leaseID, true)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	info := l.toLeaseInfo(leaseID)
+	return &info, nil
+}
+
+func (s *stateManager) LeasesByAddr(addr proto.Address, incoming bool, status proto.LeaseStatus, after *crypto.Digest, limit int) ([]proto.LeaseInfo, error) {
+	var res []proto.LeaseInfo
+	err := s.stor.leases.leasesByAddr(addr, incoming, after, true, func(id crypto.Digest, l *leasing) (bool, error) {
+		info := l.toLeaseInfo(id)
+		if status != "" && info.Status != status {
+			return true, nil
+		}
+		res = append(res, info)
+		return limit == 0 || len(res) < limit, nil
+	})
+	if err == keyvalue.ErrNotFound {
+		return nil, wrapErr(NotFoundError, errors.Errorf("lease %s is not found among the leases of address", after.String()))
+	}
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return res, nil
+}
+
 func (s *stateManager) InvokeResultByID(invokeID crypto.Digest) (*proto.ScriptResult, error) {
 	hasData, err := s.storesExtendedApiData()
 	if err != nil {
@@ -1902,6 +2313,52 @@ func (s *stateManager) ProvidesExtendedApi() (bool, error) {
 	return s.atx.providesData(), nil
 }
 
+// saveStateHashes calculates and saves state hashes of consecutive blocks starting from the given height.
+func (s *stateManager) saveStateHashes(startHeight uint64, ids []proto.BlockID) error {
+	if !s.stor.calculateHashes || len(ids) == 0 {
+		return nil
+	}
+	var prevHash []byte
+	if startHeight > 1 {
+		prev, err := s.stor.stateHashes.newestStateHash(startHeight - 1)
+		if err != nil {
+			return errors.Errorf("failed to get state hash at height %d: %v", startHeight-1, err)
+		}
+		prevHash = prev.SumHash.Bytes()
+	}
+	for i, id := range ids {
+		sh, err := s.stor.putStateHash(prevHash, startHeight+uint64(i), id)
+		if err != nil {
+			return errors.Errorf("failed to calculate state hash of block %s: %v", id.String(), err)
+		}
+		prevHash = sh.SumHash.Bytes()
+	}
+	return nil
+}
+
+func (s *stateManager) ProvidesStateHashes() (bool, error) {
+	provides, err := s.stateDB.stateStoresHashes()
+	if err != nil {
+		return false, wrapErr(RetrievalError, err)
+	}
+	return provides, nil
+}
+
+func (s *stateManager) StateHashAtHeight(height uint64) (*proto.StateHash, error) {
+	hasData, err := s.ProvidesStateHashes()
+	if err != nil {
+		return nil, err
+	}
+	if !hasData {
+		return nil, wrapErr(IncompatibilityError, errors.New("state does not have data for state hashes"))
+	}
+	sh, err := s.stor.stateHashes.stateHash(height)
+	if err != nil {
+		return nil, wrapErr(RetrievalError, err)
+	}
+	return sh, nil
+}
+
 func (s *stateManager) IsNotFound(err error) bool {
 	return IsNotFound(err)
 }
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// stateHasher collects state changes of a single kind per block and calculates
+// the hash of them the same way the legacy state hash of Scala node does.
+type stateHasher struct {
+	blocksEntries map[proto.BlockID]map[string][]byte
+	// Values of the keys pushed with pushChange() before the block.
+	blocksInitial map[proto.BlockID]map[string][]byte
+}
+
+func newStateHasher() *stateHasher {
+	return &stateHasher{
+		blocksEntries: make(map[proto.BlockID]map[string][]byte),
+		blocksInitial: make(map[proto.BlockID]map[string][]byte),
+	}
+}
+
+// push remembers the value of the key after the block.
+// Only the last value pushed for the key in the block is taken into account.
+func (h *stateHasher) push(key, value []byte, blockID proto.BlockID) {
+	entries, ok := h.blocksEntries[blockID]
+	if !ok {
+		entries = make(map[string][]byte)
+		h.blocksEntries[blockID] = entries
+	}
+	v := make([]byte, len(value))
+	copy(v, value)
+	entries[string(key)] = v
+}
+
+// pushChange remembers the value of the key after the block along with the previous value of the key.
+// Only the previous value of the first change of the key in the block is kept, and the key is not hashed
+// if its value after the block equals its value before the block. So only the net changes of the block
+// are hashed and the intermediate values within the block don't affect the hash.
+func (h *stateHasher) pushChange(key, prev, value []byte, blockID proto.BlockID) {
+	initial, ok := h.blocksInitial[blockID]
+	if !ok {
+		initial = make(map[string][]byte)
+		h.blocksInitial[blockID] = initial
+	}
+	if _, ok := initial[string(key)]; !ok {
+		v := make([]byte, len(prev))
+		copy(v, prev)
+		initial[string(key)] = v
+	}
+	h.push(key, value, blockID)
+}
+
+// stateHashAt returns the hash of all entries of the block sorted by keys.
+// Hash of empty data is returned if there were no changes in the block.
+func (h *stateHasher) stateHashAt(blockID proto.BlockID) (crypto.Digest, error) {
+	entries := h.blocksEntries[blockID]
+	initial := h.blocksInitial[blockID]
+	keys := make([]string, 0, len(entries))
+	size := 0
+	for k, v := range entries {
+		if prev, ok := initial[k]; ok && bytes.Equal(prev, v) {
+			continue
+		}
+		keys = append(keys, k)
+		size += len(k) + len(v)
+	}
+	sort.Strings(keys)
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	for _, k := range keys {
+		buf.WriteString(k)
+		buf.Write(entries[k])
+	}
+	return crypto.FastHash(buf.Bytes())
+}
+
+func (h *stateHasher) reset() {
+	h.blocksEntries = make(map[proto.BlockID]map[string][]byte)
+	h.blocksInitial = make(map[proto.BlockID]map[string][]byte)
+}
@@ -0,0 +1,129 @@
+package state
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/settings"
+	"github.com/wavesplatform/gowaves/pkg/util/common"
+)
+
+func TestStateHasher(t *testing.T) {
+	h := newStateHasher()
+	blockID0 := genBlockId(0)
+	blockID1 := genBlockId(1)
+
+	emptyHash, err := crypto.FastHash(nil)
+	require.NoError(t, err)
+	hash, err := h.stateHashAt(blockID0)
+	require.NoError(t, err)
+	assert.Equal(t, emptyHash, hash)
+
+	value := []byte{1}
+	h.push([]byte{3}, []byte{0}, blockID0)
+	h.push([]byte{2, 5}, value, blockID0)
+	// Last value of the key in the block is used.
+	h.push([]byte{3}, []byte{4}, blockID0)
+	// Pushed data is copied.
+	value[0] = 0xff
+	h.push([]byte{0xff}, []byte{7}, blockID1)
+
+	expected, err := crypto.FastHash([]byte{2, 5, 1, 3, 4})
+	require.NoError(t, err)
+	hash, err = h.stateHashAt(blockID0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	expected, err = crypto.FastHash([]byte{0xff, 7})
+	require.NoError(t, err)
+	hash, err = h.stateHashAt(blockID1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	// Keys which values are changed back within the block are not hashed.
+	h.pushChange([]byte{1}, []byte{0}, []byte{2}, blockID1)
+	h.pushChange([]byte{1}, []byte{2}, []byte{0}, blockID1)
+	h.pushChange([]byte{2}, []byte{0}, []byte{3}, blockID1)
+	h.pushChange([]byte{2}, []byte{3}, []byte{4}, blockID1)
+	expected, err = crypto.FastHash([]byte{2, 4, 0xff, 7})
+	require.NoError(t, err)
+	hash, err = h.stateHashAt(blockID1)
+	require.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	h.reset()
+	hash, err = h.stateHashAt(blockID1)
+	require.NoError(t, err)
+	assert.Equal(t, emptyHash, hash)
+}
+
+// Sections of the state hash are built with the layout of the legacy state hash of Scala node.
+func TestStateHashSections(t *testing.T) {
+	stor, path, err := createStorageObjects()
+	require.NoError(t, err, "createStorageObjects() failed")
+
+	defer func() {
+		stor.close(t)
+
+		err = common.CleanTemporaryDirs(path)
+		assert.NoError(t, err, "failed to clean test data dirs")
+	}()
+
+	entities, err := newBlockchainEntitiesStorage(stor.hs, settings.MainNetSettings, stor.rw, true)
+	require.NoError(t, err, "newBlockchainEntitiesStorage() failed")
+	sender := testGlobal.senderInfo.addr
+	recipient := testGlobal.recipientInfo.addr
+	leaseID := crypto.MustDigestFromBase58("7cZRbgbPjNNUxTpeUa4SJMRtWxtoUQtr3uAufhfDfKQd")
+
+	stor.addBlock(t, blockID0)
+	err = entities.balances.setWavesBalance(sender, &balanceProfile{1000, 0, 300}, blockID0)
+	require.NoError(t, err)
+	err = entities.balances.setWavesBalance(recipient, &balanceProfile{0, 300, 0}, blockID0)
+	require.NoError(t, err)
+	l := &leasing{isActive: true, leaseAmount: 300, sender: sender, recipient: recipient, originHeight: 1}
+	err = entities.leases.addLeasing(leaseID, l, blockID0)
+	require.NoError(t, err)
+	err = entities.aliases.createAlias("alias", &aliasInfo{addr: recipient}, blockID0)
+	require.NoError(t, err)
+	sh, err := entities.putStateHash(nil, 1, blockID0)
+	require.NoError(t, err, "putStateHash() failed")
+
+	// Section hash is the hash of keys followed by values, sorted by keys.
+	hash := func(entries map[string][]byte) crypto.Digest {
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var data []byte
+		for _, k := range keys {
+			data = append(data, k...)
+			data = append(data, entries[k]...)
+		}
+		d, err := crypto.FastHash(data)
+		require.NoError(t, err)
+		return d
+	}
+	// Zero Waves balance of the recipient didn't change, so it's not hashed.
+	assert.Equal(t, hash(map[string][]byte{
+		string(sender.Bytes()): {0, 0, 0, 0, 0, 0, 0x03, 0xe8},
+	}), sh.WavesBalanceHash)
+	// Lease balances are lease in followed by lease out.
+	assert.Equal(t, hash(map[string][]byte{
+		string(sender.Bytes()):    {0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x01, 0x2c},
+		string(recipient.Bytes()): {0, 0, 0, 0, 0, 0, 0x01, 0x2c, 0, 0, 0, 0, 0, 0, 0, 0},
+	}), sh.LeaseBalanceHash)
+	assert.Equal(t, hash(map[string][]byte{string(leaseID.Bytes()): {1}}), sh.LeaseStatusHash)
+	assert.Equal(t, hash(map[string][]byte{string(recipient.Bytes()) + "alias": nil}), sh.AliasesHash)
+	empty := hash(nil)
+	for _, d := range []crypto.Digest{sh.AssetBalanceHash, sh.DataEntryHash, sh.AccountScriptHash, sh.AssetScriptHash, sh.SponsorshipHash} {
+		assert.Equal(t, empty, d)
+	}
+	expected := proto.StateHash{BlockID: blockID0, FieldsHashes: sh.FieldsHashes}
+	require.NoError(t, expected.GenerateSumHash(nil))
+	assert.Equal(t, expected.SumHash, sh.SumHash)
+}
@@ -0,0 +1,49 @@
+package state
+
+import (
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type stateHashes struct {
+	hs *historyStorage
+}
+
+func newStateHashes(hs *historyStorage) (*stateHashes, error) {
+	return &stateHashes{hs}, nil
+}
+
+func (s *stateHashes) saveStateHash(sh *proto.StateHash, height uint64) error {
+	key := stateHashKey{height: height}
+	shBytes, err := sh.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return s.hs.addNewEntry(stateHash, key.bytes(), shBytes, sh.BlockID)
+}
+
+func (s *stateHashes) newestStateHash(height uint64) (*proto.StateHash, error) {
+	key := stateHashKey{height: height}
+	shBytes, err := s.hs.freshLatestEntryData(key.bytes(), true)
+	if err != nil {
+		return nil, err
+	}
+	var sh proto.StateHash
+	if err := sh.UnmarshalBinary(shBytes); err != nil {
+		return nil, errors.Errorf("failed to unmarshal state hash: %v", err)
+	}
+	return &sh, nil
+}
+
+func (s *stateHashes) stateHash(height uint64) (*proto.StateHash, error) {
+	key := stateHashKey{height: height}
+	shBytes, err := s.hs.latestEntryData(key.bytes(), true)
+	if err != nil {
+		return nil, err
+	}
+	var sh proto.StateHash
+	if err := sh.UnmarshalBinary(shBytes); err != nil {
+		return nil, errors.Errorf("failed to unmarshal state hash: %v", err)
+	}
+	return &sh, nil
+}
@@ -1,16 +1,20 @@
 package state
 
 import (
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/importer"
 	"github.com/wavesplatform/gowaves/pkg/keyvalue"
 	"github.com/wavesplatform/gowaves/pkg/proto"
@@ -471,3 +475,164 @@ func TestStateManager_TopBlock(t *testing.T) {
 	assert.NoError(t, err, "newStateManager() failed")
 	assert.Equal(t, correct, manager.TopBlock())
 }
+
+func TestStateHashes(t *testing.T) {
+	blocksPath, err := blocksPath()
+	assert.NoError(t, err)
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	assert.NoError(t, err, "failed to create dir for test data")
+	params := DefaultTestingStateParams()
+	params.BuildStateHashes = true
+	manager, err := newStateManager(dataDir, params, settings.MainNetSettings)
+	assert.NoError(t, err, "newStateManager() failed")
+
+	defer func() {
+		err := os.RemoveAll(dataDir)
+		assert.NoError(t, err, "failed to remove test data dirs")
+	}()
+
+	height := proto.Height(100)
+	err = importer.ApplyFromFile(manager, blocksPath, height-1, 1, false)
+	assert.NoError(t, err, "ApplyFromFile() failed")
+
+	hashes := make([]*proto.StateHash, height)
+	var prevHash []byte
+	for h := proto.Height(1); h <= height; h++ {
+		sh, err := manager.StateHashAtHeight(h)
+		assert.NoError(t, err, "StateHashAtHeight() failed")
+		id, err := manager.HeightToBlockID(h)
+		assert.NoError(t, err)
+		assert.Equal(t, id, sh.BlockID)
+		expected := *sh
+		err = expected.GenerateSumHash(prevHash)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.SumHash, sh.SumHash)
+		prevHash = sh.SumHash.Bytes()
+		hashes[h-1] = sh
+	}
+	// Genesis transactions change Waves balances only.
+	emptyHash, err := crypto.FastHash(nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, emptyHash, hashes[0].WavesBalanceHash)
+	assert.Equal(t, emptyHash, hashes[0].AssetBalanceHash)
+	assert.Equal(t, emptyHash, hashes[0].LeaseBalanceHash)
+
+	// Hashes must be the same after rollback and applying the same blocks again.
+	err = manager.RollbackToHeight(30)
+	assert.NoError(t, err)
+	err = importer.ApplyFromFile(manager, blocksPath, height-1, 30, false)
+	assert.NoError(t, err, "ApplyFromFile() failed")
+	for h := proto.Height(1); h <= height; h++ {
+		sh, err := manager.StateHashAtHeight(h)
+		assert.NoError(t, err, "StateHashAtHeight() failed")
+		assert.Equal(t, hashes[h-1], sh)
+	}
+
+	// State with hashes can't be opened without them.
+	err = manager.Close()
+	assert.NoError(t, err, "manager.Close() failed")
+	_, err = newStateManager(dataDir, DefaultTestingStateParams(), settings.MainNetSettings)
+	assert.Error(t, err)
+}
+
+func TestInMemoryBackend(t *testing.T) {
+	blocksPath, err := blocksPath()
+	assert.NoError(t, err)
+	height := proto.Height(100)
+	stateHash := func(backend keyvalue.Backend) *proto.StateHash {
+		dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+		assert.NoError(t, err, "failed to create dir for test data")
+		defer func() {
+			err := os.RemoveAll(dataDir)
+			assert.NoError(t, err, "failed to remove test data dirs")
+		}()
+		params := DefaultTestingStateParams()
+		params.BuildStateHashes = true
+		params.DbParams.Backend = backend
+		manager, err := newStateManager(dataDir, params, settings.MainNetSettings)
+		assert.NoError(t, err, "newStateManager() failed")
+		err = importer.ApplyFromFile(manager, blocksPath, height-1, 1, false)
+		assert.NoError(t, err, "ApplyFromFile() failed")
+		sh, err := manager.StateHashAtHeight(height)
+		assert.NoError(t, err, "StateHashAtHeight() failed")
+		err = manager.Close()
+		assert.NoError(t, err, "manager.Close() failed")
+		if !backend.Persistent() {
+			// Blocks are still on disk, but the rest of state is gone.
+			_, err = newStateManager(dataDir, params, settings.MainNetSettings)
+			assert.Error(t, err, "newStateManager() did not fail with stale blocks storage")
+		}
+		return sh
+	}
+	assert.Equal(t, stateHash(keyvalue.LevelDBBackend), stateHash(keyvalue.MemoryBackend))
+}
+
+// The Waves balances hash of MainNet genesis block is rebuilt from the genesis transactions with the layout
+// of the legacy state hash of Scala node: recipients' addresses followed by big-endian balances, sorted by addresses.
+// The whole state hash is a regression vector calculated by this implementation, it's not confirmed by Scala node.
+func TestGenesisStateHash(t *testing.T) {
+	dataDir, err := ioutil.TempDir(os.TempDir(), "dataDir")
+	assert.NoError(t, err, "failed to create dir for test data")
+	params := DefaultTestingStateParams()
+	params.BuildStateHashes = true
+	manager, err := newStateManager(dataDir, params, settings.MainNetSettings)
+	assert.NoError(t, err, "newStateManager() failed")
+
+	defer func() {
+		err := manager.Close()
+		assert.NoError(t, err, "manager.Close() failed")
+		err = os.RemoveAll(dataDir)
+		assert.NoError(t, err, "failed to remove test data dirs")
+	}()
+
+	genesis := settings.MainNetSettings.Genesis
+	balances := make(map[string]uint64)
+	for _, tx := range genesis.Transactions {
+		g, ok := tx.(*proto.Genesis)
+		assert.True(t, ok, "not a genesis transaction")
+		balances[string(g.Recipient.Bytes())] += g.Amount
+	}
+	addrs := make([]string, 0, len(balances))
+	for a := range balances {
+		addrs = append(addrs, a)
+	}
+	sort.Strings(addrs)
+	var data []byte
+	for _, a := range addrs {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, balances[a])
+		data = append(data, a...)
+		data = append(data, b...)
+	}
+	wavesHash, err := crypto.FastHash(data)
+	assert.NoError(t, err)
+	emptyHash, err := crypto.FastHash(nil)
+	assert.NoError(t, err)
+
+	sh, err := manager.StateHashAtHeight(1)
+	assert.NoError(t, err, "StateHashAtHeight() failed")
+	assert.Equal(t, genesis.BlockID(), sh.BlockID)
+	assert.Equal(t, wavesHash, sh.WavesBalanceHash)
+	for _, d := range []crypto.Digest{sh.AssetBalanceHash, sh.DataEntryHash, sh.AccountScriptHash, sh.AssetScriptHash,
+		sh.LeaseBalanceHash, sh.LeaseStatusHash, sh.SponsorshipHash, sh.AliasesHash} {
+		assert.Equal(t, emptyHash, d)
+	}
+
+	// Regression vector in the JSON format of /debug/stateHash/1 of Scala node.
+	var expected proto.StateHash
+	err = json.Unmarshal([]byte(`{
+		"blockId": "FSH8eAAzZNqnG8xgTZtz5xuLqXySsXgAjmFEC25hXMbEufiGjqWPnGCZFt6gLiVLJny16ipxRNAkkzjjhqTjBE2",
+		"stateHash": "fab947262e8f5f03807ee7a888c750e46d0544a04d5777f50cc6daaf5f4e8d19",
+		"wavesBalanceHash": "211af58aa42c72d0cf546d11d7b9141a00c8394e0f5da2d8e7e9f4ba30e9ad37",
+		"assetBalanceHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"dataEntryHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"accountScriptHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"assetScriptHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"leaseBalanceHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"leaseStatusHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"sponsorshipHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		"aliasHash": "0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8"
+	}`), &expected)
+	assert.NoError(t, err)
+	assert.Equal(t, &expected, sh)
+}
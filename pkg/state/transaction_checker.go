@@ -79,26 +79,16 @@ func (tc *transactionChecker) checkScriptComplexity(script *ast.Script, complexi
 	return nil
 }
 
-func estimatorByScript(script *ast.Script, version int) *estimation.Estimator {
-	var variables map[string]ast.Expr
-	var cat *estimation.Catalogue
-	switch script.Version {
-	case 1, 2:
-		variables = ast.VariablesV2()
-		cat = estimation.NewCatalogueV2()
-	case 3:
-		variables = ast.VariablesV3()
-		cat = estimation.NewCatalogueV3()
-	}
-	return estimation.NewEstimator(version, cat, variables)
-}
-
 type scriptInfo struct {
 	complexity       estimation.Costs
 	estimatorVersion byte
 	isDApp           bool
 }
 
+func estimatorByScript(script *ast.Script, version int) *estimation.Estimator {
+	return estimation.NewEstimatorForScript(version, script)
+}
+
 func (tc *transactionChecker) checkScript(scriptBytes proto.Script, estimatorVersion int) (*scriptInfo, error) {
 	script, err := ast.BuildScript(reader.NewBytesReader(scriptBytes))
 	if err != nil {
@@ -360,13 +350,8 @@ func (tc *transactionChecker) checkIssueWithSig(transaction proto.Transaction, i
 	return nil, nil
 }
 
-func (tc *transactionChecker) estimatorVersion(info *checkerInfo) int {
-	switch info.blockVersion {
-	case 4:
-		return 2
-	default:
-		return 1
-	}
+func (tc *transactionChecker) estimatorVersion() (int, error) {
+	return tc.stor.features.newestEstimatorVersion()
 }
 
 func (tc *transactionChecker) checkIssueWithProofs(transaction proto.Transaction, info *checkerInfo) ([]crypto.Digest, error) {
@@ -385,7 +370,11 @@ func (tc *transactionChecker) checkIssueWithProofs(transaction proto.Transaction
 		// No script checks / actions are needed.
 		return nil, nil
 	}
-	scriptInf, err := tc.checkScript(tx.Script, tc.estimatorVersion(info))
+	estimatorVersion, err := tc.estimatorVersion()
+	if err != nil {
+		return nil, err
+	}
+	scriptInf, err := tc.checkScript(tx.Script, estimatorVersion)
 	if err != nil {
 		return nil, errors.Errorf("checkScript() tx %s: %v", tx.ID.String(), err)
 	}
@@ -1000,7 +989,11 @@ func (tc *transactionChecker) checkSetScriptWithProofs(transaction proto.Transac
 		// No script checks / actions are needed.
 		return nil, nil
 	}
-	scriptInf, err := tc.checkScript(tx.Script, tc.estimatorVersion(info))
+	estimatorVersion, err := tc.estimatorVersion()
+	if err != nil {
+		return nil, err
+	}
+	scriptInf, err := tc.checkScript(tx.Script, estimatorVersion)
 	if err != nil {
 		return nil, errors.Errorf("checkScript() tx %s: %v", tx.ID.String(), err)
 	}
@@ -1043,7 +1036,11 @@ func (tc *transactionChecker) checkSetAssetScriptWithProofs(transaction proto.Tr
 		// No script checks / actions are needed.
 		return nil, nil
 	}
-	scriptInf, err := tc.checkScript(tx.Script, tc.estimatorVersion(info))
+	estimatorVersion, err := tc.estimatorVersion()
+	if err != nil {
+		return nil, err
+	}
+	scriptInf, err := tc.checkScript(tx.Script, estimatorVersion)
 	if err != nil {
 		return nil, errors.Errorf("checkScript() tx %s: %v", tx.ID.String(), err)
 	}
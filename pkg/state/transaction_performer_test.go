@@ -263,10 +263,11 @@ func TestPerformLeaseWithSig(t *testing.T) {
 	assert.NoError(t, err, "performLeaseWithSig() failed")
 	to.stor.flush(t)
 	leasingInfo := &leasing{
-		isActive:    true,
-		leaseAmount: tx.Amount,
-		recipient:   *tx.Recipient.Address,
-		sender:      testGlobal.senderInfo.addr,
+		isActive:     true,
+		leaseAmount:  tx.Amount,
+		recipient:    *tx.Recipient.Address,
+		sender:       testGlobal.senderInfo.addr,
+		originHeight: 1,
 	}
 
 	info, err := to.stor.entities.leases.leasingInfo(*tx.ID, true)
@@ -290,10 +291,11 @@ func TestPerformLeaseWithProofs(t *testing.T) {
 	assert.NoError(t, err, "performLeaseWithProofs() failed")
 	to.stor.flush(t)
 	leasingInfo := &leasing{
-		isActive:    true,
-		leaseAmount: tx.Amount,
-		recipient:   *tx.Recipient.Address,
-		sender:      testGlobal.senderInfo.addr,
+		isActive:     true,
+		leaseAmount:  tx.Amount,
+		recipient:    *tx.Recipient.Address,
+		sender:       testGlobal.senderInfo.addr,
+		originHeight: 1,
 	}
 
 	info, err := to.stor.entities.leases.leasingInfo(*tx.ID, true)
@@ -316,13 +318,16 @@ func TestPerformLeaseCancelWithSig(t *testing.T) {
 	err := to.tp.performLeaseWithSig(leaseTx, defaultPerformerInfo(t))
 	assert.NoError(t, err, "performLeaseWithSig() failed")
 	to.stor.flush(t)
+	tx := createLeaseCancelWithSig(t, *leaseTx.ID)
 	leasingInfo := &leasing{
-		isActive:    false,
-		leaseAmount: leaseTx.Amount,
-		recipient:   *leaseTx.Recipient.Address,
-		sender:      testGlobal.senderInfo.addr,
+		isActive:            false,
+		leaseAmount:         leaseTx.Amount,
+		recipient:           *leaseTx.Recipient.Address,
+		sender:              testGlobal.senderInfo.addr,
+		originHeight:        1,
+		cancelHeight:        1,
+		cancelTransactionID: tx.ID,
 	}
-	tx := createLeaseCancelWithSig(t, *leaseTx.ID)
 	err = to.tp.performLeaseCancelWithSig(tx, defaultPerformerInfo(t))
 	assert.NoError(t, err, "performLeaseCancelWithSig() failed")
 	to.stor.flush(t)
@@ -346,13 +351,16 @@ func TestPerformLeaseCancelWithProofs(t *testing.T) {
 	err := to.tp.performLeaseWithProofs(leaseTx, defaultPerformerInfo(t))
 	assert.NoError(t, err, "performLeaseWithProofs() failed")
 	to.stor.flush(t)
+	tx := createLeaseCancelWithProofs(t, *leaseTx.ID)
 	leasingInfo := &leasing{
-		isActive:    false,
-		leaseAmount: leaseTx.Amount,
-		recipient:   *leaseTx.Recipient.Address,
-		sender:      testGlobal.senderInfo.addr,
+		isActive:            false,
+		leaseAmount:         leaseTx.Amount,
+		recipient:           *leaseTx.Recipient.Address,
+		sender:              testGlobal.senderInfo.addr,
+		originHeight:        1,
+		cancelHeight:        1,
+		cancelTransactionID: tx.ID,
 	}
-	tx := createLeaseCancelWithProofs(t, *leaseTx.ID)
 	err = to.tp.performLeaseCancelWithProofs(tx, defaultPerformerInfo(t))
 	assert.NoError(t, err, "performLeaseCancelWithProofs() failed")
 	to.stor.flush(t)
@@ -520,7 +528,7 @@ func TestPerformSetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err := to.stor.entities.scriptsStorage.newestScriptByAddr(addr, true)
 	assert.NoError(t, err, "newestScriptByAddr() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable before flushing.
 	accountHasScript, err = to.stor.entities.scriptsStorage.accountHasScript(addr, true)
@@ -543,7 +551,7 @@ func TestPerformSetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err = to.stor.entities.scriptsStorage.newestScriptByAddr(addr, true)
 	assert.NoError(t, err, "newestScriptByAddr() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable after flushing.
 	accountHasScript, err = to.stor.entities.scriptsStorage.accountHasScript(addr, true)
@@ -554,7 +562,7 @@ func TestPerformSetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, accountHasVerifier)
 	scriptAst, err = to.stor.entities.scriptsStorage.scriptByAddr(addr, true)
 	assert.NoError(t, err, "scriptByAddr() failed after flushing")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 }
 
 func TestPerformSetAssetScriptWithProofs(t *testing.T) {
@@ -581,7 +589,7 @@ func TestPerformSetAssetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err := to.stor.entities.scriptsStorage.newestScriptByAsset(assetID, true)
 	assert.NoError(t, err, "newestScriptByAsset() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable before flushing.
 	isSmartAsset, err = to.stor.entities.scriptsStorage.isSmartAsset(assetID, true)
@@ -598,7 +606,7 @@ func TestPerformSetAssetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.stor.entities.scriptsStorage.newestScriptByAsset(assetID, true)
 	assert.NoError(t, err, "newestScriptByAsset() failed")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test stable after flushing.
 	isSmartAsset, err = to.stor.entities.scriptsStorage.isSmartAsset(assetID, true)
@@ -606,7 +614,7 @@ func TestPerformSetAssetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.stor.entities.scriptsStorage.scriptByAsset(assetID, true)
 	assert.NoError(t, err, "scriptByAsset() failed after flushing")
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	// Test discarding script.
 	err = to.stor.entities.scriptsStorage.setAssetScript(assetID, proto.Script{}, blockID0)
@@ -625,7 +633,7 @@ func TestPerformSetAssetScriptWithProofs(t *testing.T) {
 	assert.Equal(t, true, isSmartAsset)
 	scriptAst, err = to.stor.entities.scriptsStorage.scriptByAsset(assetID, true)
 	assert.NoError(t, err)
-	assert.Equal(t, testGlobal.scriptAst, scriptAst)
+	assertScriptsEqual(t, testGlobal.scriptAst, scriptAst)
 
 	to.stor.flush(t)
 
@@ -0,0 +1,417 @@
+// Package txstatus tracks transactions from UTX pool to confirmation and notifies subscribers about changes of their statuses.
+package txstatus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/pkg/errors"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/util/lock"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxIDs is the maximum number of transaction IDs in one request or subscription.
+	MaxIDs = 1000
+
+	checkInterval = 500 * time.Millisecond
+	// NG applies microblocks by rolling back and reapplying the last block, so transactions disappear from state
+	// for a moment. Transaction is reported as rolled back only if it's missing longer than rollbackDelay.
+	rollbackDelay   = 2 * time.Second
+	evictedCapacity = 10000
+	trackedCapacity = 100000
+)
+
+type Status byte
+
+const (
+	NotFound     Status = iota
+	Unconfirmed         // Waits in UTX pool
+	Evicted             // Dropped from UTX pool as invalid
+	InMicroblock        // Included into a microblock of the liquid block, can be dropped by a microfork
+	Confirmed           // Included into a key block
+	RolledBack          // Was included into a block which was rolled back
+)
+
+func (s Status) String() string {
+	switch s {
+	case NotFound:
+		return "not_found"
+	case Unconfirmed:
+		return "unconfirmed"
+	case Evicted:
+		return "evicted"
+	case InMicroblock:
+		return "in_microblock"
+	case Confirmed:
+		return "confirmed"
+	case RolledBack:
+		return "rolled_back"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+type TransactionStatus struct {
+	ID            string         `json:"id"`
+	Status        Status         `json:"status"`
+	Height        proto.Height   `json:"height,omitempty"`
+	Confirmations uint64         `json:"confirmations"`
+	Microblock    *proto.BlockID `json:"microblock,omitempty"`
+	Reason        string         `json:"reason,omitempty"` // Validation error of evicted transaction
+}
+
+// Liquid provides the last key block and the microblocks applied on top of it, it's implemented by ng.State.
+type Liquid interface {
+	LiquidBlock() (*proto.Block, []*proto.MicroBlock, bool)
+}
+
+type stateInfo interface {
+	Height() (proto.Height, error)
+	TransactionHeightByID(id []byte) (uint64, error)
+	Mutex() *lock.RwMutex
+}
+
+type utxInfo interface {
+	ExistsByID(id []byte) bool
+}
+
+// included is the last known inclusion of transaction into the blockchain.
+type included struct {
+	status       Status
+	height       proto.Height
+	microblock   *proto.BlockID
+	missingSince time.Time
+}
+
+// Tracker determines the statuses of transactions. It remembers the transactions evicted from UTX pool
+// and the transactions that were included into blocks to report the evictions and rollbacks.
+// Tracker implements utxpool.EvictionListener.
+type Tracker struct {
+	state  stateInfo
+	utx    utxInfo
+	liquid Liquid
+	scheme proto.Scheme
+	now    func() time.Time
+
+	mu       sync.Mutex
+	evicted  *fifo
+	included *fifo
+	subs     map[*Subscription]struct{}
+}
+
+// NewTracker creates the tracker, liquid is optional.
+func NewTracker(state stateInfo, utx utxInfo, liquid Liquid, scheme proto.Scheme) *Tracker {
+	return &Tracker{
+		state:    state,
+		utx:      utx,
+		liquid:   liquid,
+		scheme:   scheme,
+		now:      time.Now,
+		evicted:  newFifo(evictedCapacity),
+		included: newFifo(trackedCapacity),
+		subs:     make(map[*Subscription]struct{}),
+	}
+}
+
+func (a *Tracker) TransactionEvicted(t proto.Transaction, reason error) {
+	id, err := t.GetID(a.scheme)
+	if err != nil {
+		zap.S().Debugf("Failed to get ID of evicted transaction: %v", err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.evicted.put(string(id), reason.Error())
+}
+
+// Statuses returns the current statuses of transactions.
+func (a *Tracker) Statuses(ids [][]byte) ([]TransactionStatus, error) {
+	if len(ids) > MaxIDs {
+		return nil, errors.Errorf("too many transaction IDs, at most %d allowed", MaxIDs)
+	}
+	snapshot, err := a.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	r := make([]TransactionStatus, len(ids))
+	for i, id := range ids {
+		r[i] = a.status(snapshot, id)
+	}
+	return r, nil
+}
+
+// snapshot is the state of blockchain the statuses are calculated for.
+type snapshot struct {
+	height proto.Height
+	liquid map[string]proto.BlockID
+}
+
+func (a *Tracker) snapshot() (snapshot, error) {
+	locked := a.state.Mutex().RLock()
+	h, err := a.state.Height()
+	locked.Unlock()
+	if err != nil {
+		return snapshot{}, err
+	}
+	s := snapshot{height: h, liquid: make(map[string]proto.BlockID)}
+	if a.liquid == nil {
+		return s, nil
+	}
+	_, micros, ok := a.liquid.LiquidBlock()
+	if !ok {
+		return s, nil
+	}
+	for _, m := range micros {
+		for _, tx := range m.Transactions {
+			id, err := tx.GetID(a.scheme)
+			if err != nil {
+				return snapshot{}, err
+			}
+			s.liquid[string(id)] = m.TotalBlockID
+		}
+	}
+	return s, nil
+}
+
+func (a *Tracker) status(s snapshot, id []byte) TransactionStatus {
+	r := TransactionStatus{ID: base58.Encode(id)}
+	key := string(id)
+	if micro, ok := s.liquid[key]; ok {
+		r.Status = InMicroblock
+		r.Height = s.height
+		r.Microblock = &micro
+		a.setIncluded(key, &included{status: InMicroblock, height: s.height, microblock: &micro})
+		return r
+	}
+	locked := a.state.Mutex().RLock()
+	h, err := a.state.TransactionHeightByID(id)
+	locked.Unlock()
+	if err == nil {
+		r.Status = Confirmed
+		r.Height = h
+		if s.height > h {
+			r.Confirmations = s.height - h
+		}
+		a.setIncluded(key, &included{status: Confirmed, height: h})
+		return r
+	}
+	if a.utx.ExistsByID(id) {
+		r.Status = Unconfirmed
+		return r
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if v, ok := a.included.get(key); ok {
+		inc := v.(*included)
+		now := a.now()
+		if inc.missingSince.IsZero() {
+			inc.missingSince = now
+		}
+		if now.Sub(inc.missingSince) < rollbackDelay {
+			// Probably NG is reapplying the block, report the last known status.
+			r.Status = inc.status
+			r.Height = inc.height
+			r.Microblock = inc.microblock
+			if inc.status == Confirmed && s.height > inc.height {
+				r.Confirmations = s.height - inc.height
+			}
+			return r
+		}
+		r.Status = RolledBack
+		r.Height = inc.height
+		return r
+	}
+	if reason, ok := a.evicted.get(key); ok {
+		r.Status = Evicted
+		r.Reason = reason.(string)
+		return r
+	}
+	r.Status = NotFound
+	return r
+}
+
+func (a *Tracker) setIncluded(key string, inc *included) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.included.put(key, inc)
+}
+
+// Subscribe creates the subscription to the statuses of transactions. The current statuses are sent right away,
+// after that the statuses are sent on every change. Subscription must be closed after use.
+func (a *Tracker) Subscribe(ids [][]byte) (*Subscription, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("no transaction IDs")
+	}
+	if len(ids) > MaxIDs {
+		return nil, errors.Errorf("too many transaction IDs, at most %d allowed", MaxIDs)
+	}
+	s := &Subscription{
+		tracker: a,
+		ids:     ids,
+		last:    make(map[string]TransactionStatus, len(ids)),
+		c:       make(chan TransactionStatus, 2*len(ids)),
+	}
+	snapshot, err := a.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	s.update(a, snapshot)
+	a.mu.Lock()
+	a.subs[s] = struct{}{}
+	a.mu.Unlock()
+	return s, nil
+}
+
+// Run checks the statuses of subscribed transactions until the context is canceled.
+func (a *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			a.closeAll()
+			return
+		case <-ticker.C:
+			a.check()
+		}
+	}
+}
+
+func (a *Tracker) check() {
+	a.mu.Lock()
+	subs := make([]*Subscription, 0, len(a.subs))
+	for s := range a.subs {
+		subs = append(subs, s)
+	}
+	a.mu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+	snapshot, err := a.snapshot()
+	if err != nil {
+		zap.S().Debugf("Failed to check transaction statuses: %v", err)
+		return
+	}
+	for _, s := range subs {
+		s.update(a, snapshot)
+	}
+}
+
+func (a *Tracker) closeAll() {
+	a.mu.Lock()
+	subs := a.subs
+	a.subs = make(map[*Subscription]struct{})
+	a.mu.Unlock()
+	for s := range subs {
+		s.close()
+	}
+}
+
+func (a *Tracker) unsubscribe(s *Subscription) {
+	a.mu.Lock()
+	delete(a.subs, s)
+	a.mu.Unlock()
+}
+
+// Subscription delivers the changes of transaction statuses.
+// The channel is closed if the subscriber doesn't keep up with the changes or if the tracker stops.
+type Subscription struct {
+	tracker *Tracker
+	ids     [][]byte
+	last    map[string]TransactionStatus
+
+	mu     sync.Mutex
+	c      chan TransactionStatus
+	closed bool
+}
+
+// C returns the channel of statuses.
+func (s *Subscription) C() <-chan TransactionStatus {
+	return s.c
+}
+
+// Close cancels the subscription.
+func (s *Subscription) Close() {
+	s.tracker.unsubscribe(s)
+	s.close()
+}
+
+func (s *Subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.c)
+	}
+}
+
+func (s *Subscription) update(a *Tracker, snapshot snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	for _, id := range s.ids {
+		st := a.status(snapshot, id)
+		if prev, ok := s.last[st.ID]; ok && equal(prev, st) {
+			continue
+		}
+		s.last[st.ID] = st
+		select {
+		case s.c <- st:
+		default:
+			zap.S().Debug("Transaction status subscriber is too slow, closing subscription")
+			s.closed = true
+			close(s.c)
+			go a.unsubscribe(s)
+			return
+		}
+	}
+}
+
+func equal(a, b TransactionStatus) bool {
+	if a.Status != b.Status || a.Height != b.Height || a.Confirmations != b.Confirmations || a.Reason != b.Reason {
+		return false
+	}
+	if a.Microblock == nil || b.Microblock == nil {
+		return a.Microblock == b.Microblock
+	}
+	return *a.Microblock == *b.Microblock
+}
+
+// fifo is the map that forgets the oldest keys when the capacity is exceeded.
+type fifo struct {
+	capacity int
+	values   map[string]interface{}
+	keys     []string
+}
+
+func newFifo(capacity int) *fifo {
+	return &fifo{capacity: capacity, values: make(map[string]interface{})}
+}
+
+func (f *fifo) put(key string, value interface{}) {
+	if _, ok := f.values[key]; !ok {
+		f.keys = append(f.keys, key)
+		if len(f.keys) > f.capacity {
+			delete(f.values, f.keys[0])
+			f.keys = f.keys[1:]
+		}
+	}
+	f.values[key] = value
+}
+
+func (f *fifo) get(key string) (interface{}, bool) {
+	v, ok := f.values[key]
+	return v, ok
+}
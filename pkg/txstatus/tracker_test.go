@@ -0,0 +1,172 @@
+package txstatus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mr-tron/base58/base58"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/util/byte_helpers"
+	"github.com/wavesplatform/gowaves/pkg/util/lock"
+)
+
+type stateStub struct {
+	mu      *lock.RwMutex
+	height  proto.Height
+	heights map[string]proto.Height
+}
+
+func (a *stateStub) Height() (proto.Height, error) {
+	return a.height, nil
+}
+
+func (a *stateStub) TransactionHeightByID(id []byte) (uint64, error) {
+	if h, ok := a.heights[string(id)]; ok {
+		return h, nil
+	}
+	return 0, errors.New("not found")
+}
+
+func (a *stateStub) Mutex() *lock.RwMutex {
+	return a.mu
+}
+
+type utxStub map[string]struct{}
+
+func (a utxStub) ExistsByID(id []byte) bool {
+	_, ok := a[string(id)]
+	return ok
+}
+
+type liquidStub struct {
+	micros []*proto.MicroBlock
+}
+
+func (a *liquidStub) LiquidBlock() (*proto.Block, []*proto.MicroBlock, bool) {
+	return &proto.Block{}, a.micros, true
+}
+
+type fixture struct {
+	state   *stateStub
+	utx     utxStub
+	liquid  *liquidStub
+	tracker *Tracker
+	now     time.Time
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		state:  &stateStub{mu: lock.NewRwMutex(&sync.RWMutex{}), height: 10, heights: make(map[string]proto.Height)},
+		utx:    make(utxStub),
+		liquid: &liquidStub{},
+		now:    time.Unix(1000, 0),
+	}
+	f.tracker = NewTracker(f.state, f.utx, f.liquid, proto.MainNetScheme)
+	f.tracker.now = func() time.Time {
+		return f.now
+	}
+	return f
+}
+
+func txID(t *testing.T, tx proto.Transaction) []byte {
+	id, err := tx.GetID(proto.MainNetScheme)
+	require.NoError(t, err)
+	return id
+}
+
+func TestTracker_Statuses(t *testing.T) {
+	f := newFixture()
+	unconfirmed := txID(t, byte_helpers.TransferWithSig.Transaction)
+	evicted := byte_helpers.IssueWithSig.Transaction
+	unknown := txID(t, byte_helpers.BurnWithSig.Transaction)
+
+	f.utx[string(unconfirmed)] = struct{}{}
+	f.tracker.TransactionEvicted(evicted, errors.New("insufficient balance"))
+
+	rs, err := f.tracker.Statuses([][]byte{unconfirmed, txID(t, evicted), unknown})
+	require.NoError(t, err)
+	assert.Equal(t, []TransactionStatus{
+		{ID: base58.Encode(unconfirmed), Status: Unconfirmed},
+		{ID: base58.Encode(txID(t, evicted)), Status: Evicted, Reason: "insufficient balance"},
+		{ID: base58.Encode(unknown), Status: NotFound},
+	}, rs)
+
+	_, err = f.tracker.Statuses(make([][]byte, MaxIDs+1))
+	assert.Error(t, err)
+}
+
+func TestTracker_RolledBack(t *testing.T) {
+	f := newFixture()
+	tx := byte_helpers.TransferWithSig.Transaction
+	id := txID(t, tx)
+	micro := &proto.MicroBlock{TotalBlockID: proto.NewBlockIDFromSignature(crypto.Signature{1}), Transactions: proto.Transactions{tx}}
+	f.liquid.micros = []*proto.MicroBlock{micro}
+
+	rs, err := f.tracker.Statuses([][]byte{id})
+	require.NoError(t, err)
+	assert.Equal(t, InMicroblock, rs[0].Status)
+
+	f.liquid.micros = nil
+	f.state.heights[string(id)] = 10
+	f.state.height = 12
+	rs, err = f.tracker.Statuses([][]byte{id})
+	require.NoError(t, err)
+	assert.Equal(t, TransactionStatus{ID: base58.Encode(id), Status: Confirmed, Height: 10, Confirmations: 2}, rs[0])
+
+	// Transaction is missing for a moment while NG reapplies the block.
+	delete(f.state.heights, string(id))
+	rs, err = f.tracker.Statuses([][]byte{id})
+	require.NoError(t, err)
+	assert.Equal(t, Confirmed, rs[0].Status)
+
+	f.now = f.now.Add(rollbackDelay)
+	rs, err = f.tracker.Statuses([][]byte{id})
+	require.NoError(t, err)
+	assert.Equal(t, TransactionStatus{ID: base58.Encode(id), Status: RolledBack, Height: 10}, rs[0])
+}
+
+func TestTracker_Subscribe(t *testing.T) {
+	f := newFixture()
+	id := txID(t, byte_helpers.TransferWithSig.Transaction)
+	f.utx[string(id)] = struct{}{}
+
+	_, err := f.tracker.Subscribe(nil)
+	assert.Error(t, err)
+
+	s, err := f.tracker.Subscribe([][]byte{id})
+	require.NoError(t, err)
+	assert.Equal(t, Unconfirmed, (<-s.C()).Status)
+
+	f.tracker.check()
+	select {
+	case st := <-s.C():
+		t.Fatalf("unexpected status %v without changes", st)
+	default:
+	}
+
+	delete(f.utx, string(id))
+	f.state.heights[string(id)] = 10
+	f.tracker.check()
+	assert.Equal(t, TransactionStatus{ID: base58.Encode(id), Status: Confirmed, Height: 10}, <-s.C())
+
+	f.state.height = 11
+	f.tracker.check()
+	assert.Equal(t, uint64(1), (<-s.C()).Confirmations)
+
+	s.Close()
+	_, ok := <-s.C()
+	assert.False(t, ok)
+	assert.Empty(t, f.tracker.subs)
+
+	s, err = f.tracker.Subscribe([][]byte{id})
+	require.NoError(t, err)
+	f.tracker.closeAll()
+	<-s.C()
+	_, ok = <-s.C()
+	assert.False(t, ok)
+}
@@ -34,6 +34,8 @@ type UtxPool interface {
 	AllTransactions() []*TransactionWithBytes
 	Count() int
 	ExistsByID(id []byte) bool
+	// Evicted reports the transaction popped from the pool that is dropped because of the reason.
+	Evicted(t proto.Transaction, reason error)
 }
 
 type TransactionWithBytes struct {